@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+var (
+	// errUnauthenticated is returned when a request that acts on behalf of a
+	// player carries no valid Nakama session token.
+	errUnauthenticated = errors.New("a valid session token is required")
+	// errNotCaller is returned when a player's session is used to act on
+	// another player's account.
+	errNotCaller = errors.New("session belongs to another player")
+)
+
+// sessionClaims holds the Nakama session token claims the API relies on.
+type sessionClaims struct {
+	UserID string `json:"uid"`
+	jwt.RegisteredClaims
+}
+
+// callerID returns the player making the request, taken from the Nakama
+// session token sent as "Authorization: Bearer <token>". Tokens are verified
+// with SessionKey, Nakama's session encryption key; without a key every
+// request is refused.
+func (s *Server) callerID(r *http.Request) (shared.PlayerID, error) {
+	if len(s.cfg.SessionKey) == 0 {
+		return "", errUnauthenticated
+	}
+	raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || raw == "" {
+		return "", errUnauthenticated
+	}
+	var claims sessionClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(*jwt.Token) (any, error) {
+		return s.cfg.SessionKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || claims.UserID == "" {
+		return "", errUnauthenticated
+	}
+	return shared.PlayerID(claims.UserID), nil
+}
+
+// requireCaller checks that the request is made by playerID.
+func (s *Server) requireCaller(r *http.Request, playerID shared.PlayerID) error {
+	caller, err := s.callerID(r)
+	if err != nil {
+		return err
+	}
+	if caller != playerID {
+		return errNotCaller
+	}
+	return nil
+}
+
+// requireAdmin checks that the request carries the operator key as
+// "Authorization: Bearer <key>". Without a configured AdminKey every request
+// is refused.
+func (s *Server) requireAdmin(r *http.Request) error {
+	if s.cfg.AdminKey == "" {
+		return errUnauthenticated
+	}
+	raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(raw), []byte(s.cfg.AdminKey)) != 1 {
+		return errUnauthenticated
+	}
+	return nil
+}
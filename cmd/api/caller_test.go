@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testSessionKey = []byte("test-session-key")
+
+// sessionToken signs a Nakama-style session token for playerID.
+func sessionToken(t *testing.T, key []byte, playerID string, expiresAt time.Time) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, sessionClaims{
+		UserID:           playerID,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(expiresAt)},
+	})
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing session token: %v", err)
+	}
+	return signed
+}
+
+// doJSONAs is doJSON with a session token for caller signed with testSessionKey.
+func doJSONAs(t *testing.T, srv *Server, caller, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("encoding request body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Authorization", "Bearer "+sessionToken(t, testSessionKey, caller, time.Now().Add(time.Hour)))
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_CallerID(t *testing.T) {
+	valid := time.Now().Add(time.Hour)
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, sessionClaims{
+		UserID:           "player-1",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(valid)},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("signing unsigned token: %v", err)
+	}
+	noExpiry, err := jwt.NewWithClaims(jwt.SigningMethodHS256, sessionClaims{UserID: "player-1"}).SignedString(testSessionKey)
+	if err != nil {
+		t.Fatalf("signing token without expiry: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		key           []byte
+		authorization string
+		want          string
+		wantErr       error
+	}{
+		{name: "valid token", key: testSessionKey, authorization: "Bearer " + sessionToken(t, testSessionKey, "player-1", valid), want: "player-1"},
+		{name: "no session key", authorization: "Bearer " + sessionToken(t, testSessionKey, "player-1", valid), wantErr: errUnauthenticated},
+		{name: "no header", key: testSessionKey, wantErr: errUnauthenticated},
+		{name: "not a bearer token", key: testSessionKey, authorization: "Basic cGxheWVyLTE6", wantErr: errUnauthenticated},
+		{name: "wrong key", key: testSessionKey, authorization: "Bearer " + sessionToken(t, []byte("other-key"), "player-1", valid), wantErr: errUnauthenticated},
+		{name: "expired", key: testSessionKey, authorization: "Bearer " + sessionToken(t, testSessionKey, "player-1", time.Now().Add(-time.Minute)), wantErr: errUnauthenticated},
+		{name: "no expiry", key: testSessionKey, authorization: "Bearer " + noExpiry, wantErr: errUnauthenticated},
+		{name: "no user id", key: testSessionKey, authorization: "Bearer " + sessionToken(t, testSessionKey, "", valid), wantErr: errUnauthenticated},
+		{name: "unsigned", key: testSessionKey, authorization: "Bearer " + unsigned, wantErr: errUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(ServerConfig{SessionKey: tt.key})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			got, err := srv.callerID(req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("callerID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("callerID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultCompressionMinSize is the smallest body worth compressing when no
+// threshold is configured; below it gzip framing outweighs the savings.
+const defaultCompressionMinSize = 1024
+
+// compressionMiddleware gzips responses for clients that accept it. Bodies
+// are buffered until they reach the minimum size, so small responses are
+// sent unchanged.
+func (s *Server) compressionMiddleware(next http.Handler) http.Handler {
+	minSize := s.cfg.CompressionMinSize
+	if minSize <= 0 {
+		minSize = defaultCompressionMinSize
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.cfg.Compression {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w, status: http.StatusOK, minSize: minSize}
+		// Not deferred: after a panic the held response is dropped so the
+		// recovery middleware can still send its 500.
+		next.ServeHTTP(gw, r)
+		gw.finish()
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding value allows gzip. q=0 is a
+// refusal, and an explicit gzip entry takes precedence over a wildcard.
+func acceptsGzip(header string) bool {
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		allowed := true
+		if q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				allowed = false
+			}
+		}
+		switch coding {
+		case "gzip":
+			return allowed
+		case "*":
+			wildcard = allowed
+		}
+	}
+	return wildcard
+}
+
+// gzipResponseWriter holds back the status and body until it knows whether
+// the response is large enough to compress.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	minSize     int
+	buf         bytes.Buffer
+	gz          *gzip.Writer
+	wroteHeader bool
+	committed   bool
+}
+
+func (g *gzipResponseWriter) WriteHeader(code int) {
+	if g.wroteHeader {
+		return
+	}
+	g.wroteHeader = true
+	g.status = code
+}
+
+func (g *gzipResponseWriter) Write(p []byte) (int, error) {
+	g.wroteHeader = true
+	if g.committed {
+		if g.gz != nil {
+			return g.gz.Write(p)
+		}
+		return g.ResponseWriter.Write(p)
+	}
+	g.buf.Write(p)
+	if g.buf.Len() >= g.minSize {
+		if err := g.commit(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// commit sends the held status and buffered body, compressed or not.
+func (g *gzipResponseWriter) commit(compress bool) error {
+	g.committed = true
+	header := g.Header()
+	if compress && header.Get("Content-Encoding") == "" {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		g.ResponseWriter.WriteHeader(g.status)
+		g.gz = gzip.NewWriter(g.ResponseWriter)
+		_, err := g.gz.Write(g.buf.Bytes())
+		return err
+	}
+	g.ResponseWriter.WriteHeader(g.status)
+	_, err := g.ResponseWriter.Write(g.buf.Bytes())
+	return err
+}
+
+// finish flushes whatever the handler left behind.
+func (g *gzipResponseWriter) finish() {
+	if !g.committed {
+		_ = g.commit(false)
+		return
+	}
+	if g.gz != nil {
+		_ = g.gz.Close()
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	large := strings.Repeat(`{"player_id":"player-1","score":100},`, 50)
+	small := `{"ok":true}`
+
+	tests := []struct {
+		name           string
+		disabled       bool
+		acceptEncoding string
+		body           string
+		wantGzip       bool
+	}{
+		{name: "large body compressed", acceptEncoding: "gzip, deflate", body: large, wantGzip: true},
+		{name: "small body passes through", acceptEncoding: "gzip", body: small},
+		{name: "client without gzip", acceptEncoding: "br", body: large},
+		{name: "gzip refused with q=0", acceptEncoding: "gzip;q=0, br", body: large},
+		{name: "wildcard accepted", acceptEncoding: "*", body: large, wantGzip: true},
+		{name: "compression disabled", disabled: true, acceptEncoding: "gzip", body: large},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(ServerConfig{Compression: !tt.disabled, CompressionMinSize: 256})
+			handler := srv.compressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				// Written in two parts so the threshold is crossed mid-body.
+				half := len(tt.body) / 2
+				_, _ = io.WriteString(w, tt.body[:half])
+				_, _ = io.WriteString(w, tt.body[half:])
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/tournaments", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusCreated {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+			}
+			gotGzip := rec.Header().Get("Content-Encoding") == "gzip"
+			if gotGzip != tt.wantGzip {
+				t.Fatalf("gzip = %v, want %v", gotGzip, tt.wantGzip)
+			}
+			body := rec.Body.String()
+			if gotGzip {
+				reader, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() error = %v", err)
+				}
+				decoded, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatalf("reading gzip body: %v", err)
+				}
+				body = string(decoded)
+			}
+			if body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestCompressionMiddleware_StatusRecorded(t *testing.T) {
+	srv := newTestServer(ServerConfig{Compression: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/battles/missing/lobby", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code == http.StatusOK {
+		t.Fatalf("Expected the handler's error status to survive compression, got %d", rec.Code)
+	}
+	if rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Expected small error body to be sent uncompressed")
+	}
+	if vary := rec.Header().Get("Vary"); vary != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", vary)
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                  false,
+		"gzip":              true,
+		"GZIP":              true,
+		"deflate, gzip":     true,
+		"gzip;q=0.5":        true,
+		"gzip; q=0":         false,
+		"identity":          false,
+		"br;q=1, *;q=0.1":   true,
+		"gzip;q=0, *;q=0.5": false,
+	}
+	for header, want := range tests {
+		if got := acceptsGzip(header); got != want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
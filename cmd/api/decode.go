@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// unknownFieldPrefix starts the error encoding/json returns for an undeclared field.
+const unknownFieldPrefix = "json: unknown field "
+
+// defaultMaxBodyBytes bounds request bodies when no limit is configured.
+const defaultMaxBodyBytes = 1 << 20
+
+// errBodyTooLarge is returned for a request body over MaxBodyBytes.
+var errBodyTooLarge = errors.New("request body too large")
+
+// decodeOptions controls how decodeJSON reads a request body.
+type decodeOptions struct {
+	maxBytes int64
+	lenient  bool
+}
+
+// decodeOptions returns the body limit and strictness configured for s.
+func (s *Server) decodeOptions() decodeOptions {
+	return decodeOptions{maxBytes: s.cfg.MaxBodyBytes, lenient: s.cfg.LenientJSON}
+}
+
+// decodeJSON reads a single JSON value from the request body into dst. Bodies
+// over opts.maxBytes are rejected without being read in full, and fields that
+// T does not declare are rejected unless opts.lenient is set. Errors are
+// worded for the client rather than with encoding/json's byte offsets.
+func decodeJSON[T any](w http.ResponseWriter, r *http.Request, dst *T, opts decodeOptions) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, opts.maxBytes))
+	if !opts.lenient {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	var trailing json.RawMessage
+	if err := dec.Decode(&trailing); err != io.EOF {
+		if err != nil {
+			return decodeError(err)
+		}
+		return errors.New("request body must contain a single JSON value")
+	}
+	return nil
+}
+
+// decodeError translates an encoding/json or body read error.
+func decodeError(err error) error {
+	var (
+		tooLarge  *http.MaxBytesError
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &tooLarge):
+		return fmt.Errorf("%w: limit is %d bytes", errBodyTooLarge, tooLarge.Limit)
+	case errors.Is(err, io.EOF):
+		return errors.New("request body is required")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("request body is not valid JSON")
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return shared.NewValidationError(typeErr.Field, fmt.Sprintf("%s must be %s", typeErr.Field, jsonTypeName(typeErr.Type)))
+	}
+	if field, ok := unknownJSONField(err); ok {
+		return shared.NewValidationError(field, fmt.Sprintf("unknown field %q in request body", field))
+	}
+	return err
+}
+
+// jsonTypeName describes the JSON value expected for a Go type.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// unknownJSONField extracts the field name from a DisallowUnknownFields error.
+// encoding/json has no typed error for this case, so the message is parsed.
+func unknownJSONField(err error) (string, bool) {
+	msg := err.Error()
+	if !strings.HasPrefix(msg, unknownFieldPrefix) {
+		return "", false
+	}
+	field, unquoteErr := strconv.Unquote(strings.TrimPrefix(msg, unknownFieldPrefix))
+	if unquoteErr != nil {
+		return "", false
+	}
+	return field, true
+}
+
+// decodeBase64Field decodes a base64 request field, reporting malformed or
+// missing values against field instead of with encoding/json's byte offsets.
+func decodeBase64Field(field, value string, required bool) ([]byte, error) {
+	if value == "" {
+		if required {
+			return nil, shared.NewValidationError(field, field+" is required")
+		}
+		return nil, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, shared.NewValidationError(field, field+" must be valid base64")
+	}
+	return decoded, nil
+}
+
+// decodeTimeField parses an optional RFC3339 request field, returning nil when
+// it is empty.
+func decodeTimeField(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, shared.NewValidationError(field, field+" must be an RFC3339 timestamp")
+	}
+	return &parsed, nil
+}
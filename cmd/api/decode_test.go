@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/src/app/analytics"
+	analyticsinfra "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+)
+
+func TestDecodeJSON_UnknownFields(t *testing.T) {
+	body := map[string]any{
+		"events": []map[string]any{{"user_id": "player-1", "name": "level_complete"}},
+		"evnts":  []any{},
+	}
+
+	tests := []struct {
+		name       string
+		lenient    bool
+		wantStatus int
+		wantField  string
+	}{
+		{name: "lenient ignores unknown field", lenient: true, wantStatus: http.StatusAccepted},
+		{name: "strict by default rejects unknown field", wantStatus: http.StatusBadRequest, wantField: "evnts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := analytics.NewService(&recordingDispatcher{}, analyticsinfra.NewMemorySessionRepository())
+			srv := newTestServer(ServerConfig{AnalyticsService: service, LenientJSON: tt.lenient})
+
+			rec := doJSON(t, srv, http.MethodPost, "/v1/analytics/events", body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantField == "" {
+				return
+			}
+			var resp errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if resp.Field != tt.wantField {
+				t.Errorf("Expected field %q, got %q", tt.wantField, resp.Field)
+			}
+			if resp.Error != `unknown field "evnts" in request body` {
+				t.Errorf("Unexpected error message %q", resp.Error)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON_StrictAcceptsKnownFields(t *testing.T) {
+	service := analytics.NewService(&recordingDispatcher{}, analyticsinfra.NewMemorySessionRepository())
+	srv := newTestServer(ServerConfig{AnalyticsService: service})
+
+	rec := doJSON(t, srv, http.MethodPost, "/v1/analytics/events", TrackEventsRequest{
+		Events: []TrackEventRequest{{UserID: "player-1", Name: "level_complete"}},
+	})
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusAccepted, rec.Code, rec.Body.String())
+	}
+}
+
+func TestDecodeJSON_RejectsBadBodies(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantError string
+		wantField string
+		wantCode  string
+	}{
+		{
+			name:      "oversize body",
+			body:      `{"events":[{"user_id":"` + strings.Repeat("x", 256) + `","name":"level_complete"}]}`,
+			wantError: "request body too large: limit is 128 bytes",
+			wantCode:  "body_too_large",
+		},
+		{name: "empty body", body: "", wantError: "request body is required"},
+		{name: "malformed JSON", body: `{"events":[`, wantError: "request body is not valid JSON"},
+		{name: "trailing data", body: `{"events":[]} {"events":[]}`, wantError: "request body must contain a single JSON value"},
+		{name: "wrong type", body: `{"events":"level_complete"}`, wantError: "events must be an array", wantField: "events"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := analytics.NewService(&recordingDispatcher{}, analyticsinfra.NewMemorySessionRepository())
+			srv := newTestServer(ServerConfig{AnalyticsService: service, MaxBodyBytes: 128})
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/analytics/events", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+			}
+			var resp errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if resp.Error != tt.wantError || resp.Field != tt.wantField || resp.Code != tt.wantCode {
+				t.Errorf("Expected error %q field %q code %q, got %+v", tt.wantError, tt.wantField, tt.wantCode, resp)
+			}
+		})
+	}
+}
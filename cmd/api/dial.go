@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Defaults for the Nakama dial budget. Together they allow roughly a minute
+// for Nakama to come up during an orchestrated start.
+const (
+	DefaultDialAttempts       = 10
+	DefaultDialTimeout        = 5 * time.Second
+	DefaultDialInitialBackoff = 500 * time.Millisecond
+	DefaultDialMaxBackoff     = 10 * time.Second
+)
+
+// DialRetryConfig bounds how long the API waits for Nakama on boot.
+type DialRetryConfig struct {
+	// Attempts is the number of dials made before giving up.
+	Attempts int
+	// Timeout caps each individual dial attempt.
+	Timeout time.Duration
+	// InitialBackoff is the wait after the first failure; it doubles after
+	// each further failure up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// dialFunc opens a connection, giving up when ctx is done.
+type dialFunc func(ctx context.Context) (*grpc.ClientConn, error)
+
+// nakamaDialer returns a dialFunc that blocks until the connection is ready,
+// so an unreachable Nakama surfaces as an error rather than a lazy failure
+// on the first RPC.
+func nakamaDialer(address string) dialFunc {
+	return func(ctx context.Context) (*grpc.ClientConn, error) {
+		return grpc.DialContext(ctx, address,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithBlock(),
+		)
+	}
+}
+
+// dialWithRetry calls dial until it succeeds, the attempts are exhausted, or
+// ctx is cancelled, backing off exponentially between attempts.
+func dialWithRetry(ctx context.Context, cfg DialRetryConfig, dial dialFunc, logger *zap.Logger) (*grpc.ClientConn, error) {
+	attempts := cfg.Attempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	backoff := cfg.InitialBackoff
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		conn, err := dialOnce(ctx, cfg.Timeout, dial)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if attempt == attempts {
+			break
+		}
+		logger.Warn("nakama dial failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", attempts),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("dialing nakama: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if cfg.MaxBackoff > 0 && backoff > cfg.MaxBackoff {
+			backoff = cfg.MaxBackoff
+		}
+	}
+	return nil, fmt.Errorf("dialing nakama after %d attempts: %w", attempts, lastErr)
+}
+
+func dialOnce(ctx context.Context, timeout time.Duration, dial dialFunc) (*grpc.ClientConn, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return dial(ctx)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+type stubDialer struct {
+	failures int
+	calls    int
+	deadline bool
+}
+
+func (d *stubDialer) dial(ctx context.Context) (*grpc.ClientConn, error) {
+	d.calls++
+	_, d.deadline = ctx.Deadline()
+	if d.calls <= d.failures {
+		return nil, errors.New("connection refused")
+	}
+	return &grpc.ClientConn{}, nil
+}
+
+func TestDialWithRetry(t *testing.T) {
+	cfg := DialRetryConfig{Attempts: 3, Timeout: time.Second, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+	tests := []struct {
+		name      string
+		failures  int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "first attempt", failures: 0, wantCalls: 1},
+		{name: "succeeds after retries", failures: 2, wantCalls: 3},
+		{name: "budget exhausted", failures: 5, wantCalls: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialer := &stubDialer{failures: tt.failures}
+			conn, err := dialWithRetry(context.Background(), cfg, dialer.dial, zap.NewNop())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dialWithRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && conn == nil {
+				t.Error("expected a connection")
+			}
+			if dialer.calls != tt.wantCalls {
+				t.Errorf("dial calls = %d, want %d", dialer.calls, tt.wantCalls)
+			}
+			if !dialer.deadline {
+				t.Error("expected each attempt to carry a deadline")
+			}
+		})
+	}
+}
+
+func TestDialWithRetry_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dialer := &stubDialer{failures: 10}
+	cfg := DialRetryConfig{Attempts: 5, InitialBackoff: time.Hour}
+
+	if _, err := dialWithRetry(ctx, cfg, dialer.dial, zap.NewNop()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("dialWithRetry() error = %v, want %v", err, context.Canceled)
+	}
+	if dialer.calls != 1 {
+		t.Errorf("dial calls = %d, want 1", dialer.calls)
+	}
+}
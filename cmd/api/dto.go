@@ -0,0 +1,20 @@
+package main
+
+import "time"
+
+// API responses use snake_case field names, RFC3339 UTC timestamps, and omit
+// optional fields that carry no value.
+
+// formatTime renders a timestamp for an API response.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatOptionalTime renders an optional timestamp, returning nil when it is unset.
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	formatted := formatTime(*t)
+	return &formatted
+}
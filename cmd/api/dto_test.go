@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+)
+
+// jsonShape marshals v and returns its top-level fields.
+func jsonShape(t *testing.T, v any) map[string]any {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var shape map[string]any
+	if err := json.Unmarshal(raw, &shape); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	return shape
+}
+
+func TestFormatTime(t *testing.T) {
+	local := time.Date(2024, 3, 1, 19, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+	if got := formatTime(local); got != "2024-03-01T12:00:00Z" {
+		t.Errorf("formatTime() = %q, want UTC RFC3339", got)
+	}
+	if got := formatOptionalTime(nil); got != nil {
+		t.Errorf("formatOptionalTime(nil) = %q, want nil", *got)
+	}
+	if got := formatOptionalTime(&time.Time{}); got != nil {
+		t.Errorf("formatOptionalTime(zero) = %q, want nil", *got)
+	}
+}
+
+func TestTournamentResponse_Shape(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tr, err := tournament.NewTournament(
+		"weekly", "Weekly Cup", "", 1,
+		tournament.SortOrderDescending, tournament.OperatorBest,
+		"", true, false, 0, 0, now, 0, now,
+	)
+	if err != nil {
+		t.Fatalf("NewTournament() error = %v", err)
+	}
+
+	shape := jsonShape(t, newTournamentResponse(tr, now))
+	for _, key := range []string{"tournament_id", "title", "sort_order", "max_size", "state", "status", "time_remaining_seconds", "version", "start_time", "created_at", "updated_at"} {
+		if _, ok := shape[key]; !ok {
+			t.Errorf("Expected field %q in %v", key, shape)
+		}
+	}
+	for _, key := range []string{"description", "reset_schedule", "end_time"} {
+		if _, ok := shape[key]; ok {
+			t.Errorf("Expected empty field %q to be omitted", key)
+		}
+	}
+	if shape["start_time"] != "2024-03-01T12:00:00Z" {
+		t.Errorf("Expected RFC3339 start_time, got %v", shape["start_time"])
+	}
+
+	ended := now.Add(time.Hour)
+	tr.EndTime = &ended
+	if got := jsonShape(t, newTournamentResponse(tr, now))["end_time"]; got != "2024-03-01T13:00:00Z" {
+		t.Errorf("Expected end_time to be present once set, got %v", got)
+	}
+}
+
+func TestAuthLoginResponse_Shape(t *testing.T) {
+	shape := jsonShape(t, AuthLoginResponse{UserID: "player-1", SessionToken: "token"})
+	if len(shape) != 2 || shape["user_id"] != "player-1" || shape["session_token"] != "token" {
+		t.Errorf("Unexpected auth response shape %v", shape)
+	}
+
+	expiresAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	shape = jsonShape(t, AuthLoginResponse{UserID: "player-1", SessionToken: "token", ExpiresAt: formatOptionalTime(&expiresAt)})
+	if shape["expires_at"] != "2024-01-01T12:00:00Z" {
+		t.Errorf("expires_at = %v, want 2024-01-01T12:00:00Z", shape["expires_at"])
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/heroiclabs/nakama/v3/src/app/battles"
+	"github.com/heroiclabs/nakama/v3/src/app/tournaments"
+	domainanalytics "github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	domainbot "github.com/heroiclabs/nakama/v3/src/domain/bot"
+	"github.com/heroiclabs/nakama/v3/src/domain/group"
+	"github.com/heroiclabs/nakama/v3/src/domain/leaderboard"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+)
+
+var (
+	notFoundErrors = []error{
+		shared.ErrNotFound,
+		tournament.ErrTournamentNotFound,
+		tournament.ErrParticipantNotFound,
+		tournament.ErrSnapshotNotFound,
+		domainanalytics.ErrSessionNotFound,
+		group.ErrMemberNotFound,
+		leaderboard.ErrSubmissionNotFound,
+		leaderboard.ErrScoreNotFound,
+		leaderboard.ErrSeasonNotFound,
+		battle.ErrPlayerNotFound,
+	}
+	conflictErrors = []error{
+		shared.ErrConflict,
+		shared.ErrDuplicate,
+		tournament.ErrTournamentAlreadyExists,
+		tournament.ErrParticipantAlreadyJoined,
+		tournament.ErrConcurrentModification,
+		group.ErrAlreadyMember,
+		group.ErrGroupFull,
+		group.ErrOwnerCannotLeave,
+		leaderboard.ErrSubmissionAlreadyReserved,
+		leaderboard.ErrSeasonAlreadyExists,
+		leaderboard.ErrSeasonInactive,
+		player.ErrLastDevice,
+		battle.ErrPlayerAlreadyJoined,
+		battle.ErrBattleCancelled,
+		battle.ErrBattleFinished,
+		battle.ErrConcurrentModification,
+		battle.ErrBattleFull,
+		group.ErrConcurrentModification,
+		domainanalytics.ErrTooManySessions,
+	}
+	unauthorizedErrors = []error{
+		errUnauthenticated,
+	}
+	forbiddenErrors = []error{
+		errNotCaller,
+		group.ErrNotManager,
+	}
+	rateLimitErrors = []error{
+		shared.ErrRateLimited,
+	}
+	unavailableErrors = []error{
+		tournaments.ErrSnapshotsNotConfigured,
+		leaderboard.ErrScoresUnavailable,
+	}
+	internalErrors = []error{
+		tournaments.ErrStorageFailed,
+	}
+	upstreamErrors = []error{
+		domainanalytics.ErrDispatchFailed,
+		domainbot.ErrExecutionFailed,
+	}
+	// errorCodes gives clients a stable code for errors they are expected to
+	// branch on. The first match wins, so specific errors precede the ones they wrap.
+	errorCodes = []struct {
+		err  error
+		code string
+	}{
+		{domainbot.ErrCommandInFlight, "command_in_flight"},
+		{shared.ErrDuplicate, "duplicate"},
+		{shared.ErrContentRejected, "content_rejected"},
+		{player.ErrLastDevice, "last_device"},
+		{domainanalytics.ErrTooManySessions, "too_many_sessions"},
+		{battle.ErrBattleFull, "battle_full"},
+		{group.ErrGroupFull, "group_full"},
+		{battles.ErrUnknownPreset, "unknown_preset"},
+		{leaderboard.ErrSeasonInactive, "season_inactive"},
+		{group.ErrOwnerCannotLeave, "owner_must_transfer"},
+		{domainbot.ErrSyncUnsupported, "sync_unsupported"},
+		{errBodyTooLarge, "body_too_large"},
+	}
+)
+
+// statusForError classifies a service error into an HTTP status code,
+// falling back to 400 for validation and unrecognized domain errors.
+func statusForError(err error) int {
+	switch {
+	case isAny(err, notFoundErrors):
+		return http.StatusNotFound
+	case isAny(err, conflictErrors):
+		return http.StatusConflict
+	case isAny(err, unauthorizedErrors):
+		return http.StatusUnauthorized
+	case isAny(err, forbiddenErrors):
+		return http.StatusForbidden
+	case isAny(err, rateLimitErrors):
+		return http.StatusTooManyRequests
+	case isAny(err, unavailableErrors):
+		return http.StatusServiceUnavailable
+	case isAny(err, upstreamErrors):
+		return http.StatusBadGateway
+	case isAny(err, internalErrors):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// codeForError returns the machine-readable code for err, or an empty string.
+func codeForError(err error) string {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return ""
+}
+
+func isAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
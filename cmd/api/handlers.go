@@ -1,15 +1,26 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/heroiclabs/nakama/v3/src/app/analytics"
+	"github.com/heroiclabs/nakama/v3/src/app/auth"
 	"github.com/heroiclabs/nakama/v3/src/app/battles"
 	"github.com/heroiclabs/nakama/v3/src/app/bot"
 	"github.com/heroiclabs/nakama/v3/src/app/groups"
 	leaderboardsvc "github.com/heroiclabs/nakama/v3/src/app/leaderboard"
+	"github.com/heroiclabs/nakama/v3/src/app/tournaments"
+	domainanalytics "github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	domainbot "github.com/heroiclabs/nakama/v3/src/domain/bot"
+	"github.com/heroiclabs/nakama/v3/src/domain/leaderboard"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
 )
 
 type AuthLoginRequest struct {
@@ -18,47 +29,98 @@ type AuthLoginRequest struct {
 	Username string            `json:"username"`
 	Email    string            `json:"email"`
 	Password string            `json:"password"`
+	Token    string            `json:"token"`
 	Vars     map[string]string `json:"vars"`
 }
 
 type AuthLoginResponse struct {
-	UserID       string `json:"user_id"`
-	SessionToken string `json:"session_token"`
-	RefreshToken string `json:"refresh_token"`
-	Username     string `json:"username"`
+	UserID       string  `json:"user_id"`
+	SessionToken string  `json:"session_token"`
+	RefreshToken string  `json:"refresh_token,omitempty"`
+	Username     string  `json:"username,omitempty"`
+	ExpiresAt    *string `json:"expires_at,omitempty"`
 }
 
 func (s *Server) handleAuthLogin(w http.ResponseWriter, r *http.Request) {
 	var req AuthLoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
 		s.writeError(w, http.StatusBadRequest, err)
 		return
 	}
-	if req.Strategy == "device" {
-		result, err := s.cfg.AuthService.AuthenticateDevice(r.Context(), req.DeviceID, req.Username, req.Vars)
-		if err != nil {
-			s.writeError(w, http.StatusUnauthorized, err)
-			return
-		}
-		s.writeJSON(w, http.StatusOK, AuthLoginResponse{
-			UserID:       string(result.UserID),
-			SessionToken: result.SessionToken,
-			RefreshToken: result.RefreshToken,
-			Username:     result.Username,
-		})
-		return
+	var result auth.AuthResult
+	var err error
+	switch req.Strategy {
+	case auth.StrategyDevice:
+		result, err = s.cfg.AuthService.AuthenticateDevice(r.Context(), req.DeviceID, req.Username, req.Vars)
+	case auth.StrategyCustom:
+		result, err = s.cfg.AuthService.AuthenticateCustom(r.Context(), req.Token, req.Vars)
+	default:
+		result, err = s.cfg.AuthService.AuthenticateEmail(r.Context(), req.Email, req.Password, req.Vars)
 	}
-	result, err := s.cfg.AuthService.AuthenticateEmail(r.Context(), req.Email, req.Password, req.Vars)
 	if err != nil {
-		s.writeError(w, http.StatusUnauthorized, err)
+		s.writeError(w, loginErrorStatus(err), err)
 		return
 	}
-	s.writeJSON(w, http.StatusOK, AuthLoginResponse{
+	s.writeJSON(w, http.StatusOK, newAuthLoginResponse(result))
+}
+
+func newAuthLoginResponse(result auth.AuthResult) AuthLoginResponse {
+	return AuthLoginResponse{
 		UserID:       string(result.UserID),
 		SessionToken: result.SessionToken,
 		RefreshToken: result.RefreshToken,
 		Username:     result.Username,
-	})
+		ExpiresAt:    formatOptionalTime(&result.ExpiresAt),
+	}
+}
+
+// loginErrorStatus maps a login failure to 400 for bad input, 403 for
+// suspended or banned accounts, and 401 otherwise.
+func loginErrorStatus(err error) int {
+	if _, ok := shared.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, player.ErrAccountSuspended) || errors.Is(err, player.ErrAccountBanned) {
+		return http.StatusForbidden
+	}
+	return http.StatusUnauthorized
+}
+
+type AuthRefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (s *Server) handleAuthRefresh(w http.ResponseWriter, r *http.Request) {
+	var req AuthRefreshRequest
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	result, err := s.cfg.AuthService.RefreshSession(r.Context(), req.RefreshToken)
+	if err != nil {
+		s.writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, newAuthLoginResponse(result))
+}
+
+func (s *Server) handleRemoveDevice(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playerID := shared.PlayerID(vars["id"])
+	if err := s.requireCaller(r, playerID); err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	err := s.cfg.AuthService.RemoveDevice(r.Context(), playerID, vars["deviceId"])
+	if errors.Is(err, player.ErrDeviceInvalid) {
+		s.writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
 type CreateGroupRequest struct {
@@ -68,6 +130,7 @@ type CreateGroupRequest struct {
 	Open        bool   `json:"open"`
 	AvatarURL   string `json:"avatar_url"`
 	LangTag     string `json:"lang_tag"`
+	MaxMembers  int    `json:"max_members"`
 }
 
 type CreateGroupResponse struct {
@@ -77,7 +140,7 @@ type CreateGroupResponse struct {
 
 func (s *Server) handleCreateGroup(w http.ResponseWriter, r *http.Request) {
 	var req CreateGroupRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
 		s.writeError(w, http.StatusBadRequest, err)
 		return
 	}
@@ -88,6 +151,7 @@ func (s *Server) handleCreateGroup(w http.ResponseWriter, r *http.Request) {
 		Open:        req.Open,
 		AvatarURL:   req.AvatarURL,
 		LangTag:     req.LangTag,
+		MaxMembers:  req.MaxMembers,
 	})
 	if err != nil {
 		s.writeError(w, http.StatusBadRequest, err)
@@ -96,6 +160,66 @@ func (s *Server) handleCreateGroup(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusCreated, CreateGroupResponse{GroupID: string(out.GroupID), Handle: out.Handle})
 }
 
+type GroupMemberResponse struct {
+	PlayerID string `json:"player_id"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joined_at"`
+}
+
+func (s *Server) handleJoinGroup(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.callerID(r)
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	member, err := s.cfg.GroupService.JoinGroup(r.Context(), shared.GroupID(mux.Vars(r)["group"]), caller)
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, GroupMemberResponse{
+		PlayerID: string(member.PlayerID),
+		Role:     string(member.Role),
+		JoinedAt: formatTime(member.JoinedAt),
+	})
+}
+
+type UpdateGroupMetadataRequest struct {
+	Metadata map[string]any `json:"metadata"`
+}
+
+func (s *Server) handleUpdateGroupMetadata(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.callerID(r)
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	var req UpdateGroupMetadataRequest
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.cfg.GroupService.UpdateMetadata(r.Context(), shared.GroupID(mux.Vars(r)["group"]), caller, req.Metadata); err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleLeaveGroup(w http.ResponseWriter, r *http.Request) {
+	caller, err := s.callerID(r)
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	vars := mux.Vars(r)
+	if err := s.cfg.GroupService.LeaveGroup(r.Context(), shared.GroupID(vars["group"]), caller, shared.PlayerID(vars["player"])); err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 type StartBattleRequest struct {
 	LeaderID       string         `json:"leader_id"`
 	IdempotencyKey string         `json:"idempotency_key"`
@@ -110,7 +234,7 @@ type StartBattleResponse struct {
 
 func (s *Server) handleStartBattle(w http.ResponseWriter, r *http.Request) {
 	var req StartBattleRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
 		s.writeError(w, http.StatusBadRequest, err)
 		return
 	}
@@ -127,16 +251,112 @@ func (s *Server) handleStartBattle(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusAccepted, StartBattleResponse{BattleID: string(out.BattleID), MatchID: out.MatchID})
 }
 
+type LobbySlotResponse struct {
+	PlayerID string `json:"player_id"`
+	JoinedAt string `json:"joined_at"`
+	Ready    bool   `json:"ready"`
+}
+
+type LobbyResponse struct {
+	BattleID string              `json:"battle_id"`
+	MatchID  string              `json:"match_id,omitempty"`
+	Leader   string              `json:"leader"`
+	Status   string              `json:"status"`
+	AllReady bool                `json:"all_ready"`
+	Slots    []LobbySlotResponse `json:"slots"`
+}
+
+func (s *Server) handleGetLobby(w http.ResponseWriter, r *http.Request) {
+	battleID := mux.Vars(r)["id"]
+	lobby, err := s.cfg.BattleService.GetLobby(r.Context(), shared.BattleID(battleID))
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, lobbyResponse(lobby))
+}
+
+func lobbyResponse(lobby battles.Lobby) LobbyResponse {
+	return LobbyResponse{
+		BattleID: string(lobby.BattleID),
+		MatchID:  lobby.MatchID,
+		Leader:   string(lobby.Leader),
+		Status:   string(lobby.Status),
+		AllReady: lobby.AllReady,
+		Slots:    lobbySlots(lobby.Slots),
+	}
+}
+
+func lobbySlots(slots []battle.PlayerSlot) []LobbySlotResponse {
+	out := make([]LobbySlotResponse, 0, len(slots))
+	for _, slot := range slots {
+		out = append(out, LobbySlotResponse{
+			PlayerID: string(slot.PlayerID),
+			JoinedAt: formatTime(slot.JoinedAt),
+			Ready:    slot.Ready,
+		})
+	}
+	return out
+}
+
+type JoinBattleRequest struct {
+	PlayerID string `json:"player_id"`
+}
+
+type JoinBattleResponse struct {
+	BattleID string              `json:"battle_id"`
+	Slots    []LobbySlotResponse `json:"slots"`
+}
+
+func (s *Server) handleJoinBattle(w http.ResponseWriter, r *http.Request) {
+	var req JoinBattleRequest
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	battleID := shared.BattleID(mux.Vars(r)["id"])
+	slots, err := s.cfg.BattleService.JoinBattle(r.Context(), battles.JoinCommand{
+		BattleID: battleID,
+		PlayerID: shared.PlayerID(req.PlayerID),
+	})
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, JoinBattleResponse{BattleID: string(battleID), Slots: lobbySlots(slots)})
+}
+
+type SetReadyRequest struct {
+	PlayerID string `json:"player_id"`
+	Ready    bool   `json:"ready"`
+}
+
+func (s *Server) handleSetReady(w http.ResponseWriter, r *http.Request) {
+	var req SetReadyRequest
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	battleID := shared.BattleID(mux.Vars(r)["id"])
+	lobby, err := s.cfg.BattleService.SetReady(r.Context(), battleID, shared.PlayerID(req.PlayerID), req.Ready)
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, lobbyResponse(lobby))
+}
+
 type SubmitScoreRequest struct {
 	PlayerID       string `json:"player_id"`
 	Score          int64  `json:"score"`
 	IdempotencyKey string `json:"idempotency_key"`
+	Operator       string `json:"operator,omitempty"`
 }
 
 func (s *Server) handleSubmitScore(w http.ResponseWriter, r *http.Request) {
 	seasonID := mux.Vars(r)["season"]
 	var req SubmitScoreRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
 		s.writeError(w, http.StatusBadRequest, err)
 		return
 	}
@@ -145,29 +365,205 @@ func (s *Server) handleSubmitScore(w http.ResponseWriter, r *http.Request) {
 		SeasonID:       shared.SeasonID(seasonID),
 		Score:          req.Score,
 		IdempotencyKey: shared.IdempotencyKey(req.IdempotencyKey),
+		Operator:       leaderboard.Operator(req.Operator),
 	})
 	if err != nil {
-		s.writeError(w, http.StatusBadRequest, err)
+		s.writeError(w, statusForError(err), err)
 		return
 	}
 	w.WriteHeader(http.StatusAccepted)
 }
 
+type LeaderboardRecordResponse struct {
+	OwnerID   string         `json:"owner_id"`
+	Score     int64          `json:"score"`
+	Rank      int64          `json:"rank"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	ExpiresAt string         `json:"expires_at,omitempty"`
+}
+
+type ListLeaderboardResponse struct {
+	Records    []LeaderboardRecordResponse `json:"records"`
+	NextCursor string                      `json:"next_cursor,omitempty"`
+}
+
+func (s *Server) handleListLeaderboard(w http.ResponseWriter, r *http.Request) {
+	seasonID := mux.Vars(r)["season"]
+	result, err := s.cfg.LeaderboardService.ListRecords(r.Context(), leaderboardsvc.ListCommand{
+		SeasonID: shared.SeasonID(seasonID),
+		Window:   leaderboard.Window(r.URL.Query().Get("window")),
+		Limit:    queryInt(r, "limit", 0),
+		Cursor:   r.URL.Query().Get("cursor"),
+	})
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	resp := ListLeaderboardResponse{
+		Records:    make([]LeaderboardRecordResponse, 0, len(result.Records)),
+		NextCursor: result.NextCursor,
+	}
+	for _, record := range result.Records {
+		entry := LeaderboardRecordResponse{
+			OwnerID:  string(record.OwnerID),
+			Score:    record.Score,
+			Rank:     record.Rank,
+			Metadata: record.Metadata,
+		}
+		if !record.ExpiresAt.IsZero() {
+			entry.ExpiresAt = formatTime(record.ExpiresAt)
+		}
+		resp.Records = append(resp.Records, entry)
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+type LeaderboardRankResponse struct {
+	OwnerID string `json:"owner_id"`
+	Score   int64  `json:"score"`
+	Rank    int64  `json:"rank"`
+}
+
+func (s *Server) handleGetLeaderboardRank(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rank, err := s.cfg.LeaderboardService.GetPlayerRank(r.Context(), shared.SeasonID(vars["season"]), shared.PlayerID(vars["player"]))
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, LeaderboardRankResponse{
+		OwnerID: string(rank.OwnerID),
+		Score:   rank.Score,
+		Rank:    rank.Rank,
+	})
+}
+
+// CreateSeasonRequest schedules a season. Timestamps are RFC3339.
+type CreateSeasonRequest struct {
+	SeasonID string `json:"season_id"`
+	StartsAt string `json:"starts_at"`
+	EndsAt   string `json:"ends_at"`
+}
+
+type SeasonResponse struct {
+	SeasonID string `json:"season_id"`
+	StartsAt string `json:"starts_at"`
+	EndsAt   string `json:"ends_at"`
+	Active   bool   `json:"active"`
+}
+
+func newSeasonResponse(season *leaderboard.Season) SeasonResponse {
+	return SeasonResponse{
+		SeasonID: string(season.ID),
+		StartsAt: formatTime(season.StartsAt),
+		EndsAt:   formatTime(season.EndsAt),
+		Active:   season.Active,
+	}
+}
+
+func (s *Server) handleCreateSeason(w http.ResponseWriter, r *http.Request) {
+	var req CreateSeasonRequest
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cmd := leaderboardsvc.CreateSeasonCommand{SeasonID: shared.SeasonID(req.SeasonID)}
+	startsAt, err := decodeTimeField("starts_at", req.StartsAt)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if startsAt != nil {
+		cmd.StartsAt = *startsAt
+	}
+	endsAt, err := decodeTimeField("ends_at", req.EndsAt)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if endsAt != nil {
+		cmd.EndsAt = *endsAt
+	}
+	season, err := s.cfg.LeaderboardService.CreateSeason(r.Context(), cmd)
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, newSeasonResponse(season))
+}
+
+func (s *Server) handleGetSeason(w http.ResponseWriter, r *http.Request) {
+	season, err := s.cfg.LeaderboardService.GetSeason(r.Context(), shared.SeasonID(mux.Vars(r)["season"]))
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, newSeasonResponse(season))
+}
+
+// ResetSeasonRequest closes a season's current score window. ResetAt is
+// RFC3339 and defaults to now.
+type ResetSeasonRequest struct {
+	ResetAt string `json:"reset_at"`
+}
+
+type ResetSeasonResponse struct {
+	Expired int `json:"expired"`
+}
+
+func (s *Server) handleResetSeason(w http.ResponseWriter, r *http.Request) {
+	if err := s.requireAdmin(r); err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	var req ResetSeasonRequest
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	resetAt := s.cfg.Clock()
+	parsed, err := decodeTimeField("reset_at", req.ResetAt)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if parsed != nil {
+		resetAt = *parsed
+	}
+	expired, err := s.cfg.LeaderboardService.Reset(r.Context(), shared.SeasonID(mux.Vars(r)["season"]), resetAt)
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, ResetSeasonResponse{Expired: expired})
+}
+
+// BotWebhookRequest carries a bot command. Payload is the command body,
+// base64-encoded. Sync runs the command inline and returns its response.
 type BotWebhookRequest struct {
 	CommandID      string `json:"command_id"`
 	Channel        string `json:"channel"`
 	PlayerID       string `json:"player_id"`
-	Payload        []byte `json:"payload"`
+	Payload        string `json:"payload"`
 	IdempotencyKey string `json:"idempotency_key"`
+	Sync           bool   `json:"sync,omitempty"`
 }
 
+// BotWebhookResponse acknowledges a command. Response, base64-encoded, is set
+// for synchronous commands.
 type BotWebhookResponse struct {
-	Accepted bool `json:"accepted"`
+	Accepted bool   `json:"accepted"`
+	Response []byte `json:"response,omitempty"`
 }
 
 func (s *Server) handleBotWebhook(w http.ResponseWriter, r *http.Request) {
 	var req BotWebhookRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	payload, err := decodeBase64Field("payload", req.Payload, true)
+	if err != nil {
 		s.writeError(w, http.StatusBadRequest, err)
 		return
 	}
@@ -175,12 +571,500 @@ func (s *Server) handleBotWebhook(w http.ResponseWriter, r *http.Request) {
 		CommandID:      shared.BotCommandID(req.CommandID),
 		Channel:        req.Channel,
 		PlayerID:       shared.PlayerID(req.PlayerID),
-		Payload:        req.Payload,
+		Payload:        payload,
 		IdempotencyKey: shared.IdempotencyKey(req.IdempotencyKey),
+		Sync:           req.Sync,
 	})
 	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	status := http.StatusAccepted
+	if req.Sync {
+		status = http.StatusOK
+	}
+	s.writeJSON(w, status, BotWebhookResponse{Accepted: out.Accepted, Response: out.Response})
+}
+
+type BotCommandResponse struct {
+	CommandID   string  `json:"command_id"`
+	Channel     string  `json:"channel"`
+	State       string  `json:"state"`
+	RetryCount  int     `json:"retry_count"`
+	LastError   string  `json:"last_error,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+	AttemptedAt *string `json:"attempted_at,omitempty"`
+	CompletedAt *string `json:"completed_at,omitempty"`
+}
+
+func newBotCommandResponse(cmd *domainbot.Command) BotCommandResponse {
+	return BotCommandResponse{
+		CommandID:   string(cmd.ID),
+		Channel:     cmd.Channel,
+		State:       string(cmd.State),
+		RetryCount:  cmd.RetryCount,
+		LastError:   cmd.LastError,
+		CreatedAt:   formatTime(cmd.CreatedAt),
+		AttemptedAt: formatOptionalTime(&cmd.AttemptedAt),
+		CompletedAt: formatOptionalTime(&cmd.CompletedAt),
+	}
+}
+
+func (s *Server) handleGetBotCommand(w http.ResponseWriter, r *http.Request) {
+	cmd, err := s.cfg.BotService.GetStatus(r.Context(), shared.BotCommandID(mux.Vars(r)["id"]))
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, newBotCommandResponse(cmd))
+}
+
+// CompleteBotCommandRequest reports a worker's attempt at a command. An empty
+// Error means the command succeeded. Workers authenticate with the admin key.
+type CompleteBotCommandRequest struct {
+	Error string `json:"error,omitempty"`
+}
+
+func (s *Server) handleCompleteBotCommand(w http.ResponseWriter, r *http.Request) {
+	if err := s.requireAdmin(r); err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	var req CompleteBotCommandRequest
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var attemptErr error
+	if req.Error != "" {
+		attemptErr = errors.New(req.Error)
+	}
+	cmd, err := s.cfg.BotService.Complete(r.Context(), shared.BotCommandID(mux.Vars(r)["id"]), attemptErr)
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, newBotCommandResponse(cmd))
+}
+
+type TrackEventRequest struct {
+	UserID     string `json:"user_id"`
+	Name       string `json:"name"`
+	AppName    string `json:"app_name"`
+	AppVersion string `json:"app_version"`
+	OSName     string `json:"os_name"`
+	OSVersion  string `json:"os_version"`
+	Forwarded  bool   `json:"forwarded"`
+	// Properties are custom attributes such as level or score.
+	Properties map[string]any `json:"properties"`
+}
+
+type TrackEventsRequest struct {
+	Events []TrackEventRequest `json:"events"`
+}
+
+// TrackEventsResponse reports how many events were accepted. When dispatch
+// fails partway, Error and Code describe the failure and the events from index
+// Accepted on can be retried.
+type TrackEventsResponse struct {
+	Accepted int    `json:"accepted"`
+	Error    string `json:"error,omitempty"`
+	Code     string `json:"code,omitempty"`
+}
+
+func (s *Server) handleTrackEvents(w http.ResponseWriter, r *http.Request) {
+	var req TrackEventsRequest
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
 		s.writeError(w, http.StatusBadRequest, err)
 		return
 	}
-	s.writeJSON(w, http.StatusAccepted, BotWebhookResponse{Accepted: out.Accepted})
+	if len(req.Events) == 0 {
+		s.writeError(w, http.StatusBadRequest, errors.New("events are required"))
+		return
+	}
+	if len(req.Events) > s.cfg.MaxIngestEvents {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("batch of %d events exceeds the maximum of %d", len(req.Events), s.cfg.MaxIngestEvents))
+		return
+	}
+	cmds := make([]analytics.TrackEventCommand, 0, len(req.Events))
+	for _, event := range req.Events {
+		cmds = append(cmds, analytics.TrackEventCommand{
+			UserID:     shared.PlayerID(event.UserID),
+			Name:       domainanalytics.EventName(event.Name),
+			AppName:    event.AppName,
+			AppVersion: event.AppVersion,
+			OSName:     event.OSName,
+			OSVersion:  event.OSVersion,
+			Forwarded:  event.Forwarded,
+			Properties: event.Properties,
+		})
+	}
+	accepted, err := s.cfg.AnalyticsService.TrackEvents(r.Context(), cmds)
+	if err != nil && accepted > 0 {
+		s.writeJSON(w, statusForError(err), TrackEventsResponse{Accepted: accepted, Error: err.Error(), Code: codeForError(err)})
+		return
+	}
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusAccepted, TrackEventsResponse{Accepted: accepted})
+}
+
+type EndAllSessionsResponse struct {
+	Ended int `json:"ended"`
+}
+
+func (s *Server) handleEndAllSessions(w http.ResponseWriter, r *http.Request) {
+	if err := s.requireAdmin(r); err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	playerID := mux.Vars(r)["id"]
+	ended, err := s.cfg.AnalyticsService.EndAllSessions(r.Context(), shared.PlayerID(playerID))
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, EndAllSessionsResponse{Ended: ended})
+}
+
+type PlayerSummaryResponse struct {
+	PlayerID    string `json:"player_id"`
+	DisplayName string `json:"display_name"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type SearchPlayersResponse struct {
+	Players []PlayerSummaryResponse `json:"players"`
+}
+
+func (s *Server) handleSearchPlayers(w http.ResponseWriter, r *http.Request) {
+	results, err := s.cfg.AuthService.SearchPlayers(r.Context(), auth.SearchPlayersQuery{
+		Query: r.URL.Query().Get("q"),
+		Limit: queryInt(r, "limit", 0),
+	})
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	resp := SearchPlayersResponse{Players: make([]PlayerSummaryResponse, 0, len(results))}
+	for _, result := range results {
+		resp.Players = append(resp.Players, PlayerSummaryResponse{
+			PlayerID:    string(result.ID),
+			DisplayName: result.DisplayName,
+			CreatedAt:   formatTime(result.CreatedAt),
+		})
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+type PlayerTournamentResponse struct {
+	TournamentID string `json:"tournament_id"`
+	Title        string `json:"title"`
+	State        string `json:"state"`
+	Attempts     int    `json:"attempts"`
+	JoinedAt     string `json:"joined_at"`
+}
+
+type ListPlayerTournamentsResponse struct {
+	Tournaments []PlayerTournamentResponse `json:"tournaments"`
+	Total       int                        `json:"total"`
+	Limit       int                        `json:"limit"`
+	Offset      int                        `json:"offset"`
+}
+
+func (s *Server) handleListPlayerTournaments(w http.ResponseWriter, r *http.Request) {
+	playerID := mux.Vars(r)["id"]
+	page, err := s.cfg.TournamentService.ListPlayerTournaments(r.Context(), tournaments.ListPlayerTournamentsQuery{
+		PlayerID: shared.PlayerID(playerID),
+		Limit:    queryInt(r, "limit", 0),
+		Offset:   queryInt(r, "offset", 0),
+	})
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	resp := ListPlayerTournamentsResponse{
+		Tournaments: make([]PlayerTournamentResponse, 0, len(page.Items)),
+		Total:       page.Total,
+		Limit:       page.Limit,
+		Offset:      page.Offset,
+	}
+	for _, result := range page.Items {
+		resp.Tournaments = append(resp.Tournaments, PlayerTournamentResponse{
+			TournamentID: string(result.Tournament.ID),
+			Title:        result.Tournament.Title,
+			State:        string(result.Tournament.State),
+			Attempts:     result.Attempts,
+			JoinedAt:     formatTime(result.JoinedAt),
+		})
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+type PlayerBattleResponse struct {
+	BattleID  string `json:"battle_id"`
+	Leader    string `json:"leader"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ListPlayerBattlesResponse struct {
+	Battles []PlayerBattleResponse `json:"battles"`
+}
+
+func (s *Server) handleListPlayerBattles(w http.ResponseWriter, r *http.Request) {
+	playerID := mux.Vars(r)["id"]
+	results, err := s.cfg.BattleService.ListPlayerBattles(r.Context(), battles.ListPlayerBattlesQuery{
+		PlayerID: shared.PlayerID(playerID),
+		Limit:    queryInt(r, "limit", 0),
+		Offset:   queryInt(r, "offset", 0),
+	})
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	resp := ListPlayerBattlesResponse{Battles: make([]PlayerBattleResponse, 0, len(results))}
+	for _, b := range results {
+		resp.Battles = append(resp.Battles, PlayerBattleResponse{
+			BattleID:  string(b.ID),
+			Leader:    string(b.Leader),
+			CreatedAt: formatTime(b.CreatedAt),
+		})
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+type TournamentResponse struct {
+	TournamentID         string  `json:"tournament_id"`
+	Title                string  `json:"title"`
+	Description          string  `json:"description,omitempty"`
+	Category             int     `json:"category"`
+	SortOrder            string  `json:"sort_order"`
+	Operator             string  `json:"operator"`
+	ResetSchedule        string  `json:"reset_schedule,omitempty"`
+	Authoritative        bool    `json:"authoritative"`
+	JoinRequired         bool    `json:"join_required"`
+	MaxSize              int     `json:"max_size"`
+	MaxNumScore          int     `json:"max_num_score"`
+	State                string  `json:"state"`
+	Status               string  `json:"status"`
+	TimeRemainingSeconds int64   `json:"time_remaining_seconds"`
+	Version              int64   `json:"version"`
+	StartTime            string  `json:"start_time"`
+	EndTime              *string `json:"end_time,omitempty"`
+	CreatedAt            string  `json:"created_at"`
+	UpdatedAt            string  `json:"updated_at"`
+}
+
+func (s *Server) handleGetTournament(w http.ResponseWriter, r *http.Request) {
+	tournamentID := mux.Vars(r)["id"]
+	t, err := s.cfg.TournamentService.GetTournament(r.Context(), tournaments.GetTournamentQuery{
+		TournamentID: shared.TournamentID(tournamentID),
+	})
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, newTournamentResponse(t, s.cfg.Clock()))
+}
+
+func newTournamentResponse(t *tournament.Tournament, now time.Time) TournamentResponse {
+	return TournamentResponse{
+		TournamentID:         string(t.ID),
+		Title:                t.Title,
+		Description:          t.Description,
+		Category:             t.Category,
+		SortOrder:            string(t.SortOrder),
+		Operator:             string(t.Operator),
+		ResetSchedule:        t.ResetSchedule,
+		Authoritative:        t.Authoritative,
+		JoinRequired:         t.JoinRequired,
+		MaxSize:              t.MaxSize,
+		MaxNumScore:          t.MaxNumScore,
+		State:                string(t.State),
+		Status:               string(t.Status(now)),
+		TimeRemainingSeconds: int64(t.TimeRemaining(now) / time.Second),
+		Version:              t.Version,
+		StartTime:            formatTime(t.StartTime),
+		EndTime:              formatOptionalTime(t.EndTime),
+		CreatedAt:            formatTime(t.CreatedAt),
+		UpdatedAt:            formatTime(t.UpdatedAt),
+	}
+}
+
+type UpdateResetScheduleRequest struct {
+	ResetSchedule string `json:"reset_schedule"`
+}
+
+func (s *Server) handleUpdateResetSchedule(w http.ResponseWriter, r *http.Request) {
+	tournamentID := mux.Vars(r)["id"]
+	var req UpdateResetScheduleRequest
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	t, err := s.cfg.TournamentService.UpdateResetSchedule(r.Context(), tournaments.UpdateResetScheduleCommand{
+		TournamentID:  shared.TournamentID(tournamentID),
+		ResetSchedule: req.ResetSchedule,
+	})
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, newTournamentResponse(t, s.cfg.Clock()))
+}
+
+// ValidateTournamentRequest mirrors the fields checked when a tournament is
+// created. Timestamps are RFC3339 and duration is in seconds.
+type ValidateTournamentRequest struct {
+	TournamentID string `json:"tournament_id"`
+	Title        string `json:"title"`
+	Category     int    `json:"category"`
+	SortOrder    string `json:"sort_order"`
+	Operator     string `json:"operator"`
+	MaxSize      int    `json:"max_size"`
+	MaxNumScore  int    `json:"max_num_score"`
+	StartTime    string `json:"start_time"`
+	Duration     int64  `json:"duration"`
+	JoinOpensAt  string `json:"join_opens_at,omitempty"`
+	JoinClosesAt string `json:"join_closes_at,omitempty"`
+}
+
+type FieldErrorResponse struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidateTournamentResponse struct {
+	Valid  bool                 `json:"valid"`
+	Errors []FieldErrorResponse `json:"errors"`
+}
+
+// handleValidateTournament is a dry run of tournament creation: it reports
+// every invalid field and creates nothing. An invalid payload is still a 200;
+// only a body that cannot be decoded is rejected.
+func (s *Server) handleValidateTournament(w http.ResponseWriter, r *http.Request) {
+	var req ValidateTournamentRequest
+	if err := decodeJSON(w, r, &req, s.decodeOptions()); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var errs shared.ValidationErrors
+	startTime, err := decodeTimeField("start_time", req.StartTime)
+	errs.Add("start_time", err)
+	joinOpensAt, err := decodeTimeField("join_opens_at", req.JoinOpensAt)
+	errs.Add("join_opens_at", err)
+	joinClosesAt, err := decodeTimeField("join_closes_at", req.JoinClosesAt)
+	errs.Add("join_closes_at", err)
+
+	cmd := tournaments.CreateTournamentCommand{
+		ID:           shared.TournamentID(req.TournamentID),
+		Title:        req.Title,
+		Category:     req.Category,
+		SortOrder:    tournament.SortOrder(req.SortOrder),
+		Operator:     tournament.Operator(req.Operator),
+		MaxSize:      req.MaxSize,
+		MaxNumScore:  req.MaxNumScore,
+		Duration:     time.Duration(req.Duration) * time.Second,
+		JoinOpensAt:  joinOpensAt,
+		JoinClosesAt: joinClosesAt,
+	}
+	if startTime != nil {
+		cmd.StartTime = *startTime
+	}
+	for _, verr := range s.cfg.TournamentService.ValidateTournament(cmd) {
+		// A start time that failed to parse is already reported.
+		if verr.Field == "start_time" && req.StartTime != "" {
+			continue
+		}
+		errs = append(errs, verr)
+	}
+
+	resp := ValidateTournamentResponse{Valid: len(errs) == 0, Errors: make([]FieldErrorResponse, 0, len(errs))}
+	for _, verr := range errs {
+		resp.Errors = append(resp.Errors, FieldErrorResponse{Field: verr.Field, Message: verr.Message})
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+type StandingResponse struct {
+	Rank     int64  `json:"rank"`
+	OwnerID  string `json:"owner_id"`
+	Username string `json:"username,omitempty"`
+	Score    int64  `json:"score"`
+	Subscore int64  `json:"subscore"`
+	NumScore int    `json:"num_score"`
+	Attempts int    `json:"attempts,omitempty"`
+}
+
+type StandingsResponse struct {
+	Records    []StandingResponse `json:"records"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+	PrevCursor string             `json:"prev_cursor,omitempty"`
+}
+
+func (s *Server) handleGetStandings(w http.ResponseWriter, r *http.Request) {
+	tournamentID := mux.Vars(r)["id"]
+	result, err := s.cfg.TournamentService.GetStandings(r.Context(), tournaments.GetStandingsQuery{
+		TournamentID: shared.TournamentID(tournamentID),
+		Limit:        queryInt(r, "limit", 0),
+		Cursor:       r.URL.Query().Get("cursor"),
+		Top:          queryInt(r, "top", 0),
+	})
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	resp := StandingsResponse{
+		Records:    make([]StandingResponse, 0, len(result.Records)),
+		NextCursor: result.NextCursor,
+		PrevCursor: result.PrevCursor,
+	}
+	for _, record := range result.Records {
+		resp.Records = append(resp.Records, StandingResponse{
+			Rank:     record.Rank,
+			OwnerID:  record.OwnerID,
+			Username: record.Username,
+			Score:    record.Score,
+			Subscore: record.Subscore,
+			NumScore: record.NumScore,
+			Attempts: record.Attempts,
+		})
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+type StandingsSnapshotResponse struct {
+	TournamentID string             `json:"tournament_id"`
+	Title        string             `json:"title"`
+	TakenAt      string             `json:"taken_at"`
+	Records      []StandingResponse `json:"records"`
+}
+
+func (s *Server) handleGetStandingsSnapshot(w http.ResponseWriter, r *http.Request) {
+	tournamentID := mux.Vars(r)["id"]
+	snapshot, err := s.cfg.TournamentService.GetSnapshot(r.Context(), shared.TournamentID(tournamentID))
+	if err != nil {
+		s.writeError(w, statusForError(err), err)
+		return
+	}
+	resp := StandingsSnapshotResponse{
+		TournamentID: string(snapshot.TournamentID),
+		Title:        snapshot.Title,
+		TakenAt:      formatTime(snapshot.TakenAt),
+		Records:      make([]StandingResponse, 0, len(snapshot.Standings)),
+	}
+	for _, standing := range snapshot.Standings {
+		resp.Records = append(resp.Records, StandingResponse{
+			Rank:     standing.Rank,
+			OwnerID:  standing.OwnerID,
+			Username: standing.Username,
+			Score:    standing.Score,
+			Subscore: standing.Subscore,
+			NumScore: standing.NumScore,
+		})
+	}
+	s.writeJSON(w, http.StatusOK, resp)
 }
@@ -0,0 +1,1559 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/heroiclabs/nakama/v3/src/app/analytics"
+	"github.com/heroiclabs/nakama/v3/src/app/auth"
+	"github.com/heroiclabs/nakama/v3/src/app/battles"
+	"github.com/heroiclabs/nakama/v3/src/app/bot"
+	"github.com/heroiclabs/nakama/v3/src/app/groups"
+	leaderboardsvc "github.com/heroiclabs/nakama/v3/src/app/leaderboard"
+	"github.com/heroiclabs/nakama/v3/src/app/tournaments"
+	domainanalytics "github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	domainbot "github.com/heroiclabs/nakama/v3/src/domain/bot"
+	"github.com/heroiclabs/nakama/v3/src/domain/group"
+	"github.com/heroiclabs/nakama/v3/src/domain/leaderboard"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+	analyticsinfra "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+	battleinfra "github.com/heroiclabs/nakama/v3/src/infra/battle"
+	idempotencyinfra "github.com/heroiclabs/nakama/v3/src/infra/idempotency"
+	leaderboardinfra "github.com/heroiclabs/nakama/v3/src/infra/leaderboard"
+	playerinfra "github.com/heroiclabs/nakama/v3/src/infra/player"
+	tournamentinfra "github.com/heroiclabs/nakama/v3/src/infra/tournament"
+)
+
+type recordingDispatcher struct {
+	batches [][]*domainanalytics.Event
+	// failAfter, when positive, fails every batch after that many succeed.
+	failAfter int
+}
+
+func (d *recordingDispatcher) Dispatch(ctx context.Context, events []*domainanalytics.Event) error {
+	if d.failAfter > 0 && len(d.batches) >= d.failAfter {
+		return domainanalytics.ErrDispatchFailed
+	}
+	d.batches = append(d.batches, events)
+	return nil
+}
+
+func newTestServer(cfg ServerConfig) *Server {
+	cfg.Logger = zap.NewNop()
+	cfg.Registry = prometheus.NewRegistry()
+	return NewServer(cfg)
+}
+
+func doJSON(t *testing.T, srv *Server, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("encoding request body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandleTrackEvents_MaxEvents(t *testing.T) {
+	const maxEvents = 5
+
+	makeRequest := func(n int) TrackEventsRequest {
+		req := TrackEventsRequest{}
+		for i := 0; i < n; i++ {
+			req.Events = append(req.Events, TrackEventRequest{
+				UserID: fmt.Sprintf("player-%d", i),
+				Name:   "level_complete",
+			})
+		}
+		return req
+	}
+
+	tests := []struct {
+		name        string
+		events      int
+		wantStatus  int
+		wantBatches int
+	}{
+		{name: "empty batch", events: 0, wantStatus: http.StatusBadRequest},
+		{name: "at maximum", events: maxEvents, wantStatus: http.StatusAccepted, wantBatches: 3},
+		{name: "over maximum", events: maxEvents + 1, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher := &recordingDispatcher{}
+			service := analytics.NewService(dispatcher, analyticsinfra.NewMemorySessionRepository())
+			service.BatchSize = 2
+
+			srv := newTestServer(ServerConfig{AnalyticsService: service, MaxIngestEvents: maxEvents})
+			rec := doJSON(t, srv, http.MethodPost, "/v1/analytics/events", makeRequest(tt.events))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if len(dispatcher.batches) != tt.wantBatches {
+				t.Errorf("Expected %d dispatched batches, got %d", tt.wantBatches, len(dispatcher.batches))
+			}
+		})
+	}
+}
+
+func TestHandleTrackEvents_PartialFailure(t *testing.T) {
+	service := analytics.NewService(&recordingDispatcher{failAfter: 1}, analyticsinfra.NewMemorySessionRepository())
+	service.BatchSize = 2
+	srv := newTestServer(ServerConfig{AnalyticsService: service, MaxIngestEvents: 5})
+
+	req := TrackEventsRequest{}
+	for i := 0; i < 5; i++ {
+		req.Events = append(req.Events, TrackEventRequest{UserID: fmt.Sprintf("player-%d", i), Name: "level_complete"})
+	}
+	rec := doJSON(t, srv, http.MethodPost, "/v1/analytics/events", req)
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusBadGateway, rec.Code, rec.Body.String())
+	}
+
+	var resp TrackEventsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Accepted != 2 || resp.Error == "" {
+		t.Errorf("Expected 2 accepted with an error, got %+v", resp)
+	}
+}
+
+func TestHandleTrackEvents_ValidationErrorField(t *testing.T) {
+	service := analytics.NewService(&recordingDispatcher{}, analyticsinfra.NewMemorySessionRepository())
+	srv := newTestServer(ServerConfig{AnalyticsService: service})
+
+	rec := doJSON(t, srv, http.MethodPost, "/v1/analytics/events", TrackEventsRequest{
+		Events: []TrackEventRequest{{UserID: "", Name: "level_complete"}},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	var resp errorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Field != "player_id" {
+		t.Errorf("Expected field %q, got %q", "player_id", resp.Field)
+	}
+	if resp.Error != "player id is required" {
+		t.Errorf("Expected message %q, got %q", "player id is required", resp.Error)
+	}
+}
+
+func TestHandleEndAllSessions_RequiresAdminKey(t *testing.T) {
+	ctx := context.Background()
+	service := analytics.NewService(&recordingDispatcher{}, analyticsinfra.NewMemorySessionRepository())
+	if err := service.StartSession(ctx, analytics.StartSessionCommand{UserID: "player-1", Version: "1.0.0"}); err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	srv := newTestServer(ServerConfig{AnalyticsService: service, AdminKey: testAdminKey, SessionKey: testSessionKey})
+
+	rec := doJSON(t, srv, http.MethodPost, "/v1/admin/players/player-1/sessions/end", nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status %d without credentials, got %d", http.StatusUnauthorized, rec.Code)
+	}
+	rec = doJSONAs(t, srv, "player-1", http.MethodPost, "/v1/admin/players/player-1/sessions/end", nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status %d for a player session, got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	rec = doAdmin(t, srv, http.MethodPost, "/v1/admin/players/player-1/sessions/end", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp EndAllSessionsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Ended != 1 {
+		t.Errorf("Expected 1 session ended, got %d", resp.Ended)
+	}
+}
+
+func TestHandleGetTournament(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := tournamentinfra.NewMemoryRepository()
+	existing, err := tournament.NewTournament(
+		"weekly", "Weekly Cup", "", 1,
+		tournament.SortOrderDescending, tournament.OperatorBest,
+		"", true, false, 0, 0, now, 2*time.Hour, now,
+	)
+	if err != nil {
+		t.Fatalf("NewTournament() error = %v", err)
+	}
+	if err := repo.Save(context.Background(), existing); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	service := tournaments.NewService(repo, tournamentinfra.NewMemoryParticipantRepository(), nil)
+	srv := newTestServer(ServerConfig{
+		TournamentService: service,
+		Clock:             func() time.Time { return now.Add(30 * time.Minute) },
+	})
+
+	t.Run("found", func(t *testing.T) {
+		rec := doJSON(t, srv, http.MethodGet, "/v1/tournaments/weekly", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		var resp TournamentResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		if resp.TournamentID != "weekly" || resp.Title != "Weekly Cup" {
+			t.Errorf("Unexpected tournament %+v", resp)
+		}
+		if resp.StartTime != "2024-03-01T12:00:00Z" {
+			t.Errorf("Expected RFC3339 start time, got %q", resp.StartTime)
+		}
+		if resp.Status != "active" || resp.TimeRemainingSeconds != 90*60 {
+			t.Errorf("Expected active with 5400s left, got %q with %ds", resp.Status, resp.TimeRemainingSeconds)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		rec := doJSON(t, srv, http.MethodGet, "/v1/tournaments/missing", nil)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusNotFound, rec.Code, rec.Body.String())
+		}
+		var resp errorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		if resp.Error != tournament.ErrTournamentNotFound.Error() {
+			t.Errorf("Expected error %q, got %q", tournament.ErrTournamentNotFound, resp.Error)
+		}
+	})
+}
+
+type fakeStandingsProvider struct {
+	tournaments.NakamaProvider
+	records tournaments.RecordList
+}
+
+func (p *fakeStandingsProvider) ListRecords(ctx context.Context, tournamentID shared.TournamentID, limit int, cursor string) (tournaments.RecordList, error) {
+	return p.records, nil
+}
+
+func TestHandleGetStandings(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := tournamentinfra.NewMemoryRepository()
+	existing, err := tournament.NewTournament(
+		"weekly", "Weekly Cup", "", 1,
+		tournament.SortOrderDescending, tournament.OperatorBest,
+		"", true, false, 0, 0, now, 0, now,
+	)
+	if err != nil {
+		t.Fatalf("NewTournament() error = %v", err)
+	}
+	if err := repo.Save(context.Background(), existing); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	provider := &fakeStandingsProvider{records: tournaments.RecordList{
+		Records: []tournaments.Record{
+			{OwnerID: "player-1", Username: "alice", Score: 300, Rank: 1},
+			{OwnerID: "player-2", Username: "bob", Score: 200, Rank: 2},
+		},
+		NextCursor: "next",
+	}}
+	service := tournaments.NewService(repo, tournamentinfra.NewMemoryParticipantRepository(), provider)
+	srv := newTestServer(ServerConfig{TournamentService: service})
+
+	rec := doJSON(t, srv, http.MethodGet, "/v1/tournaments/weekly/standings?limit=2", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp StandingsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(resp.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(resp.Records))
+	}
+	if resp.Records[0].OwnerID != "player-1" || resp.Records[0].Rank != 1 || resp.Records[0].Score != 300 {
+		t.Errorf("Unexpected first record %+v", resp.Records[0])
+	}
+	if resp.NextCursor != "next" {
+		t.Errorf("Expected next cursor %q, got %q", "next", resp.NextCursor)
+	}
+
+	rec = doJSON(t, srv, http.MethodGet, "/v1/tournaments/missing/standings", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+// failingParticipantRepo fails every player listing.
+type failingParticipantRepo struct {
+	tournament.ParticipantRepository
+}
+
+func (failingParticipantRepo) ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*tournament.Participant, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestHandleListPlayerTournaments(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := tournamentinfra.NewMemoryRepository()
+	participants := tournamentinfra.NewMemoryParticipantRepository()
+	for i, id := range []shared.TournamentID{"cup-a", "cup-b", "cup-c"} {
+		cup, err := tournament.NewTournament(id, "Cup", "", 0, tournament.SortOrderDescending, tournament.OperatorBest, "", true, false, 0, 0, now, time.Hour, now)
+		if err != nil {
+			t.Fatalf("NewTournament() error = %v", err)
+		}
+		if err := repo.Save(context.Background(), cup); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		p, err := tournament.NewParticipant(id, "player-1", now.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("NewParticipant() error = %v", err)
+		}
+		if err := participants.Save(context.Background(), p); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	srv := newTestServer(ServerConfig{TournamentService: tournaments.NewService(repo, participants, nil)})
+
+	rec := doJSON(t, srv, http.MethodGet, "/v1/players/player-1/tournaments?limit=2&offset=1", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp ListPlayerTournamentsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Total != 3 || resp.Limit != 2 || resp.Offset != 1 || len(resp.Tournaments) != 2 || resp.Tournaments[0].TournamentID != "cup-b" {
+		t.Errorf("Expected cup-b and cup-c of 3 at offset 1, got %+v", resp)
+	}
+
+	failing := newTestServer(ServerConfig{TournamentService: tournaments.NewService(repo, failingParticipantRepo{}, nil)})
+	if rec := doJSON(t, failing, http.MethodGet, "/v1/players/player-1/tournaments", nil); rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d for a repository failure, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if rec := doJSON(t, srv, http.MethodGet, "/v1/players/%20/tournaments", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid player, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestHandleUpdateResetSchedule(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := tournamentinfra.NewMemoryRepository()
+	existing, err := tournament.NewTournament(
+		"weekly", "Weekly Cup", "", 1,
+		tournament.SortOrderDescending, tournament.OperatorBest,
+		"", true, false, 0, 0, now, 0, now,
+	)
+	if err != nil {
+		t.Fatalf("NewTournament() error = %v", err)
+	}
+	if err := repo.Save(context.Background(), existing); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	service := tournaments.NewService(repo, tournamentinfra.NewMemoryParticipantRepository(), nil)
+	srv := newTestServer(ServerConfig{TournamentService: service})
+
+	tests := []struct {
+		name       string
+		path       string
+		schedule   string
+		wantStatus int
+	}{
+		{name: "valid schedule", path: "/v1/tournaments/weekly/reset-schedule", schedule: "0 0 * * 1", wantStatus: http.StatusOK},
+		{name: "invalid schedule", path: "/v1/tournaments/weekly/reset-schedule", schedule: "bogus", wantStatus: http.StatusBadRequest},
+		{name: "unknown tournament", path: "/v1/tournaments/missing/reset-schedule", schedule: "0 0 * * 1", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doJSON(t, srv, http.MethodPatch, tt.path, UpdateResetScheduleRequest{ResetSchedule: tt.schedule})
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp TournamentResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if resp.ResetSchedule != tt.schedule {
+				t.Errorf("Expected reset schedule %q, got %q", tt.schedule, resp.ResetSchedule)
+			}
+		})
+	}
+}
+
+func TestHandleValidateTournament(t *testing.T) {
+	repo := tournamentinfra.NewMemoryRepository()
+	service := tournaments.NewService(repo, tournamentinfra.NewMemoryParticipantRepository(), nil)
+	srv := newTestServer(ServerConfig{TournamentService: service})
+
+	tests := []struct {
+		name       string
+		req        ValidateTournamentRequest
+		wantFields []string
+	}{
+		{
+			name: "valid payload",
+			req: ValidateTournamentRequest{
+				TournamentID: "weekly",
+				Title:        "Weekly Cup",
+				SortOrder:    "asc",
+				Operator:     "best",
+				StartTime:    "2024-03-01T12:00:00Z",
+				Duration:     3600,
+			},
+		},
+		{
+			name: "every invalid field reported",
+			req: ValidateTournamentRequest{
+				Category:     -1,
+				Operator:     "incr",
+				SortOrder:    "asc",
+				MaxSize:      -5,
+				StartTime:    "yesterday",
+				JoinOpensAt:  "2024-03-02T00:00:00Z",
+				JoinClosesAt: "2024-03-01T00:00:00Z",
+			},
+			wantFields: []string{"start_time", "tournament_id", "title", "category", "max_size", "operator", "join_closes_at"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doJSON(t, srv, http.MethodPost, "/v1/tournaments/validate", tt.req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+			}
+			var resp ValidateTournamentResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if resp.Valid != (len(tt.wantFields) == 0) {
+				t.Errorf("Expected valid = %v, got %v", len(tt.wantFields) == 0, resp.Valid)
+			}
+			if len(resp.Errors) != len(tt.wantFields) {
+				t.Fatalf("Expected %d errors, got %+v", len(tt.wantFields), resp.Errors)
+			}
+			for i, field := range tt.wantFields {
+				if resp.Errors[i].Field != field || resp.Errors[i].Message == "" {
+					t.Errorf("Expected error %d on field %q, got %+v", i, field, resp.Errors[i])
+				}
+			}
+		})
+	}
+
+	if _, err := repo.Get(context.Background(), "weekly"); err == nil {
+		t.Error("Expected validation not to create the tournament")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/tournaments/validate", strings.NewReader("{"))
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for malformed JSON, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestHandleSearchPlayers(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := playerinfra.NewMemoryRepository()
+	for id, name := range map[shared.PlayerID]string{"player-1": "Alice", "player-2": "Bob"} {
+		account, err := player.NewPlayerAccount(id, string(id)+"@example.com", name, now)
+		if err != nil {
+			t.Fatalf("NewPlayerAccount() error = %v", err)
+		}
+		_ = repo.Save(context.Background(), account)
+	}
+	srv := newTestServer(ServerConfig{AuthService: auth.NewService(repo, nil)})
+
+	t.Run("match", func(t *testing.T) {
+		rec := doJSON(t, srv, http.MethodGet, "/v1/players?q=al", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		var resp map[string][]map[string]any
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		players := resp["players"]
+		if len(players) != 1 || players[0]["player_id"] != "player-1" || players[0]["display_name"] != "Alice" {
+			t.Fatalf("Unexpected players %v", players)
+		}
+		if _, ok := players[0]["email"]; ok {
+			t.Error("Expected email to be left out of search results")
+		}
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		rec := doJSON(t, srv, http.MethodGet, "/v1/players?q=zed", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != `{"players":[]}` {
+			t.Errorf("Expected an empty list, got %s", body)
+		}
+	})
+
+	t.Run("missing query", func(t *testing.T) {
+		rec := doJSON(t, srv, http.MethodGet, "/v1/players", nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestHandleGetStandingsSnapshot(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := tournamentinfra.NewMemoryRepository()
+	existing, err := tournament.NewTournament(
+		"weekly", "Weekly Cup", "", 1,
+		tournament.SortOrderDescending, tournament.OperatorBest,
+		"", true, false, 0, 0, now, 0, now,
+	)
+	if err != nil {
+		t.Fatalf("NewTournament() error = %v", err)
+	}
+	if err := repo.Save(context.Background(), existing); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	provider := &fakeStandingsProvider{records: tournaments.RecordList{
+		Records: []tournaments.Record{
+			{OwnerID: "player-1", Username: "alice", Score: 300, Rank: 1},
+			{OwnerID: "player-2", Username: "bob", Score: 200, Rank: 2},
+		},
+	}}
+	service := tournaments.NewService(repo, tournamentinfra.NewMemoryParticipantRepository(), provider)
+	service.Clock = func() time.Time { return now }
+	service.Snapshots = tournamentinfra.NewMemorySnapshotRepository()
+	srv := newTestServer(ServerConfig{TournamentService: service})
+
+	if _, err := service.SnapshotStandings(context.Background(), "weekly"); err != nil {
+		t.Fatalf("SnapshotStandings() error = %v", err)
+	}
+	if err := repo.Delete(context.Background(), "weekly"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	rec := doJSON(t, srv, http.MethodGet, "/v1/tournaments/weekly/standings/final", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp StandingsSnapshotResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Title != "Weekly Cup" || resp.TakenAt != formatTime(now) || len(resp.Records) != 2 {
+		t.Fatalf("Unexpected snapshot %+v", resp)
+	}
+	if resp.Records[0].OwnerID != "player-1" || resp.Records[0].Rank != 1 {
+		t.Errorf("Unexpected first record %+v", resp.Records[0])
+	}
+
+	rec = doJSON(t, srv, http.MethodGet, "/v1/tournaments/missing/standings/final", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestHandleGetStandingsSnapshot_NotConfigured(t *testing.T) {
+	service := tournaments.NewService(tournamentinfra.NewMemoryRepository(), tournamentinfra.NewMemoryParticipantRepository(), &fakeStandingsProvider{})
+	srv := newTestServer(ServerConfig{TournamentService: service})
+
+	rec := doJSON(t, srv, http.MethodGet, "/v1/tournaments/weekly/standings/final", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusServiceUnavailable, rec.Code, rec.Body.String())
+	}
+}
+
+type stubBotRepo struct{}
+
+func (stubBotRepo) ReserveCommand(ctx context.Context, key shared.IdempotencyKey) (*domainbot.Command, error) {
+	return nil, shared.ErrNotFound
+}
+
+func (stubBotRepo) Get(ctx context.Context, id shared.BotCommandID) (*domainbot.Command, error) {
+	return nil, domainbot.ErrCommandNotFound
+}
+
+func (stubBotRepo) Save(ctx context.Context, command *domainbot.Command) error {
+	return nil
+}
+
+func (stubBotRepo) MarkProcessed(ctx context.Context, id shared.BotCommandID, state domainbot.CommandState) error {
+	return nil
+}
+
+func (stubBotRepo) ListDueForRetry(ctx context.Context, now time.Time, limit int) ([]*domainbot.Command, error) {
+	return nil, nil
+}
+
+type stubProducer struct{}
+
+func (stubProducer) Enqueue(ctx context.Context, command *domainbot.Command) error {
+	return nil
+}
+
+func TestHandleBotWebhook_InFlightDuplicate(t *testing.T) {
+	store := idempotencyinfra.NewMemoryStore()
+	if _, err := store.Reserve(context.Background(), "bot", "key-1", shared.DefaultIdempotencyTTL); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	service := bot.NewService(stubBotRepo{}, stubProducer{}, nil)
+	service.Idempotency = store
+	srv := newTestServer(ServerConfig{BotService: service})
+
+	rec := doJSON(t, srv, http.MethodPost, "/v1/bot/webhook", BotWebhookRequest{
+		CommandID:      "command-1",
+		Channel:        "discord",
+		PlayerID:       "player-1",
+		Payload:        "e30=",
+		IdempotencyKey: "key-1",
+	})
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusConflict, rec.Code, rec.Body.String())
+	}
+	var resp errorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Code != "command_in_flight" {
+		t.Errorf("Expected code %q, got %q", "command_in_flight", resp.Code)
+	}
+
+	rec = doJSON(t, srv, http.MethodPost, "/v1/bot/webhook", BotWebhookRequest{Channel: "discord", Payload: "e30=", IdempotencyKey: "key-2"})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected validation failure status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+// echoExecutor answers a synchronous command with its own payload, or fails
+// with err.
+type echoExecutor struct{ err error }
+
+func (e echoExecutor) Execute(ctx context.Context, command *domainbot.Command) ([]byte, error) {
+	return command.Payload, e.err
+}
+
+func TestHandleBotWebhook_Sync(t *testing.T) {
+	tests := []struct {
+		name       string
+		executor   bot.Executor
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "executed inline", executor: echoExecutor{}, wantStatus: http.StatusOK},
+		{name: "executor failure", executor: echoExecutor{err: errors.New("wallet unavailable")}, wantStatus: http.StatusBadGateway},
+		{name: "no executor", wantStatus: http.StatusBadRequest, wantCode: "sync_unsupported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := bot.NewService(stubBotRepo{}, stubProducer{}, nil)
+			service.Idempotency = idempotencyinfra.NewMemoryStore()
+			service.Executor = tt.executor
+			srv := newTestServer(ServerConfig{BotService: service})
+
+			rec := doJSON(t, srv, http.MethodPost, "/v1/bot/webhook", BotWebhookRequest{
+				CommandID:      "command-1",
+				Channel:        "discord",
+				Payload:        "e30=",
+				IdempotencyKey: "key-1",
+				Sync:           true,
+			})
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				var resp errorResponse
+				if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+					t.Fatalf("decoding response: %v", err)
+				}
+				if resp.Code != tt.wantCode {
+					t.Errorf("Expected code %q, got %q", tt.wantCode, resp.Code)
+				}
+				return
+			}
+			var resp BotWebhookResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if !resp.Accepted || string(resp.Response) != "{}" {
+				t.Errorf("Expected the executor response, got %+v", resp)
+			}
+		})
+	}
+}
+
+func TestHandleBotWebhook_MalformedPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantMsg string
+	}{
+		{name: "malformed base64", payload: "not base64!", wantMsg: "payload must be valid base64"},
+		{name: "empty payload", payload: "", wantMsg: "payload is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(ServerConfig{BotService: bot.NewService(stubBotRepo{}, stubProducer{}, nil)})
+
+			rec := doJSON(t, srv, http.MethodPost, "/v1/bot/webhook", BotWebhookRequest{
+				CommandID:      "command-1",
+				Channel:        "discord",
+				PlayerID:       "player-1",
+				Payload:        tt.payload,
+				IdempotencyKey: "key-1",
+			})
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+			}
+			var resp errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if resp.Field != "payload" || resp.Error != tt.wantMsg {
+				t.Errorf("Expected payload error %q, got field %q error %q", tt.wantMsg, resp.Field, resp.Error)
+			}
+		})
+	}
+}
+
+type fakeRefreshProvider struct {
+	auth.AuthProvider
+	sessions map[string]auth.AuthResult
+}
+
+func (p *fakeRefreshProvider) SessionRefresh(ctx context.Context, refreshToken string) (auth.AuthResult, error) {
+	result, ok := p.sessions[refreshToken]
+	if !ok {
+		return auth.AuthResult{}, fmt.Errorf("invalid refresh token")
+	}
+	return result, nil
+}
+
+func TestHandleAuthRefresh(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := playerinfra.NewMemoryRepository()
+	for _, id := range []shared.PlayerID{"player-1", "player-2"} {
+		account, err := player.NewPlayerAccount(id, string(id)+"@example.com", string(id), now)
+		if err != nil {
+			t.Fatalf("NewPlayerAccount() error = %v", err)
+		}
+		_ = repo.Save(context.Background(), account)
+	}
+	suspended, _ := repo.GetByID(context.Background(), "player-2")
+	suspended.Suspend("cheating")
+
+	provider := &fakeRefreshProvider{sessions: map[string]auth.AuthResult{
+		"refresh-1": {UserID: "player-1", SessionToken: "session-2", RefreshToken: "refresh-2"},
+		"refresh-9": {UserID: "player-2", SessionToken: "session-9", RefreshToken: "refresh-10"},
+	}}
+	srv := newTestServer(ServerConfig{AuthService: auth.NewService(repo, provider)})
+
+	rec := doJSON(t, srv, http.MethodPost, "/v1/auth/refresh", AuthRefreshRequest{RefreshToken: "refresh-1"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp AuthLoginResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.UserID != "player-1" || resp.SessionToken != "session-2" || resp.RefreshToken != "refresh-2" {
+		t.Errorf("Unexpected refresh response %+v", resp)
+	}
+	account, _ := repo.GetByID(context.Background(), "player-1")
+	if n := len(account.Sessions); n == 0 || account.Sessions[n-1].SessionID != "session-2" {
+		t.Errorf("Expected rotated session to be recorded, got %+v", account.Sessions)
+	}
+
+	for name, token := range map[string]string{"invalid token": "bogus", "suspended account": "refresh-9", "missing token": ""} {
+		t.Run(name, func(t *testing.T) {
+			rec := doJSON(t, srv, http.MethodPost, "/v1/auth/refresh", AuthRefreshRequest{RefreshToken: token})
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+			}
+		})
+	}
+}
+
+type fakeCustomProvider struct {
+	auth.AuthProvider
+	users map[string]shared.PlayerID
+}
+
+func (p *fakeCustomProvider) AuthenticateCustom(ctx context.Context, token string, vars map[string]string) (auth.AuthResult, error) {
+	id, ok := p.users[token]
+	if !ok {
+		return auth.AuthResult{}, fmt.Errorf("invalid custom token")
+	}
+	return auth.AuthResult{UserID: id, SessionToken: "session-" + string(id), Username: string(id)}, nil
+}
+
+func TestHandleAuthLogin_Custom(t *testing.T) {
+	repo := playerinfra.NewMemoryRepository()
+	provider := &fakeCustomProvider{users: map[string]shared.PlayerID{"jwt-1": "player-1"}}
+	srv := newTestServer(ServerConfig{AuthService: auth.NewService(repo, provider)})
+
+	rec := doJSON(t, srv, http.MethodPost, "/v1/auth/login", AuthLoginRequest{Strategy: "custom", Token: "jwt-1", Vars: map[string]string{"email": "player-1@example.com"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp AuthLoginResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.UserID != "player-1" || resp.SessionToken != "session-player-1" {
+		t.Errorf("Unexpected login response %+v", resp)
+	}
+	if _, err := repo.GetByID(context.Background(), "player-1"); err != nil {
+		t.Errorf("Expected account to be created, got %v", err)
+	}
+
+	rec = doJSON(t, srv, http.MethodPost, "/v1/auth/login", AuthLoginRequest{Strategy: "custom"})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for missing token, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	rec = doJSON(t, srv, http.MethodPost, "/v1/auth/login", AuthLoginRequest{Strategy: "custom", Token: "bogus"})
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for invalid token, got %d", http.StatusUnauthorized, rec.Code)
+	}
+}
+
+type fakeLoginProvider struct {
+	auth.AuthProvider
+}
+
+func (p *fakeLoginProvider) AuthenticateEmail(ctx context.Context, email, password string, vars map[string]string) (auth.AuthResult, error) {
+	if password != "secret" {
+		return auth.AuthResult{}, fmt.Errorf("invalid credentials")
+	}
+	return auth.AuthResult{UserID: shared.PlayerID(strings.Split(email, "@")[0]), SessionToken: "session"}, nil
+}
+
+func TestHandleAuthLogin_Suspended(t *testing.T) {
+	repo := playerinfra.NewMemoryRepository()
+	account, err := player.NewPlayerAccount("player-1", "player-1@example.com", "player-1", time.Now())
+	if err != nil {
+		t.Fatalf("NewPlayerAccount() error = %v", err)
+	}
+	account.Suspend("cheating")
+	_ = repo.Save(context.Background(), account)
+	srv := newTestServer(ServerConfig{AuthService: auth.NewService(repo, &fakeLoginProvider{})})
+
+	rec := doJSON(t, srv, http.MethodPost, "/v1/auth/login", AuthLoginRequest{Email: "player-1@example.com", Password: "secret"})
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusForbidden, rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "cheating") {
+		t.Errorf("Expected suspension message in body, got %s", rec.Body.String())
+	}
+
+	rec = doJSON(t, srv, http.MethodPost, "/v1/auth/login", AuthLoginRequest{Email: "player-1@example.com", Password: "wrong"})
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for bad credentials, got %d", http.StatusUnauthorized, rec.Code)
+	}
+}
+
+type stubBattleRepo struct {
+	battles.Repository
+	battles map[shared.BattleID]*battle.Battle
+}
+
+func (r *stubBattleRepo) Get(ctx context.Context, id shared.BattleID) (*battle.Battle, error) {
+	if b, ok := r.battles[id]; ok {
+		return b, nil
+	}
+	return nil, shared.ErrNotFound
+}
+
+func (r *stubBattleRepo) Save(ctx context.Context, b *battle.Battle) error {
+	r.battles[b.ID] = b
+	return nil
+}
+
+func TestHandleJoinBattle(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	existing, err := battle.NewBattle("battle-1", "leader-1", "key-1", now)
+	if err != nil {
+		t.Fatalf("NewBattle() error = %v", err)
+	}
+	repo := &stubBattleRepo{battles: map[shared.BattleID]*battle.Battle{existing.ID: existing}}
+	srv := newTestServer(ServerConfig{BattleService: battles.NewService(repo, nil)})
+
+	rec := doJSON(t, srv, http.MethodPost, "/v1/battles/battle-1/join", map[string]string{"player_id": "player-2"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp JoinBattleResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.BattleID != "battle-1" || len(resp.Slots) != 2 || resp.Slots[1].PlayerID != "player-2" || resp.Slots[1].Ready {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		playerID   string
+		wantStatus int
+	}{
+		{name: "already joined", path: "/v1/battles/battle-1/join", playerID: "player-2", wantStatus: http.StatusConflict},
+		{name: "missing battle", path: "/v1/battles/missing/join", playerID: "player-3", wantStatus: http.StatusNotFound},
+		{name: "missing player", path: "/v1/battles/battle-1/join", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doJSON(t, srv, http.MethodPost, tt.path, map[string]string{"player_id": tt.playerID})
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleGetLobby(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	existing, err := battle.NewBattle("battle-1", "leader-1", "key-1", now)
+	if err != nil {
+		t.Fatalf("NewBattle() error = %v", err)
+	}
+	existing.MatchID = "match-1"
+	_ = existing.AddPlayer("player-2", now.Add(time.Minute))
+
+	repo := &stubBattleRepo{battles: map[shared.BattleID]*battle.Battle{existing.ID: existing}}
+	srv := newTestServer(ServerConfig{BattleService: battles.NewService(repo, nil)})
+
+	rec := doJSON(t, srv, http.MethodGet, "/v1/battles/battle-1/lobby", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp LobbyResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Leader != "leader-1" || resp.MatchID != "match-1" || resp.Status != "active" || resp.AllReady {
+		t.Errorf("Unexpected lobby %+v", resp)
+	}
+	if len(resp.Slots) != 2 || !resp.Slots[0].Ready || resp.Slots[1].PlayerID != "player-2" || resp.Slots[1].Ready {
+		t.Errorf("Unexpected slots %+v", resp.Slots)
+	}
+	if resp.Slots[1].JoinedAt != "2024-03-01T12:01:00Z" {
+		t.Errorf("Expected joined_at %q, got %q", "2024-03-01T12:01:00Z", resp.Slots[1].JoinedAt)
+	}
+
+	rec = doJSON(t, srv, http.MethodGet, "/v1/battles/missing/lobby", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestHandleSetReady(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	existing, err := battle.NewBattle("battle-1", "leader-1", "key-1", now)
+	if err != nil {
+		t.Fatalf("NewBattle() error = %v", err)
+	}
+	_ = existing.AddPlayer("player-2", now)
+	repo := &stubBattleRepo{battles: map[shared.BattleID]*battle.Battle{existing.ID: existing}}
+	srv := newTestServer(ServerConfig{BattleService: battles.NewService(repo, nil)})
+
+	rec := doJSON(t, srv, http.MethodPatch, "/v1/battles/battle-1/ready", SetReadyRequest{PlayerID: "player-2", Ready: true})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp LobbyResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if !resp.AllReady || len(resp.Slots) != 2 || !resp.Slots[1].Ready {
+		t.Errorf("Unexpected lobby %+v", resp)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		playerID   string
+		wantStatus int
+	}{
+		{name: "not in battle", path: "/v1/battles/battle-1/ready", playerID: "player-3", wantStatus: http.StatusNotFound},
+		{name: "missing battle", path: "/v1/battles/missing/ready", playerID: "player-2", wantStatus: http.StatusNotFound},
+		{name: "missing player", path: "/v1/battles/battle-1/ready", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doJSON(t, srv, http.MethodPatch, tt.path, SetReadyRequest{PlayerID: tt.playerID, Ready: true})
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleListPlayerBattles(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := battleinfra.NewMemoryRepository()
+	for i, id := range []shared.BattleID{"battle-1", "battle-2"} {
+		b, err := battle.NewBattle(id, "leader-1", shared.IdempotencyKey("key-"+string(id)), now.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("NewBattle() error = %v", err)
+		}
+		_ = b.AddPlayer("player-2", now)
+		if err := repo.Save(context.Background(), b); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	srv := newTestServer(ServerConfig{BattleService: battles.NewService(repo, nil)})
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{name: "multiple battles", path: "/v1/players/player-2/battles", wantStatus: http.StatusOK, wantIDs: []string{"battle-2", "battle-1"}},
+		{name: "paged", path: "/v1/players/player-2/battles?limit=1&offset=1", wantStatus: http.StatusOK, wantIDs: []string{"battle-1"}},
+		{name: "no battles", path: "/v1/players/player-3/battles", wantStatus: http.StatusOK, wantIDs: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doJSON(t, srv, http.MethodGet, tt.path, nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			var resp ListPlayerBattlesResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			ids := make([]string, 0, len(resp.Battles))
+			for _, b := range resp.Battles {
+				ids = append(ids, b.BattleID)
+				if b.Leader != "leader-1" || b.CreatedAt == "" {
+					t.Errorf("Unexpected battle %+v", b)
+				}
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("Expected battles %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+// seasonRepo is a leaderboard repository that only stores seasons; scores
+// go through a ScoreStore.
+type seasonRepo struct {
+	leaderboardsvc.Repository
+	seasons map[shared.SeasonID]leaderboard.Season
+}
+
+func newSeasonRepo(seasons ...leaderboard.Season) *seasonRepo {
+	repo := &seasonRepo{seasons: make(map[shared.SeasonID]leaderboard.Season)}
+	for _, season := range seasons {
+		repo.seasons[season.ID] = season
+	}
+	return repo
+}
+
+func (r *seasonRepo) CreateSeason(ctx context.Context, season *leaderboard.Season) error {
+	if _, exists := r.seasons[season.ID]; exists {
+		return leaderboard.ErrSeasonAlreadyExists
+	}
+	r.seasons[season.ID] = *season
+	return nil
+}
+
+func (r *seasonRepo) GetSeason(ctx context.Context, id shared.SeasonID) (*leaderboard.Season, error) {
+	season, exists := r.seasons[id]
+	if !exists {
+		return nil, leaderboard.ErrSeasonNotFound
+	}
+	return &season, nil
+}
+
+func newLeaderboardTestServer(t *testing.T, scores map[shared.PlayerID]int64) *Server {
+	t.Helper()
+	service := leaderboardsvc.NewService(newSeasonRepo(leaderboard.Season{
+		ID:       "season-1",
+		StartsAt: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+	}))
+	service.Scores = leaderboardinfra.NewMemoryScoreStore(leaderboardinfra.DefaultExpiredCapacity)
+	for player, score := range scores {
+		if _, err := service.Submit(context.Background(), leaderboardsvc.SubmitCommand{
+			PlayerID:       player,
+			SeasonID:       "season-1",
+			Score:          score,
+			IdempotencyKey: shared.IdempotencyKey("submit-" + string(player)),
+		}); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+	return newTestServer(ServerConfig{LeaderboardService: service})
+}
+
+func TestHandleListLeaderboard(t *testing.T) {
+	srv := newLeaderboardTestServer(t, map[shared.PlayerID]int64{"player-1": 300, "player-2": 900, "player-3": 600})
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantOwners []string
+		wantCursor string
+	}{
+		{name: "first page", path: "/v1/leaderboard/season-1?limit=2", wantStatus: http.StatusOK, wantOwners: []string{"player-2", "player-3"}, wantCursor: "2"},
+		{name: "last page", path: "/v1/leaderboard/season-1?limit=2&cursor=2", wantStatus: http.StatusOK, wantOwners: []string{"player-1"}},
+		{name: "empty season", path: "/v1/leaderboard/season-2", wantStatus: http.StatusOK, wantOwners: []string{}},
+		{name: "unknown window", path: "/v1/leaderboard/season-1?window=weekly", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doJSON(t, srv, http.MethodGet, tt.path, nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp ListLeaderboardResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			owners := make([]string, 0, len(resp.Records))
+			for _, record := range resp.Records {
+				owners = append(owners, record.OwnerID)
+			}
+			if strings.Join(owners, ",") != strings.Join(tt.wantOwners, ",") {
+				t.Errorf("Expected owners %v, got %v", tt.wantOwners, owners)
+			}
+			if resp.NextCursor != tt.wantCursor {
+				t.Errorf("Expected next cursor %q, got %q", tt.wantCursor, resp.NextCursor)
+			}
+		})
+	}
+}
+
+func TestHandleResetSeason(t *testing.T) {
+	service := leaderboardsvc.NewService(newSeasonRepo(leaderboard.Season{
+		ID:       "weekly",
+		StartsAt: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+	}))
+	service.Scores = leaderboardinfra.NewMemoryScoreStore(leaderboardinfra.DefaultExpiredCapacity)
+	submittedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.Clock = func() time.Time { return submittedAt }
+	if _, err := service.Submit(context.Background(), leaderboardsvc.SubmitCommand{PlayerID: "player-1", SeasonID: "weekly", Score: 500, IdempotencyKey: "submit-1"}); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	srv := newTestServer(ServerConfig{LeaderboardService: service, AdminKey: testAdminKey})
+
+	if rec := doJSON(t, srv, http.MethodPost, "/v1/admin/seasons/weekly/reset", ResetSeasonRequest{}); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status %d without the admin key, got %d", http.StatusUnauthorized, rec.Code)
+	}
+	if rec := doAdmin(t, srv, http.MethodPost, "/v1/admin/seasons/weekly/reset", ResetSeasonRequest{ResetAt: "yesterday"}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d for a malformed reset time, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	rec := doAdmin(t, srv, http.MethodPost, "/v1/admin/seasons/weekly/reset", ResetSeasonRequest{ResetAt: "2024-03-02T00:00:00Z"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp ResetSeasonResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Expired != 1 {
+		t.Errorf("Expected 1 score expired, got %d", resp.Expired)
+	}
+
+	for path, want := range map[string]int{"/v1/leaderboard/weekly": 0, "/v1/leaderboard/weekly?window=all_time": 1} {
+		var list ListLeaderboardResponse
+		if err := json.NewDecoder(doJSON(t, srv, http.MethodGet, path, nil).Body).Decode(&list); err != nil {
+			t.Fatalf("decoding %s: %v", path, err)
+		}
+		if len(list.Records) != want {
+			t.Errorf("Expected %d records from %s, got %+v", want, path, list.Records)
+		}
+	}
+
+	noScores := newTestServer(ServerConfig{LeaderboardService: leaderboardsvc.NewService(newSeasonRepo()), AdminKey: testAdminKey})
+	if rec := doAdmin(t, noScores, http.MethodPost, "/v1/admin/seasons/weekly/reset", ResetSeasonRequest{}); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d without score windows, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+}
+
+func TestHandleGetLeaderboardRank(t *testing.T) {
+	srv := newLeaderboardTestServer(t, map[shared.PlayerID]int64{"player-1": 300, "player-2": 900})
+
+	rec := doJSON(t, srv, http.MethodGet, "/v1/leaderboard/season-1/rank/player-1", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp LeaderboardRankResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if want := (LeaderboardRankResponse{OwnerID: "player-1", Score: 300, Rank: 2}); resp != want {
+		t.Errorf("Expected rank %+v, got %+v", want, resp)
+	}
+
+	rec = doJSON(t, srv, http.MethodGet, "/v1/leaderboard/season-1/rank/player-9", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a player without a score, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestHandleSeasons(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := leaderboardsvc.NewService(newSeasonRepo())
+	service.Scores = leaderboardinfra.NewMemoryScoreStore(leaderboardinfra.DefaultExpiredCapacity)
+	service.Clock = func() time.Time { return now }
+	srv := newTestServer(ServerConfig{LeaderboardService: service})
+
+	tests := []struct {
+		name       string
+		req        CreateSeasonRequest
+		wantStatus int
+		wantActive bool
+	}{
+		{name: "open season", req: CreateSeasonRequest{SeasonID: "season-1", StartsAt: "2024-03-01T00:00:00Z", EndsAt: "2024-03-08T00:00:00Z"}, wantStatus: http.StatusCreated, wantActive: true},
+		{name: "scheduled season", req: CreateSeasonRequest{SeasonID: "season-2", StartsAt: "2024-04-01T00:00:00Z", EndsAt: "2024-04-08T00:00:00Z"}, wantStatus: http.StatusCreated},
+		{name: "duplicate", req: CreateSeasonRequest{SeasonID: "season-1", StartsAt: "2024-03-01T00:00:00Z", EndsAt: "2024-03-08T00:00:00Z"}, wantStatus: http.StatusConflict},
+		{name: "malformed start", req: CreateSeasonRequest{SeasonID: "season-3", StartsAt: "tomorrow", EndsAt: "2024-03-08T00:00:00Z"}, wantStatus: http.StatusBadRequest},
+		{name: "ends before start", req: CreateSeasonRequest{SeasonID: "season-3", StartsAt: "2024-03-08T00:00:00Z", EndsAt: "2024-03-01T00:00:00Z"}, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doJSON(t, srv, http.MethodPost, "/v1/seasons", tt.req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			var resp SeasonResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			want := SeasonResponse{SeasonID: tt.req.SeasonID, StartsAt: tt.req.StartsAt, EndsAt: tt.req.EndsAt, Active: tt.wantActive}
+			if resp != want {
+				t.Errorf("Expected season %+v, got %+v", want, resp)
+			}
+		})
+	}
+
+	rec := doJSON(t, srv, http.MethodGet, "/v1/seasons/season-2", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec = doJSON(t, srv, http.MethodGet, "/v1/seasons/missing", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown season, got %d", http.StatusNotFound, rec.Code)
+	}
+
+	rec = doJSON(t, srv, http.MethodPost, "/v1/leaderboard/season-2", SubmitScoreRequest{PlayerID: "player-1", Score: 100, IdempotencyKey: "submit-1"})
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "season_inactive") {
+		t.Errorf("Expected a season_inactive conflict before the season opens, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleRemoveDevice(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := playerinfra.NewMemoryRepository()
+	account, err := player.NewPlayerAccount("player-1", "player-1@example.com", "Alice", now)
+	if err != nil {
+		t.Fatalf("NewPlayerAccount() error = %v", err)
+	}
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "phone", LastSeen: now})
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "tablet", LastSeen: now})
+	_ = repo.Save(context.Background(), account)
+	authService := auth.NewService(repo, nil)
+	authService.RequireDevice = true
+	srv := newTestServer(ServerConfig{AuthService: authService, SessionKey: testSessionKey})
+
+	tests := []struct {
+		name       string
+		path       string
+		caller     string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "no session", path: "/v1/accounts/player-1/devices/phone", wantStatus: http.StatusUnauthorized},
+		{name: "another player", path: "/v1/accounts/player-1/devices/phone", caller: "player-2", wantStatus: http.StatusForbidden},
+		{name: "removed", path: "/v1/accounts/player-1/devices/phone", caller: "player-1", wantStatus: http.StatusNoContent},
+		{name: "unknown device", path: "/v1/accounts/player-1/devices/phone", caller: "player-1", wantStatus: http.StatusNotFound},
+		{name: "last device", path: "/v1/accounts/player-1/devices/tablet", caller: "player-1", wantStatus: http.StatusConflict, wantCode: "last_device"},
+		{name: "unknown account", path: "/v1/accounts/missing/devices/tablet", caller: "missing", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rec *httptest.ResponseRecorder
+			if tt.caller != "" {
+				rec = doJSONAs(t, srv, tt.caller, http.MethodDelete, tt.path, nil)
+			} else {
+				rec = doJSON(t, srv, http.MethodDelete, tt.path, nil)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var resp errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("Expected code %q, got %q", tt.wantCode, resp.Code)
+			}
+		})
+	}
+}
+
+// memoryGroupRepo keeps groups in a map; the group service only needs Get and Save.
+type memoryGroupRepo struct {
+	groups.Repository
+	groups map[shared.GroupID]*group.Group
+}
+
+func (r *memoryGroupRepo) Get(ctx context.Context, id shared.GroupID) (*group.Group, error) {
+	g, ok := r.groups[id]
+	if !ok {
+		return nil, shared.ErrNotFound
+	}
+	return g, nil
+}
+
+func (r *memoryGroupRepo) Save(ctx context.Context, g *group.Group) error {
+	r.groups[g.ID] = g
+	return nil
+}
+
+// recordingGroupProvider records metadata updates.
+type recordingGroupProvider struct {
+	groups.Provider
+	metadata map[shared.GroupID]map[string]any
+}
+
+func (p *recordingGroupProvider) UpdateMetadata(ctx context.Context, groupID shared.GroupID, metadata map[string]any) error {
+	p.metadata[groupID] = metadata
+	return nil
+}
+
+func TestHandleGroupMembers(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	owned, err := group.NewGroup("group-1", "Night Owls", "owner-1", 0, now, nil)
+	if err != nil {
+		t.Fatalf("NewGroup() error = %v", err)
+	}
+	service := groups.NewService(&memoryGroupRepo{groups: map[shared.GroupID]*group.Group{owned.ID: owned}}, nil)
+	service.Clock = func() time.Time { return now }
+	srv := newTestServer(ServerConfig{GroupService: service, SessionKey: testSessionKey})
+
+	rec := doJSONAs(t, srv, "player-1", http.MethodPost, "/v1/groups/group-1/members", nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var member GroupMemberResponse
+	if err := json.NewDecoder(rec.Body).Decode(&member); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if want := (GroupMemberResponse{PlayerID: "player-1", Role: "member", JoinedAt: "2024-03-01T12:00:00Z"}); member != want {
+		t.Errorf("Expected member %+v, got %+v", want, member)
+	}
+
+	if rec := doJSONAs(t, srv, "player-2", http.MethodPost, "/v1/groups/group-1/members", nil); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		caller     string
+		body       any
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "join twice", method: http.MethodPost, path: "/v1/groups/group-1/members", caller: "player-1", wantStatus: http.StatusConflict},
+		{name: "join unknown group", method: http.MethodPost, path: "/v1/groups/group-2/members", caller: "player-1", wantStatus: http.StatusNotFound},
+		{name: "join without session", method: http.MethodPost, path: "/v1/groups/group-1/members", wantStatus: http.StatusUnauthorized},
+		{name: "leave without session", method: http.MethodDelete, path: "/v1/groups/group-1/members/player-1", wantStatus: http.StatusUnauthorized},
+		{name: "member removes another member", method: http.MethodDelete, path: "/v1/groups/group-1/members/player-1", caller: "player-2", wantStatus: http.StatusForbidden},
+		{name: "owner leaves", method: http.MethodDelete, path: "/v1/groups/group-1/members/owner-1", caller: "owner-1", wantStatus: http.StatusConflict, wantCode: "owner_must_transfer"},
+		{name: "owner removes a member", method: http.MethodDelete, path: "/v1/groups/group-1/members/player-2", caller: "owner-1", wantStatus: http.StatusNoContent},
+		{name: "member leaves", method: http.MethodDelete, path: "/v1/groups/group-1/members/player-1", caller: "player-1", wantStatus: http.StatusNoContent},
+		{name: "member leaves twice", method: http.MethodDelete, path: "/v1/groups/group-1/members/player-1", caller: "player-1", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rec *httptest.ResponseRecorder
+			if tt.caller != "" {
+				rec = doJSONAs(t, srv, tt.caller, tt.method, tt.path, tt.body)
+			} else {
+				rec = doJSON(t, srv, tt.method, tt.path, tt.body)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantCode != "" && !strings.Contains(rec.Body.String(), tt.wantCode) {
+				t.Errorf("Expected code %q in %s", tt.wantCode, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleUpdateGroupMetadata(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	owned, err := group.NewGroup("group-1", "Night Owls", "owner-1", 0, now, nil)
+	if err != nil {
+		t.Fatalf("NewGroup() error = %v", err)
+	}
+	if _, err := owned.AddMember("player-1", now); err != nil {
+		t.Fatalf("AddMember() error = %v", err)
+	}
+	provider := &recordingGroupProvider{metadata: make(map[shared.GroupID]map[string]any)}
+	service := groups.NewService(&memoryGroupRepo{groups: map[shared.GroupID]*group.Group{owned.ID: owned}}, provider)
+	srv := newTestServer(ServerConfig{GroupService: service, SessionKey: testSessionKey})
+
+	tests := []struct {
+		name       string
+		path       string
+		caller     string
+		wantStatus int
+	}{
+		{name: "owner", path: "/v1/groups/group-1/metadata", caller: "owner-1", wantStatus: http.StatusNoContent},
+		{name: "member", path: "/v1/groups/group-1/metadata", caller: "player-1", wantStatus: http.StatusForbidden},
+		{name: "outsider", path: "/v1/groups/group-1/metadata", caller: "player-2", wantStatus: http.StatusForbidden},
+		{name: "unknown group", path: "/v1/groups/group-2/metadata", caller: "owner-1", wantStatus: http.StatusNotFound},
+		{name: "no session", path: "/v1/groups/group-1/metadata", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider.metadata = make(map[shared.GroupID]map[string]any)
+			req := UpdateGroupMetadataRequest{Metadata: map[string]any{"banner": "wolf"}}
+			var rec *httptest.ResponseRecorder
+			if tt.caller != "" {
+				rec = doJSONAs(t, srv, tt.caller, http.MethodPatch, tt.path, req)
+			} else {
+				rec = doJSON(t, srv, http.MethodPatch, tt.path, req)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			wantUpdated := tt.wantStatus == http.StatusNoContent
+			if updated := len(provider.metadata) == 1; updated != wantUpdated {
+				t.Errorf("Expected metadata updated = %v, got %v", wantUpdated, provider.metadata)
+			}
+		})
+	}
+}
+
+// memoryBotRepo keeps saved commands so they can be read back by ID.
+type memoryBotRepo struct {
+	stubBotRepo
+	commands map[shared.BotCommandID]*domainbot.Command
+}
+
+func (r *memoryBotRepo) Get(ctx context.Context, id shared.BotCommandID) (*domainbot.Command, error) {
+	cmd, ok := r.commands[id]
+	if !ok {
+		return nil, domainbot.ErrCommandNotFound
+	}
+	return cmd, nil
+}
+
+func (r *memoryBotRepo) Save(ctx context.Context, command *domainbot.Command) error {
+	r.commands[command.ID] = command
+	return nil
+}
+
+func TestHandleBotCommandStatus(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := bot.NewService(&memoryBotRepo{commands: make(map[shared.BotCommandID]*domainbot.Command)}, stubProducer{}, nil)
+	service.Idempotency = idempotencyinfra.NewMemoryStore()
+	service.Clock = func() time.Time { return now }
+	srv := newTestServer(ServerConfig{BotService: service, AdminKey: testAdminKey})
+
+	rec := doJSON(t, srv, http.MethodPost, "/v1/bot/webhook", BotWebhookRequest{CommandID: "command-1", Channel: "discord", Payload: "e30=", IdempotencyKey: "key-1"})
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, rec.Code, rec.Body.String())
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       any
+		anonymous  bool
+		wantStatus int
+		want       BotCommandResponse
+	}{
+		{
+			name: "pending", method: http.MethodGet, path: "/v1/bot/commands/command-1", wantStatus: http.StatusOK,
+			want: BotCommandResponse{CommandID: "command-1", Channel: "discord", State: "pending", CreatedAt: "2024-03-01T12:00:00Z"},
+		},
+		{name: "complete without admin key", method: http.MethodPost, path: "/v1/bot/commands/command-1/complete", body: CompleteBotCommandRequest{}, anonymous: true, wantStatus: http.StatusUnauthorized},
+		{
+			name: "failed attempt", method: http.MethodPost, path: "/v1/bot/commands/command-1/complete", body: CompleteBotCommandRequest{Error: "discord timeout"}, wantStatus: http.StatusOK,
+			want: BotCommandResponse{CommandID: "command-1", Channel: "discord", State: "failed", RetryCount: 1, LastError: "discord timeout", CreatedAt: "2024-03-01T12:00:00Z", AttemptedAt: formatOptionalTime(&now)},
+		},
+		{
+			name: "completed", method: http.MethodPost, path: "/v1/bot/commands/command-1/complete", body: CompleteBotCommandRequest{}, wantStatus: http.StatusOK,
+			want: BotCommandResponse{CommandID: "command-1", Channel: "discord", State: "completed", RetryCount: 1, CreatedAt: "2024-03-01T12:00:00Z", AttemptedAt: formatOptionalTime(&now), CompletedAt: formatOptionalTime(&now)},
+		},
+		{name: "unknown command", method: http.MethodGet, path: "/v1/bot/commands/command-2", wantStatus: http.StatusNotFound},
+		{name: "complete unknown command", method: http.MethodPost, path: "/v1/bot/commands/command-2/complete", body: CompleteBotCommandRequest{}, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rec *httptest.ResponseRecorder
+			if tt.anonymous {
+				rec = doJSON(t, srv, tt.method, tt.path, tt.body)
+			} else {
+				rec = doAdmin(t, srv, tt.method, tt.path, tt.body)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got BotCommandResponse
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
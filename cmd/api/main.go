@@ -2,33 +2,144 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/heroiclabs/nakama/v3/apigrpc"
+	"github.com/heroiclabs/nakama/v3/src/app/analytics"
 	"github.com/heroiclabs/nakama/v3/src/app/auth"
 	"github.com/heroiclabs/nakama/v3/src/app/battles"
 	"github.com/heroiclabs/nakama/v3/src/app/bot"
 	"github.com/heroiclabs/nakama/v3/src/app/groups"
 	leaderboardsvc "github.com/heroiclabs/nakama/v3/src/app/leaderboard"
+	"github.com/heroiclabs/nakama/v3/src/app/tournaments"
+	domainanalytics "github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	analyticsinfra "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+	battleinfra "github.com/heroiclabs/nakama/v3/src/infra/battle"
+	botinfra "github.com/heroiclabs/nakama/v3/src/infra/bot"
+	idempotencyinfra "github.com/heroiclabs/nakama/v3/src/infra/idempotency"
+	leaderboardinfra "github.com/heroiclabs/nakama/v3/src/infra/leaderboard"
+	metricsinfra "github.com/heroiclabs/nakama/v3/src/infra/metrics"
 	nakamainfra "github.com/heroiclabs/nakama/v3/src/infra/nakama"
+	playerinfra "github.com/heroiclabs/nakama/v3/src/infra/player"
+	tournamentinfra "github.com/heroiclabs/nakama/v3/src/infra/tournament"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type Config struct {
-	HTTPAddress       string
-	NakamaGRPCAddress string
+	HTTPAddress        string
+	NakamaGRPCAddress  string
+	SegmentWriteKey    string
+	AnalyticsMaxEvents int
+	AnalyticsBatchSize int
+	AnalyticsHighWater int
+	AnalyticsSpillDir  string
+	AnalyticsBatchWait time.Duration
+	PlayerSearchMode   string
+	CorrelationHeaders []string
+	Maintenance        bool
+	MaintenanceMessage string
+	MaintenanceExempt  []string
+	StrictJSON         bool
+	PlayerCacheSize    int
+	PlayerCacheTTL     time.Duration
+	ExperimentSeed     string
+	NakamaDial         DialRetryConfig
+	BlockedWords       []string
+	BlockedWordsFile   string
+	Compression        bool
+	CompressionMinSize int
+	RequireDevice      bool
+	MaxSessions        int
+	SessionLimitPolicy string
+	// AttemptBatchWindow and AttemptsInFlight enable tournament attempt
+	// batching when either is set.
+	AttemptBatchWindow time.Duration
+	AttemptsInFlight   int
+	// Redis enables the durable bot command queue when Addr is set;
+	// otherwise commands go to the no-op BotQueue.
+	Redis        botinfra.RedisConfig
+	BotQueueKey  string
+	MaxBodyBytes int
+	// DatabaseURL points at the Nakama database, where the runtime module
+	// stores final tournament standings and analytics sessions are kept.
+	// Without it the standings endpoint answers 503 and sessions are held in
+	// memory.
+	DatabaseURL string
+	// NakamaHTTPKey authenticates server-to-server RPCs. When set, account
+	// changes are synced to Nakama through the runtime module.
+	NakamaHTTPKey string
+	// SessionKey is Nakama's session encryption key, used to verify the
+	// caller's session token.
+	SessionKey string
+	// AdminKey is the operator credential for admin endpoints such as the
+	// maintenance toggle.
+	AdminKey string
+	// LeaderboardScoreWindows keeps leaderboard scores in this process rather
+	// than in Nakama. Submissions then apply their operator with conditional
+	// writes, and resetting a season closes its current window while the
+	// closed scores stay in all-time listings. Scores are lost on restart.
+	LeaderboardScoreWindows bool
 }
 
 func loadConfig() Config {
 	cfg := Config{
-		HTTPAddress:       getEnv("SANDAI_HTTP_ADDR", ":8080"),
-		NakamaGRPCAddress: getEnv("SANDAI_NAKAMA_GRPC_ADDR", "127.0.0.1:7349"),
+		HTTPAddress:        getEnv("SANDAI_HTTP_ADDR", ":8080"),
+		NakamaGRPCAddress:  getEnv("SANDAI_NAKAMA_GRPC_ADDR", "127.0.0.1:7349"),
+		SegmentWriteKey:    getEnv("SANDAI_SEGMENT_WRITE_KEY", ""),
+		AnalyticsMaxEvents: getEnvInt("SANDAI_ANALYTICS_MAX_EVENTS", 500),
+		AnalyticsBatchSize: getEnvInt("SANDAI_ANALYTICS_BATCH_SIZE", analytics.DefaultBatchSize),
+		AnalyticsHighWater: getEnvInt("SANDAI_ANALYTICS_HIGH_WATER_MARK", analyticsinfra.DefaultAsyncBufferSize*3/4),
+		CorrelationHeaders: getEnvList("SANDAI_CORRELATION_HEADERS"),
+		AnalyticsSpillDir:  getEnv("SANDAI_ANALYTICS_SPILL_DIR", ""),
+		AnalyticsBatchWait: getEnvDuration("SANDAI_ANALYTICS_FLUSH_INTERVAL", 0),
+		PlayerSearchMode:   getEnv("SANDAI_PLAYER_SEARCH_MODE", string(player.SearchPrefix)),
+		Maintenance:        getEnvBool("SANDAI_MAINTENANCE_MODE", false),
+		MaintenanceMessage: getEnv("SANDAI_MAINTENANCE_MESSAGE", ""),
+		MaintenanceExempt:  getEnvList("SANDAI_MAINTENANCE_EXEMPT_PATHS"),
+		StrictJSON:         getEnvBool("SANDAI_STRICT_JSON", true),
+		PlayerCacheSize:    getEnvInt("SANDAI_PLAYER_CACHE_SIZE", 0),
+		PlayerCacheTTL:     getEnvDuration("SANDAI_PLAYER_CACHE_TTL", 60*time.Second),
+		ExperimentSeed:     getEnv("SANDAI_EXPERIMENT_SEED", ""),
+		BlockedWords:       getEnvList("SANDAI_BLOCKED_WORDS"),
+		BlockedWordsFile:   getEnv("SANDAI_BLOCKED_WORDS_FILE", ""),
+		Compression:        getEnvBool("SANDAI_COMPRESSION", true),
+		CompressionMinSize: getEnvInt("SANDAI_COMPRESSION_MIN_BYTES", defaultCompressionMinSize),
+		RequireDevice:      getEnvBool("SANDAI_REQUIRE_DEVICE", false),
+		MaxSessions:        getEnvInt("SANDAI_MAX_SESSIONS", 0),
+		SessionLimitPolicy: getEnv("SANDAI_SESSION_LIMIT_POLICY", string(analytics.SessionLimitReject)),
+		AttemptBatchWindow: getEnvDuration("SANDAI_TOURNAMENT_ATTEMPT_BATCH_WINDOW", 0),
+		AttemptsInFlight:   getEnvInt("SANDAI_TOURNAMENT_ATTEMPTS_IN_FLIGHT", 0),
+		Redis: botinfra.RedisConfig{
+			Addr:     getEnv("SANDAI_REDIS_ADDR", ""),
+			Password: getEnv("SANDAI_REDIS_PASSWORD", ""),
+			DB:       getEnvInt("SANDAI_REDIS_DB", 0),
+			Timeout:  getEnvDuration("SANDAI_REDIS_TIMEOUT", botinfra.DefaultRedisTimeout),
+			TLS:      getEnvBool("SANDAI_REDIS_TLS", false),
+		},
+		BotQueueKey:   getEnv("SANDAI_BOT_QUEUE_KEY", botinfra.DefaultRedisQueueKey),
+		MaxBodyBytes:  getEnvInt("SANDAI_MAX_BODY_BYTES", defaultMaxBodyBytes),
+		DatabaseURL:   getEnv("SANDAI_DATABASE_URL", ""),
+		SessionKey:    getEnv("SANDAI_SESSION_ENCRYPTION_KEY", ""),
+		AdminKey:      getEnv("SANDAI_ADMIN_KEY", ""),
+		NakamaHTTPKey: getEnv("SANDAI_NAKAMA_HTTP_KEY", ""),
+		NakamaDial: DialRetryConfig{
+			Attempts:       getEnvInt("SANDAI_NAKAMA_DIAL_ATTEMPTS", DefaultDialAttempts),
+			Timeout:        getEnvDuration("SANDAI_NAKAMA_DIAL_TIMEOUT", DefaultDialTimeout),
+			InitialBackoff: getEnvDuration("SANDAI_NAKAMA_DIAL_BACKOFF", DefaultDialInitialBackoff),
+			MaxBackoff:     getEnvDuration("SANDAI_NAKAMA_DIAL_MAX_BACKOFF", DefaultDialMaxBackoff),
+		},
+		LeaderboardScoreWindows: getEnvBool("SANDAI_LEADERBOARD_SCORE_WINDOWS", false),
 	}
 	return cfg
 }
@@ -41,6 +152,9 @@ func main() {
 	defer func() { _ = logger.Sync() }()
 
 	cfg := loadConfig()
+	if err := analytics.SessionLimitPolicy(cfg.SessionLimitPolicy).Validate(); err != nil {
+		logger.Fatal("invalid SANDAI_SESSION_LIMIT_POLICY", zap.String("policy", cfg.SessionLimitPolicy), zap.Error(err))
+	}
 
 	baseCtx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer cancel()
@@ -56,38 +170,183 @@ func main() {
 		}()
 	}
 
-	conn, err := grpc.DialContext(baseCtx, cfg.NakamaGRPCAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := dialWithRetry(baseCtx, cfg.NakamaDial, nakamaDialer(cfg.NakamaGRPCAddress), logger)
 	if err != nil {
 		logger.Fatal("failed to dial nakama", zap.Error(err))
 	}
 	defer conn.Close()
 
 	nakamaClient := apigrpc.NewNakamaClient(conn)
+	repoMetrics := metricsinfra.NewRepositoryMetrics(prometheus.DefaultRegisterer)
 
 	playerRepo := &nakamainfra.PlayerRepository{Client: nakamaClient}
-	authProvider := &nakamainfra.AuthClient{Client: nakamaClient}
+	authProvider := playerinfra.NewTranslatingAuthProvider(&nakamainfra.AuthClient{Client: nakamaClient})
 	groupRepo := &nakamainfra.GroupRepository{Client: nakamaClient}
-	matchRepo := &nakamainfra.BattleRepository{Client: nakamaClient}
+	matchRepo := &metricsinfra.BattleRepository{Next: &nakamainfra.BattleRepository{Client: nakamaClient}, Metrics: repoMetrics}
 	leaderboardRepo := &nakamainfra.LeaderboardRepository{Client: nakamaClient}
 	botRepo := &nakamainfra.BotRepository{Client: nakamaClient}
-	botQueue := &nakamainfra.BotQueue{}
+	var botQueue bot.QueueProducer = &nakamainfra.BotQueue{}
+	if cfg.Redis.Addr != "" {
+		redisClient := botinfra.NewGoRedisClient(cfg.Redis)
+		defer redisClient.Close()
+		botQueue = botinfra.NewRedisQueueProducer(redisClient, cfg.BotQueueKey)
+	} else {
+		logger.Info("no redis address set, bot commands will not be queued")
+	}
 	notifier := &nakamainfra.NotificationClient{Client: nakamaClient}
 	matchProvider := &nakamainfra.MatchClient{Client: nakamaClient}
 	groupProvider := &nakamainfra.GroupClient{Client: nakamaClient}
+	tournamentProvider := &nakamainfra.TournamentClient{Client: nakamaClient}
+
+	idempotencyStore := idempotencyinfra.NewMemoryStore()
 
-	authService := auth.NewService(playerRepo, authProvider)
+	var authRepo auth.PlayerRepository = playerRepo
+	if cfg.PlayerCacheSize > 0 {
+		authRepo = playerinfra.NewCachedRepository(playerRepo, cfg.PlayerCacheSize, cfg.PlayerCacheTTL)
+	}
+	authService := auth.NewService(authRepo, authProvider)
+	authService.SearchMode = player.SearchMode(cfg.PlayerSearchMode)
+	authService.RequireDevice = cfg.RequireDevice
+	authService.Logins = metricsinfra.NewAuthMetrics(prometheus.DefaultRegisterer)
+	if cfg.NakamaHTTPKey != "" {
+		authService.Accounts = playerinfra.NewRPCAccountProvider(nakamaClient, cfg.NakamaHTTPKey)
+	} else {
+		logger.Info("no nakama http key set, account changes will not be synced to Nakama")
+	}
 	groupService := groups.NewService(groupRepo, groupProvider)
+	contentFilter, err := loadContentFilter(cfg)
+	if err != nil {
+		logger.Fatal("failed to load blocked words", zap.Error(err))
+	}
+	authService.Filter = contentFilter
+	groupService.Filter = contentFilter
 	battleService := battles.NewService(matchRepo, matchProvider)
+	battleService.Logger = logger
+	battleService.Idempotency = idempotencyStore
+	battleService.Active = battleinfra.NewMemoryActiveIndex()
+	authService.OnSuspended = func(ctx context.Context, id shared.PlayerID) error {
+		_, err := battleService.CancelLedBattles(ctx, id)
+		return err
+	}
 	leaderboardService := leaderboardsvc.NewService(leaderboardRepo)
+	leaderboardService.Pending = leaderboardinfra.NewMemoryPendingRepository()
+	leaderboardService.Idempotency = idempotencyStore
+	leaderboardService.Logger = logger
+	if cfg.LeaderboardScoreWindows {
+		leaderboardService.Scores = leaderboardinfra.NewMemoryScoreStore(leaderboardinfra.DefaultExpiredCapacity)
+	}
 	botService := bot.NewService(botRepo, botQueue, notifier)
+	botService.Idempotency = idempotencyStore
+	botService.Logger = logger
+	botService.Acks = botinfra.NewMemoryAckStore(botinfra.DefaultAckCapacity)
+	// No bot Executor is wired yet, so webhook requests with "sync": true are
+	// rejected with sync_unsupported and every command goes through the queue.
+	var segmentDispatcher domainanalytics.EventDispatcher = analyticsinfra.NewSegmentDispatcher(cfg.SegmentWriteKey, "")
+	if cfg.SegmentWriteKey == "" {
+		logger.Info("no segment write key set, logging analytics events instead")
+		segmentDispatcher = analyticsinfra.NewLoggingDispatcher(logger)
+	}
+	backgroundDispatcher := segmentDispatcher
+	if cfg.AnalyticsSpillDir != "" {
+		spillStore, err := analyticsinfra.NewFileFailedBatchStore(cfg.AnalyticsSpillDir)
+		if err != nil {
+			logger.Fatal("failed to open analytics spill directory", zap.Error(err))
+		}
+		spillover := analyticsinfra.NewSpilloverDispatcher(segmentDispatcher, spillStore)
+		spillover.OnSpill = func(batch domainanalytics.FailedBatch, cause error) {
+			logger.Warn("analytics batch stored for replay", zap.String("batch_id", batch.ID), zap.Error(cause))
+		}
+		go spillover.RunReplay(baseCtx, time.Minute, func(err error) {
+			logger.Warn("analytics replay failed", zap.Error(err))
+		})
+		backgroundDispatcher = spillover
+	}
+	var asyncDispatcher *analyticsinfra.AsyncDispatcher
+	if cfg.AnalyticsBatchWait > 0 {
+		asyncDispatcher = analyticsinfra.NewBatchingAsyncDispatcher(backgroundDispatcher, analyticsinfra.DefaultAsyncBufferSize, cfg.AnalyticsBatchSize, cfg.AnalyticsBatchWait)
+	} else {
+		asyncDispatcher = analyticsinfra.NewAsyncDispatcher(backgroundDispatcher, analyticsinfra.DefaultAsyncBufferSize)
+	}
+	asyncDispatcher.OnError = func(err error) {
+		logger.Warn("analytics dispatch failed", zap.Error(err))
+	}
+	asyncDispatcher.HighWaterMark = cfg.AnalyticsHighWater
+	asyncDispatcher.OnHighWater = func(depth, capacity int) {
+		logger.Warn("analytics buffer above high-water mark", zap.Int("depth", depth), zap.Int("capacity", capacity))
+	}
+	if err := metricsinfra.RegisterDispatcherBuffer(prometheus.DefaultRegisterer, asyncDispatcher); err != nil {
+		logger.Warn("failed to register analytics buffer metrics", zap.Error(err))
+	}
+	var db *sql.DB
+	if cfg.DatabaseURL != "" {
+		db, err = sql.Open("pgx", cfg.DatabaseURL)
+		if err != nil {
+			logger.Fatal("failed to open database", zap.Error(err))
+		}
+		defer db.Close()
+	}
+	var sessionRepo domainanalytics.SessionRepository = analyticsinfra.NewMemorySessionRepository()
+	if db != nil {
+		if err := analyticsinfra.CreateSessionTable(baseCtx, db); err != nil {
+			logger.Fatal("failed to create analytics session table", zap.Error(err))
+		}
+		sessionRepo = analyticsinfra.NewSQLSessionRepository(db)
+	}
+	analyticsService := analytics.NewService(
+		asyncDispatcher,
+		&metricsinfra.SessionRepository{Next: sessionRepo, Metrics: repoMetrics},
+	)
+	analyticsService.BatchSize = cfg.AnalyticsBatchSize
+	analyticsService.Direct = segmentDispatcher
+	analyticsService.Identities = analyticsinfra.NewMemoryIdentifyCache(analyticsinfra.DefaultIdentifyTTL, analyticsinfra.DefaultIdentifyCapacity)
+	analyticsService.History = analyticsinfra.NewMemorySessionHistory(analyticsinfra.DefaultSessionHistoryLimit)
+	analyticsService.MaxSessions = cfg.MaxSessions
+	analyticsService.SessionLimit = analytics.SessionLimitPolicy(cfg.SessionLimitPolicy)
+	analyticsService.Experiments = analytics.NewExperimentAssigner(cfg.ExperimentSeed)
+	var attemptProvider tournaments.NakamaProvider = tournamentProvider
+	if cfg.AttemptBatchWindow > 0 || cfg.AttemptsInFlight > 0 {
+		attemptProvider = tournamentinfra.NewBatchingProvider(tournamentProvider, cfg.AttemptBatchWindow, cfg.AttemptsInFlight)
+	}
+	tournamentService := tournaments.NewService(
+		&metricsinfra.TournamentRepository{Next: tournamentinfra.NewMemoryRepository(), Metrics: repoMetrics},
+		&metricsinfra.ParticipantRepository{Next: tournamentinfra.NewMemoryParticipantRepository(), Metrics: repoMetrics},
+		attemptProvider,
+	)
+	if db != nil {
+		if err := tournamentinfra.CreateTables(baseCtx, db); err != nil {
+			logger.Fatal("failed to create tournament tables", zap.Error(err))
+		}
+		tournamentService.Snapshots = tournamentinfra.NewSQLSnapshotRepository(db)
+	} else {
+		logger.Info("no database url set, tournament standings snapshots are unavailable")
+	}
+	tournamentService.Logger = logger
+	if cfg.SessionKey == "" {
+		logger.Warn("no session encryption key set, endpoints acting for a player will answer 401")
+	}
+	if cfg.AdminKey == "" {
+		logger.Warn("no admin key set, admin endpoints will answer 401")
+	}
 
 	server := NewServer(ServerConfig{
-		Logger:             logger,
-		AuthService:        authService,
-		GroupService:       groupService,
-		BattleService:      battleService,
-		LeaderboardService: leaderboardService,
-		BotService:         botService,
+		Logger:                 logger,
+		AuthService:            authService,
+		GroupService:           groupService,
+		BattleService:          battleService,
+		LeaderboardService:     leaderboardService,
+		BotService:             botService,
+		AnalyticsService:       analyticsService,
+		TournamentService:      tournamentService,
+		MaxIngestEvents:        cfg.AnalyticsMaxEvents,
+		CorrelationHeaders:     cfg.CorrelationHeaders,
+		Maintenance:            NewMaintenanceMode(cfg.Maintenance, cfg.MaintenanceMessage),
+		MaintenanceExemptPaths: cfg.MaintenanceExempt,
+		LenientJSON:            !cfg.StrictJSON,
+		Compression:            cfg.Compression,
+		CompressionMinSize:     cfg.CompressionMinSize,
+		MaxBodyBytes:           int64(cfg.MaxBodyBytes),
+		SessionKey:             []byte(cfg.SessionKey),
+		AdminKey:               cfg.AdminKey,
 	})
 
 	httpServer := &http.Server{
@@ -111,6 +370,36 @@ func main() {
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
 		logger.Error("graceful shutdown failed", zap.Error(err))
 	}
+	drained, err := botService.Drain(shutdownCtx)
+	logger.Info("bot commands drained", zap.Int("drained", drained.Drained), zap.Int("abandoned", drained.Abandoned))
+	if err != nil {
+		logger.Warn("bot command drain incomplete", zap.Error(err))
+	}
+	if err := analyticsService.Close(shutdownCtx); err != nil {
+		logger.Warn("analytics flush failed", zap.Error(err))
+	}
+}
+
+// loadContentFilter builds the name filter from the configured word list and
+// file. It returns nil when no words are configured.
+func loadContentFilter(cfg Config) (shared.ContentFilter, error) {
+	words := cfg.BlockedWords
+	if cfg.BlockedWordsFile != "" {
+		file, err := os.Open(cfg.BlockedWordsFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		fromFile, err := shared.ParseWordList(file)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, fromFile...)
+	}
+	if len(words) == 0 {
+		return nil, nil
+	}
+	return shared.NewWordListFilter(words), nil
 }
 
 func getEnv(key, fallback string) string {
@@ -119,3 +408,42 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// getEnvDuration reads a Go duration string such as "5s" or "500ms".
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// getEnvList reads a comma-separated list, dropping empty entries.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
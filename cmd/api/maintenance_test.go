@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	srv := newTestServer(ServerConfig{Maintenance: NewMaintenanceMode(true, "back soon")})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "mutating request blocked", method: http.MethodPost, path: "/v1/groups", wantStatus: http.StatusServiceUnavailable},
+		{name: "patch blocked", method: http.MethodPatch, path: "/v1/tournaments/t1/reset-schedule", wantStatus: http.StatusServiceUnavailable},
+		{name: "health allowed", method: http.MethodGet, path: "/healthz", wantStatus: http.StatusOK},
+		{name: "metrics allowed", method: http.MethodGet, path: "/metrics", wantStatus: http.StatusOK},
+		{name: "maintenance state readable", method: http.MethodGet, path: "/v1/admin/maintenance", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doJSON(t, srv, tt.method, tt.path, nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	rec := doJSON(t, srv, http.MethodPost, "/v1/groups", nil)
+	var resp errorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Error != "back soon" {
+		t.Errorf("error = %q, want %q", resp.Error, "back soon")
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+}
+
+const testAdminKey = "test-admin-key"
+
+// doAdmin is doJSON with the operator key testAdminKey.
+func doAdmin(t *testing.T, srv *Server, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		t.Fatalf("encoding request body: %v", err)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMaintenanceMiddleware_Toggle(t *testing.T) {
+	srv := newTestServer(ServerConfig{AdminKey: testAdminKey})
+
+	// An empty body reaches the handler and fails decoding, proving the request was not blocked.
+	if rec := doJSON(t, srv, http.MethodPost, "/v1/groups", nil); rec.Code != http.StatusBadRequest {
+		t.Fatalf("status before toggle = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec := doAdmin(t, srv, http.MethodPut, "/v1/admin/maintenance", MaintenanceRequest{Enabled: true})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enable status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var state MaintenanceResponse
+	if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if !state.Enabled || state.Message != defaultMaintenanceMessage {
+		t.Errorf("state = %+v, want enabled with default message", state)
+	}
+	if rec := doJSON(t, srv, http.MethodPost, "/v1/groups", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status during maintenance = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	if rec := doAdmin(t, srv, http.MethodPut, "/v1/admin/maintenance", MaintenanceRequest{Enabled: false}); rec.Code != http.StatusOK {
+		t.Fatalf("disable status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := doJSON(t, srv, http.MethodPost, "/v1/groups", nil); rec.Code != http.StatusBadRequest {
+		t.Fatalf("status after toggle = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMaintenanceMiddleware_CustomExemptions(t *testing.T) {
+	srv := newTestServer(ServerConfig{
+		Maintenance:              NewMaintenanceMode(true, ""),
+		MaintenanceExemptPaths:   []string{"/v1/groups"},
+		MaintenanceExemptMethods: []string{http.MethodHead},
+	})
+
+	if rec := doJSON(t, srv, http.MethodPost, "/v1/groups", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("exempt path status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := doJSON(t, srv, http.MethodGet, "/healthz", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("non-exempt read status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestHandleSetMaintenance_RequiresAdminKey(t *testing.T) {
+	tests := []struct {
+		name          string
+		adminKey      string
+		authorization string
+	}{
+		{name: "no admin key configured", authorization: "Bearer " + testAdminKey},
+		{name: "no credential", adminKey: testAdminKey},
+		{name: "wrong key", adminKey: testAdminKey, authorization: "Bearer other-key"},
+		{name: "not a bearer token", adminKey: testAdminKey, authorization: testAdminKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maintenance := NewMaintenanceMode(false, "")
+			srv := newTestServer(ServerConfig{AdminKey: tt.adminKey, Maintenance: maintenance})
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/maintenance", bytes.NewBufferString(`{"enabled":true}`))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(rec, req)
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+			if enabled, _ := maintenance.State(); enabled {
+				t.Error("maintenance was switched on without the admin key")
+			}
+		})
+	}
+}
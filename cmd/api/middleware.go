@@ -3,33 +3,79 @@ package main
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/gofrs/uuid/v5"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
 )
 
-type contextKey string
+// correlationResponseHeader carries the chosen correlation id back to the caller.
+const correlationResponseHeader = "X-Request-Id"
+
+const traceparentHeader = "traceparent"
 
-const correlationKey contextKey = "correlation_id"
+// defaultCorrelationHeaders lists the request headers checked for a correlation id, in priority order.
+var defaultCorrelationHeaders = []string{"X-Request-Id", "X-Correlation-Id", traceparentHeader}
 
 func (s *Server) correlationMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		reqID := r.Header.Get("X-Request-Id")
+		reqID := extractCorrelationID(r.Header, s.cfg.CorrelationHeaders)
 		if reqID == "" {
 			reqID = generateCorrelationID()
-			w.Header().Set("X-Request-Id", reqID)
 		}
-		ctx := context.WithValue(r.Context(), correlationKey, reqID)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		w.Header().Set(correlationResponseHeader, reqID)
+		next.ServeHTTP(w, r.WithContext(shared.WithCorrelationID(r.Context(), reqID)))
 	})
 }
 
+// extractCorrelationID returns the first non-empty id found in headers, checked
+// in order. A traceparent header contributes its W3C trace id. It returns an
+// empty string when no header carries a usable id.
+func extractCorrelationID(header http.Header, names []string) string {
+	for _, name := range names {
+		value := strings.TrimSpace(header.Get(name))
+		if value == "" {
+			continue
+		}
+		if strings.EqualFold(name, traceparentHeader) {
+			if traceID, ok := parseTraceparent(value); ok {
+				return traceID
+			}
+			continue
+		}
+		return value
+	}
+	return ""
+}
+
+// parseTraceparent extracts the trace id from a W3C traceparent value of the
+// form version-traceid-parentid-flags.
+func parseTraceparent(value string) (string, bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", false
+	}
+	traceID := strings.ToLower(parts[1])
+	if !isHex(traceID) || traceID == strings.Repeat("0", 32) {
+		return "", false
+	}
+	return traceID, true
+}
+
+func isHex(value string) bool {
+	for _, c := range value {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
 func generateCorrelationID() string {
 	return uuid.Must(uuid.NewV4()).String()
 }
 
 func correlationIDFromContext(ctx context.Context) string {
-	if value, ok := ctx.Value(correlationKey).(string); ok {
-		return value
-	}
-	return ""
+	return shared.CorrelationID(ctx)
 }
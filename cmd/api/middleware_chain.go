@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// MiddlewareStage is a position in the request pipeline. Lower stages wrap
+// higher ones, so the request passes through them in this order:
+//
+//	recovery → correlation → CORS → logging → metrics → compression → auth → handler
+//
+// Recovery is outermost so it also catches panics raised by other middleware.
+// Correlation runs before logging so log lines carry the request id, and auth
+// runs last so rejected requests are still logged and measured. Compression
+// sits inside logging and metrics so they record the status the handler chose.
+type MiddlewareStage int
+
+const (
+	StageRecovery MiddlewareStage = iota
+	StageCorrelation
+	StageCORS
+	StageLogging
+	StageMetrics
+	StageCompression
+	StageAuth
+)
+
+// Middleware wraps a handler.
+type Middleware func(http.Handler) http.Handler
+
+// StagedMiddleware is a middleware registered at a pipeline stage.
+type StagedMiddleware struct {
+	Stage      MiddlewareStage
+	Name       string
+	Middleware Middleware
+}
+
+// MiddlewareChain orders middleware by stage. Middleware registered at the
+// same stage run in registration order, after the built-ins for that stage.
+type MiddlewareChain struct {
+	entries []StagedMiddleware
+}
+
+// Register adds middleware at the given stage.
+func (c *MiddlewareChain) Register(stage MiddlewareStage, name string, mw Middleware) {
+	c.entries = append(c.entries, StagedMiddleware{Stage: stage, Name: name, Middleware: mw})
+}
+
+// Ordered returns the middleware outermost first.
+func (c *MiddlewareChain) Ordered() []StagedMiddleware {
+	ordered := append([]StagedMiddleware(nil), c.entries...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Stage < ordered[j].Stage })
+	return ordered
+}
+
+// Then wraps h with every registered middleware.
+func (c *MiddlewareChain) Then(h http.Handler) http.Handler {
+	ordered := c.Ordered()
+	for i := len(ordered) - 1; i >= 0; i-- {
+		h = ordered[i].Middleware(h)
+	}
+	return h
+}
+
+// middlewareChain returns the built-in middleware plus any from the config.
+func (s *Server) middlewareChain() *MiddlewareChain {
+	chain := &MiddlewareChain{}
+	chain.Register(StageRecovery, "recovery", s.recoveryMiddleware)
+	chain.Register(StageCorrelation, "correlation", s.correlationMiddleware)
+	chain.Register(StageLogging, "logging", s.loggingMiddleware)
+	chain.Register(StageMetrics, "metrics", s.metricsMiddleware)
+	chain.Register(StageCompression, "compression", s.compressionMiddleware)
+	chain.Register(StageAuth, "maintenance", s.maintenanceMiddleware)
+	for _, mw := range s.cfg.Middleware {
+		chain.Register(mw.Stage, mw.Name, mw.Middleware)
+	}
+	return chain
+}
+
+// recoveryMiddleware turns a panic into a 500 response instead of dropping the connection.
+func (s *Server) recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				s.cfg.Logger.Error("http handler panic",
+					zap.Any("panic", rec),
+					zap.String("path", r.URL.Path),
+					zap.String("request_id", correlationIDFromContext(r.Context())),
+				)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// recordingMiddleware appends name to calls when the request passes through it.
+func recordingMiddleware(calls *[]string, name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls = append(*calls, name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestMiddlewareChain_Order(t *testing.T) {
+	var calls []string
+	chain := &MiddlewareChain{}
+	chain.Register(StageAuth, "auth", recordingMiddleware(&calls, "auth"))
+	chain.Register(StageLogging, "logging", recordingMiddleware(&calls, "logging"))
+	chain.Register(StageRecovery, "recovery", recordingMiddleware(&calls, "recovery"))
+	chain.Register(StageCORS, "cors", recordingMiddleware(&calls, "cors"))
+	chain.Register(StageCorrelation, "correlation", recordingMiddleware(&calls, "correlation"))
+	chain.Register(StageLogging, "audit", recordingMiddleware(&calls, "audit"))
+
+	handler := chain.Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := []string{"recovery", "correlation", "cors", "logging", "audit", "auth", "handler"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("execution order = %v, want %v", calls, want)
+	}
+}
+
+func TestServer_CustomMiddleware(t *testing.T) {
+	var seenRequestID string
+	srv := newTestServer(ServerConfig{
+		Middleware: []StagedMiddleware{
+			{Stage: StageAuth, Name: "auth", Middleware: func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					seenRequestID = correlationIDFromContext(r.Context())
+					if r.Header.Get("Authorization") == "" {
+						http.Error(w, "unauthorized", http.StatusUnauthorized)
+						return
+					}
+					panic("handler exploded")
+				})
+			}},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/tournaments/tournament-123", nil)
+	req.Header.Set("X-Request-Id", "req-42")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if seenRequestID != "req-42" {
+		t.Errorf("Expected auth middleware to run after correlation, saw request id %q", seenRequestID)
+	}
+
+	// A panic below the recovery stage becomes a 500.
+	req = httptest.NewRequest(http.MethodGet, "/v1/tournaments/tournament-123", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
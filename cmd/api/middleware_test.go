@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExtractCorrelationID(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		names   []string
+		want    string
+	}{
+		{
+			name:    "request id",
+			headers: map[string]string{"X-Request-Id": "req-1"},
+			names:   defaultCorrelationHeaders,
+			want:    "req-1",
+		},
+		{
+			name:    "correlation id",
+			headers: map[string]string{"X-Correlation-Id": "corr-1"},
+			names:   defaultCorrelationHeaders,
+			want:    "corr-1",
+		},
+		{
+			name:    "traceparent",
+			headers: map[string]string{"traceparent": traceparent},
+			names:   defaultCorrelationHeaders,
+			want:    "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:    "priority order",
+			headers: map[string]string{"X-Correlation-Id": "corr-1", "traceparent": traceparent},
+			names:   defaultCorrelationHeaders,
+			want:    "corr-1",
+		},
+		{
+			name:    "custom order",
+			headers: map[string]string{"X-Request-Id": "req-1", "traceparent": traceparent},
+			names:   []string{"traceparent", "X-Request-Id"},
+			want:    "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:    "malformed traceparent falls through",
+			headers: map[string]string{"traceparent": "garbage", "X-Correlation-Id": "corr-1"},
+			names:   []string{"traceparent", "X-Correlation-Id"},
+			want:    "corr-1",
+		},
+		{
+			name:    "all-zero trace id is invalid",
+			headers: map[string]string{"traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+			names:   defaultCorrelationHeaders,
+			want:    "",
+		},
+		{
+			name:    "no headers",
+			headers: nil,
+			names:   defaultCorrelationHeaders,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.headers {
+				header.Set(k, v)
+			}
+			if got := extractCorrelationID(header, tt.names); got != tt.want {
+				t.Errorf("extractCorrelationID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCorrelationMiddleware_EchoesID(t *testing.T) {
+	srv := newTestServer(ServerConfig{})
+
+	var seen string
+	handler := srv.correlationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = correlationIDFromContext(r.Context())
+	}))
+
+	t.Run("uses incoming header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Correlation-Id", "corr-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if seen != "corr-1" {
+			t.Errorf("Expected context id %q, got %q", "corr-1", seen)
+		}
+		if got := rec.Header().Get(correlationResponseHeader); got != "corr-1" {
+			t.Errorf("Expected echoed id %q, got %q", "corr-1", got)
+		}
+	})
+
+	t.Run("generates as fallback", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if seen == "" {
+			t.Fatal("Expected a generated correlation id")
+		}
+		if got := rec.Header().Get(correlationResponseHeader); got != seen {
+			t.Errorf("Expected echoed id %q, got %q", seen, got)
+		}
+	})
+}
@@ -12,20 +12,61 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.uber.org/zap"
 
+	"github.com/heroiclabs/nakama/v3/src/app/analytics"
 	"github.com/heroiclabs/nakama/v3/src/app/auth"
 	"github.com/heroiclabs/nakama/v3/src/app/battles"
 	"github.com/heroiclabs/nakama/v3/src/app/bot"
 	"github.com/heroiclabs/nakama/v3/src/app/groups"
 	leaderboardsvc "github.com/heroiclabs/nakama/v3/src/app/leaderboard"
+	"github.com/heroiclabs/nakama/v3/src/app/tournaments"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
 )
 
+// defaultMaxIngestEvents bounds the analytics ingest endpoint when no limit is configured.
+const defaultMaxIngestEvents = 500
+
 type ServerConfig struct {
 	Logger             *zap.Logger
+	Registry           prometheus.Registerer
 	AuthService        *auth.Service
 	GroupService       *groups.Service
 	BattleService      *battles.Service
 	LeaderboardService *leaderboardsvc.Service
 	BotService         *bot.Service
+	AnalyticsService   *analytics.Service
+	TournamentService  *tournaments.Service
+	MaxIngestEvents    int
+	// LenientJSON accepts request bodies carrying fields the endpoint does not
+	// declare, for older clients. By default such bodies are rejected with 400.
+	LenientJSON bool
+	// CorrelationHeaders lists the request headers checked for a correlation
+	// id, in priority order. Defaults to defaultCorrelationHeaders.
+	CorrelationHeaders []string
+	// Middleware is added to the request pipeline at the given stages,
+	// alongside the built-in recovery, correlation, logging, and metrics.
+	Middleware []StagedMiddleware
+	// Clock supplies the time used for derived response fields. Defaults to time.Now.
+	Clock func() time.Time
+	// Maintenance rejects mutating requests with 503 while enabled. Defaults
+	// to a disabled toggle.
+	Maintenance *MaintenanceMode
+	// MaintenanceExemptPaths and MaintenanceExemptMethods stay reachable while
+	// maintenance is on. Default to /healthz, /metrics and read-only methods.
+	MaintenanceExemptPaths   []string
+	MaintenanceExemptMethods []string
+	// Compression gzips responses for clients that send Accept-Encoding: gzip.
+	// Bodies smaller than CompressionMinSize bytes (default 1024) are sent as is.
+	Compression        bool
+	CompressionMinSize int
+	// MaxBodyBytes caps the size of JSON request bodies. Defaults to 1MB.
+	MaxBodyBytes int64
+	// SessionKey verifies the Nakama session tokens that identify the caller
+	// on endpoints acting for a player. It must match Nakama's
+	// session.encryption_key; when empty those endpoints answer 401.
+	SessionKey []byte
+	// AdminKey is the operator credential required by admin endpoints that
+	// change server state; when empty those endpoints answer 401.
+	AdminKey string
 }
 
 // Server wires HTTP endpoints to application services with observability instrumentation.
@@ -37,6 +78,30 @@ type Server struct {
 }
 
 func NewServer(cfg ServerConfig) *Server {
+	if cfg.Registry == nil {
+		cfg.Registry = prometheus.DefaultRegisterer
+	}
+	if cfg.MaxIngestEvents <= 0 {
+		cfg.MaxIngestEvents = defaultMaxIngestEvents
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = func() time.Time { return time.Now().UTC() }
+	}
+	if len(cfg.CorrelationHeaders) == 0 {
+		cfg.CorrelationHeaders = defaultCorrelationHeaders
+	}
+	if cfg.Maintenance == nil {
+		cfg.Maintenance = NewMaintenanceMode(false, "")
+	}
+	if len(cfg.MaintenanceExemptPaths) == 0 {
+		cfg.MaintenanceExemptPaths = defaultMaintenanceExemptPaths
+	}
+	if len(cfg.MaintenanceExemptMethods) == 0 {
+		cfg.MaintenanceExemptMethods = defaultMaintenanceExemptMethods
+	}
 	srv := &Server{cfg: cfg}
 	srv.initMetrics()
 	srv.buildRouter()
@@ -61,22 +126,50 @@ func (s *Server) initMetrics() {
 		Name:      "requests_total",
 		Help:      "Total HTTP requests by route",
 	}, []string{"route", "method", "code"})
-	prometheus.MustRegister(s.httpMetrics, s.requestCounter)
+	s.cfg.Registry.MustRegister(s.httpMetrics, s.requestCounter)
 }
 
 func (s *Server) buildRouter() {
 	r := mux.NewRouter()
-	r.Use(s.correlationMiddleware)
-	r.Use(s.loggingMiddleware)
-	r.Use(s.metricsMiddleware)
+	for _, mw := range s.middlewareChain().Ordered() {
+		r.Use(mux.MiddlewareFunc(mw.Middleware))
+	}
 
 	apiRouter := r.PathPrefix("/v1").Subrouter()
 	apiRouter.Handle("/auth/login", otelhttp.NewHandler(http.HandlerFunc(s.handleAuthLogin), "AuthLogin")).Methods(http.MethodPost)
+	apiRouter.Handle("/auth/refresh", otelhttp.NewHandler(http.HandlerFunc(s.handleAuthRefresh), "AuthRefresh")).Methods(http.MethodPost)
+	apiRouter.Handle("/accounts/{id}/devices/{deviceId}", otelhttp.NewHandler(http.HandlerFunc(s.handleRemoveDevice), "RemoveDevice")).Methods(http.MethodDelete)
 	apiRouter.Handle("/groups", otelhttp.NewHandler(http.HandlerFunc(s.handleCreateGroup), "CreateGroup")).Methods(http.MethodPost)
+	apiRouter.Handle("/groups/{group}/members", otelhttp.NewHandler(http.HandlerFunc(s.handleJoinGroup), "JoinGroup")).Methods(http.MethodPost)
+	apiRouter.Handle("/groups/{group}/members/{player}", otelhttp.NewHandler(http.HandlerFunc(s.handleLeaveGroup), "LeaveGroup")).Methods(http.MethodDelete)
+	apiRouter.Handle("/groups/{group}/metadata", otelhttp.NewHandler(http.HandlerFunc(s.handleUpdateGroupMetadata), "UpdateGroupMetadata")).Methods(http.MethodPatch)
 	apiRouter.Handle("/battles", otelhttp.NewHandler(http.HandlerFunc(s.handleStartBattle), "StartBattle")).Methods(http.MethodPost)
+	apiRouter.Handle("/battles/{id}/join", otelhttp.NewHandler(http.HandlerFunc(s.handleJoinBattle), "JoinBattle")).Methods(http.MethodPost)
+	apiRouter.Handle("/battles/{id}/lobby", otelhttp.NewHandler(http.HandlerFunc(s.handleGetLobby), "GetBattleLobby")).Methods(http.MethodGet)
+	apiRouter.Handle("/battles/{id}/ready", otelhttp.NewHandler(http.HandlerFunc(s.handleSetReady), "SetBattleReady")).Methods(http.MethodPatch)
 	apiRouter.Handle("/leaderboard/{season}", otelhttp.NewHandler(http.HandlerFunc(s.handleSubmitScore), "SubmitLeaderboard")).Methods(http.MethodPost)
+	apiRouter.Handle("/leaderboard/{season}", otelhttp.NewHandler(http.HandlerFunc(s.handleListLeaderboard), "ListLeaderboard")).Methods(http.MethodGet)
+	apiRouter.Handle("/leaderboard/{season}/rank/{player}", otelhttp.NewHandler(http.HandlerFunc(s.handleGetLeaderboardRank), "GetLeaderboardRank")).Methods(http.MethodGet)
+	apiRouter.Handle("/seasons", otelhttp.NewHandler(http.HandlerFunc(s.handleCreateSeason), "CreateSeason")).Methods(http.MethodPost)
+	apiRouter.Handle("/seasons/{season}", otelhttp.NewHandler(http.HandlerFunc(s.handleGetSeason), "GetSeason")).Methods(http.MethodGet)
 	apiRouter.Handle("/bot/webhook", otelhttp.NewHandler(http.HandlerFunc(s.handleBotWebhook), "BotWebhook")).Methods(http.MethodPost)
-
+	apiRouter.Handle("/bot/commands/{id}", otelhttp.NewHandler(http.HandlerFunc(s.handleGetBotCommand), "GetBotCommand")).Methods(http.MethodGet)
+	apiRouter.Handle("/bot/commands/{id}/complete", otelhttp.NewHandler(http.HandlerFunc(s.handleCompleteBotCommand), "CompleteBotCommand")).Methods(http.MethodPost)
+	apiRouter.Handle("/tournaments/validate", otelhttp.NewHandler(http.HandlerFunc(s.handleValidateTournament), "ValidateTournament")).Methods(http.MethodPost)
+	apiRouter.Handle("/tournaments/{id}", otelhttp.NewHandler(http.HandlerFunc(s.handleGetTournament), "GetTournament")).Methods(http.MethodGet)
+	apiRouter.Handle("/tournaments/{id}/reset-schedule", otelhttp.NewHandler(http.HandlerFunc(s.handleUpdateResetSchedule), "UpdateResetSchedule")).Methods(http.MethodPatch)
+	apiRouter.Handle("/tournaments/{id}/standings", otelhttp.NewHandler(http.HandlerFunc(s.handleGetStandings), "GetTournamentStandings")).Methods(http.MethodGet)
+	apiRouter.Handle("/tournaments/{id}/standings/final", otelhttp.NewHandler(http.HandlerFunc(s.handleGetStandingsSnapshot), "GetTournamentStandingsSnapshot")).Methods(http.MethodGet)
+	apiRouter.Handle("/players", otelhttp.NewHandler(http.HandlerFunc(s.handleSearchPlayers), "SearchPlayers")).Methods(http.MethodGet)
+	apiRouter.Handle("/players/{id}/tournaments", otelhttp.NewHandler(http.HandlerFunc(s.handleListPlayerTournaments), "ListPlayerTournaments")).Methods(http.MethodGet)
+	apiRouter.Handle("/players/{id}/battles", otelhttp.NewHandler(http.HandlerFunc(s.handleListPlayerBattles), "ListPlayerBattles")).Methods(http.MethodGet)
+	apiRouter.Handle("/admin/players/{id}/sessions/end", otelhttp.NewHandler(http.HandlerFunc(s.handleEndAllSessions), "EndAllSessions")).Methods(http.MethodPost)
+	apiRouter.Handle("/admin/seasons/{season}/reset", otelhttp.NewHandler(http.HandlerFunc(s.handleResetSeason), "ResetSeason")).Methods(http.MethodPost)
+	apiRouter.Handle("/admin/maintenance", otelhttp.NewHandler(http.HandlerFunc(s.handleGetMaintenance), "GetMaintenance")).Methods(http.MethodGet)
+	apiRouter.Handle("/admin/maintenance", otelhttp.NewHandler(http.HandlerFunc(s.handleSetMaintenance), "SetMaintenance")).Methods(http.MethodPut)
+	apiRouter.Handle("/analytics/events", otelhttp.NewHandler(http.HandlerFunc(s.handleTrackEvents), "TrackEvents")).Methods(http.MethodPost)
+
+	r.Handle("/healthz", http.HandlerFunc(s.handleHealthz)).Methods(http.MethodGet)
 	r.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
 	s.router = r
 }
@@ -87,12 +180,28 @@ func (s *Server) writeJSON(w http.ResponseWriter, status int, payload any) {
 	_ = json.NewEncoder(w).Encode(payload)
 }
 
+// queryInt reads an integer query parameter, returning fallback when absent or malformed.
+func queryInt(r *http.Request, key string, fallback int) int {
+	if value := r.URL.Query().Get(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
 type errorResponse struct {
 	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+	Field string `json:"field,omitempty"`
 }
 
 func (s *Server) writeError(w http.ResponseWriter, status int, err error) {
-	s.writeJSON(w, status, errorResponse{Error: err.Error()})
+	resp := errorResponse{Error: err.Error(), Code: codeForError(err)}
+	if verr, ok := shared.AsValidationError(err); ok {
+		resp.Field = verr.Field
+	}
+	s.writeJSON(w, status, resp)
 }
 
 func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
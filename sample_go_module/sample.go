@@ -26,7 +26,7 @@ import (
 
 func InitModule(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, initializer runtime.Initializer) error {
 
-	if err := registerTournamentRuntime(initializer); err != nil {
+	if err := registerTournamentRuntime(ctx, db, initializer); err != nil {
 		return err
 	}
 
@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/gofrs/uuid/v5"
 	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
+	infraTournament "github.com/heroiclabs/nakama/v3/src/infra/tournament"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type tournamentCreatePayload struct {
@@ -25,6 +29,7 @@ type tournamentCreatePayload struct {
 	MaxSize       int    `json:"max_size"`
 	MaxNumScore   int    `json:"max_num_score"`
 	JoinRequired  bool   `json:"join_required"`
+	EnableRanks   bool   `json:"enable_ranks"`
 }
 
 type tournamentIDPayload struct {
@@ -37,7 +42,13 @@ type tournamentAddAttemptPayload struct {
 	Count        int    `json:"count"`
 }
 
-func registerTournamentRuntime(initializer runtime.Initializer) error {
+func registerTournamentRuntime(ctx context.Context, db *sql.DB, initializer runtime.Initializer) error {
+	if db != nil {
+		if err := infraTournament.CreateTables(ctx, db); err != nil {
+			return fmt.Errorf("creating tournament tables: %w", err)
+		}
+	}
+
 	if err := initializer.RegisterTournamentEnd(tournamentEndCallback); err != nil {
 		return err
 	}
@@ -64,7 +75,15 @@ func registerTournamentRuntime(initializer runtime.Initializer) error {
 	return nil
 }
 
-func tournamentEndCallback(ctx context.Context, _ runtime.Logger, _ *sql.DB, nk runtime.NakamaModule, tournament *api.Tournament, _ int64, reset int64) error {
+func tournamentEndCallback(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, tournament *api.Tournament, _ int64, reset int64) error {
+	snapshot, err := NewTournamentServiceAdapter(db, nk).SnapshotStandings(ctx, tournament.GetId())
+	if err != nil {
+		return fmt.Errorf("snapshotting final standings: %w", err)
+	}
+	if snapshot != nil {
+		logger.Info("captured final standings for tournament %s (%d records)", tournament.GetId(), len(snapshot.Standings))
+	}
+
 	records, _, _, _, err := nk.LeaderboardRecordsList(ctx, tournament.GetId(), nil, 1, "", reset)
 	if err != nil {
 		return fmt.Errorf("fetching tournament records: %w", err)
@@ -75,13 +94,45 @@ func tournamentEndCallback(ctx context.Context, _ runtime.Logger, _ *sql.DB, nk
 	}
 
 	metadata := map[string]interface{}{"won": tournament.GetId()}
-	if err := nk.AccountUpdateId(ctx, records[0].GetOwnerId(), "", metadata, "", "", "", "", ""); err != nil {
+	if err := mergeAccountMetadata(ctx, nk, records[0].GetOwnerId(), metadata); err != nil {
 		return fmt.Errorf("updating winner account metadata: %w", err)
 	}
 
 	return nil
 }
 
+// mergeAccountMetadata adds updates to the account's existing metadata instead
+// of replacing it. Accounts that no longer exist are skipped.
+func mergeAccountMetadata(ctx context.Context, nk runtime.NakamaModule, userID string, updates map[string]interface{}) error {
+	account, err := nk.AccountGetId(ctx, userID)
+	if err != nil {
+		if isAccountNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("reading account metadata: %w", err)
+	}
+
+	metadata := make(map[string]interface{}, len(updates))
+	if raw := account.GetUser().GetMetadata(); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+			return fmt.Errorf("decoding account metadata: %w", err)
+		}
+	}
+	for key, value := range updates {
+		metadata[key] = value
+	}
+
+	return nk.AccountUpdateId(ctx, userID, "", metadata, "", "", "", "", "")
+}
+
+func isAccountNotFound(err error) bool {
+	var runtimeErr *runtime.Error
+	if errors.As(err, &runtimeErr) && runtimeErr.Code == int(codes.NotFound) {
+		return true
+	}
+	return status.Code(err) == codes.NotFound
+}
+
 func tournamentResetCallback(ctx context.Context, _ runtime.Logger, _ *sql.DB, nk runtime.NakamaModule, tournament *api.Tournament, _ int64, _ int64) error {
 	records, _, _, _, err := nk.LeaderboardRecordsList(ctx, tournament.GetId(), nil, 1, "", 0)
 	if err != nil {
@@ -93,7 +144,7 @@ func tournamentResetCallback(ctx context.Context, _ runtime.Logger, _ *sql.DB, n
 	}
 
 	metadata := map[string]interface{}{"expiry_tournament": tournament.GetId()}
-	if err := nk.AccountUpdateId(ctx, records[0].GetOwnerId(), "", metadata, "", "", "", "", ""); err != nil {
+	if err := mergeAccountMetadata(ctx, nk, records[0].GetOwnerId(), metadata); err != nil {
 		return fmt.Errorf("updating account metadata on tournament reset: %w", err)
 	}
 
@@ -111,7 +162,7 @@ func leaderboardResetCallback(ctx context.Context, _ runtime.Logger, _ *sql.DB,
 	}
 
 	metadata := map[string]interface{}{"expiry_leaderboard": leaderboard.GetId()}
-	if err := nk.AccountUpdateId(ctx, records[0].GetOwnerId(), "", metadata, "", "", "", "", ""); err != nil {
+	if err := mergeAccountMetadata(ctx, nk, records[0].GetOwnerId(), metadata); err != nil {
 		return fmt.Errorf("updating account metadata on leaderboard reset: %w", err)
 	}
 
@@ -195,7 +246,7 @@ func rpcAddAttemptTournament(ctx context.Context, _ runtime.Logger, _ *sql.DB, n
 }
 
 func createTournament(ctx context.Context, nk runtime.NakamaModule, id string, args *tournamentCreatePayload) error {
-	if err := nk.TournamentCreate(ctx, id, args.Authoritative, args.SortOrder, args.Operator, args.ResetSchedule, nil, args.Title, args.Description, args.Category, args.StartTime, args.EndTime, args.Duration, args.MaxSize, args.MaxNumScore, args.JoinRequired, false); err != nil {
+	if err := nk.TournamentCreate(ctx, id, args.Authoritative, args.SortOrder, args.Operator, args.ResetSchedule, nil, args.Title, args.Description, args.Category, args.StartTime, args.EndTime, args.Duration, args.MaxSize, args.MaxNumScore, args.JoinRequired, args.EnableRanks); err != nil {
 		return fmt.Errorf("creating tournament %s: %w", id, err)
 	}
 	return nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -20,13 +21,25 @@ type TournamentServiceAdapter struct {
 	service *tournaments.Service
 }
 
-// NewTournamentServiceAdapter creates a new adapter with DDD service.
-func NewTournamentServiceAdapter(nk runtime.NakamaModule) *TournamentServiceAdapter {
-	repo := infraTournament.NewMemoryRepository()
-	participantRepo := infraTournament.NewMemoryParticipantRepository()
+// NewTournamentServiceAdapter creates a new adapter with DDD service. With a
+// non-nil db the service stores tournaments, participants and standings
+// snapshots in the runtime database, whose tables registerTournamentRuntime
+// creates at module init; otherwise it keeps them in memory.
+func NewTournamentServiceAdapter(db *sql.DB, nk runtime.NakamaModule) *TournamentServiceAdapter {
+	var (
+		repo            tournament.Repository            = infraTournament.NewMemoryRepository()
+		participantRepo tournament.ParticipantRepository = infraTournament.NewMemoryParticipantRepository()
+		snapshots       tournament.SnapshotRepository    = infraTournament.NewMemorySnapshotRepository()
+	)
+	if db != nil {
+		repo = infraTournament.NewSQLRepository(db)
+		participantRepo = infraTournament.NewSQLParticipantRepository(db)
+		snapshots = infraTournament.NewSQLSnapshotRepository(db)
+	}
 	provider := infraTournament.NewNakamaProvider(nk)
 
 	service := tournaments.NewService(repo, participantRepo, provider)
+	service.Snapshots = snapshots
 
 	return &TournamentServiceAdapter{
 		service: service,
@@ -35,6 +48,11 @@ func NewTournamentServiceAdapter(nk runtime.NakamaModule) *TournamentServiceAdap
 
 // CreateTournament creates a tournament using the DDD service.
 func (a *TournamentServiceAdapter) CreateTournament(ctx context.Context, payload tournamentCreatePayload) (string, error) {
+	sortOrder, operator, err := tournament.ParseScoring(payload.SortOrder, payload.Operator)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), 3)
+	}
+
 	id, err := uuid.NewV4()
 	if err != nil {
 		return "", fmt.Errorf("generating tournament id: %w", err)
@@ -45,8 +63,8 @@ func (a *TournamentServiceAdapter) CreateTournament(ctx context.Context, payload
 		Title:         payload.Title,
 		Description:   payload.Description,
 		Category:      payload.Category,
-		SortOrder:     tournament.SortOrder(payload.SortOrder),
-		Operator:      tournament.Operator(payload.Operator),
+		SortOrder:     sortOrder,
+		Operator:      operator,
 		ResetSchedule: payload.ResetSchedule,
 		Authoritative: payload.Authoritative,
 		JoinRequired:  payload.JoinRequired,
@@ -54,6 +72,7 @@ func (a *TournamentServiceAdapter) CreateTournament(ctx context.Context, payload
 		MaxNumScore:   payload.MaxNumScore,
 		StartTime:     time.Unix(int64(payload.StartTime), 0),
 		Duration:      time.Duration(payload.Duration) * time.Second,
+		EnableRanks:   payload.EnableRanks,
 	}
 
 	if payload.EndTime > 0 {
@@ -93,18 +112,29 @@ func (a *TournamentServiceAdapter) AddAttempt(ctx context.Context, tournamentID,
 	return a.service.AddAttempt(ctx, cmd)
 }
 
+// SnapshotStandings stores the final standings of a tournament the service
+// manages. It returns a nil snapshot for tournaments created outside the
+// service, which have nothing to snapshot against.
+func (a *TournamentServiceAdapter) SnapshotStandings(ctx context.Context, tournamentID string) (*tournament.StandingsSnapshot, error) {
+	snapshot, err := a.service.SnapshotStandings(ctx, shared.TournamentID(tournamentID))
+	if errors.Is(err, tournament.ErrTournamentNotFound) {
+		return nil, nil
+	}
+	return snapshot, err
+}
+
 // RPC handler functions using the adapter
-func rpcCreateTournamentWithAdapter(ctx context.Context, _ runtime.Logger, _ *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+func rpcCreateTournamentWithAdapter(ctx context.Context, _ runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	args, err := decodeTournamentCreatePayload(payload)
 	if err != nil {
 		return "", err
 	}
 
-	adapter := NewTournamentServiceAdapter(nk)
+	adapter := NewTournamentServiceAdapter(db, nk)
 	return adapter.CreateTournament(ctx, *args)
 }
 
-func rpcDeleteTournamentWithAdapter(ctx context.Context, _ runtime.Logger, _ *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+func rpcDeleteTournamentWithAdapter(ctx context.Context, _ runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	var args tournamentIDPayload
 	if err := json.Unmarshal([]byte(payload), &args); err != nil {
 		return "", runtime.NewError("invalid payload", 3)
@@ -113,7 +143,7 @@ func rpcDeleteTournamentWithAdapter(ctx context.Context, _ runtime.Logger, _ *sq
 		return "", runtime.NewError("tournament_id is required", 3)
 	}
 
-	adapter := NewTournamentServiceAdapter(nk)
+	adapter := NewTournamentServiceAdapter(db, nk)
 	if err := adapter.DeleteTournament(ctx, args.TournamentID); err != nil {
 		return "", fmt.Errorf("deleting tournament: %w", err)
 	}
@@ -121,7 +151,7 @@ func rpcDeleteTournamentWithAdapter(ctx context.Context, _ runtime.Logger, _ *sq
 	return "{}", nil
 }
 
-func rpcAddAttemptTournamentWithAdapter(ctx context.Context, _ runtime.Logger, _ *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+func rpcAddAttemptTournamentWithAdapter(ctx context.Context, _ runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	var args tournamentAddAttemptPayload
 	if err := json.Unmarshal([]byte(payload), &args); err != nil {
 		return "", runtime.NewError("invalid payload", 3)
@@ -133,7 +163,7 @@ func rpcAddAttemptTournamentWithAdapter(ctx context.Context, _ runtime.Logger, _
 		return "", runtime.NewError("count must be non-zero", 3)
 	}
 
-	adapter := NewTournamentServiceAdapter(nk)
+	adapter := NewTournamentServiceAdapter(db, nk)
 	if err := adapter.AddAttempt(ctx, args.TournamentID, args.OwnerID, args.Count); err != nil {
 		return "", fmt.Errorf("adding tournament attempt: %w", err)
 	}
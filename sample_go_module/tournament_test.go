@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeAccountModule struct {
+	runtime.NakamaModule
+	metadata string
+	getErr   error
+
+	updated map[string]interface{}
+}
+
+func (m *fakeAccountModule) AccountGetId(ctx context.Context, userID string) (*api.Account, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return &api.Account{User: &api.User{Id: userID, Metadata: m.metadata}}, nil
+}
+
+func (m *fakeAccountModule) AccountUpdateId(ctx context.Context, userID, username string, metadata map[string]interface{}, displayName, timezone, location, langTag, avatarUrl string) error {
+	m.updated = metadata
+	return nil
+}
+
+func TestMergeAccountMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    string
+		getErr      error
+		wantUpdated map[string]interface{}
+		wantErr     bool
+	}{
+		{
+			name:        "preserves existing keys",
+			metadata:    `{"level":7,"won":"old"}`,
+			wantUpdated: map[string]interface{}{"level": float64(7), "won": "tournament-1"},
+		},
+		{
+			name:        "empty metadata",
+			metadata:    "",
+			wantUpdated: map[string]interface{}{"won": "tournament-1"},
+		},
+		{
+			name:   "account not found is skipped",
+			getErr: runtime.NewError("account not found", 5),
+		},
+		{
+			name:   "not found status is skipped",
+			getErr: status.Error(codes.NotFound, "user missing"),
+		},
+		{
+			name:    "read failure",
+			getErr:  errors.New("db unavailable"),
+			wantErr: true,
+		},
+		{
+			name:     "malformed metadata",
+			metadata: "{",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nk := &fakeAccountModule{metadata: tt.metadata, getErr: tt.getErr}
+
+			err := mergeAccountMetadata(context.Background(), nk, "user-1", map[string]interface{}{"won": "tournament-1"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mergeAccountMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, _ := json.Marshal(nk.updated)
+			want, _ := json.Marshal(tt.wantUpdated)
+			if tt.wantUpdated == nil {
+				want = []byte("null")
+			}
+			if string(got) != string(want) {
+				t.Errorf("Expected metadata %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+type fakeTournamentModule struct {
+	runtime.NakamaModule
+	sortOrder string
+	operator  string
+}
+
+func (m *fakeTournamentModule) TournamentCreate(ctx context.Context, id string, authoritative bool, sortOrder, operator, resetSchedule string, metadata map[string]interface{}, title, description string, category, startTime, endTime, duration, maxSize, maxNumScore int, joinRequired, enableRanks bool) error {
+	m.sortOrder, m.operator = sortOrder, operator
+	return nil
+}
+
+func TestTournamentServiceAdapter_CreateTournamentScoring(t *testing.T) {
+	tests := []struct {
+		name          string
+		sortOrder     string
+		operator      string
+		wantSortOrder string
+		wantOperator  string
+		wantErr       bool
+	}{
+		{name: "defaults", wantSortOrder: "desc", wantOperator: "best"},
+		{name: "asc best", sortOrder: "asc", operator: "best", wantSortOrder: "asc", wantOperator: "best"},
+		{name: "desc best", sortOrder: "desc", operator: "best", wantSortOrder: "desc", wantOperator: "best"},
+		{name: "asc set", sortOrder: "asc", operator: "set", wantSortOrder: "asc", wantOperator: "set"},
+		{name: "desc set", sortOrder: "desc", operator: "set", wantSortOrder: "desc", wantOperator: "set"},
+		{name: "desc incr", sortOrder: "desc", operator: "incr", wantSortOrder: "desc", wantOperator: "incr"},
+		{name: "asc decr", sortOrder: "asc", operator: "decr", wantSortOrder: "asc", wantOperator: "decr"},
+		{name: "spelled out sort order", sortOrder: "descending", operator: "best", wantErr: true},
+		{name: "unknown operator", sortOrder: "desc", operator: "max", wantErr: true},
+		{name: "incompatible pair", sortOrder: "asc", operator: "incr", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nk := &fakeTournamentModule{}
+			adapter := NewTournamentServiceAdapter(nil, nk)
+			_, err := adapter.CreateTournament(context.Background(), tournamentCreatePayload{
+				Title:     "Weekly Cup",
+				SortOrder: tt.sortOrder,
+				Operator:  tt.operator,
+				StartTime: 1700000000,
+				Duration:  3600,
+			})
+			if tt.wantErr {
+				var runtimeErr *runtime.Error
+				if !errors.As(err, &runtimeErr) || runtimeErr.Code != 3 {
+					t.Fatalf("CreateTournament() error = %v, want an invalid argument error", err)
+				}
+				if nk.sortOrder != "" {
+					t.Error("Expected no tournament to be created in Nakama")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateTournament() error = %v", err)
+			}
+			if nk.sortOrder != tt.wantSortOrder || nk.operator != tt.wantOperator {
+				t.Errorf("Nakama scoring = %s/%s, want %s/%s", nk.sortOrder, nk.operator, tt.wantSortOrder, tt.wantOperator)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package analytics
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// Exposure event property keys.
+const (
+	ExperimentProperty = "experiment"
+	VariantProperty    = "variant"
+)
+
+// ExperimentAssigner buckets users into experiment variants. Assignment hashes
+// the seed, experiment key, and user ID, so a user always lands in the same
+// variant of an experiment while the seed is unchanged. Changing the seed
+// reshuffles every experiment.
+type ExperimentAssigner struct {
+	Seed string
+}
+
+// NewExperimentAssigner creates an assigner using seed.
+func NewExperimentAssigner(seed string) *ExperimentAssigner {
+	return &ExperimentAssigner{Seed: seed}
+}
+
+// Assign returns the variant userID falls into. Each variant receives a share
+// of users proportional to its weight.
+func (a *ExperimentAssigner) Assign(userID shared.PlayerID, experiment analytics.Experiment) (string, error) {
+	if err := userID.Validate(); err != nil {
+		return "", err
+	}
+	if err := experiment.Validate(); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s", a.Seed, experiment.Key, userID)))
+	bucket := binary.BigEndian.Uint64(sum[:8]) % uint64(experiment.TotalWeight())
+	for _, v := range experiment.Variants {
+		if bucket < uint64(v.Weight) {
+			return v.Name, nil
+		}
+		bucket -= uint64(v.Weight)
+	}
+	// Unreachable: bucket is always below the total weight.
+	return experiment.Variants[len(experiment.Variants)-1].Name, nil
+}
+
+// AssignVariantCommand contains parameters for assigning an experiment variant.
+type AssignVariantCommand struct {
+	UserID     shared.PlayerID
+	Experiment analytics.Experiment
+}
+
+// AssignVariant buckets the user into a variant and tracks an exposure event
+// carrying the experiment key and variant. The variant is returned even when
+// the exposure could not be dispatched, since assignment does not depend on it.
+func (s *Service) AssignVariant(ctx context.Context, cmd AssignVariantCommand) (string, error) {
+	assigner := s.Experiments
+	if assigner == nil {
+		assigner = &ExperimentAssigner{}
+	}
+	variant, err := assigner.Assign(cmd.UserID, cmd.Experiment)
+	if err != nil {
+		return "", err
+	}
+
+	event, err := analytics.NewTrackEvent(cmd.UserID, analytics.EventNameExperimentExposure, s.ContextFactory(), s.Clock())
+	if err != nil {
+		return "", err
+	}
+	event.WithProperty(ExperimentProperty, cmd.Experiment.Key).WithProperty(VariantProperty, variant)
+	if err := s.Dispatcher.Dispatch(ctx, []*analytics.Event{event}); err != nil {
+		return variant, analytics.ErrDispatchFailed
+	}
+	return variant, nil
+}
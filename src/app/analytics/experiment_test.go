@@ -0,0 +1,141 @@
+package analytics_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/src/app/analytics"
+	domainAnalytics "github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraAnalytics "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+)
+
+var checkoutExperiment = domainAnalytics.Experiment{
+	Key: "checkout_button",
+	Variants: []domainAnalytics.Variant{
+		{Name: "control", Weight: 1},
+		{Name: "green", Weight: 3},
+	},
+}
+
+func TestExperimentAssigner_Deterministic(t *testing.T) {
+	assigner := analytics.NewExperimentAssigner("seed-1")
+
+	for i := 0; i < 100; i++ {
+		userID := shared.PlayerID(fmt.Sprintf("player-%d", i))
+		first, err := assigner.Assign(userID, checkoutExperiment)
+		if err != nil {
+			t.Fatalf("Assign() error = %v", err)
+		}
+		for j := 0; j < 3; j++ {
+			again, _ := analytics.NewExperimentAssigner("seed-1").Assign(userID, checkoutExperiment)
+			if again != first {
+				t.Fatalf("Assign(%s) = %q then %q, want a stable variant", userID, first, again)
+			}
+		}
+	}
+
+	reseeded := analytics.NewExperimentAssigner("seed-2")
+	changed := 0
+	for i := 0; i < 100; i++ {
+		userID := shared.PlayerID(fmt.Sprintf("player-%d", i))
+		a, _ := assigner.Assign(userID, checkoutExperiment)
+		b, _ := reseeded.Assign(userID, checkoutExperiment)
+		if a != b {
+			changed++
+		}
+	}
+	if changed == 0 {
+		t.Error("Expected a different seed to reshuffle some users")
+	}
+}
+
+func TestExperimentAssigner_WeightDistribution(t *testing.T) {
+	const users = 20000
+	assigner := analytics.NewExperimentAssigner("seed-1")
+
+	counts := make(map[string]int)
+	for i := 0; i < users; i++ {
+		variant, err := assigner.Assign(shared.PlayerID(fmt.Sprintf("player-%d", i)), checkoutExperiment)
+		if err != nil {
+			t.Fatalf("Assign() error = %v", err)
+		}
+		counts[variant]++
+	}
+
+	for _, v := range checkoutExperiment.Variants {
+		want := float64(users) * float64(v.Weight) / float64(checkoutExperiment.TotalWeight())
+		if got := float64(counts[v.Name]); math.Abs(got-want)/want > 0.05 {
+			t.Errorf("variant %q got %d users, want about %.0f", v.Name, counts[v.Name], want)
+		}
+	}
+}
+
+func TestExperimentAssigner_InvalidExperiment(t *testing.T) {
+	assigner := analytics.NewExperimentAssigner("")
+
+	tests := []struct {
+		name       string
+		experiment domainAnalytics.Experiment
+	}{
+		{name: "missing key", experiment: domainAnalytics.Experiment{Variants: []domainAnalytics.Variant{{Name: "a", Weight: 1}}}},
+		{name: "no variants", experiment: domainAnalytics.Experiment{Key: "exp"}},
+		{name: "zero weight", experiment: domainAnalytics.Experiment{Key: "exp", Variants: []domainAnalytics.Variant{{Name: "a", Weight: 0}}}},
+		{name: "duplicate variant", experiment: domainAnalytics.Experiment{Key: "exp", Variants: []domainAnalytics.Variant{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := assigner.Assign("player-1", tt.experiment); !errors.Is(err, domainAnalytics.ErrInvalidExperiment) {
+				t.Errorf("Assign() error = %v, wantErr %v", err, domainAnalytics.ErrInvalidExperiment)
+			}
+		})
+	}
+}
+
+func TestService_AssignVariant_TracksExposure(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := &testsupport.FakeDispatcher{}
+	service := analytics.NewService(dispatcher, infraAnalytics.NewMemorySessionRepository())
+	service.Clock = testsupport.NewFakeClock().Now
+	service.Experiments = analytics.NewExperimentAssigner("seed-1")
+
+	variant, err := service.AssignVariant(ctx, analytics.AssignVariantCommand{UserID: "player-1", Experiment: checkoutExperiment})
+	if err != nil {
+		t.Fatalf("AssignVariant() error = %v", err)
+	}
+	want, _ := service.Experiments.Assign("player-1", checkoutExperiment)
+	if variant != want {
+		t.Errorf("AssignVariant() = %q, want %q", variant, want)
+	}
+
+	events := dispatcher.Events()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 exposure event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Name != domainAnalytics.EventNameExperimentExposure || event.UserID != "player-1" {
+		t.Errorf("Unexpected exposure event %+v", event)
+	}
+	if event.Properties[analytics.ExperimentProperty] != "checkout_button" || event.Properties[analytics.VariantProperty] != variant {
+		t.Errorf("Unexpected exposure properties %v", event.Properties)
+	}
+}
+
+func TestService_AssignVariant_DispatchFailure(t *testing.T) {
+	dispatchErr := errors.New("segment down")
+	service := analytics.NewService(&mockDispatcher{
+		dispatchFunc: func(ctx context.Context, events []*domainAnalytics.Event) error { return dispatchErr },
+	}, infraAnalytics.NewMemorySessionRepository())
+
+	variant, err := service.AssignVariant(context.Background(), analytics.AssignVariantCommand{UserID: "player-1", Experiment: checkoutExperiment})
+	if !errors.Is(err, domainAnalytics.ErrDispatchFailed) {
+		t.Errorf("AssignVariant() error = %v, wantErr %v", err, domainAnalytics.ErrDispatchFailed)
+	}
+	if variant == "" {
+		t.Error("Expected the variant to be returned despite the dispatch failure")
+	}
+}
@@ -2,19 +2,96 @@ package analytics
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"runtime"
+	"sort"
 	"time"
 
 	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
 )
 
+// DefaultBatchSize is the maximum number of events handed to the dispatcher in one call.
+const DefaultBatchSize = 100
+
 // Service coordinates analytics operations.
 type Service struct {
 	Dispatcher analytics.EventDispatcher
 	Sessions   analytics.SessionRepository
 	Clock      func() time.Time
 	ContextFactory func() analytics.Context
+	// BatchSize caps how many events TrackEvents passes to a single Dispatch call.
+	// Zero or negative dispatches the whole batch at once.
+	BatchSize int
+	// Direct delivers events for TrackEventSync, bypassing any buffering done
+	// by Dispatcher. When nil, Dispatcher is used.
+	Direct analytics.EventDispatcher
+	// Identities suppresses repeat identify events for users whose traits have
+	// not changed. When nil, every session start sends an identify.
+	Identities IdentifyCache
+	// History, when set, records every session so EndAllSessions can reach
+	// sessions that are no longer the user's latest.
+	History analytics.SessionHistory
+	// Experiments buckets users for AssignVariant. When nil, an assigner with
+	// an empty seed is used.
+	Experiments *ExperimentAssigner
+	// OnSessionEnded, when set, receives the length of every session
+	// EndSession ends, measured on Clock.
+	OnSessionEnded func(userID shared.PlayerID, duration time.Duration)
+	// MaxSessions caps how many sessions a user may have active at once,
+	// counting those in History. Zero or negative means no cap.
+	MaxSessions int
+	// SessionLimit decides what StartSession does for a user already at
+	// MaxSessions. The zero value rejects the new session.
+	SessionLimit SessionLimitPolicy
+}
+
+// SessionLimitPolicy is the action StartSession takes when a user already
+// has MaxSessions active sessions.
+type SessionLimitPolicy string
+
+const (
+	// SessionLimitReject fails StartSession with ErrTooManySessions.
+	SessionLimitReject SessionLimitPolicy = "reject"
+	// SessionLimitEndOldest ends the user's oldest sessions to make room for
+	// the new one.
+	SessionLimitEndOldest SessionLimitPolicy = "end_oldest"
+)
+
+// Validate rejects policies outside the known set. The empty policy is valid
+// and means SessionLimitReject.
+func (p SessionLimitPolicy) Validate() error {
+	switch p {
+	case "", SessionLimitReject, SessionLimitEndOldest:
+		return nil
+	}
+	return shared.NewValidationError("session_limit", "must be reject or end_oldest")
+}
+
+// SessionDurationProperty is the end event property holding the session
+// length in seconds.
+const SessionDurationProperty = "duration_seconds"
+
+// IdentifyCache remembers the traits each user was last identified with.
+type IdentifyCache interface {
+	// Seen reports whether userID was identified with traitsHash recently enough to skip re-sending.
+	Seen(userID shared.PlayerID, traitsHash string, now time.Time) bool
+	// Remember records that userID was identified with traitsHash at now.
+	Remember(userID shared.PlayerID, traitsHash string, now time.Time)
+}
+
+// Flusher is implemented by dispatchers that buffer events before sending them.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Closer is implemented by dispatchers that hold buffered events and a
+// background worker which must be shut down.
+type Closer interface {
+	Close(ctx context.Context) error
 }
 
 // NewService creates a new analytics service.
@@ -24,6 +101,7 @@ func NewService(dispatcher analytics.EventDispatcher, sessions analytics.Session
 		Sessions:   sessions,
 		Clock:      func() time.Time { return time.Now().UTC() },
 		ContextFactory: defaultContextFactory,
+		BatchSize:      DefaultBatchSize,
 	}
 }
 
@@ -32,53 +110,103 @@ type StartSessionCommand struct {
 	UserID  shared.PlayerID
 	Version string
 	Variant string
+	// SessionID optionally identifies the session so it can later be ended
+	// on its own while the user's other sessions stay active.
+	SessionID string
+}
+
+// StartSessionResult reports what StartSessionWithEvents dispatched.
+type StartSessionResult struct {
+	Session *analytics.Session
+	// Events are the dispatched events in order: one end event per evicted
+	// session, an identify event when Identified, then the start event.
+	Events []*analytics.Event
+	// Evicted is the number of sessions ended to stay within MaxSessions.
+	Evicted int
+	// Identified reports whether an identify event was sent.
+	Identified bool
 }
 
 // StartSession initiates a user session and dispatches tracking events.
 func (s *Service) StartSession(ctx context.Context, cmd StartSessionCommand) error {
+	_, err := s.StartSessionWithEvents(ctx, cmd)
+	return err
+}
+
+// StartSessionWithEvents behaves like StartSession and also returns the
+// session and the events dispatched for it.
+func (s *Service) StartSessionWithEvents(ctx context.Context, cmd StartSessionCommand) (StartSessionResult, error) {
 	if err := cmd.UserID.Validate(); err != nil {
-		return err
+		return StartSessionResult{}, err
 	}
 	if cmd.Version == "" {
-		return analytics.ErrInvalidEvent
+		return StartSessionResult{}, analytics.ErrInvalidEvent
 	}
 
 	now := s.Clock()
+	evicted, err := s.enforceSessionLimit(ctx, cmd.UserID, now)
+	if err != nil {
+		return StartSessionResult{}, err
+	}
 	session, err := analytics.NewSession(cmd.UserID, cmd.Version, cmd.Variant, now)
 	if err != nil {
-		return err
+		return StartSessionResult{}, err
 	}
+	session.ID = cmd.SessionID
 
 	// Save session
 	if err := s.Sessions.Save(ctx, session); err != nil {
-		return err
+		return StartSessionResult{}, err
+	}
+	if err := s.recordHistory(ctx, session); err != nil {
+		return StartSessionResult{}, err
 	}
 
 	// Create events
 	context := s.ContextFactory()
-	identifyEvent, err := analytics.NewIdentifyEvent(cmd.UserID, context, now)
-	if err != nil {
-		return err
+	events := make([]*analytics.Event, 0, len(evicted)+2)
+	for _, old := range evicted {
+		endEvent, err := s.sessionEndEvent(old, context, now)
+		if err != nil {
+			return StartSessionResult{}, err
+		}
+		events = append(events, endEvent)
+	}
+	traitsHash := identifyTraitsHash(context, cmd.Version, cmd.Variant)
+	identify := s.Identities == nil || !s.Identities.Seen(cmd.UserID, traitsHash, now)
+	if identify {
+		identifyEvent, err := analytics.NewIdentifyEvent(cmd.UserID, context, now)
+		if err != nil {
+			return StartSessionResult{}, err
+		}
+		events = append(events, identifyEvent)
 	}
 
 	trackEvent, err := analytics.NewTrackEvent(cmd.UserID, analytics.EventNameStart, context, now)
 	if err != nil {
-		return err
+		return StartSessionResult{}, err
 	}
 	trackEvent.WithAppInfo(cmd.Variant, cmd.Version).WithOSInfo(runtime.GOOS, runtime.GOARCH)
+	events = append(events, trackEvent)
 
 	// Dispatch events
-	events := []*analytics.Event{identifyEvent, trackEvent}
 	if err := s.Dispatcher.Dispatch(ctx, events); err != nil {
-		return analytics.ErrDispatchFailed
+		return StartSessionResult{}, analytics.ErrDispatchFailed
 	}
 
-	return nil
+	if identify && s.Identities != nil {
+		s.Identities.Remember(cmd.UserID, traitsHash, now)
+	}
+
+	return StartSessionResult{Session: session, Events: events, Evicted: len(evicted), Identified: identify}, nil
 }
 
 // EndSessionCommand contains parameters for ending a session.
 type EndSessionCommand struct {
 	UserID shared.PlayerID
+	// SessionID, when set, ends only that session and leaves the user's other
+	// sessions active. When empty, the user's current session is ended.
+	SessionID string
 }
 
 // EndSession terminates a user session and dispatches tracking event.
@@ -88,7 +216,7 @@ func (s *Service) EndSession(ctx context.Context, cmd EndSessionCommand) error {
 	}
 
 	// Get existing session
-	session, err := s.Sessions.Get(ctx, cmd.UserID)
+	session, current, err := s.findSession(ctx, cmd)
 	if err != nil {
 		return err
 	}
@@ -99,13 +227,17 @@ func (s *Service) EndSession(ctx context.Context, cmd EndSessionCommand) error {
 	}
 
 	// Update session
-	if err := s.Sessions.Save(ctx, session); err != nil {
+	if current {
+		if err := s.Sessions.Save(ctx, session); err != nil {
+			return err
+		}
+	}
+	if err := s.recordHistory(ctx, session); err != nil {
 		return err
 	}
 
 	// Create end event
-	context := s.ContextFactory()
-	trackEvent, err := analytics.NewTrackEvent(cmd.UserID, analytics.EventNameEnd, context, now)
+	trackEvent, err := s.sessionEndEvent(session, s.ContextFactory(), now)
 	if err != nil {
 		return err
 	}
@@ -117,11 +249,178 @@ func (s *Service) EndSession(ctx context.Context, cmd EndSessionCommand) error {
 	}
 
 	// Clean up session
-	_ = s.Sessions.Delete(ctx, cmd.UserID)
+	if current {
+		_ = s.Sessions.Delete(ctx, cmd.UserID)
+	}
 
 	return nil
 }
 
+// sessionEndEvent reports the session's duration to OnSessionEnded and builds
+// the end event for a session that has just been ended.
+func (s *Service) sessionEndEvent(session *analytics.Session, context analytics.Context, now time.Time) (*analytics.Event, error) {
+	duration := session.Duration()
+	if s.OnSessionEnded != nil {
+		s.OnSessionEnded(session.UserID, duration)
+	}
+
+	trackEvent, err := analytics.NewTrackEvent(session.UserID, analytics.EventNameEnd, context, now)
+	if err != nil {
+		return nil, err
+	}
+	trackEvent.WithProperty(SessionDurationProperty, duration.Seconds())
+	if session.ID != "" {
+		trackEvent.WithProperty("session_id", session.ID)
+	}
+	return trackEvent, nil
+}
+
+// enforceSessionLimit makes room for a new session when the user is at
+// MaxSessions. Under SessionLimitEndOldest it ends and returns the oldest
+// active sessions; otherwise it returns ErrTooManySessions.
+func (s *Service) enforceSessionLimit(ctx context.Context, userID shared.PlayerID, now time.Time) ([]*analytics.Session, error) {
+	if s.MaxSessions <= 0 {
+		return nil, nil
+	}
+	active, err := s.activeSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	excess := len(active) - s.MaxSessions + 1
+	if excess <= 0 {
+		return nil, nil
+	}
+	if s.SessionLimit != SessionLimitEndOldest {
+		return nil, analytics.ErrTooManySessions
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].StartedAt.Before(active[j].StartedAt)
+	})
+	evicted := active[:excess]
+	for _, session := range evicted {
+		if err := session.End(now); err != nil {
+			return nil, err
+		}
+		if err := s.recordHistory(ctx, session); err != nil {
+			return nil, err
+		}
+	}
+	return evicted, nil
+}
+
+// findSession resolves the session EndSession should end and reports whether
+// it is the user's current session in the session store.
+func (s *Service) findSession(ctx context.Context, cmd EndSessionCommand) (*analytics.Session, bool, error) {
+	current, err := s.Sessions.Get(ctx, cmd.UserID)
+	if cmd.SessionID == "" {
+		return current, err == nil, err
+	}
+	if err != nil && !errors.Is(err, analytics.ErrSessionNotFound) {
+		return nil, false, err
+	}
+	if err == nil && current.ID == cmd.SessionID {
+		return current, true, nil
+	}
+	if s.History == nil {
+		return nil, false, analytics.ErrSessionNotFound
+	}
+	session, err := s.History.Get(ctx, cmd.UserID, cmd.SessionID)
+	if err != nil {
+		return nil, false, err
+	}
+	return session, false, nil
+}
+
+// EndAllSessions ends every active session for a user and dispatches an end
+// event for each, returning how many sessions were ended. Calling it for a
+// user with no active sessions is a no-op.
+func (s *Service) EndAllSessions(ctx context.Context, userID shared.PlayerID) (int, error) {
+	if err := userID.Validate(); err != nil {
+		return 0, err
+	}
+
+	active, err := s.activeSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if len(active) == 0 {
+		return 0, nil
+	}
+
+	// End copies, as repositories may hand out their stored sessions, so a
+	// failed dispatch leaves every session active and a retry sends their end
+	// events again.
+	now := s.Clock()
+	context := s.ContextFactory()
+	ended := make([]*analytics.Session, 0, len(active))
+	events := make([]*analytics.Event, 0, len(active))
+	for _, session := range active {
+		session := *session
+		if err := session.End(now); err != nil {
+			return 0, err
+		}
+
+		trackEvent, err := s.sessionEndEvent(&session, context, now)
+		if err != nil {
+			return 0, err
+		}
+		ended = append(ended, &session)
+		events = append(events, trackEvent)
+	}
+
+	if err := s.Dispatcher.Dispatch(ctx, events); err != nil {
+		return 0, analytics.ErrDispatchFailed
+	}
+	for _, session := range ended {
+		if err := s.recordHistory(ctx, session); err != nil {
+			return 0, err
+		}
+	}
+
+	// Clean up the current session
+	_ = s.Sessions.Delete(ctx, userID)
+
+	return len(active), nil
+}
+
+// activeSessions collects the user's active sessions from the history and
+// the current session store, without duplicates.
+func (s *Service) activeSessions(ctx context.Context, userID shared.PlayerID) ([]*analytics.Session, error) {
+	var active []*analytics.Session
+	if s.History != nil {
+		sessions, err := s.History.ListActive(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		active = sessions
+	}
+
+	current, err := s.Sessions.Get(ctx, userID)
+	if errors.Is(err, analytics.ErrSessionNotFound) {
+		return active, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !current.IsActive() {
+		return active, nil
+	}
+	for _, session := range active {
+		if session.SameAs(current) {
+			return active, nil
+		}
+	}
+	return append(active, current), nil
+}
+
+func (s *Service) recordHistory(ctx context.Context, session *analytics.Session) error {
+	if s.History == nil {
+		return nil
+	}
+	return s.History.Record(ctx, session)
+}
+
 // TrackEventCommand contains parameters for tracking custom events.
 type TrackEventCommand struct {
 	UserID  shared.PlayerID
@@ -130,35 +429,148 @@ type TrackEventCommand struct {
 	AppVersion string
 	OSName  string
 	OSVersion string
+	// Forwarded marks an event relayed by a server on a client's behalf
+	// rather than sent by the client itself, clearing Context.Direct.
+	Forwarded bool
+	// Properties are custom attributes sent with the event; keys must not
+	// be empty.
+	Properties map[string]any
 }
 
 // TrackEvent dispatches a custom tracking event.
 func (s *Service) TrackEvent(ctx context.Context, cmd TrackEventCommand) error {
-	if err := cmd.UserID.Validate(); err != nil {
+	event, err := s.newTrackEvent(cmd, s.Clock())
+	if err != nil {
 		return err
 	}
 
-	now := s.Clock()
-	context := s.ContextFactory()
-	
-	event, err := analytics.NewTrackEvent(cmd.UserID, cmd.Name, context, now)
+	events := []*analytics.Event{event}
+	if err := s.Dispatcher.Dispatch(ctx, events); err != nil {
+		return analytics.ErrDispatchFailed
+	}
+
+	return nil
+}
+
+// TrackEventSync dispatches a custom tracking event immediately, bypassing any
+// async buffering, and returns once the dispatcher has accepted or rejected it.
+// Use it for events that must be delivered before the caller proceeds.
+func (s *Service) TrackEventSync(ctx context.Context, cmd TrackEventCommand) error {
+	event, err := s.newTrackEvent(cmd, s.Clock())
 	if err != nil {
 		return err
 	}
 
+	dispatcher := s.Direct
+	if dispatcher == nil {
+		dispatcher = s.Dispatcher
+	}
+	if err := dispatcher.Dispatch(ctx, []*analytics.Event{event}); err != nil {
+		return analytics.ErrDispatchFailed
+	}
+
+	return nil
+}
+
+// Flush delivers any events buffered by the dispatcher. It is a no-op for
+// dispatchers that send synchronously.
+func (s *Service) Flush(ctx context.Context) error {
+	flusher, ok := s.Dispatcher.(Flusher)
+	if !ok {
+		return nil
+	}
+	return flusher.Flush(ctx)
+}
+
+// Close delivers buffered events and stops the dispatcher's background work.
+// Call it once no more events will be tracked, such as on shutdown.
+// Dispatchers that do not buffer are left untouched.
+func (s *Service) Close(ctx context.Context) error {
+	if closer, ok := s.Dispatcher.(Closer); ok {
+		return closer.Close(ctx)
+	}
+	return s.Flush(ctx)
+}
+
+// TrackEvents dispatches a batch of custom tracking events and returns how
+// many were accepted. Every command is validated before anything is sent, and
+// the events are handed to the dispatcher in chunks of at most BatchSize.
+// When a chunk fails, the count covers the chunks already sent, so the caller
+// can retry only the commands from that index on.
+func (s *Service) TrackEvents(ctx context.Context, cmds []TrackEventCommand) (int, error) {
+	if len(cmds) == 0 {
+		return 0, nil
+	}
+
+	now := s.Clock()
+	events := make([]*analytics.Event, 0, len(cmds))
+	for _, cmd := range cmds {
+		event, err := s.newTrackEvent(cmd, now)
+		if err != nil {
+			return 0, err
+		}
+		events = append(events, event)
+	}
+
+	accepted := 0
+	for _, chunk := range chunkEvents(events, s.BatchSize) {
+		if err := s.Dispatcher.Dispatch(ctx, chunk); err != nil {
+			return accepted, analytics.ErrDispatchFailed
+		}
+		accepted += len(chunk)
+	}
+
+	return accepted, nil
+}
+
+func (s *Service) newTrackEvent(cmd TrackEventCommand, now time.Time) (*analytics.Event, error) {
+	if err := cmd.UserID.Validate(); err != nil {
+		return nil, err
+	}
+
+	eventContext := s.ContextFactory()
+	if cmd.Forwarded {
+		eventContext.Direct = false
+	}
+	event, err := analytics.NewTrackEvent(cmd.UserID, cmd.Name, eventContext, now)
+	if err != nil {
+		return nil, err
+	}
+
 	if cmd.AppName != "" || cmd.AppVersion != "" {
 		event.WithAppInfo(cmd.AppName, cmd.AppVersion)
 	}
 	if cmd.OSName != "" || cmd.OSVersion != "" {
 		event.WithOSInfo(cmd.OSName, cmd.OSVersion)
 	}
+	event.WithProperties(cmd.Properties)
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
 
-	events := []*analytics.Event{event}
-	if err := s.Dispatcher.Dispatch(ctx, events); err != nil {
-		return analytics.ErrDispatchFailed
+	return event, nil
+}
+
+func chunkEvents(events []*analytics.Event, size int) [][]*analytics.Event {
+	if size <= 0 || len(events) <= size {
+		return [][]*analytics.Event{events}
+	}
+	chunks := make([][]*analytics.Event, 0, (len(events)+size-1)/size)
+	for start := 0; start < len(events); start += size {
+		end := start + size
+		if end > len(events) {
+			end = len(events)
+		}
+		chunks = append(chunks, events[start:end])
 	}
+	return chunks
+}
 
-	return nil
+// identifyTraitsHash fingerprints the traits an identify event describes so
+// unchanged users can be skipped.
+func identifyTraitsHash(ctx analytics.Context, version, variant string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%t|%s|%s|%s|%s", ctx.Direct, ctx.Library.Name, ctx.Library.Version, version, variant)))
+	return hex.EncodeToString(sum[:])
 }
 
 func defaultContextFactory() analytics.Context {
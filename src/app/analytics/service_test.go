@@ -3,12 +3,15 @@ package analytics_test
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/heroiclabs/nakama/v3/src/app/analytics"
 	domainAnalytics "github.com/heroiclabs/nakama/v3/src/domain/analytics"
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraAnalytics "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
 )
 
 // Mock implementations
@@ -221,6 +224,38 @@ func TestService_EndSession(t *testing.T) {
 	}
 }
 
+func TestService_EndSession_Duration(t *testing.T) {
+	ctx := context.Background()
+	clock := testsupport.NewFakeClock()
+	dispatcher := &testsupport.FakeDispatcher{}
+	service := analytics.NewService(dispatcher, infraAnalytics.NewMemorySessionRepository())
+	service.Clock = clock.Now
+	var (
+		endedUser shared.PlayerID
+		ended     time.Duration
+	)
+	service.OnSessionEnded = func(userID shared.PlayerID, duration time.Duration) {
+		endedUser, ended = userID, duration
+	}
+
+	if err := service.StartSession(ctx, analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0"}); err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	clock.Advance(90 * time.Second)
+	if err := service.EndSession(ctx, analytics.EndSessionCommand{UserID: "player-123"}); err != nil {
+		t.Fatalf("EndSession() error = %v", err)
+	}
+
+	if endedUser != "player-123" || ended != 90*time.Second {
+		t.Errorf("OnSessionEnded got (%q, %v), want (player-123, 1m30s)", endedUser, ended)
+	}
+	events := dispatcher.Events()
+	end := events[len(events)-1]
+	if end.Name != domainAnalytics.EventNameEnd || end.Properties[analytics.SessionDurationProperty] != 90.0 {
+		t.Errorf("end event = %s %v, want duration 90", end.Name, end.Properties)
+	}
+}
+
 func TestService_TrackEvent(t *testing.T) {
 	ctx := context.Background()
 
@@ -257,6 +292,15 @@ func TestService_TrackEvent(t *testing.T) {
 			dispatchErr: errors.New("dispatch failed"),
 			wantErr:     true,
 		},
+		{
+			name: "empty property key",
+			cmd: analytics.TrackEventCommand{
+				UserID:     "player-123",
+				Name:       "level_complete",
+				Properties: map[string]any{"": 3},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -277,3 +321,783 @@ func TestService_TrackEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestService_TrackEvents(t *testing.T) {
+	ctx := context.Background()
+
+	makeCmds := func(n int) []analytics.TrackEventCommand {
+		cmds := make([]analytics.TrackEventCommand, 0, n)
+		for i := 0; i < n; i++ {
+			cmds = append(cmds, analytics.TrackEventCommand{
+				UserID: "player-123",
+				Name:   "level_complete",
+			})
+		}
+		return cmds
+	}
+
+	tests := []struct {
+		name       string
+		cmds       []analytics.TrackEventCommand
+		batchSize  int
+		wantChunks []int
+		wantErr    bool
+	}{
+		{
+			name:       "empty batch dispatches nothing",
+			cmds:       nil,
+			batchSize:  10,
+			wantChunks: nil,
+		},
+		{
+			name:       "exactly at batch size",
+			cmds:       makeCmds(10),
+			batchSize:  10,
+			wantChunks: []int{10},
+		},
+		{
+			name:       "one over batch size",
+			cmds:       makeCmds(11),
+			batchSize:  10,
+			wantChunks: []int{10, 1},
+		},
+		{
+			name:       "several chunks",
+			cmds:       makeCmds(25),
+			batchSize:  10,
+			wantChunks: []int{10, 10, 5},
+		},
+		{
+			name:       "unbounded batch size",
+			cmds:       makeCmds(25),
+			batchSize:  0,
+			wantChunks: []int{25},
+		},
+		{
+			name: "invalid event rejects whole batch",
+			cmds: append(makeCmds(3), analytics.TrackEventCommand{
+				UserID: "",
+				Name:   "level_complete",
+			}),
+			batchSize:  10,
+			wantChunks: nil,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher := &testsupport.FakeDispatcher{}
+			service := analytics.NewService(dispatcher, infraAnalytics.NewMemorySessionRepository())
+			service.Clock = testsupport.NewFakeClock().Now
+			service.BatchSize = tt.batchSize
+
+			accepted, err := service.TrackEvents(ctx, tt.cmds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TrackEvents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && accepted != len(tt.cmds) {
+				t.Errorf("Expected %d accepted, got %d", len(tt.cmds), accepted)
+			}
+
+			chunks := dispatcher.BatchSizes()
+			if len(chunks) != len(tt.wantChunks) {
+				t.Fatalf("Expected chunks %v, got %v", tt.wantChunks, chunks)
+			}
+			for i := range chunks {
+				if chunks[i] != tt.wantChunks[i] {
+					t.Errorf("Expected chunks %v, got %v", tt.wantChunks, chunks)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestService_TrackEvents_PartialFailure(t *testing.T) {
+	calls := 0
+	dispatcher := &mockDispatcher{
+		dispatchFunc: func(ctx context.Context, events []*domainAnalytics.Event) error {
+			calls++
+			if calls == 3 {
+				return errors.New("backend unavailable")
+			}
+			return nil
+		},
+	}
+	service := analytics.NewService(dispatcher, infraAnalytics.NewMemorySessionRepository())
+	service.Clock = testsupport.NewFakeClock().Now
+	service.BatchSize = 10
+
+	cmds := make([]analytics.TrackEventCommand, 0, 25)
+	for i := 0; i < 25; i++ {
+		cmds = append(cmds, analytics.TrackEventCommand{UserID: "player-123", Name: "level_complete"})
+	}
+
+	accepted, err := service.TrackEvents(context.Background(), cmds)
+	if !errors.Is(err, domainAnalytics.ErrDispatchFailed) {
+		t.Fatalf("TrackEvents() error = %v, want ErrDispatchFailed", err)
+	}
+	if accepted != 20 {
+		t.Errorf("Expected the 20 events of the first two chunks accepted, got %d", accepted)
+	}
+}
+
+func TestService_TrackEvent_Forwarded(t *testing.T) {
+	tests := []struct {
+		name       string
+		forwarded  bool
+		wantDirect bool
+	}{
+		{name: "client event", forwarded: false, wantDirect: true},
+		{name: "server forwarded", forwarded: true, wantDirect: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher := &testsupport.FakeDispatcher{}
+			service := analytics.NewService(dispatcher, &mockSessionRepo{})
+
+			cmd := analytics.TrackEventCommand{UserID: "player-123", Name: "purchase", Forwarded: tt.forwarded}
+			if err := service.TrackEvent(context.Background(), cmd); err != nil {
+				t.Fatalf("TrackEvent() error = %v", err)
+			}
+			events := dispatcher.Events()
+			if len(events) != 1 {
+				t.Fatalf("Expected 1 event, got %d", len(events))
+			}
+			if events[0].Context.Direct != tt.wantDirect {
+				t.Errorf("Context.Direct = %v, want %v", events[0].Context.Direct, tt.wantDirect)
+			}
+		})
+	}
+}
+
+func TestService_TrackEvent_Properties(t *testing.T) {
+	dispatcher := &testsupport.FakeDispatcher{}
+	service := analytics.NewService(dispatcher, &mockSessionRepo{})
+
+	cmd := analytics.TrackEventCommand{
+		UserID:     "player-123",
+		Name:       "level_complete",
+		Properties: map[string]any{"level": 3, "score": 1200},
+	}
+	if err := service.TrackEvent(context.Background(), cmd); err != nil {
+		t.Fatalf("TrackEvent() error = %v", err)
+	}
+	events := dispatcher.Events()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if !reflect.DeepEqual(events[0].Properties, cmd.Properties) {
+		t.Errorf("Properties = %v, want %v", events[0].Properties, cmd.Properties)
+	}
+}
+
+func TestService_TrackEventSync(t *testing.T) {
+	ctx := context.Background()
+	cmd := analytics.TrackEventCommand{UserID: "player-123", Name: "purchase"}
+
+	t.Run("delivers through direct dispatcher before returning", func(t *testing.T) {
+		var buffered, direct int
+		service := analytics.NewService(&mockDispatcher{
+			dispatchFunc: func(ctx context.Context, events []*domainAnalytics.Event) error {
+				buffered += len(events)
+				return nil
+			},
+		}, &mockSessionRepo{})
+		service.Direct = &mockDispatcher{
+			dispatchFunc: func(ctx context.Context, events []*domainAnalytics.Event) error {
+				direct += len(events)
+				return nil
+			},
+		}
+
+		if err := service.TrackEventSync(ctx, cmd); err != nil {
+			t.Fatalf("TrackEventSync() error = %v", err)
+		}
+		if direct != 1 {
+			t.Errorf("Expected 1 event delivered directly, got %d", direct)
+		}
+		if buffered != 0 {
+			t.Errorf("Expected no buffered events, got %d", buffered)
+		}
+	})
+
+	t.Run("returns dispatch error", func(t *testing.T) {
+		sendErr := errors.New("segment unavailable")
+		service := analytics.NewService(&mockDispatcher{
+			dispatchFunc: func(ctx context.Context, events []*domainAnalytics.Event) error {
+				return sendErr
+			},
+		}, &mockSessionRepo{})
+
+		err := service.TrackEventSync(ctx, cmd)
+		if !errors.Is(err, domainAnalytics.ErrDispatchFailed) {
+			t.Errorf("TrackEventSync() error = %v, want ErrDispatchFailed", err)
+		}
+	})
+
+	t.Run("invalid command", func(t *testing.T) {
+		service := analytics.NewService(&mockDispatcher{}, &mockSessionRepo{})
+		if err := service.TrackEventSync(ctx, analytics.TrackEventCommand{Name: "purchase"}); err == nil {
+			t.Error("Expected validation error")
+		}
+	})
+}
+
+func TestService_StartSession_IdentifyCache(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type step struct {
+		at           time.Duration
+		version      string
+		wantIdentify bool
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "unchanged traits are suppressed",
+			steps: []step{
+				{at: 0, version: "1.0.0", wantIdentify: true},
+				{at: time.Minute, version: "1.0.0", wantIdentify: false},
+			},
+		},
+		{
+			name: "changed traits are re-sent",
+			steps: []step{
+				{at: 0, version: "1.0.0", wantIdentify: true},
+				{at: time.Minute, version: "1.1.0", wantIdentify: true},
+				{at: 2 * time.Minute, version: "1.1.0", wantIdentify: false},
+			},
+		},
+		{
+			name: "expired entry is re-sent",
+			steps: []step{
+				{at: 0, version: "1.0.0", wantIdentify: true},
+				{at: 2 * time.Hour, version: "1.0.0", wantIdentify: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured []*domainAnalytics.Event
+			dispatcher := &mockDispatcher{
+				dispatchFunc: func(ctx context.Context, events []*domainAnalytics.Event) error {
+					captured = events
+					return nil
+				},
+			}
+
+			service := analytics.NewService(dispatcher, &mockSessionRepo{})
+			service.Identities = infraAnalytics.NewMemoryIdentifyCache(time.Hour, 0)
+
+			for i, st := range tt.steps {
+				now := start.Add(st.at)
+				service.Clock = func() time.Time { return now }
+
+				err := service.StartSession(ctx, analytics.StartSessionCommand{
+					UserID:  "player-123",
+					Version: st.version,
+					Variant: "production",
+				})
+				if err != nil {
+					t.Fatalf("step %d: StartSession() error = %v", i, err)
+				}
+
+				var identified, tracked bool
+				for _, event := range captured {
+					switch event.Type {
+					case domainAnalytics.EventTypeIdentify:
+						identified = true
+					case domainAnalytics.EventTypeTrack:
+						tracked = true
+					}
+				}
+				if identified != st.wantIdentify {
+					t.Errorf("step %d: identify sent = %v, want %v", i, identified, st.wantIdentify)
+				}
+				if !tracked {
+					t.Errorf("step %d: expected start track event", i)
+				}
+			}
+		})
+	}
+}
+
+func TestService_EndAllSessions(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		starts    int
+		wantEnded int
+	}{
+		{name: "no active sessions", starts: 0, wantEnded: 0},
+		{name: "single session", starts: 1, wantEnded: 1},
+		{name: "multiple active sessions", starts: 3, wantEnded: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var endEvents int
+			dispatcher := &mockDispatcher{
+				dispatchFunc: func(ctx context.Context, events []*domainAnalytics.Event) error {
+					for _, event := range events {
+						if event.Name == domainAnalytics.EventNameEnd {
+							endEvents++
+						}
+					}
+					return nil
+				},
+			}
+
+			history := infraAnalytics.NewMemorySessionHistory(infraAnalytics.DefaultSessionHistoryLimit)
+			service := analytics.NewService(dispatcher, infraAnalytics.NewMemorySessionRepository())
+			service.History = history
+
+			for i := 0; i < tt.starts; i++ {
+				now := start.Add(time.Duration(i) * time.Minute)
+				service.Clock = func() time.Time { return now }
+				if err := service.StartSession(ctx, analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0"}); err != nil {
+					t.Fatalf("StartSession() error = %v", err)
+				}
+			}
+
+			end := start.Add(time.Hour)
+			service.Clock = func() time.Time { return end }
+
+			ended, err := service.EndAllSessions(ctx, "player-123")
+			if err != nil {
+				t.Fatalf("EndAllSessions() error = %v", err)
+			}
+			if ended != tt.wantEnded {
+				t.Errorf("Expected %d sessions ended, got %d", tt.wantEnded, ended)
+			}
+			if endEvents != tt.wantEnded {
+				t.Errorf("Expected %d end events, got %d", tt.wantEnded, endEvents)
+			}
+
+			active, err := history.ListActive(ctx, "player-123")
+			if err != nil {
+				t.Fatalf("ListActive() error = %v", err)
+			}
+			if len(active) != 0 {
+				t.Errorf("Expected no active sessions left, got %d", len(active))
+			}
+
+			ended, err = service.EndAllSessions(ctx, "player-123")
+			if err != nil {
+				t.Fatalf("second EndAllSessions() error = %v", err)
+			}
+			if ended != 0 {
+				t.Errorf("Expected second call to end nothing, got %d", ended)
+			}
+		})
+	}
+}
+
+func TestService_EndAllSessions_DispatchFailureKeepsSessions(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := &testsupport.FakeDispatcher{}
+	history := infraAnalytics.NewMemorySessionHistory(infraAnalytics.DefaultSessionHistoryLimit)
+	service := analytics.NewService(dispatcher, infraAnalytics.NewMemorySessionRepository())
+	service.History = history
+
+	for _, id := range []string{"phone", "tablet"} {
+		if err := service.StartSession(ctx, analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0", SessionID: id}); err != nil {
+			t.Fatalf("StartSession(%s) error = %v", id, err)
+		}
+	}
+
+	dispatcher.Err = errors.New("collector unavailable")
+	if _, err := service.EndAllSessions(ctx, "player-123"); !errors.Is(err, domainAnalytics.ErrDispatchFailed) {
+		t.Fatalf("EndAllSessions() error = %v, want ErrDispatchFailed", err)
+	}
+	if active, _ := history.ListActive(ctx, "player-123"); len(active) != 2 {
+		t.Fatalf("Expected both sessions to stay active after a failed dispatch, got %d", len(active))
+	}
+
+	dispatcher.Err = nil
+	ended, err := service.EndAllSessions(ctx, "player-123")
+	if err != nil {
+		t.Fatalf("retried EndAllSessions() error = %v", err)
+	}
+	if ended != 2 {
+		t.Errorf("Expected the retry to end 2 sessions, got %d", ended)
+	}
+	batches := dispatcher.Batches()
+	if last := batches[len(batches)-1]; len(last) != 2 {
+		t.Errorf("Expected the retry to dispatch 2 end events, got %d", len(last))
+	}
+}
+
+func TestService_EndAllSessions_DispatchFailureWithoutHistory(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	dispatcher := &testsupport.FakeDispatcher{}
+	sessions := infraAnalytics.NewMemorySessionRepository()
+	service := analytics.NewService(dispatcher, sessions)
+	var durations []time.Duration
+	service.OnSessionEnded = func(userID shared.PlayerID, duration time.Duration) {
+		durations = append(durations, duration)
+	}
+
+	service.Clock = func() time.Time { return start }
+	if err := service.StartSession(ctx, analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0", SessionID: "phone"}); err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	service.Clock = func() time.Time { return start.Add(time.Minute) }
+
+	dispatcher.Err = errors.New("collector unavailable")
+	if _, err := service.EndAllSessions(ctx, "player-123"); !errors.Is(err, domainAnalytics.ErrDispatchFailed) {
+		t.Fatalf("EndAllSessions() error = %v, want ErrDispatchFailed", err)
+	}
+	if current, err := sessions.Get(ctx, "player-123"); err != nil || !current.IsActive() {
+		t.Fatalf("Expected the current session to stay active after a failed dispatch, got %+v, %v", current, err)
+	}
+
+	dispatcher.Err = nil
+	ended, err := service.EndAllSessions(ctx, "player-123")
+	if err != nil {
+		t.Fatalf("retried EndAllSessions() error = %v", err)
+	}
+	if ended != 1 {
+		t.Fatalf("Expected the retry to end 1 session, got %d", ended)
+	}
+	batches := dispatcher.Batches()
+	last := batches[len(batches)-1]
+	if len(last) != 1 || last[0].Name != domainAnalytics.EventNameEnd {
+		t.Fatalf("Expected the retry to dispatch one end event, got %v", last)
+	}
+	if got := last[0].Properties["session_id"]; got != "phone" {
+		t.Errorf("session_id = %v, want phone", got)
+	}
+	if got := last[0].Properties[analytics.SessionDurationProperty]; got != 60.0 {
+		t.Errorf("%s = %v, want 60", analytics.SessionDurationProperty, got)
+	}
+	if len(durations) == 0 || durations[len(durations)-1] != time.Minute {
+		t.Errorf("Expected OnSessionEnded to receive 1m, got %v", durations)
+	}
+	if _, err := sessions.Get(ctx, "player-123"); !errors.Is(err, domainAnalytics.ErrSessionNotFound) {
+		t.Errorf("Expected the current session to be removed, got %v", err)
+	}
+}
+
+func TestService_EndSession_BySessionID(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var endEvents []*domainAnalytics.Event
+	dispatcher := &mockDispatcher{
+		dispatchFunc: func(ctx context.Context, events []*domainAnalytics.Event) error {
+			for _, event := range events {
+				if event.Name == domainAnalytics.EventNameEnd {
+					endEvents = append(endEvents, event)
+				}
+			}
+			return nil
+		},
+	}
+	history := infraAnalytics.NewMemorySessionHistory(infraAnalytics.DefaultSessionHistoryLimit)
+	sessions := infraAnalytics.NewMemorySessionRepository()
+	service := analytics.NewService(dispatcher, sessions)
+	service.History = history
+
+	for i, id := range []string{"phone", "tablet", "desktop"} {
+		now := start.Add(time.Duration(i) * time.Minute)
+		service.Clock = func() time.Time { return now }
+		if err := service.StartSession(ctx, analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0", SessionID: id}); err != nil {
+			t.Fatalf("StartSession(%s) error = %v", id, err)
+		}
+	}
+	service.Clock = func() time.Time { return start.Add(time.Hour) }
+
+	activeIDs := func() []string {
+		active, err := history.ListActive(ctx, "player-123")
+		if err != nil {
+			t.Fatalf("ListActive() error = %v", err)
+		}
+		ids := make([]string, 0, len(active))
+		for _, session := range active {
+			ids = append(ids, session.ID)
+		}
+		return ids
+	}
+
+	// An older session is ended without touching the current one.
+	if err := service.EndSession(ctx, analytics.EndSessionCommand{UserID: "player-123", SessionID: "phone"}); err != nil {
+		t.Fatalf("EndSession(phone) error = %v", err)
+	}
+	if got, want := activeIDs(), []string{"tablet", "desktop"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("active sessions = %v, want %v", got, want)
+	}
+	if current, err := sessions.Get(ctx, "player-123"); err != nil || current.ID != "desktop" || !current.IsActive() {
+		t.Errorf("current session = %+v, %v; want active desktop", current, err)
+	}
+	if len(endEvents) != 1 || endEvents[0].Properties["session_id"] != "phone" {
+		t.Errorf("end events = %+v, want one for phone", endEvents)
+	}
+
+	// The current session is ended and cleared from the session store.
+	if err := service.EndSession(ctx, analytics.EndSessionCommand{UserID: "player-123", SessionID: "desktop"}); err != nil {
+		t.Fatalf("EndSession(desktop) error = %v", err)
+	}
+	if got, want := activeIDs(), []string{"tablet"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("active sessions = %v, want %v", got, want)
+	}
+	if _, err := sessions.Get(ctx, "player-123"); !errors.Is(err, domainAnalytics.ErrSessionNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, domainAnalytics.ErrSessionNotFound)
+	}
+
+	if err := service.EndSession(ctx, analytics.EndSessionCommand{UserID: "player-123", SessionID: "unknown"}); !errors.Is(err, domainAnalytics.ErrSessionNotFound) {
+		t.Errorf("EndSession(unknown) error = %v, want %v", err, domainAnalytics.ErrSessionNotFound)
+	}
+	if err := service.EndSession(ctx, analytics.EndSessionCommand{UserID: "player-123", SessionID: "phone"}); err == nil {
+		t.Error("Expected error ending an already ended session")
+	}
+}
+
+func TestService_EndAllSessions_WithoutHistory(t *testing.T) {
+	ctx := context.Background()
+	service := analytics.NewService(&mockDispatcher{}, infraAnalytics.NewMemorySessionRepository())
+
+	if err := service.StartSession(ctx, analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0"}); err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	ended, err := service.EndAllSessions(ctx, "player-123")
+	if err != nil {
+		t.Fatalf("EndAllSessions() error = %v", err)
+	}
+	if ended != 1 {
+		t.Errorf("Expected current session to be ended, got %d", ended)
+	}
+
+	if _, err := service.EndAllSessions(ctx, ""); err == nil {
+		t.Error("Expected validation error for empty user id")
+	}
+}
+
+func TestService_StartSession_MaxSessions(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     analytics.SessionLimitPolicy
+		wantErr    error
+		wantActive []string
+		wantEnded  []string
+	}{
+		{
+			name:       "reject new session",
+			policy:     analytics.SessionLimitReject,
+			wantErr:    domainAnalytics.ErrTooManySessions,
+			wantActive: []string{"phone", "tablet"},
+		},
+		{
+			name:       "default rejects",
+			wantErr:    domainAnalytics.ErrTooManySessions,
+			wantActive: []string{"phone", "tablet"},
+		},
+		{
+			name:       "end oldest session",
+			policy:     analytics.SessionLimitEndOldest,
+			wantActive: []string{"tablet", "desktop"},
+			wantEnded:  []string{"phone"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clock := testsupport.NewFakeClock()
+			dispatcher := &testsupport.FakeDispatcher{}
+			history := infraAnalytics.NewMemorySessionHistory(infraAnalytics.DefaultSessionHistoryLimit)
+			sessions := infraAnalytics.NewMemorySessionRepository()
+			service := analytics.NewService(dispatcher, sessions)
+			service.Clock = clock.Now
+			service.History = history
+			service.MaxSessions = 2
+			service.SessionLimit = tt.policy
+
+			for _, id := range []string{"phone", "tablet"} {
+				if err := service.StartSession(ctx, analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0", SessionID: id}); err != nil {
+					t.Fatalf("StartSession(%s) error = %v", id, err)
+				}
+				clock.Advance(time.Minute)
+			}
+			before := len(dispatcher.Events())
+
+			err := service.StartSession(ctx, analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0", SessionID: "desktop"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("StartSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			active, err := history.ListActive(ctx, "player-123")
+			if err != nil {
+				t.Fatalf("ListActive() error = %v", err)
+			}
+			var activeIDs []string
+			for _, session := range active {
+				activeIDs = append(activeIDs, session.ID)
+			}
+			if !reflect.DeepEqual(activeIDs, tt.wantActive) {
+				t.Errorf("active sessions = %v, want %v", activeIDs, tt.wantActive)
+			}
+
+			var endedIDs []string
+			for _, event := range dispatcher.Events()[before:] {
+				if event.Name == domainAnalytics.EventNameEnd {
+					endedIDs = append(endedIDs, event.Properties["session_id"].(string))
+				}
+			}
+			if !reflect.DeepEqual(endedIDs, tt.wantEnded) {
+				t.Errorf("end events for %v, want %v", endedIDs, tt.wantEnded)
+			}
+			current, err := sessions.Get(ctx, "player-123")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if want := tt.wantActive[len(tt.wantActive)-1]; current.ID != want {
+				t.Errorf("current session = %q, want %q", current.ID, want)
+			}
+		})
+	}
+}
+
+func TestSessionLimitPolicy_Validate(t *testing.T) {
+	for _, policy := range []analytics.SessionLimitPolicy{"", analytics.SessionLimitReject, analytics.SessionLimitEndOldest} {
+		if err := policy.Validate(); err != nil {
+			t.Errorf("Validate(%q) error = %v", policy, err)
+		}
+	}
+	for _, policy := range []analytics.SessionLimitPolicy{"end-oldest", "Reject", "drop"} {
+		if verr, ok := shared.AsValidationError(policy.Validate()); !ok || verr.Field != "session_limit" {
+			t.Errorf("Validate(%q) error = %v, want a session_limit validation error", policy, verr)
+		}
+	}
+}
+
+func TestService_StartSessionWithEvents(t *testing.T) {
+	tests := []struct {
+		name           string
+		setup          func(t *testing.T, service *analytics.Service)
+		wantEvents     []string
+		wantEvicted    int
+		wantIdentified bool
+	}{
+		{
+			name:           "first start identifies",
+			wantEvents:     []string{"identify", "track/start"},
+			wantIdentified: true,
+		},
+		{
+			name: "known traits skip identify",
+			setup: func(t *testing.T, service *analytics.Service) {
+				service.Identities = infraAnalytics.NewMemoryIdentifyCache(time.Hour, 0)
+				if err := service.StartSession(context.Background(), analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0", SessionID: "phone"}); err != nil {
+					t.Fatalf("StartSession() error = %v", err)
+				}
+			},
+			wantEvents: []string{"track/start"},
+		},
+		{
+			name: "evicted sessions end first",
+			setup: func(t *testing.T, service *analytics.Service) {
+				service.MaxSessions = 1
+				service.SessionLimit = analytics.SessionLimitEndOldest
+				if err := service.StartSession(context.Background(), analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0", SessionID: "phone"}); err != nil {
+					t.Fatalf("StartSession() error = %v", err)
+				}
+			},
+			wantEvents:     []string{"track/end", "identify", "track/start"},
+			wantEvicted:    1,
+			wantIdentified: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dispatcher := &testsupport.FakeDispatcher{}
+			service := analytics.NewService(dispatcher, infraAnalytics.NewMemorySessionRepository())
+			service.Clock = testsupport.NewFakeClock().Now
+			service.History = infraAnalytics.NewMemorySessionHistory(infraAnalytics.DefaultSessionHistoryLimit)
+			if tt.setup != nil {
+				tt.setup(t, service)
+			}
+			before := len(dispatcher.Events())
+
+			result, err := service.StartSessionWithEvents(ctx, analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0", SessionID: "desktop"})
+			if err != nil {
+				t.Fatalf("StartSessionWithEvents() error = %v", err)
+			}
+
+			var got []string
+			for _, event := range result.Events {
+				kind := string(event.Type)
+				if event.Name != "" {
+					kind += "/" + string(event.Name)
+				}
+				got = append(got, kind)
+			}
+			if !reflect.DeepEqual(got, tt.wantEvents) {
+				t.Errorf("StartSessionWithEvents() events = %v, want %v", got, tt.wantEvents)
+			}
+			if dispatched := dispatcher.Events()[before:]; !reflect.DeepEqual(dispatched, result.Events) {
+				t.Errorf("Expected result events to match the %d dispatched events", len(dispatched))
+			}
+			if result.Evicted != tt.wantEvicted {
+				t.Errorf("StartSessionWithEvents() evicted = %d, want %d", result.Evicted, tt.wantEvicted)
+			}
+			if result.Identified != tt.wantIdentified {
+				t.Errorf("StartSessionWithEvents() identified = %v, want %v", result.Identified, tt.wantIdentified)
+			}
+			if result.Session == nil || result.Session.ID != "desktop" {
+				t.Errorf("StartSessionWithEvents() session = %+v, want id desktop", result.Session)
+			}
+		})
+	}
+}
+
+func TestService_StartSessionWithEvents_DispatchFailure(t *testing.T) {
+	dispatcher := &mockDispatcher{
+		dispatchFunc: func(ctx context.Context, events []*domainAnalytics.Event) error {
+			return errors.New("dispatch failed")
+		},
+	}
+	service := analytics.NewService(dispatcher, infraAnalytics.NewMemorySessionRepository())
+
+	result, err := service.StartSessionWithEvents(context.Background(), analytics.StartSessionCommand{UserID: "player-123", Version: "1.0.0"})
+	if !errors.Is(err, domainAnalytics.ErrDispatchFailed) {
+		t.Fatalf("StartSessionWithEvents() error = %v, want %v", err, domainAnalytics.ErrDispatchFailed)
+	}
+	if len(result.Events) != 0 {
+		t.Errorf("Expected no events on failure, got %d", len(result.Events))
+	}
+}
+
+func TestService_Close(t *testing.T) {
+	ctx := context.Background()
+	next := &testsupport.FakeDispatcher{}
+	dispatcher := infraAnalytics.NewBatchingAsyncDispatcher(next, 0, 10, time.Hour)
+	service := analytics.NewService(dispatcher, infraAnalytics.NewMemorySessionRepository())
+
+	if err := service.TrackEvent(ctx, analytics.TrackEventCommand{UserID: "player-123", Name: "level_complete"}); err != nil {
+		t.Fatalf("TrackEvent() error = %v", err)
+	}
+	if err := service.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if sizes := next.BatchSizes(); !reflect.DeepEqual(sizes, []int{1}) {
+		t.Errorf("Expected the buffered event to be sent on Close, got batches %v", sizes)
+	}
+
+	plain := analytics.NewService(&mockDispatcher{}, infraAnalytics.NewMemorySessionRepository())
+	if err := plain.Close(ctx); err != nil {
+		t.Errorf("Close() of an unbuffered dispatcher error = %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// Login strategies reported to a LoginRecorder.
+const (
+	StrategyDevice = "device"
+	StrategyEmail  = "email"
+	StrategyCustom = "custom"
+)
+
+// Login failure reasons returned by FailureReason.
+const (
+	ReasonInvalidCredentials = "invalid_credentials"
+	ReasonSuspended          = "suspended"
+	ReasonBanned             = "banned"
+	ReasonInvalidRequest     = "invalid_request"
+	ReasonError              = "error"
+)
+
+// LoginRecorder observes the outcome of every login attempt, for example to
+// export success and failure rates.
+type LoginRecorder interface {
+	// RecordLogin is called once per attempt with the strategy used and the
+	// error the attempt failed with, or nil on success.
+	RecordLogin(strategy string, err error)
+}
+
+// FailureReason classifies a login error into one of the Reason constants.
+// Errors that say nothing about the player, such as a Nakama outage, are
+// ReasonError.
+func FailureReason(err error) string {
+	switch {
+	case errors.Is(err, player.ErrAccountBanned):
+		return ReasonBanned
+	case errors.Is(err, player.ErrAccountSuspended):
+		return ReasonSuspended
+	case errors.Is(err, player.ErrBadCredentials):
+		return ReasonInvalidCredentials
+	}
+	if _, ok := shared.AsValidationError(err); ok {
+		return ReasonInvalidRequest
+	}
+	return ReasonError
+}
+
+func (s *Service) recordLogin(strategy string, err error) {
+	if s.Logins != nil {
+		s.Logins.RecordLogin(strategy, err)
+	}
+}
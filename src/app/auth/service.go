@@ -3,6 +3,8 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/heroiclabs/nakama/v3/src/domain/player"
@@ -15,14 +17,27 @@ type AuthResult struct {
 	SessionToken string
 	RefreshToken string
 	Username     string
+	// ExpiresAt is when the session token expires. It is zero when the
+	// provider did not report it and the token could not be parsed.
+	ExpiresAt time.Time
 }
 
 // AuthProvider describes the Nakama authentication integration.
+// Implementations report rejected credentials as player.ErrBadCredentials
+// and banned accounts as player.ErrAccountBanned.
 type AuthProvider interface {
 	AuthenticateDevice(ctx context.Context, deviceID, username string, vars map[string]string) (AuthResult, error)
 	AuthenticateEmail(ctx context.Context, email, password string, vars map[string]string) (AuthResult, error)
+	// AuthenticateCustom signs in with a token issued by an external identity
+	// provider, such as a signed JWT.
+	AuthenticateCustom(ctx context.Context, token string, vars map[string]string) (AuthResult, error)
+	// SessionRefresh exchanges a refresh token for a new session.
+	SessionRefresh(ctx context.Context, refreshToken string) (AuthResult, error)
 }
 
+// ErrRefreshTokenRequired is returned when RefreshSession is called without a token.
+var ErrRefreshTokenRequired = errors.New("refresh token is required")
+
 // PlayerRepository defines the persistence contract needed by the service.
 type PlayerRepository interface {
 	player.Repository
@@ -36,18 +51,85 @@ type Service struct {
 	Repo  PlayerRepository
 	Auth  AuthProvider
 	Clock Clock
+	// OnSuspended, when set, runs after a suspension is saved so dependent
+	// state such as the player's running battles can be torn down.
+	OnSuspended func(ctx context.Context, id shared.PlayerID) error
+	// Accounts, when set, receives account changes so Nakama stays in sync
+	// with the local player record.
+	Accounts player.AccountProvider
+	// SearchMode selects prefix or substring matching for SearchPlayers.
+	SearchMode player.SearchMode
+	// Filter, when set, screens display names in UpdateProfile.
+	Filter shared.ContentFilter
+	// Logins, when set, is told the outcome of every login attempt.
+	Logins LoginRecorder
+	// RequireDevice stops players from removing their last registered device.
+	RequireDevice bool
 }
 
 func NewService(repo PlayerRepository, authProvider AuthProvider) *Service {
 	return &Service{
-		Repo:  repo,
-		Auth:  authProvider,
-		Clock: func() time.Time { return time.Now().UTC() },
+		Repo:       repo,
+		Auth:       authProvider,
+		Clock:      func() time.Time { return time.Now().UTC() },
+		SearchMode: player.SearchPrefix,
 	}
 }
 
 func (s *Service) AuthenticateDevice(ctx context.Context, deviceID, username string, vars map[string]string) (AuthResult, error) {
-	result, err := s.Auth.AuthenticateDevice(ctx, deviceID, username, vars)
+	return s.login(ctx, credentials{strategy: StrategyDevice, deviceID: deviceID, username: username, vars: vars})
+}
+
+func (s *Service) AuthenticateEmail(ctx context.Context, email, password string, vars map[string]string) (AuthResult, error) {
+	return s.login(ctx, credentials{strategy: StrategyEmail, email: email, password: password, vars: vars})
+}
+
+// AuthenticateCustom signs a player in with an external identity provider
+// token. The account is created on first login.
+func (s *Service) AuthenticateCustom(ctx context.Context, token string, vars map[string]string) (AuthResult, error) {
+	return s.login(ctx, credentials{strategy: StrategyCustom, token: token, vars: vars})
+}
+
+// credentials is a login attempt; strategy selects which fields are used.
+type credentials struct {
+	strategy string
+	deviceID string
+	username string
+	email    string
+	password string
+	token    string
+	vars     map[string]string
+}
+
+// login signs a player in and records the outcome with Logins.
+func (s *Service) login(ctx context.Context, creds credentials) (AuthResult, error) {
+	result, err := s.authenticate(ctx, creds)
+	s.recordLogin(creds.strategy, err)
+	return result, err
+}
+
+// authenticate checks creds with the AuthProvider and records the session on
+// the player's account, creating the account on first login.
+func (s *Service) authenticate(ctx context.Context, creds credentials) (AuthResult, error) {
+	email, username := creds.vars["email"], ""
+	var result AuthResult
+	var err error
+	switch creds.strategy {
+	case StrategyDevice:
+		result, err = s.Auth.AuthenticateDevice(ctx, creds.deviceID, creds.username, creds.vars)
+		username = creds.username
+	case StrategyEmail:
+		result, err = s.Auth.AuthenticateEmail(ctx, creds.email, creds.password, creds.vars)
+		email, username = creds.email, result.Username
+	case StrategyCustom:
+		if strings.TrimSpace(creds.token) == "" {
+			return AuthResult{}, shared.NewValidationError("token", "custom token is required")
+		}
+		result, err = s.Auth.AuthenticateCustom(ctx, creds.token, creds.vars)
+		username = result.Username
+	default:
+		return AuthResult{}, fmt.Errorf("unknown login strategy %q", creds.strategy)
+	}
 	if err != nil {
 		return AuthResult{}, err
 	}
@@ -57,38 +139,204 @@ func (s *Service) AuthenticateDevice(ctx context.Context, deviceID, username str
 		if !errors.Is(err, shared.ErrNotFound) {
 			return AuthResult{}, err
 		}
-		account, err = player.NewPlayerAccount(result.UserID, vars["email"], username, now)
+		account, err = player.NewPlayerAccount(result.UserID, email, username, now)
 		if err != nil {
 			return AuthResult{}, err
 		}
+	} else if err := account.CheckActive(); err != nil {
+		return AuthResult{}, err
+	}
+	if creds.strategy == StrategyDevice {
+		_ = account.RegisterDevice(player.DeviceFingerprint{ID: creds.deviceID, Platform: creds.vars["platform"], LastSeen: now})
 	}
-	_ = account.RegisterDevice(player.DeviceFingerprint{ID: deviceID, Platform: vars["platform"], LastSeen: now})
 	account.RecordSession(player.SessionMetadata{SessionID: result.SessionToken, IssuedAt: now})
 	if err := s.Repo.Save(ctx, account); err != nil {
 		return AuthResult{}, err
 	}
-	return result, nil
+	return withExpiry(result), nil
 }
 
-func (s *Service) AuthenticateEmail(ctx context.Context, email, password string, vars map[string]string) (AuthResult, error) {
-	result, err := s.Auth.AuthenticateEmail(ctx, email, password, vars)
+// RefreshSession exchanges a refresh token for a fresh session without
+// re-sending credentials. Suspended accounts cannot refresh; the rotated
+// session is recorded on the account.
+func (s *Service) RefreshSession(ctx context.Context, refreshToken string) (AuthResult, error) {
+	if refreshToken == "" {
+		return AuthResult{}, ErrRefreshTokenRequired
+	}
+	result, err := s.Auth.SessionRefresh(ctx, refreshToken)
 	if err != nil {
 		return AuthResult{}, err
 	}
-	now := s.Clock()
 	account, err := s.Repo.GetByID(ctx, result.UserID)
 	if err != nil {
-		if !errors.Is(err, shared.ErrNotFound) {
-			return AuthResult{}, err
-		}
-		account, err = player.NewPlayerAccount(result.UserID, email, result.Username, now)
-		if err != nil {
-			return AuthResult{}, err
+		return AuthResult{}, err
+	}
+	if err := account.CheckActive(); err != nil {
+		return AuthResult{}, err
+	}
+	session := player.SessionMetadata{SessionID: result.SessionToken, IssuedAt: s.Clock()}
+	if err := s.Repo.AppendSession(ctx, result.UserID, session); err != nil {
+		return AuthResult{}, err
+	}
+	return withExpiry(result), nil
+}
+
+// SuspendAccountCommand suspends a player account.
+type SuspendAccountCommand struct {
+	PlayerID shared.PlayerID
+	Reason   string
+}
+
+// SuspendAccount marks the account suspended and notifies OnSuspended. The
+// suspension is kept even if the hook fails; its error is returned so the
+// caller can retry the cleanup.
+func (s *Service) SuspendAccount(ctx context.Context, cmd SuspendAccountCommand) error {
+	if err := cmd.PlayerID.Validate(); err != nil {
+		return err
+	}
+	account, err := s.Repo.GetByID(ctx, cmd.PlayerID)
+	if err != nil {
+		return err
+	}
+	account.Suspend(cmd.Reason)
+	if err := s.Repo.Save(ctx, account); err != nil {
+		return err
+	}
+	syncErr := s.syncAccount(ctx, cmd.PlayerID, player.AccountChanges{
+		Metadata: map[string]any{"suspended": true, "suspension_msg": cmd.Reason},
+	})
+	if s.OnSuspended != nil {
+		return errors.Join(syncErr, s.OnSuspended(ctx, cmd.PlayerID))
+	}
+	return syncErr
+}
+
+// RevokeDevice removes a device from the player's account, for example after
+// the phone was lost. It is RemoveDevice under its original name.
+func (s *Service) RevokeDevice(ctx context.Context, playerID shared.PlayerID, deviceID string) error {
+	return s.RemoveDevice(ctx, playerID, deviceID)
+}
+
+// RemoveDevice deregisters a device from the player's account. With
+// RequireDevice set, removing the only remaining device fails with
+// player.ErrLastDevice.
+func (s *Service) RemoveDevice(ctx context.Context, playerID shared.PlayerID, deviceID string) error {
+	if err := playerID.Validate(); err != nil {
+		return err
+	}
+	account, err := s.Repo.GetByID(ctx, playerID)
+	if err != nil {
+		return err
+	}
+	if err := account.RemoveDevice(deviceID, s.RequireDevice, s.Clock()); err != nil {
+		return err
+	}
+	return s.Repo.Save(ctx, account)
+}
+
+// UpdateProfileCommand changes a player's profile fields. Nil fields are left unchanged.
+type UpdateProfileCommand struct {
+	PlayerID    shared.PlayerID
+	DisplayName *string
+}
+
+// UpdateProfile saves profile changes locally and pushes them to Nakama.
+func (s *Service) UpdateProfile(ctx context.Context, cmd UpdateProfileCommand) error {
+	if err := cmd.PlayerID.Validate(); err != nil {
+		return err
+	}
+	if cmd.DisplayName != nil {
+		if err := shared.CheckContent(s.Filter, "display_name", *cmd.DisplayName); err != nil {
+			return err
 		}
 	}
-	account.RecordSession(player.SessionMetadata{SessionID: result.SessionToken, IssuedAt: now})
+	account, err := s.Repo.GetByID(ctx, cmd.PlayerID)
+	if err != nil {
+		return err
+	}
+	if account.Suspended {
+		return player.ErrAccountSuspended
+	}
+
+	var changes player.AccountChanges
+	if cmd.DisplayName != nil && *cmd.DisplayName != account.DisplayName {
+		account.SetDisplayName(*cmd.DisplayName)
+		changes.DisplayName = cmd.DisplayName
+	}
+	if changes.IsEmpty() {
+		return nil
+	}
 	if err := s.Repo.Save(ctx, account); err != nil {
-		return AuthResult{}, err
+		return err
+	}
+	return s.syncAccount(ctx, cmd.PlayerID, changes)
+}
+
+func (s *Service) syncAccount(ctx context.Context, id shared.PlayerID, changes player.AccountChanges) error {
+	if s.Accounts == nil || changes.IsEmpty() {
+		return nil
+	}
+	if err := s.Accounts.UpdateAccount(ctx, id, changes); err != nil {
+		return fmt.Errorf("syncing account to nakama: %w", err)
+	}
+	return nil
+}
+
+// DefaultSearchLimit and MaxSearchLimit bound the page size of SearchPlayers.
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// SearchPlayersQuery contains parameters for a display name search.
+type SearchPlayersQuery struct {
+	Query string
+	Limit int
+}
+
+// PlayerSummary is the public view of a player returned by searches. It
+// leaves out email, devices, sessions, and moderation details.
+type PlayerSummary struct {
+	ID          shared.PlayerID
+	DisplayName string
+	CreatedAt   time.Time
+}
+
+// SearchPlayers finds players by display name, ignoring case.
+func (s *Service) SearchPlayers(ctx context.Context, query SearchPlayersQuery) ([]PlayerSummary, error) {
+	text := strings.TrimSpace(query.Query)
+	if text == "" {
+		return nil, player.ErrSearchQueryEmpty
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	accounts, err := s.Repo.SearchByDisplayName(ctx, player.SearchQuery{Text: text, Mode: s.SearchMode}, limit)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]PlayerSummary, 0, len(accounts))
+	for _, account := range accounts {
+		summaries = append(summaries, PlayerSummary{
+			ID:          account.ID,
+			DisplayName: account.DisplayName,
+			CreatedAt:   account.CreatedAt,
+		})
+	}
+	return summaries, nil
+}
+
+// withExpiry fills ExpiresAt from the session token when the provider left it unset.
+func withExpiry(result AuthResult) AuthResult {
+	if result.ExpiresAt.IsZero() {
+		if expiresAt, ok := SessionExpiry(result.SessionToken); ok {
+			result.ExpiresAt = expiresAt
+		}
 	}
-	return result, nil
+	return result
 }
@@ -0,0 +1,743 @@
+package auth_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/app/auth"
+	"github.com/heroiclabs/nakama/v3/src/app/battles"
+	domainBattle "github.com/heroiclabs/nakama/v3/src/domain/battle"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraBattle "github.com/heroiclabs/nakama/v3/src/infra/battle"
+	infraPlayer "github.com/heroiclabs/nakama/v3/src/infra/player"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+)
+
+type mockPlayerRepo struct {
+	accounts map[shared.PlayerID]*player.PlayerAccount
+	sessions []player.SessionMetadata
+}
+
+func (m *mockPlayerRepo) GetByID(ctx context.Context, id shared.PlayerID) (*player.PlayerAccount, error) {
+	if account, ok := m.accounts[id]; ok {
+		return account, nil
+	}
+	return nil, shared.ErrNotFound
+}
+
+func (m *mockPlayerRepo) Save(ctx context.Context, account *player.PlayerAccount) error {
+	if m.accounts != nil {
+		m.accounts[account.ID] = account
+	}
+	return nil
+}
+
+func (m *mockPlayerRepo) SearchByDisplayName(ctx context.Context, query player.SearchQuery, limit int) ([]*player.PlayerAccount, error) {
+	return nil, nil
+}
+
+func (m *mockPlayerRepo) AppendSession(ctx context.Context, id shared.PlayerID, session player.SessionMetadata) error {
+	m.sessions = append(m.sessions, session)
+	return nil
+}
+
+type mockAuthProvider struct {
+	result      auth.AuthResult
+	authErr     error
+	refreshErr  error
+	customCalls int
+}
+
+func (m *mockAuthProvider) AuthenticateDevice(ctx context.Context, deviceID, username string, vars map[string]string) (auth.AuthResult, error) {
+	return m.result, m.authErr
+}
+
+func (m *mockAuthProvider) AuthenticateEmail(ctx context.Context, email, password string, vars map[string]string) (auth.AuthResult, error) {
+	return m.result, m.authErr
+}
+
+func (m *mockAuthProvider) AuthenticateCustom(ctx context.Context, token string, vars map[string]string) (auth.AuthResult, error) {
+	m.customCalls++
+	return m.result, m.authErr
+}
+
+func (m *mockAuthProvider) SessionRefresh(ctx context.Context, refreshToken string) (auth.AuthResult, error) {
+	if m.refreshErr != nil {
+		return auth.AuthResult{}, m.refreshErr
+	}
+	return m.result, nil
+}
+
+// sessionToken builds an unsigned JWT carrying the given payload.
+func sessionToken(payload string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(payload)) + ".signature"
+}
+
+func TestSessionExpiry(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "valid token",
+			token:  sessionToken(`{"uid":"player-1","exp":1704110400}`),
+			want:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{name: "missing exp", token: sessionToken(`{"uid":"player-1"}`)},
+		{name: "invalid payload", token: sessionToken(`not json`)},
+		{name: "not a jwt", token: "opaque-token"},
+		{name: "bad encoding", token: "a.!!!.c"},
+		{name: "empty", token: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := auth.SessionExpiry(tt.token)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("SessionExpiry() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestService_AuthenticateDevice_ExpiresAt(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("parsed from token", func(t *testing.T) {
+		provider := &mockAuthProvider{result: auth.AuthResult{
+			UserID:       "player-1",
+			SessionToken: sessionToken(`{"exp":1704110400}`),
+		}}
+		service := auth.NewService(&mockPlayerRepo{}, provider)
+
+		result, err := service.AuthenticateDevice(ctx, "device-1", "player", map[string]string{"email": "player@example.com"})
+		if err != nil {
+			t.Fatalf("AuthenticateDevice() error = %v", err)
+		}
+		if want := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC); !result.ExpiresAt.Equal(want) {
+			t.Errorf("ExpiresAt = %v, want %v", result.ExpiresAt, want)
+		}
+	})
+
+	t.Run("provider value kept", func(t *testing.T) {
+		want := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		provider := &mockAuthProvider{result: auth.AuthResult{
+			UserID:       "player-1",
+			SessionToken: sessionToken(`{"exp":1704110400}`),
+			ExpiresAt:    want,
+		}}
+		service := auth.NewService(&mockPlayerRepo{}, provider)
+
+		result, err := service.AuthenticateEmail(ctx, "player@example.com", "secret", nil)
+		if err != nil {
+			t.Fatalf("AuthenticateEmail() error = %v", err)
+		}
+		if !result.ExpiresAt.Equal(want) {
+			t.Errorf("ExpiresAt = %v, want %v", result.ExpiresAt, want)
+		}
+	})
+
+	t.Run("unparsable token", func(t *testing.T) {
+		provider := &mockAuthProvider{result: auth.AuthResult{UserID: "player-1", SessionToken: "opaque-token"}}
+		service := auth.NewService(&mockPlayerRepo{}, provider)
+
+		result, err := service.AuthenticateDevice(ctx, "device-1", "player", map[string]string{"email": "player@example.com"})
+		if err != nil {
+			t.Fatalf("AuthenticateDevice() error = %v", err)
+		}
+		if !result.ExpiresAt.IsZero() {
+			t.Errorf("ExpiresAt = %v, want zero", result.ExpiresAt)
+		}
+	})
+}
+
+func TestService_Authenticate_Suspended(t *testing.T) {
+	ctx := context.Background()
+	logins := map[string]func(*auth.Service) error{
+		"device": func(s *auth.Service) error {
+			_, err := s.AuthenticateDevice(ctx, "device-1", "player", map[string]string{"email": "player@example.com"})
+			return err
+		},
+		"email": func(s *auth.Service) error {
+			_, err := s.AuthenticateEmail(ctx, "player@example.com", "secret", nil)
+			return err
+		},
+	}
+	for name, login := range logins {
+		t.Run(name, func(t *testing.T) {
+			account, _ := player.NewPlayerAccount("player-1", "player@example.com", "player", testsupport.DefaultTime)
+			account.Suspend("cheating")
+			repo := &mockPlayerRepo{accounts: map[shared.PlayerID]*player.PlayerAccount{"player-1": account}}
+			provider := &mockAuthProvider{result: auth.AuthResult{UserID: "player-1", SessionToken: "session-1"}}
+			service := auth.NewService(repo, provider)
+
+			err := login(service)
+			if !errors.Is(err, player.ErrAccountSuspended) {
+				t.Fatalf("login error = %v, want %v", err, player.ErrAccountSuspended)
+			}
+			var suspended *player.SuspendedError
+			if !errors.As(err, &suspended) || suspended.Message != "cheating" {
+				t.Errorf("login error = %v, want suspension message %q", err, "cheating")
+			}
+			if len(account.Sessions) != 0 || len(account.Devices) != 0 {
+				t.Errorf("expected no session or device recorded, got %+v", account)
+			}
+		})
+	}
+}
+
+type loginRecord struct {
+	strategy string
+	reason   string
+}
+
+type recordingLogins struct {
+	records []loginRecord
+}
+
+func (r *recordingLogins) RecordLogin(strategy string, err error) {
+	reason := ""
+	if err != nil {
+		reason = auth.FailureReason(err)
+	}
+	r.records = append(r.records, loginRecord{strategy: strategy, reason: reason})
+}
+
+func TestService_Authenticate_RecordsOutcome(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		suspended bool
+		authErr   error
+		login     func(*auth.Service) error
+		want      loginRecord
+	}{
+		{
+			name: "device success",
+			login: func(s *auth.Service) error {
+				_, err := s.AuthenticateDevice(ctx, "device-1", "player", nil)
+				return err
+			},
+			want: loginRecord{strategy: auth.StrategyDevice},
+		},
+		{
+			name:    "email bad credentials",
+			authErr: fmt.Errorf("nakama: %w", player.ErrBadCredentials),
+			login: func(s *auth.Service) error {
+				_, err := s.AuthenticateEmail(ctx, "player@example.com", "wrong", nil)
+				return err
+			},
+			want: loginRecord{strategy: auth.StrategyEmail, reason: auth.ReasonInvalidCredentials},
+		},
+		{
+			name:      "email suspended",
+			suspended: true,
+			login: func(s *auth.Service) error {
+				_, err := s.AuthenticateEmail(ctx, "player@example.com", "secret", nil)
+				return err
+			},
+			want: loginRecord{strategy: auth.StrategyEmail, reason: auth.ReasonSuspended},
+		},
+		{
+			name:    "custom banned",
+			authErr: player.ErrAccountBanned,
+			login: func(s *auth.Service) error {
+				_, err := s.AuthenticateCustom(ctx, "token", nil)
+				return err
+			},
+			want: loginRecord{strategy: auth.StrategyCustom, reason: auth.ReasonBanned},
+		},
+		{
+			name: "custom missing token",
+			login: func(s *auth.Service) error {
+				_, err := s.AuthenticateCustom(ctx, " ", nil)
+				return err
+			},
+			want: loginRecord{strategy: auth.StrategyCustom, reason: auth.ReasonInvalidRequest},
+		},
+		{
+			name:    "provider outage",
+			authErr: errors.New("connection refused"),
+			login: func(s *auth.Service) error {
+				_, err := s.AuthenticateDevice(ctx, "device-1", "player", nil)
+				return err
+			},
+			want: loginRecord{strategy: auth.StrategyDevice, reason: auth.ReasonError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account, _ := player.NewPlayerAccount("player-1", "player@example.com", "player", testsupport.DefaultTime)
+			if tt.suspended {
+				account.Suspend("cheating")
+			}
+			repo := &mockPlayerRepo{accounts: map[shared.PlayerID]*player.PlayerAccount{"player-1": account}}
+			provider := &mockAuthProvider{
+				result:  auth.AuthResult{UserID: "player-1", SessionToken: "session-1"},
+				authErr: tt.authErr,
+			}
+			logins := &recordingLogins{}
+			service := auth.NewService(repo, provider)
+			service.Logins = logins
+
+			err := tt.login(service)
+			if (err != nil) != (tt.want.reason != "") {
+				t.Fatalf("login error = %v, want failure %q", err, tt.want.reason)
+			}
+			if want := []loginRecord{tt.want}; !reflect.DeepEqual(logins.records, want) {
+				t.Errorf("recorded = %+v, want %+v", logins.records, want)
+			}
+		})
+	}
+}
+
+func TestService_AuthenticateCustom(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates account", func(t *testing.T) {
+		repo := &mockPlayerRepo{accounts: map[shared.PlayerID]*player.PlayerAccount{}}
+		provider := &mockAuthProvider{result: auth.AuthResult{UserID: "player-1", SessionToken: "session-1", Username: "player"}}
+		service := auth.NewService(repo, provider)
+		service.Clock = testsupport.NewFakeClock().Now
+
+		result, err := service.AuthenticateCustom(ctx, "jwt-token", map[string]string{"email": "player@example.com"})
+		if err != nil {
+			t.Fatalf("AuthenticateCustom() error = %v", err)
+		}
+		if result.SessionToken != "session-1" {
+			t.Errorf("SessionToken = %q, want %q", result.SessionToken, "session-1")
+		}
+		account := repo.accounts["player-1"]
+		if account == nil {
+			t.Fatal("expected account to be created")
+		}
+		if account.Email != "player@example.com" || account.DisplayName != "player" {
+			t.Errorf("unexpected account %+v", account)
+		}
+		if len(account.Sessions) != 1 || account.Sessions[0].SessionID != "session-1" {
+			t.Errorf("Sessions = %+v, want session-1 recorded", account.Sessions)
+		}
+	})
+
+	t.Run("empty token", func(t *testing.T) {
+		provider := &mockAuthProvider{}
+		service := auth.NewService(&mockPlayerRepo{}, provider)
+
+		_, err := service.AuthenticateCustom(ctx, " ", nil)
+		verr, ok := shared.AsValidationError(err)
+		if !ok || verr.Field != "token" {
+			t.Fatalf("AuthenticateCustom() error = %v, want token validation error", err)
+		}
+		if provider.customCalls != 0 {
+			t.Errorf("provider called %d times, want 0", provider.customCalls)
+		}
+	})
+
+	t.Run("suspended account", func(t *testing.T) {
+		account, _ := player.NewPlayerAccount("player-1", "player@example.com", "player", testsupport.DefaultTime)
+		account.Suspend("cheating")
+		repo := &mockPlayerRepo{accounts: map[shared.PlayerID]*player.PlayerAccount{"player-1": account}}
+		provider := &mockAuthProvider{result: auth.AuthResult{UserID: "player-1", SessionToken: "session-1"}}
+		service := auth.NewService(repo, provider)
+
+		if _, err := service.AuthenticateCustom(ctx, "jwt-token", nil); !errors.Is(err, player.ErrAccountSuspended) {
+			t.Fatalf("AuthenticateCustom() error = %v, want %v", err, player.ErrAccountSuspended)
+		}
+		if len(account.Sessions) != 0 {
+			t.Errorf("Sessions = %+v, want none recorded", account.Sessions)
+		}
+	})
+}
+
+type battleRepo struct {
+	saved map[shared.BattleID]*domainBattle.Battle
+}
+
+func (r *battleRepo) Get(ctx context.Context, id shared.BattleID) (*domainBattle.Battle, error) {
+	if b, ok := r.saved[id]; ok {
+		return b, nil
+	}
+	return nil, shared.ErrNotFound
+}
+
+func (r *battleRepo) GetByIdempotencyKey(ctx context.Context, key shared.IdempotencyKey) (*domainBattle.Battle, error) {
+	for _, b := range r.saved {
+		if b.IdempotencyKey == key {
+			return b, nil
+		}
+	}
+	return nil, shared.ErrNotFound
+}
+
+func (r *battleRepo) Save(ctx context.Context, b *domainBattle.Battle) error {
+	r.saved[b.ID] = b
+	return nil
+}
+
+func (r *battleRepo) StoreSnapshot(ctx context.Context, id shared.BattleID, state domainBattle.MatchState) error {
+	return nil
+}
+
+func (r *battleRepo) ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*domainBattle.Battle, error) {
+	return nil, nil
+}
+
+type matchProvider struct {
+	closed []string
+}
+
+func (p *matchProvider) CreateMatch(ctx context.Context, payload battles.StartBattlePayload) (battles.StartBattleResult, error) {
+	id := "battle-" + string(payload.LeaderID)
+	return battles.StartBattleResult{BattleID: shared.BattleID(id), MatchID: "match-" + string(payload.LeaderID)}, nil
+}
+
+func (p *matchProvider) CloseMatch(ctx context.Context, matchID string) error {
+	p.closed = append(p.closed, matchID)
+	return nil
+}
+
+func TestService_RevokeDevice(t *testing.T) {
+	ctx := context.Background()
+	account, _ := player.NewPlayerAccount("player-1", "player@example.com", "player", testsupport.DefaultTime)
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "phone", LastSeen: testsupport.DefaultTime})
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "tablet", LastSeen: testsupport.DefaultTime})
+	repo := infraPlayer.NewMemoryRepository()
+	_ = repo.Save(ctx, account)
+	service := auth.NewService(repo, &mockAuthProvider{})
+	service.Clock = testsupport.NewFakeClock().Now
+
+	if err := service.RevokeDevice(ctx, "player-1", "phone"); err != nil {
+		t.Fatalf("RevokeDevice() error = %v", err)
+	}
+	saved, _ := repo.GetByID(ctx, "player-1")
+	if devices := saved.ListDevices(); len(devices) != 1 || devices[0].ID != "tablet" {
+		t.Errorf("ListDevices() = %+v, want only tablet", devices)
+	}
+
+	if err := service.RevokeDevice(ctx, "player-1", "phone"); !errors.Is(err, player.ErrDeviceInvalid) {
+		t.Errorf("RevokeDevice() error = %v, want %v", err, player.ErrDeviceInvalid)
+	}
+	if err := service.RevokeDevice(ctx, "missing", "phone"); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("RevokeDevice() error = %v, want %v", err, shared.ErrNotFound)
+	}
+}
+
+func TestService_RemoveDevice_RequireDevice(t *testing.T) {
+	ctx := context.Background()
+	account, _ := player.NewPlayerAccount("player-1", "player@example.com", "player", testsupport.DefaultTime)
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "phone", LastSeen: testsupport.DefaultTime})
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "tablet", LastSeen: testsupport.DefaultTime})
+	repo := infraPlayer.NewMemoryRepository()
+	_ = repo.Save(ctx, account)
+	service := auth.NewService(repo, &mockAuthProvider{})
+	service.Clock = testsupport.NewFakeClock().Now
+	service.RequireDevice = true
+
+	if err := service.RemoveDevice(ctx, "player-1", "phone"); err != nil {
+		t.Fatalf("RemoveDevice() error = %v", err)
+	}
+	if err := service.RemoveDevice(ctx, "player-1", "tablet"); !errors.Is(err, player.ErrLastDevice) {
+		t.Errorf("RemoveDevice() error = %v, want %v", err, player.ErrLastDevice)
+	}
+	saved, _ := repo.GetByID(ctx, "player-1")
+	if len(saved.Devices) != 1 {
+		t.Errorf("Devices = %+v, want the last device kept", saved.Devices)
+	}
+}
+
+func TestService_SuspendAccount_CancelsLedBattles(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := &mockPlayerRepo{accounts: map[shared.PlayerID]*player.PlayerAccount{}}
+	for _, id := range []shared.PlayerID{"leader-1", "member-1"} {
+		account, err := player.NewPlayerAccount(id, string(id)+"@example.com", string(id), now)
+		if err != nil {
+			t.Fatalf("NewPlayerAccount() error = %v", err)
+		}
+		repo.accounts[id] = account
+	}
+
+	battleStore := &battleRepo{saved: map[shared.BattleID]*domainBattle.Battle{}}
+	provider := &matchProvider{}
+	battleService := battles.NewService(battleStore, provider)
+	battleService.Active = infraBattle.NewMemoryActiveIndex()
+	if _, err := battleService.StartBattle(ctx, battles.StartCommand{LeaderID: "leader-1", IdempotencyKey: "start-1"}); err != nil {
+		t.Fatalf("StartBattle() error = %v", err)
+	}
+	if err := battleStore.saved["battle-leader-1"].AddPlayer("member-1", now); err != nil {
+		t.Fatalf("AddPlayer() error = %v", err)
+	}
+
+	service := auth.NewService(repo, &mockAuthProvider{})
+	service.OnSuspended = func(ctx context.Context, id shared.PlayerID) error {
+		_, err := battleService.CancelLedBattles(ctx, id)
+		return err
+	}
+
+	// A participant's suspension leaves the battle running.
+	if err := service.SuspendAccount(ctx, auth.SuspendAccountCommand{PlayerID: "member-1", Reason: "spam"}); err != nil {
+		t.Fatalf("SuspendAccount(member) error = %v", err)
+	}
+	if len(provider.closed) != 0 {
+		t.Errorf("Expected no matches closed for a non-leader, got %v", provider.closed)
+	}
+	if !repo.accounts["member-1"].Suspended {
+		t.Error("Expected member account to be suspended")
+	}
+
+	if err := service.SuspendAccount(ctx, auth.SuspendAccountCommand{PlayerID: "leader-1", Reason: "cheating"}); err != nil {
+		t.Fatalf("SuspendAccount(leader) error = %v", err)
+	}
+	if len(provider.closed) != 1 || provider.closed[0] != "match-leader-1" {
+		t.Errorf("Expected the leader's match to be closed, got %v", provider.closed)
+	}
+	if got := battleStore.saved["battle-leader-1"].Status; got != domainBattle.StatusCancelled {
+		t.Errorf("Battle status = %q, want %q", got, domainBattle.StatusCancelled)
+	}
+}
+
+func TestService_SuspendAccount_HookError(t *testing.T) {
+	ctx := context.Background()
+	account, _ := player.NewPlayerAccount("player-1", "player@example.com", "player", time.Now())
+	repo := &mockPlayerRepo{accounts: map[shared.PlayerID]*player.PlayerAccount{"player-1": account}}
+	hookErr := errors.New("match close failed")
+
+	service := auth.NewService(repo, &mockAuthProvider{})
+	service.OnSuspended = func(ctx context.Context, id shared.PlayerID) error { return hookErr }
+
+	if err := service.SuspendAccount(ctx, auth.SuspendAccountCommand{PlayerID: "player-1"}); !errors.Is(err, hookErr) {
+		t.Errorf("SuspendAccount() error = %v, want %v", err, hookErr)
+	}
+	if !repo.accounts["player-1"].Suspended {
+		t.Error("Expected suspension to be kept when the hook fails")
+	}
+	if err := service.SuspendAccount(ctx, auth.SuspendAccountCommand{PlayerID: "missing"}); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("SuspendAccount() error = %v, want %v", err, shared.ErrNotFound)
+	}
+}
+
+type mockAccountProvider struct {
+	updates []player.AccountChanges
+	err     error
+}
+
+func (m *mockAccountProvider) UpdateAccount(ctx context.Context, id shared.PlayerID, changes player.AccountChanges) error {
+	m.updates = append(m.updates, changes)
+	return m.err
+}
+
+func newAccountRepo(t *testing.T, id shared.PlayerID) *mockPlayerRepo {
+	t.Helper()
+	account, err := player.NewPlayerAccount(id, "player@example.com", "Old Name", time.Now())
+	if err != nil {
+		t.Fatalf("NewPlayerAccount() error = %v", err)
+	}
+	return &mockPlayerRepo{accounts: map[shared.PlayerID]*player.PlayerAccount{id: account}}
+}
+
+func TestService_UpdateProfile_SyncsAccount(t *testing.T) {
+	ctx := context.Background()
+	repo := newAccountRepo(t, "player-1")
+	accounts := &mockAccountProvider{}
+	service := auth.NewService(repo, &mockAuthProvider{})
+	service.Accounts = accounts
+
+	name := "New Name"
+	if err := service.UpdateProfile(ctx, auth.UpdateProfileCommand{PlayerID: "player-1", DisplayName: &name}); err != nil {
+		t.Fatalf("UpdateProfile() error = %v", err)
+	}
+	if repo.accounts["player-1"].DisplayName != name {
+		t.Errorf("DisplayName = %q, want %q", repo.accounts["player-1"].DisplayName, name)
+	}
+	if len(accounts.updates) != 1 {
+		t.Fatalf("Expected one account update, got %d", len(accounts.updates))
+	}
+	if got := accounts.updates[0]; got.DisplayName == nil || *got.DisplayName != name || got.Metadata != nil {
+		t.Errorf("Unexpected account changes %+v", got)
+	}
+
+	// Unchanged fields produce no provider call.
+	if err := service.UpdateProfile(ctx, auth.UpdateProfileCommand{PlayerID: "player-1", DisplayName: &name}); err != nil {
+		t.Fatalf("UpdateProfile() repeat error = %v", err)
+	}
+	if len(accounts.updates) != 1 {
+		t.Errorf("Expected no update for an unchanged profile, got %d", len(accounts.updates))
+	}
+}
+
+func TestService_UpdateProfile_ContentFilter(t *testing.T) {
+	ctx := context.Background()
+	repo := newAccountRepo(t, "player-1")
+	accounts := &mockAccountProvider{}
+	service := auth.NewService(repo, &mockAuthProvider{})
+	service.Accounts = accounts
+	service.Filter = shared.NewWordListFilter([]string{"darn"})
+	original := repo.accounts["player-1"].DisplayName
+
+	blocked := "Darn Player"
+	err := service.UpdateProfile(ctx, auth.UpdateProfileCommand{PlayerID: "player-1", DisplayName: &blocked})
+	var rejected *shared.ContentRejectedError
+	if !errors.As(err, &rejected) || rejected.Field != "display_name" {
+		t.Fatalf("UpdateProfile() error = %v, want display_name rejection", err)
+	}
+	if repo.accounts["player-1"].DisplayName != original || len(accounts.updates) != 0 {
+		t.Errorf("Expected blocked name to leave the profile untouched")
+	}
+
+	allowed := "Friendly Player"
+	if err := service.UpdateProfile(ctx, auth.UpdateProfileCommand{PlayerID: "player-1", DisplayName: &allowed}); err != nil {
+		t.Fatalf("UpdateProfile() error = %v", err)
+	}
+	if repo.accounts["player-1"].DisplayName != allowed {
+		t.Errorf("DisplayName = %q, want %q", repo.accounts["player-1"].DisplayName, allowed)
+	}
+}
+
+func TestService_SuspendAccount_SyncsAccount(t *testing.T) {
+	ctx := context.Background()
+	repo := newAccountRepo(t, "player-1")
+	accounts := &mockAccountProvider{}
+	service := auth.NewService(repo, &mockAuthProvider{})
+	service.Accounts = accounts
+
+	if err := service.SuspendAccount(ctx, auth.SuspendAccountCommand{PlayerID: "player-1", Reason: "cheating"}); err != nil {
+		t.Fatalf("SuspendAccount() error = %v", err)
+	}
+	if len(accounts.updates) != 1 {
+		t.Fatalf("Expected one account update, got %d", len(accounts.updates))
+	}
+	got := accounts.updates[0]
+	if got.DisplayName != nil || got.Metadata["suspended"] != true || got.Metadata["suspension_msg"] != "cheating" {
+		t.Errorf("Unexpected account changes %+v", got)
+	}
+}
+
+func TestService_UpdateProfile_ProviderError(t *testing.T) {
+	ctx := context.Background()
+	repo := newAccountRepo(t, "player-1")
+	providerErr := errors.New("nakama unavailable")
+	service := auth.NewService(repo, &mockAuthProvider{})
+	service.Accounts = &mockAccountProvider{err: providerErr}
+
+	name := "New Name"
+	if err := service.UpdateProfile(ctx, auth.UpdateProfileCommand{PlayerID: "player-1", DisplayName: &name}); !errors.Is(err, providerErr) {
+		t.Errorf("UpdateProfile() error = %v, want %v", err, providerErr)
+	}
+
+	service.Accounts = nil
+	other := "Other Name"
+	if err := service.UpdateProfile(ctx, auth.UpdateProfileCommand{PlayerID: "player-1", DisplayName: &other}); err != nil {
+		t.Errorf("UpdateProfile() without provider error = %v", err)
+	}
+}
+
+func TestService_SearchPlayers(t *testing.T) {
+	ctx := context.Background()
+	repo := infraPlayer.NewMemoryRepository()
+	for id, name := range map[shared.PlayerID]string{"player-1": "Alice", "player-2": "alicia", "player-3": "Bob Alison", "player-4": "Carol"} {
+		account, err := player.NewPlayerAccount(id, string(id)+"@example.com", name, time.Now())
+		if err != nil {
+			t.Fatalf("NewPlayerAccount() error = %v", err)
+		}
+		_ = repo.Save(ctx, account)
+	}
+
+	tests := []struct {
+		name    string
+		mode    player.SearchMode
+		query   auth.SearchPlayersQuery
+		want    []string
+		wantErr error
+	}{
+		{name: "prefix ignores case", mode: player.SearchPrefix, query: auth.SearchPlayersQuery{Query: "ALI"}, want: []string{"Alice", "alicia"}},
+		{name: "substring", mode: player.SearchSubstring, query: auth.SearchPlayersQuery{Query: "ali"}, want: []string{"Alice", "Bob Alison", "alicia"}},
+		{name: "limit", mode: player.SearchSubstring, query: auth.SearchPlayersQuery{Query: "ali", Limit: 1}, want: []string{"Alice"}},
+		{name: "no matches", mode: player.SearchPrefix, query: auth.SearchPlayersQuery{Query: "zed"}, want: []string{}},
+		{name: "empty query", mode: player.SearchPrefix, query: auth.SearchPlayersQuery{Query: "  "}, wantErr: player.ErrSearchQueryEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := auth.NewService(repo, &mockAuthProvider{})
+			service.SearchMode = tt.mode
+
+			got, err := service.SearchPlayers(ctx, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SearchPlayers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			names := make([]string, 0, len(got))
+			for _, summary := range got {
+				names = append(names, summary.DisplayName)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("SearchPlayers() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_RefreshSession(t *testing.T) {
+	ctx := context.Background()
+	invalidToken := errors.New("invalid refresh token")
+
+	tests := []struct {
+		name       string
+		token      string
+		refreshErr error
+		suspended  bool
+		wantErr    error
+	}{
+		{name: "rotates session", token: "refresh-1"},
+		{name: "missing token", token: "", wantErr: auth.ErrRefreshTokenRequired},
+		{name: "invalid token", token: "bogus", refreshErr: invalidToken, wantErr: invalidToken},
+		{name: "suspended account", token: "refresh-1", suspended: true, wantErr: player.ErrAccountSuspended},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newAccountRepo(t, "player-1")
+			if tt.suspended {
+				repo.accounts["player-1"].Suspend("cheating")
+			}
+			provider := &mockAuthProvider{
+				result: auth.AuthResult{
+					UserID:       "player-1",
+					SessionToken: sessionToken(`{"exp":1704110400}`),
+					RefreshToken: "refresh-2",
+				},
+				refreshErr: tt.refreshErr,
+			}
+			service := auth.NewService(repo, provider)
+			service.Clock = testsupport.NewFakeClock().Now
+
+			result, err := service.RefreshSession(ctx, tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RefreshSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(repo.sessions) != 0 {
+					t.Errorf("Expected no session to be recorded, got %d", len(repo.sessions))
+				}
+				return
+			}
+			if result.RefreshToken != "refresh-2" || !result.ExpiresAt.Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) {
+				t.Errorf("Unexpected result %+v", result)
+			}
+			if len(repo.sessions) != 1 || repo.sessions[0].SessionID != result.SessionToken || !repo.sessions[0].IssuedAt.Equal(testsupport.DefaultTime) {
+				t.Errorf("Expected rotated session to be appended, got %+v", repo.sessions)
+			}
+		})
+	}
+}
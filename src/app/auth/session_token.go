@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// SessionExpiry reads the exp claim from a Nakama session token. Nakama issues
+// sessions as JWTs, so the expiry can be recovered from the payload without
+// verifying the signature. It reports false when the token cannot be parsed
+// or carries no expiry.
+func SessionExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		ExpiresAt int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ExpiresAt <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.ExpiresAt, 0).UTC(), true
+}
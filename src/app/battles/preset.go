@@ -0,0 +1,78 @@
+package battles
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownPreset is returned when a battle is started with a preset that
+// has not been registered.
+var ErrUnknownPreset = errors.New("unknown battle preset")
+
+// Preset is a named battle configuration a start command can select.
+type Preset struct {
+	Name string
+	// MaxSlots caps the battle's players. Zero means unlimited.
+	MaxSlots int
+	// Authoritative asks Nakama for a server-authoritative match.
+	Authoritative bool
+	// Metadata is merged under the start command's metadata, which wins on
+	// conflicting keys.
+	Metadata map[string]any
+}
+
+var (
+	presetsMu sync.RWMutex
+	presets   = map[string]Preset{}
+)
+
+func init() {
+	RegisterPreset(Preset{Name: "1v1", MaxSlots: 2, Authoritative: true, Metadata: map[string]any{"mode": "duel"}})
+	RegisterPreset(Preset{Name: "2v2", MaxSlots: 4, Authoritative: true, Metadata: map[string]any{"mode": "team", "team_size": 2}})
+	RegisterPreset(Preset{Name: "ffa", MaxSlots: 8, Authoritative: true, Metadata: map[string]any{"mode": "free_for_all"}})
+}
+
+// RegisterPreset makes a preset available to StartBattle. Game modules call it
+// from init. Like database/sql.Register, it panics if the name is empty or
+// already registered, or if MaxSlots is negative.
+func RegisterPreset(p Preset) {
+	if p.Name == "" {
+		panic("battles: RegisterPreset with empty name")
+	}
+	if p.MaxSlots < 0 {
+		panic(fmt.Sprintf("battles: RegisterPreset %q with negative MaxSlots", p.Name))
+	}
+	presetsMu.Lock()
+	defer presetsMu.Unlock()
+	if _, ok := presets[p.Name]; ok {
+		panic(fmt.Sprintf("battles: RegisterPreset called twice for %q", p.Name))
+	}
+	presets[p.Name] = p
+}
+
+// LookupPreset returns the preset registered under name, or ErrUnknownPreset.
+func LookupPreset(name string) (Preset, error) {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+	p, ok := presets[name]
+	if !ok {
+		return Preset{}, fmt.Errorf("%w: %q", ErrUnknownPreset, name)
+	}
+	return p, nil
+}
+
+// mergeMetadata returns the preset's metadata overlaid with the request's.
+func (p Preset) mergeMetadata(request map[string]any) map[string]any {
+	if len(p.Metadata) == 0 {
+		return request
+	}
+	merged := make(map[string]any, len(p.Metadata)+len(request))
+	for k, v := range p.Metadata {
+		merged[k] = v
+	}
+	for k, v := range request {
+		merged[k] = v
+	}
+	return merged
+}
@@ -2,15 +2,29 @@ package battles
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
+	"go.uber.org/zap"
+
 	"github.com/heroiclabs/nakama/v3/src/domain/battle"
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
 )
 
+// ErrBattleNotPersisted is returned when a match was created but the battle record could not be stored.
+var ErrBattleNotPersisted = errors.New("battle could not be persisted")
+
 // MatchProvider abstracts Nakama matchmaker or authoritative match calls.
 type MatchProvider interface {
 	CreateMatch(ctx context.Context, payload StartBattlePayload) (StartBattleResult, error)
+	CloseMatch(ctx context.Context, matchID string) error
+}
+
+// OrphanRecorder keeps track of matches that are live in Nakama without a local battle record.
+type OrphanRecorder interface {
+	RecordOrphan(ctx context.Context, battleID shared.BattleID, matchID string, cause error) error
 }
 
 type Repository interface {
@@ -22,6 +36,9 @@ type StartBattlePayload struct {
 	LeaderID shared.PlayerID
 	Metadata map[string]any
 	Preset   string
+	MaxSlots int
+	// Authoritative asks for a server-authoritative match.
+	Authoritative bool
 }
 
 // StartBattleResult contains the match ID returned by Nakama.
@@ -30,18 +47,32 @@ type StartBattleResult struct {
 	MatchID  string
 }
 
+// idempotencyScope namespaces battle start keys in a shared IdempotencyStore.
+const idempotencyScope = "battle"
+
 // Service coordinates battle creation.
 type Service struct {
 	Repo     Repository
 	Provider MatchProvider
-	Clock    func() time.Time
+	Orphans  OrphanRecorder
+	// Active, when set, indexes running battles so they can be cancelled by leader.
+	Active battle.ActiveIndex
+	Clock  func() time.Time
+	// Idempotency, when set, replays the original result for a repeated
+	// idempotency key instead of creating a second match.
+	Idempotency    shared.IdempotencyStore
+	IdempotencyTTL time.Duration
+	// Logger, when set, records start decisions and failures tagged with the
+	// request's correlation id.
+	Logger *zap.Logger
 }
 
 func NewService(repo Repository, provider MatchProvider) *Service {
 	return &Service{
-		Repo:     repo,
-		Provider: provider,
-		Clock:    func() time.Time { return time.Now().UTC() },
+		Repo:           repo,
+		Provider:       provider,
+		Clock:          func() time.Time { return time.Now().UTC() },
+		IdempotencyTTL: shared.DefaultIdempotencyTTL,
 	}
 }
 
@@ -49,7 +80,11 @@ type StartCommand struct {
 	LeaderID       shared.PlayerID
 	IdempotencyKey shared.IdempotencyKey
 	Metadata       map[string]any
-	Preset         string
+	// Preset names a registered Preset. Empty starts a battle with
+	// battle.DefaultMaxSlots and no preset metadata.
+	Preset string
+	// MaxSlots caps the battle's players. Zero takes the preset's capacity.
+	MaxSlots int
 }
 
 type StartResult struct {
@@ -57,6 +92,9 @@ type StartResult struct {
 	MatchID  string
 }
 
+// StartBattle creates a Nakama match and its battle record. A repeated
+// idempotency key returns the battle it first started instead of creating a
+// second match, whether the key is found in Idempotency or in the repository.
 func (s *Service) StartBattle(ctx context.Context, cmd StartCommand) (StartResult, error) {
 	if err := cmd.LeaderID.Validate(); err != nil {
 		return StartResult{}, err
@@ -64,22 +102,386 @@ func (s *Service) StartBattle(ctx context.Context, cmd StartCommand) (StartResul
 	if err := cmd.IdempotencyKey.Validate(); err != nil {
 		return StartResult{}, err
 	}
+	preset := Preset{MaxSlots: battle.DefaultMaxSlots}
+	if cmd.Preset != "" {
+		var err error
+		if preset, err = LookupPreset(cmd.Preset); err != nil {
+			return StartResult{}, err
+		}
+	}
+	if s.Idempotency == nil {
+		return s.startBattle(ctx, cmd, preset)
+	}
+
+	record, err := s.Idempotency.Reserve(ctx, idempotencyScope, cmd.IdempotencyKey, s.IdempotencyTTL)
+	if errors.Is(err, shared.ErrDuplicate) && record.Completed() {
+		var previous StartResult
+		if err := json.Unmarshal(record.Result, &previous); err != nil {
+			return StartResult{}, err
+		}
+		s.log(ctx).Info("battle start replayed",
+			zap.String("idempotency_key", string(cmd.IdempotencyKey)),
+			zap.String("battle_id", string(previous.BattleID)))
+		return previous, nil
+	}
+	if err != nil {
+		return StartResult{}, err
+	}
+
+	result, err := s.startBattle(ctx, cmd, preset)
+	if err != nil {
+		_ = s.Idempotency.Release(ctx, idempotencyScope, cmd.IdempotencyKey)
+		return StartResult{}, err
+	}
+	// The battle has started, so failing to record it is only logged: a retry
+	// finds the battle by its idempotency key in the repository instead.
+	encoded, err := json.Marshal(result)
+	if err == nil {
+		err = s.Idempotency.Complete(ctx, idempotencyScope, cmd.IdempotencyKey, encoded)
+	}
+	if err != nil {
+		s.log(ctx).Warn("battle start not recorded for replay",
+			zap.String("idempotency_key", string(cmd.IdempotencyKey)),
+			zap.String("battle_id", string(result.BattleID)),
+			zap.Error(err))
+	}
+	return result, nil
+}
+
+func (s *Service) startBattle(ctx context.Context, cmd StartCommand, preset Preset) (StartResult, error) {
+	existing, err := s.Repo.GetByIdempotencyKey(ctx, cmd.IdempotencyKey)
+	if err == nil {
+		if existing.Leader != cmd.LeaderID {
+			return StartResult{}, fmt.Errorf("%w: idempotency key used by another leader", shared.ErrDuplicate)
+		}
+		s.log(ctx).Info("battle start deduplicated",
+			zap.String("idempotency_key", string(cmd.IdempotencyKey)),
+			zap.String("battle_id", string(existing.ID)))
+		return StartResult{BattleID: existing.ID, MatchID: existing.MatchID}, nil
+	}
+	if !errors.Is(err, shared.ErrNotFound) {
+		return StartResult{}, err
+	}
+
+	maxSlots := cmd.MaxSlots
+	if maxSlots <= 0 {
+		maxSlots = preset.MaxSlots
+	}
 	payload := StartBattlePayload{
-		LeaderID: cmd.LeaderID,
-		Metadata: cmd.Metadata,
-		Preset:   cmd.Preset,
+		LeaderID:      cmd.LeaderID,
+		Metadata:      preset.mergeMetadata(cmd.Metadata),
+		Preset:        cmd.Preset,
+		MaxSlots:      maxSlots,
+		Authoritative: preset.Authoritative,
 	}
 	result, err := s.Provider.CreateMatch(ctx, payload)
 	if err != nil {
+		s.log(ctx).Error("match create failed", zap.String("leader_id", string(cmd.LeaderID)), zap.Error(err))
 		return StartResult{}, err
 	}
 	now := s.Clock()
 	aggregate, err := battle.NewBattle(result.BattleID, cmd.LeaderID, cmd.IdempotencyKey, now)
 	if err != nil {
-		return StartResult{}, err
+		return StartResult{}, s.compensate(ctx, result, err)
 	}
+	aggregate.MatchID = result.MatchID
+	aggregate.MaxSlots = maxSlots
 	if err := s.Repo.Save(ctx, aggregate); err != nil {
-		return StartResult{}, err
+		return StartResult{}, s.compensate(ctx, result, err)
+	}
+	if s.Active != nil {
+		_ = s.Active.Add(ctx, aggregate)
 	}
+	s.log(ctx).Info("battle started",
+		zap.String("battle_id", string(result.BattleID)),
+		zap.String("match_id", result.MatchID),
+		zap.String("leader_id", string(cmd.LeaderID)))
 	return StartResult{BattleID: result.BattleID, MatchID: result.MatchID}, nil
 }
+
+// compensate tears down a match whose battle could not be stored. If the match
+// cannot be closed it is handed to the orphan recorder for later reconciliation.
+func (s *Service) compensate(ctx context.Context, result StartBattleResult, cause error) error {
+	logger := s.log(ctx).With(zap.String("battle_id", string(result.BattleID)), zap.String("match_id", result.MatchID))
+	closeErr := s.Provider.CloseMatch(ctx, result.MatchID)
+	if closeErr == nil {
+		logger.Warn("battle not persisted, match closed", zap.Error(cause))
+		return fmt.Errorf("%w: %v", ErrBattleNotPersisted, cause)
+	}
+	logger.Error("battle not persisted, match left running", zap.Error(cause), zap.NamedError("close_error", closeErr))
+	if s.Orphans != nil {
+		_ = s.Orphans.RecordOrphan(ctx, result.BattleID, result.MatchID, cause)
+	}
+	return fmt.Errorf("%w: %v (match %s left running: %v)", ErrBattleNotPersisted, cause, result.MatchID, closeErr)
+}
+
+// log returns Logger tagged with the request's correlation id, or a no-op
+// logger when Logger is nil.
+func (s *Service) log(ctx context.Context) *zap.Logger {
+	if s.Logger == nil {
+		return zap.NewNop()
+	}
+	if id := shared.CorrelationID(ctx); id != "" {
+		return s.Logger.With(zap.String("request_id", id))
+	}
+	return s.Logger
+}
+
+// CancelLedBattles cancels every running battle led by the given player,
+// closing its Nakama match. Battles the player merely takes part in are left
+// alone. Each battle is reloaded from the repository first, so battles that
+// have already finished or been cancelled are dropped from the index without
+// touching their match. It returns the number of battles cancelled; failures
+// on individual battles are joined into the returned error.
+func (s *Service) CancelLedBattles(ctx context.Context, leader shared.PlayerID) (int, error) {
+	if err := leader.Validate(); err != nil {
+		return 0, err
+	}
+	if s.Active == nil {
+		return 0, nil
+	}
+	led, err := s.Active.ListByLeader(ctx, leader)
+	if err != nil {
+		return 0, err
+	}
+
+	var cancelled int
+	var errs []error
+	for _, id := range led {
+		ok, err := s.cancel(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("battle %s: %w", id, err))
+			continue
+		}
+		if ok {
+			cancelled++
+		}
+	}
+	return cancelled, errors.Join(errs...)
+}
+
+// cancel closes the match of an indexed battle and marks it cancelled. It
+// reports false, after removing the battle from the index, when the battle is
+// gone or no longer active.
+func (s *Service) cancel(ctx context.Context, id shared.BattleID) (bool, error) {
+	b, err := s.Repo.Get(ctx, id)
+	if errors.Is(err, shared.ErrNotFound) {
+		return false, s.Active.Remove(ctx, id)
+	}
+	if err != nil {
+		return false, err
+	}
+	if b.Status != battle.StatusActive {
+		return false, s.Active.Remove(ctx, id)
+	}
+	if b.MatchID != "" {
+		if err := s.Provider.CloseMatch(ctx, b.MatchID); err != nil {
+			return false, err
+		}
+	}
+	if _, err := s.update(ctx, id, func(b *battle.Battle) error { return b.Cancel(s.Clock()) }); err != nil {
+		return false, err
+	}
+	return true, s.Active.Remove(ctx, id)
+}
+
+// FinishBattle records that a battle's match has ended and drops it from the
+// active index. Finishing a battle that is already over changes nothing.
+func (s *Service) FinishBattle(ctx context.Context, battleID shared.BattleID) error {
+	if err := battleID.Validate(); err != nil {
+		return err
+	}
+	_, err := s.update(ctx, battleID, func(b *battle.Battle) error {
+		if b.Status != battle.StatusActive {
+			return errUnchanged
+		}
+		return b.Finish(s.Clock())
+	})
+	if err != nil {
+		return err
+	}
+	if s.Active != nil {
+		return s.Active.Remove(ctx, battleID)
+	}
+	return nil
+}
+
+// maxUpdateAttempts bounds how often update reloads a battle that changed
+// between its read and its save.
+const maxUpdateAttempts = 5
+
+// errUnchanged lets an update's mutate function skip the save.
+var errUnchanged = errors.New("battle unchanged")
+
+// update loads a battle, applies mutate and saves it, reloading and applying
+// mutate again when the save loses a race with another writer. It gives up
+// with battle.ErrConcurrentModification after maxUpdateAttempts.
+func (s *Service) update(ctx context.Context, id shared.BattleID, mutate func(*battle.Battle) error) (*battle.Battle, error) {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		b, err := s.Repo.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := mutate(b); err != nil {
+			if errors.Is(err, errUnchanged) {
+				return b, nil
+			}
+			return nil, err
+		}
+		err = s.Repo.Save(ctx, b)
+		if err == nil {
+			return b, nil
+		}
+		if !errors.Is(err, battle.ErrConcurrentModification) {
+			return nil, err
+		}
+	}
+	return nil, battle.ErrConcurrentModification
+}
+
+// UpdateSnapshot records the authoritative match state of a battle so the
+// match can be resumed or replayed. State without an UpdatedAt is stamped
+// with Clock. Unknown battles fail with the repository's not-found error.
+func (s *Service) UpdateSnapshot(ctx context.Context, battleID shared.BattleID, state battle.MatchState) error {
+	if err := battleID.Validate(); err != nil {
+		return err
+	}
+	b, err := s.Repo.Get(ctx, battleID)
+	if err != nil {
+		return err
+	}
+	if state.UpdatedAt.IsZero() {
+		state.UpdatedAt = s.Clock()
+	}
+	b.UpdateSnapshot(state)
+	return s.Repo.StoreSnapshot(ctx, battleID, b.StateSnapshot)
+}
+
+// ListPlayerBattlesQuery contains parameters for listing a player's battles.
+type ListPlayerBattlesQuery struct {
+	PlayerID shared.PlayerID
+	Limit    int
+	Offset   int
+}
+
+// ListPlayerBattles returns the battles a player has held a slot in, newest
+// first, including ones they did not lead.
+func (s *Service) ListPlayerBattles(ctx context.Context, query ListPlayerBattlesQuery) ([]*battle.Battle, error) {
+	if err := query.PlayerID.Validate(); err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+	return s.Repo.ListByPlayer(ctx, query.PlayerID, query.Limit, query.Offset)
+}
+
+// JoinCommand adds a player to an existing battle.
+type JoinCommand struct {
+	BattleID shared.BattleID
+	PlayerID shared.PlayerID
+}
+
+// JoinBattle gives a player a slot in an existing battle and returns the
+// battle's slots after the join. It fails with battle.ErrPlayerAlreadyJoined
+// when the player already holds a slot and battle.ErrBattleFull when every
+// slot is taken.
+func (s *Service) JoinBattle(ctx context.Context, cmd JoinCommand) ([]battle.PlayerSlot, error) {
+	if err := cmd.BattleID.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cmd.PlayerID.Validate(); err != nil {
+		return nil, err
+	}
+	b, err := s.update(ctx, cmd.BattleID, func(b *battle.Battle) error {
+		return b.AddPlayer(cmd.PlayerID, s.Clock())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]battle.PlayerSlot(nil), b.Slots...), nil
+}
+
+// Lobby is the pre-match view of a battle: who holds a slot and whether they are ready.
+type Lobby struct {
+	BattleID shared.BattleID
+	MatchID  string
+	Leader   shared.PlayerID
+	Status   battle.Status
+	Slots    []battle.PlayerSlot
+	AllReady bool
+}
+
+// GetLobby returns the slots and ready flags of a battle so clients can poll
+// readiness before the match loop starts.
+func (s *Service) GetLobby(ctx context.Context, battleID shared.BattleID) (Lobby, error) {
+	if err := battleID.Validate(); err != nil {
+		return Lobby{}, err
+	}
+	b, err := s.Repo.Get(ctx, battleID)
+	if err != nil {
+		return Lobby{}, err
+	}
+	return newLobby(b), nil
+}
+
+func newLobby(b *battle.Battle) Lobby {
+	return Lobby{
+		BattleID: b.ID,
+		MatchID:  b.MatchID,
+		Leader:   b.Leader,
+		Status:   b.Status,
+		Slots:    append([]battle.PlayerSlot(nil), b.Slots...),
+		AllReady: b.AllReady(),
+	}
+}
+
+// SetReady sets a player's ready flag and returns the updated lobby. It
+// fails with battle.ErrPlayerNotFound when the player holds no slot.
+func (s *Service) SetReady(ctx context.Context, battleID shared.BattleID, playerID shared.PlayerID, ready bool) (Lobby, error) {
+	if err := battleID.Validate(); err != nil {
+		return Lobby{}, err
+	}
+	if err := playerID.Validate(); err != nil {
+		return Lobby{}, err
+	}
+	b, err := s.update(ctx, battleID, func(b *battle.Battle) error {
+		return b.MarkReady(playerID, ready, s.Clock())
+	})
+	if err != nil {
+		return Lobby{}, err
+	}
+	return newLobby(b), nil
+}
+
+// CanStartCommand asks whether a battle may begin. Force lets the leader
+// start without waiting for the other players to ready up.
+type CanStartCommand struct {
+	BattleID shared.BattleID
+	PlayerID shared.PlayerID
+	Force    bool
+}
+
+// CanStart reports whether the battle may begin: it needs at least two slots
+// with every non-leader slot ready, unless the leader forces the start.
+// Forcing by anyone else fails with battle.ErrNotLeader. Cancelled battles
+// can never start.
+func (s *Service) CanStart(ctx context.Context, cmd CanStartCommand) (bool, error) {
+	if err := cmd.BattleID.Validate(); err != nil {
+		return false, err
+	}
+	b, err := s.Repo.Get(ctx, cmd.BattleID)
+	if err != nil {
+		return false, err
+	}
+	if cmd.Force {
+		if cmd.PlayerID != b.Leader {
+			return false, battle.ErrNotLeader
+		}
+		return b.Status == battle.StatusActive, nil
+	}
+	return b.ReadyToStart(), nil
+}
@@ -0,0 +1,925 @@
+package battles_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/app/battles"
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraBattle "github.com/heroiclabs/nakama/v3/src/infra/battle"
+	"github.com/heroiclabs/nakama/v3/src/infra/idempotency"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Mock implementations
+type mockBattleRepo struct {
+	getFunc           func(ctx context.Context, id shared.BattleID) (*battle.Battle, error)
+	getByKeyFunc      func(ctx context.Context, key shared.IdempotencyKey) (*battle.Battle, error)
+	saveFunc          func(ctx context.Context, b *battle.Battle) error
+	storeSnapshotFunc func(ctx context.Context, id shared.BattleID, state battle.MatchState) error
+	listByPlayerFunc  func(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*battle.Battle, error)
+}
+
+func (m *mockBattleRepo) Get(ctx context.Context, id shared.BattleID) (*battle.Battle, error) {
+	if m.getFunc != nil {
+		return m.getFunc(ctx, id)
+	}
+	return nil, shared.ErrNotFound
+}
+
+func (m *mockBattleRepo) GetByIdempotencyKey(ctx context.Context, key shared.IdempotencyKey) (*battle.Battle, error) {
+	if m.getByKeyFunc != nil {
+		return m.getByKeyFunc(ctx, key)
+	}
+	return nil, shared.ErrNotFound
+}
+
+func (m *mockBattleRepo) Save(ctx context.Context, b *battle.Battle) error {
+	if m.saveFunc != nil {
+		return m.saveFunc(ctx, b)
+	}
+	return nil
+}
+
+func (m *mockBattleRepo) StoreSnapshot(ctx context.Context, id shared.BattleID, state battle.MatchState) error {
+	if m.storeSnapshotFunc != nil {
+		return m.storeSnapshotFunc(ctx, id, state)
+	}
+	return nil
+}
+
+func (m *mockBattleRepo) ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*battle.Battle, error) {
+	if m.listByPlayerFunc != nil {
+		return m.listByPlayerFunc(ctx, playerID, limit, offset)
+	}
+	return nil, nil
+}
+
+type mockMatchProvider struct {
+	createFunc func(ctx context.Context, payload battles.StartBattlePayload) (battles.StartBattleResult, error)
+	closeFunc  func(ctx context.Context, matchID string) error
+}
+
+func (m *mockMatchProvider) CreateMatch(ctx context.Context, payload battles.StartBattlePayload) (battles.StartBattleResult, error) {
+	if m.createFunc != nil {
+		return m.createFunc(ctx, payload)
+	}
+	return battles.StartBattleResult{BattleID: "battle-1", MatchID: "match-1"}, nil
+}
+
+func (m *mockMatchProvider) CloseMatch(ctx context.Context, matchID string) error {
+	if m.closeFunc != nil {
+		return m.closeFunc(ctx, matchID)
+	}
+	return nil
+}
+
+type mockOrphanRecorder struct {
+	matchIDs []string
+}
+
+func (m *mockOrphanRecorder) RecordOrphan(ctx context.Context, battleID shared.BattleID, matchID string, cause error) error {
+	m.matchIDs = append(m.matchIDs, matchID)
+	return nil
+}
+
+func newTestService(repo battles.Repository, provider battles.MatchProvider) *battles.Service {
+	service := battles.NewService(repo, provider)
+	service.Clock = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+	return service
+}
+
+func TestService_StartBattle(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		cmd        battles.StartCommand
+		saveErr    error
+		closeErr   error
+		wantErr    error
+		wantClosed []string
+		wantOrphan []string
+	}{
+		{
+			name: "successful start",
+			cmd: battles.StartCommand{
+				LeaderID:       "player-1",
+				IdempotencyKey: "key-1",
+			},
+		},
+		{
+			name: "save failure closes match",
+			cmd: battles.StartCommand{
+				LeaderID:       "player-1",
+				IdempotencyKey: "key-1",
+			},
+			saveErr:    errors.New("save failed"),
+			wantErr:    battles.ErrBattleNotPersisted,
+			wantClosed: []string{"match-1"},
+		},
+		{
+			name: "save failure with close failure records orphan",
+			cmd: battles.StartCommand{
+				LeaderID:       "player-1",
+				IdempotencyKey: "key-1",
+			},
+			saveErr:    errors.New("save failed"),
+			closeErr:   errors.New("close failed"),
+			wantErr:    battles.ErrBattleNotPersisted,
+			wantClosed: []string{"match-1"},
+			wantOrphan: []string{"match-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var closed []string
+			repo := &mockBattleRepo{
+				saveFunc: func(ctx context.Context, b *battle.Battle) error {
+					return tt.saveErr
+				},
+			}
+			provider := &mockMatchProvider{
+				closeFunc: func(ctx context.Context, matchID string) error {
+					closed = append(closed, matchID)
+					return tt.closeErr
+				},
+			}
+			orphans := &mockOrphanRecorder{}
+
+			service := newTestService(repo, provider)
+			service.Orphans = orphans
+
+			result, err := service.StartBattle(ctx, tt.cmd)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("StartBattle() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("StartBattle() error = %v", err)
+			} else if result.MatchID != "match-1" {
+				t.Errorf("Expected match id %q, got %q", "match-1", result.MatchID)
+			}
+
+			if len(closed) != len(tt.wantClosed) {
+				t.Errorf("Expected closed matches %v, got %v", tt.wantClosed, closed)
+			}
+			if len(orphans.matchIDs) != len(tt.wantOrphan) {
+				t.Errorf("Expected orphaned matches %v, got %v", tt.wantOrphan, orphans.matchIDs)
+			}
+		})
+	}
+}
+
+func TestService_StartBattle_MaxSlots(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      battles.StartCommand
+		wantSlot int
+	}{
+		{name: "no preset", cmd: battles.StartCommand{}, wantSlot: battle.DefaultMaxSlots},
+		{name: "duel preset", cmd: battles.StartCommand{Preset: "1v1"}, wantSlot: 2},
+		{name: "team preset", cmd: battles.StartCommand{Preset: "2v2"}, wantSlot: 4},
+		{name: "explicit capacity", cmd: battles.StartCommand{Preset: "1v1", MaxSlots: 8}, wantSlot: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *battle.Battle
+			var payload battles.StartBattlePayload
+			repo := &mockBattleRepo{saveFunc: func(ctx context.Context, b *battle.Battle) error {
+				saved = b
+				return nil
+			}}
+			provider := &mockMatchProvider{
+				createFunc: func(ctx context.Context, p battles.StartBattlePayload) (battles.StartBattleResult, error) {
+					payload = p
+					return battles.StartBattleResult{BattleID: "battle-1", MatchID: "match-1"}, nil
+				},
+			}
+			service := newTestService(repo, provider)
+
+			cmd := tt.cmd
+			cmd.LeaderID = "player-1"
+			cmd.IdempotencyKey = "key-1"
+			if _, err := service.StartBattle(context.Background(), cmd); err != nil {
+				t.Fatalf("StartBattle() error = %v", err)
+			}
+			if payload.MaxSlots != tt.wantSlot {
+				t.Errorf("payload MaxSlots = %d, want %d", payload.MaxSlots, tt.wantSlot)
+			}
+			if saved == nil || saved.MaxSlots != tt.wantSlot {
+				t.Errorf("saved battle = %+v, want MaxSlots %d", saved, tt.wantSlot)
+			}
+		})
+	}
+}
+
+// init registers a game-module preset the way a module outside the package would.
+func init() {
+	battles.RegisterPreset(battles.Preset{
+		Name:     "test_koth",
+		MaxSlots: 6,
+		Metadata: map[string]any{"mode": "king_of_the_hill", "rounds": 3},
+	})
+}
+
+func TestService_StartBattle_Presets(t *testing.T) {
+	tests := []struct {
+		name              string
+		cmd               battles.StartCommand
+		wantErr           error
+		wantMetadata      map[string]any
+		wantAuthoritative bool
+	}{
+		{
+			name:    "unknown preset",
+			cmd:     battles.StartCommand{Preset: "1vs1"},
+			wantErr: battles.ErrUnknownPreset,
+		},
+		{
+			name:              "builtin preset metadata",
+			cmd:               battles.StartCommand{Preset: "1v1"},
+			wantMetadata:      map[string]any{"mode": "duel"},
+			wantAuthoritative: true,
+		},
+		{
+			name:         "request metadata wins",
+			cmd:          battles.StartCommand{Preset: "test_koth", Metadata: map[string]any{"rounds": 5, "map": "dunes"}},
+			wantMetadata: map[string]any{"mode": "king_of_the_hill", "rounds": 5, "map": "dunes"},
+		},
+		{
+			name:         "no preset keeps request metadata",
+			cmd:          battles.StartCommand{Metadata: map[string]any{"map": "dunes"}},
+			wantMetadata: map[string]any{"map": "dunes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload *battles.StartBattlePayload
+			provider := &mockMatchProvider{
+				createFunc: func(ctx context.Context, p battles.StartBattlePayload) (battles.StartBattleResult, error) {
+					payload = &p
+					return battles.StartBattleResult{BattleID: "battle-1", MatchID: "match-1"}, nil
+				},
+			}
+			service := newTestService(&mockBattleRepo{}, provider)
+
+			cmd := tt.cmd
+			cmd.LeaderID = "player-1"
+			cmd.IdempotencyKey = "key-1"
+			_, err := service.StartBattle(context.Background(), cmd)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("StartBattle() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if payload != nil {
+					t.Error("Expected no match to be created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StartBattle() error = %v", err)
+			}
+			if !reflect.DeepEqual(payload.Metadata, tt.wantMetadata) {
+				t.Errorf("payload Metadata = %v, want %v", payload.Metadata, tt.wantMetadata)
+			}
+			if payload.Authoritative != tt.wantAuthoritative {
+				t.Errorf("payload Authoritative = %v, want %v", payload.Authoritative, tt.wantAuthoritative)
+			}
+		})
+	}
+}
+
+func TestRegisterPreset_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		preset battles.Preset
+	}{
+		{name: "empty name", preset: battles.Preset{}},
+		{name: "duplicate name", preset: battles.Preset{Name: "1v1", MaxSlots: 2}},
+		{name: "negative slots", preset: battles.Preset{Name: "test_negative", MaxSlots: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterPreset(%+v) did not panic", tt.preset)
+				}
+			}()
+			battles.RegisterPreset(tt.preset)
+		})
+	}
+}
+
+func TestService_StartBattle_Logging(t *testing.T) {
+	tests := []struct {
+		name      string
+		createErr error
+		saveErr   error
+		closeErr  error
+		wantLevel zapcore.Level
+		wantMsg   string
+	}{
+		{name: "started", wantLevel: zapcore.InfoLevel, wantMsg: "battle started"},
+		{name: "create failure", createErr: errors.New("nakama unavailable"), wantLevel: zapcore.ErrorLevel, wantMsg: "match create failed"},
+		{name: "save failure", saveErr: errors.New("save failed"), wantLevel: zapcore.WarnLevel, wantMsg: "battle not persisted, match closed"},
+		{name: "orphaned match", saveErr: errors.New("save failed"), closeErr: errors.New("close failed"), wantLevel: zapcore.ErrorLevel, wantMsg: "battle not persisted, match left running"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			repo := &mockBattleRepo{
+				saveFunc: func(ctx context.Context, b *battle.Battle) error { return tt.saveErr },
+			}
+			provider := &mockMatchProvider{
+				createFunc: func(ctx context.Context, payload battles.StartBattlePayload) (battles.StartBattleResult, error) {
+					return battles.StartBattleResult{BattleID: "battle-1", MatchID: "match-1"}, tt.createErr
+				},
+				closeFunc: func(ctx context.Context, matchID string) error { return tt.closeErr },
+			}
+			service := newTestService(repo, provider)
+			service.Logger = zap.New(core)
+
+			ctx := shared.WithCorrelationID(context.Background(), "req-1")
+			_, _ = service.StartBattle(ctx, battles.StartCommand{LeaderID: "player-1", IdempotencyKey: "key-1"})
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("Expected 1 log line, got %d", len(entries))
+			}
+			entry := entries[0]
+			if entry.Level != tt.wantLevel || entry.Message != tt.wantMsg {
+				t.Errorf("log = %s %q, want %s %q", entry.Level, entry.Message, tt.wantLevel, tt.wantMsg)
+			}
+			if got := entry.ContextMap()["request_id"]; got != "req-1" {
+				t.Errorf("request_id = %v, want req-1", got)
+			}
+		})
+	}
+}
+
+func TestService_StartBattle_NoLogger(t *testing.T) {
+	service := newTestService(&mockBattleRepo{}, &mockMatchProvider{})
+	if _, err := service.StartBattle(context.Background(), battles.StartCommand{LeaderID: "player-1", IdempotencyKey: "key-1"}); err != nil {
+		t.Fatalf("StartBattle() error = %v", err)
+	}
+}
+
+func TestService_StartBattle_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	var created int
+	provider := &mockMatchProvider{
+		createFunc: func(ctx context.Context, payload battles.StartBattlePayload) (battles.StartBattleResult, error) {
+			created++
+			return battles.StartBattleResult{BattleID: "battle-1", MatchID: "match-1"}, nil
+		},
+	}
+	service := newTestService(&mockBattleRepo{}, provider)
+	service.Idempotency = idempotency.NewMemoryStore()
+
+	cmd := battles.StartCommand{LeaderID: "player-1", IdempotencyKey: "start-1"}
+	first, err := service.StartBattle(ctx, cmd)
+	if err != nil {
+		t.Fatalf("StartBattle() error = %v", err)
+	}
+	second, err := service.StartBattle(ctx, cmd)
+	if err != nil {
+		t.Fatalf("StartBattle() duplicate error = %v", err)
+	}
+
+	if created != 1 {
+		t.Errorf("Expected one match to be created, got %d", created)
+	}
+	if second != first {
+		t.Errorf("Expected duplicate to replay %+v, got %+v", first, second)
+	}
+}
+
+func TestService_StartBattle_ExistingBattle(t *testing.T) {
+	ctx := context.Background()
+	var created int
+	provider := &mockMatchProvider{
+		createFunc: func(ctx context.Context, payload battles.StartBattlePayload) (battles.StartBattleResult, error) {
+			created++
+			return battles.StartBattleResult{BattleID: "battle-1", MatchID: "match-1"}, nil
+		},
+	}
+	service := newTestService(infraBattle.NewMemoryRepository(), provider)
+
+	cmd := battles.StartCommand{LeaderID: "player-1", IdempotencyKey: "start-1"}
+	first, err := service.StartBattle(ctx, cmd)
+	if err != nil {
+		t.Fatalf("StartBattle() error = %v", err)
+	}
+	second, err := service.StartBattle(ctx, cmd)
+	if err != nil {
+		t.Fatalf("StartBattle() duplicate error = %v", err)
+	}
+	if created != 1 {
+		t.Errorf("Expected one match to be created, got %d", created)
+	}
+	if second != first {
+		t.Errorf("Expected duplicate to return %+v, got %+v", first, second)
+	}
+
+	_, err = service.StartBattle(ctx, battles.StartCommand{LeaderID: "player-2", IdempotencyKey: "start-1"})
+	if !errors.Is(err, shared.ErrDuplicate) {
+		t.Errorf("StartBattle() other leader error = %v, want %v", err, shared.ErrDuplicate)
+	}
+	if created != 1 {
+		t.Errorf("Expected no match for another leader, got %d created", created)
+	}
+}
+
+func TestService_CancelLedBattles(t *testing.T) {
+	ctx := context.Background()
+	var closed []string
+	provider := &mockMatchProvider{
+		createFunc: func(ctx context.Context, payload battles.StartBattlePayload) (battles.StartBattleResult, error) {
+			id := string(payload.LeaderID)
+			return battles.StartBattleResult{BattleID: shared.BattleID("battle-" + id), MatchID: "match-" + id}, nil
+		},
+		closeFunc: func(ctx context.Context, matchID string) error {
+			closed = append(closed, matchID)
+			return nil
+		},
+	}
+	repo := infraBattle.NewMemoryRepository()
+	service := newTestService(repo, provider)
+	service.Active = infraBattle.NewMemoryActiveIndex()
+
+	for _, leader := range []shared.PlayerID{"player-1", "player-2"} {
+		if _, err := service.StartBattle(ctx, battles.StartCommand{LeaderID: leader, IdempotencyKey: shared.IdempotencyKey("start-" + leader)}); err != nil {
+			t.Fatalf("StartBattle() error = %v", err)
+		}
+	}
+	// A join saved after the battle was indexed must survive the cancellation.
+	if _, err := service.JoinBattle(ctx, battles.JoinCommand{BattleID: "battle-player-1", PlayerID: "player-3"}); err != nil {
+		t.Fatalf("JoinBattle() error = %v", err)
+	}
+
+	n, err := service.CancelLedBattles(ctx, "player-1")
+	if err != nil {
+		t.Fatalf("CancelLedBattles() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("CancelLedBattles() = %d, want 1", n)
+	}
+	if len(closed) != 1 || closed[0] != "match-player-1" {
+		t.Errorf("Expected only match-player-1 to be closed, got %v", closed)
+	}
+	cancelled, _ := repo.Get(ctx, "battle-player-1")
+	if cancelled.Status != battle.StatusCancelled || !cancelled.HasPlayer("player-3") {
+		t.Errorf("Expected battle-player-1 to be cancelled with its joins kept, got %+v", cancelled)
+	}
+	if other, _ := repo.Get(ctx, "battle-player-2"); other.Status != battle.StatusActive {
+		t.Errorf("Expected battle-player-2 to stay active, got %q", other.Status)
+	}
+
+	// Cancelled battles leave the index, so a repeat is a no-op.
+	if n, err := service.CancelLedBattles(ctx, "player-1"); err != nil || n != 0 {
+		t.Errorf("CancelLedBattles() repeat = %d, %v, want 0, nil", n, err)
+	}
+}
+
+func TestService_CancelLedBattles_CloseFailure(t *testing.T) {
+	ctx := context.Background()
+	closeErr := errors.New("nakama unavailable")
+	provider := &mockMatchProvider{closeFunc: func(ctx context.Context, matchID string) error { return closeErr }}
+	service := newTestService(infraBattle.NewMemoryRepository(), provider)
+	service.Active = infraBattle.NewMemoryActiveIndex()
+
+	if _, err := service.StartBattle(ctx, battles.StartCommand{LeaderID: "player-1", IdempotencyKey: "start-1"}); err != nil {
+		t.Fatalf("StartBattle() error = %v", err)
+	}
+	n, err := service.CancelLedBattles(ctx, "player-1")
+	if !errors.Is(err, closeErr) || n != 0 {
+		t.Errorf("CancelLedBattles() = %d, %v, want 0, %v", n, err, closeErr)
+	}
+	// The battle stays indexed so the cancellation can be retried.
+	provider.closeFunc = nil
+	if n, err := service.CancelLedBattles(ctx, "player-1"); err != nil || n != 1 {
+		t.Errorf("CancelLedBattles() retry = %d, %v, want 1, nil", n, err)
+	}
+}
+
+func TestService_FinishBattle(t *testing.T) {
+	ctx := context.Background()
+	var closed []string
+	provider := &mockMatchProvider{closeFunc: func(ctx context.Context, matchID string) error {
+		closed = append(closed, matchID)
+		return nil
+	}}
+	repo := infraBattle.NewMemoryRepository()
+	service := newTestService(repo, provider)
+	service.Active = infraBattle.NewMemoryActiveIndex()
+
+	if _, err := service.StartBattle(ctx, battles.StartCommand{LeaderID: "player-1", IdempotencyKey: "start-1"}); err != nil {
+		t.Fatalf("StartBattle() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := service.FinishBattle(ctx, "battle-1"); err != nil {
+			t.Fatalf("FinishBattle() call %d error = %v", i, err)
+		}
+	}
+	if b, _ := repo.Get(ctx, "battle-1"); b.Status != battle.StatusFinished {
+		t.Errorf("Expected battle-1 to be finished, got %q", b.Status)
+	}
+	if _, err := service.JoinBattle(ctx, battles.JoinCommand{BattleID: "battle-1", PlayerID: "player-2"}); !errors.Is(err, battle.ErrBattleFinished) {
+		t.Errorf("JoinBattle() error = %v, want %v", err, battle.ErrBattleFinished)
+	}
+
+	// A battle finished by another process is still indexed here; reloading
+	// it keeps its ended match from being closed and drops it from the index.
+	provider.createFunc = func(ctx context.Context, payload battles.StartBattlePayload) (battles.StartBattleResult, error) {
+		return battles.StartBattleResult{BattleID: "battle-2", MatchID: "match-2"}, nil
+	}
+	if _, err := service.StartBattle(ctx, battles.StartCommand{LeaderID: "player-1", IdempotencyKey: "start-2"}); err != nil {
+		t.Fatalf("StartBattle() error = %v", err)
+	}
+	other, _ := repo.Get(ctx, "battle-2")
+	_ = other.Finish(time.Now())
+	_ = repo.Save(ctx, other)
+	if n, err := service.CancelLedBattles(ctx, "player-1"); err != nil || n != 0 || len(closed) != 0 {
+		t.Errorf("CancelLedBattles() = %d, %v with closed %v, want nothing cancelled", n, err, closed)
+	}
+	if ids, _ := service.Active.ListByLeader(ctx, "player-1"); len(ids) != 0 {
+		t.Errorf("Expected finished battles to leave the index, got %v", ids)
+	}
+	if err := service.FinishBattle(ctx, "missing"); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("FinishBattle() unknown battle error = %v, want %v", err, shared.ErrNotFound)
+	}
+}
+
+func TestService_JoinBattle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	errSave := errors.New("db down")
+
+	tests := []struct {
+		name      string
+		cmd       battles.JoinCommand
+		cancelled bool
+		maxSlots  int
+		saveErr   error
+		wantErr   error
+		wantSlots []shared.PlayerID
+	}{
+		{name: "joins", cmd: battles.JoinCommand{BattleID: "battle-1", PlayerID: "player-2"}, wantSlots: []shared.PlayerID{"leader-1", "player-2"}},
+		{name: "already joined", cmd: battles.JoinCommand{BattleID: "battle-1", PlayerID: "leader-1"}, wantErr: battle.ErrPlayerAlreadyJoined},
+		{name: "cancelled battle", cmd: battles.JoinCommand{BattleID: "battle-1", PlayerID: "player-2"}, cancelled: true, wantErr: battle.ErrBattleCancelled},
+		{name: "full battle", cmd: battles.JoinCommand{BattleID: "battle-1", PlayerID: "player-2"}, maxSlots: 1, wantErr: battle.ErrBattleFull},
+		{name: "free slot", cmd: battles.JoinCommand{BattleID: "battle-1", PlayerID: "player-2"}, maxSlots: 2, wantSlots: []shared.PlayerID{"leader-1", "player-2"}},
+		{name: "missing battle", cmd: battles.JoinCommand{BattleID: "missing", PlayerID: "player-2"}, wantErr: shared.ErrNotFound},
+		{name: "save failure", cmd: battles.JoinCommand{BattleID: "battle-1", PlayerID: "player-2"}, saveErr: errSave, wantErr: errSave},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing, err := battle.NewBattle("battle-1", "leader-1", "key-1", now)
+			if err != nil {
+				t.Fatalf("NewBattle() error = %v", err)
+			}
+			if tt.cancelled {
+				_ = existing.Cancel(now)
+			}
+			existing.MaxSlots = tt.maxSlots
+			var saved *battle.Battle
+			repo := &mockBattleRepo{
+				getFunc: func(ctx context.Context, id shared.BattleID) (*battle.Battle, error) {
+					if id == existing.ID {
+						return existing, nil
+					}
+					return nil, shared.ErrNotFound
+				},
+				saveFunc: func(ctx context.Context, b *battle.Battle) error {
+					saved = b
+					return tt.saveErr
+				},
+			}
+			service := newTestService(repo, &mockMatchProvider{})
+
+			slots, err := service.JoinBattle(ctx, tt.cmd)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("JoinBattle() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JoinBattle() error = %v", err)
+			}
+			if saved == nil {
+				t.Fatal("Expected the battle to be saved")
+			}
+			var got []shared.PlayerID
+			for _, slot := range slots {
+				got = append(got, slot.PlayerID)
+			}
+			if !reflect.DeepEqual(got, tt.wantSlots) {
+				t.Errorf("JoinBattle() slots = %v, want %v", got, tt.wantSlots)
+			}
+		})
+	}
+}
+
+func TestService_JoinBattle_Concurrent(t *testing.T) {
+	const joiners = 6
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := infraBattle.NewMemoryRepository()
+	existing, err := battle.NewBattle("battle-1", "leader-1", "key-1", now)
+	if err != nil {
+		t.Fatalf("NewBattle() error = %v", err)
+	}
+	existing.MaxSlots = 4
+	if err := repo.Save(ctx, existing); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	service := newTestService(repo, &mockMatchProvider{})
+
+	var (
+		wg     sync.WaitGroup
+		joined atomic.Int32
+	)
+	for i := 0; i < joiners; i++ {
+		wg.Add(1)
+		go func(player shared.PlayerID) {
+			defer wg.Done()
+			_, err := service.JoinBattle(ctx, battles.JoinCommand{BattleID: "battle-1", PlayerID: player})
+			switch {
+			case err == nil:
+				joined.Add(1)
+			case !errors.Is(err, battle.ErrBattleFull) && !errors.Is(err, battle.ErrConcurrentModification):
+				t.Errorf("JoinBattle(%s) error = %v", player, err)
+			}
+		}(shared.PlayerID(fmt.Sprintf("player-%d", i)))
+	}
+	wg.Wait()
+
+	stored, err := repo.Get(ctx, "battle-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(stored.Slots) > stored.MaxSlots {
+		t.Errorf("Expected at most %d slots, got %d", stored.MaxSlots, len(stored.Slots))
+	}
+	if int(joined.Load()) != len(stored.Slots)-1 {
+		t.Errorf("Expected every successful join to keep its slot: %d joins, %d slots", joined.Load(), len(stored.Slots))
+	}
+}
+
+func TestService_GetLobby(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	existing, err := battle.NewBattle("battle-1", "leader-1", "key-1", now)
+	if err != nil {
+		t.Fatalf("NewBattle() error = %v", err)
+	}
+	existing.MatchID = "match-1"
+	if err := existing.AddPlayer("player-2", now); err != nil {
+		t.Fatalf("AddPlayer() error = %v", err)
+	}
+
+	repo := &mockBattleRepo{
+		getFunc: func(ctx context.Context, id shared.BattleID) (*battle.Battle, error) {
+			if id == existing.ID {
+				return existing, nil
+			}
+			return nil, shared.ErrNotFound
+		},
+	}
+	service := newTestService(repo, &mockMatchProvider{})
+
+	lobby, err := service.GetLobby(ctx, "battle-1")
+	if err != nil {
+		t.Fatalf("GetLobby() error = %v", err)
+	}
+	if lobby.Leader != "leader-1" || lobby.MatchID != "match-1" || len(lobby.Slots) != 2 {
+		t.Fatalf("Unexpected lobby %+v", lobby)
+	}
+	if !lobby.Slots[0].Ready || lobby.Slots[1].Ready || lobby.AllReady {
+		t.Errorf("Expected only the leader to be ready, got %+v", lobby.Slots)
+	}
+
+	if err := existing.MarkReady("player-2", true, now); err != nil {
+		t.Fatalf("MarkReady() error = %v", err)
+	}
+	if lobby, _ := service.GetLobby(ctx, "battle-1"); !lobby.AllReady {
+		t.Error("Expected lobby to be ready once every slot is ready")
+	}
+
+	if _, err := service.GetLobby(ctx, "missing"); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("GetLobby() error = %v, wantErr %v", err, shared.ErrNotFound)
+	}
+	if _, err := service.GetLobby(ctx, ""); err == nil {
+		t.Error("Expected an error for an empty battle id")
+	}
+}
+
+func TestService_SetReady(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		playerID  shared.PlayerID
+		ready     bool
+		cancelled bool
+		wantErr   error
+		wantReady []bool
+		wantAll   bool
+	}{
+		{name: "ready up", playerID: "player-2", ready: true, wantReady: []bool{true, true}, wantAll: true},
+		{name: "leader unreadies", playerID: "leader-1", wantReady: []bool{false, false}},
+		{name: "not in battle", playerID: "player-3", ready: true, wantErr: battle.ErrPlayerNotFound},
+		{name: "cancelled battle", playerID: "player-2", ready: true, cancelled: true, wantErr: battle.ErrBattleCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing, err := battle.NewBattle("battle-1", "leader-1", "key-1", now)
+			if err != nil {
+				t.Fatalf("NewBattle() error = %v", err)
+			}
+			_ = existing.AddPlayer("player-2", now)
+			if tt.cancelled {
+				_ = existing.Cancel(now)
+			}
+			saved := false
+			repo := &mockBattleRepo{
+				getFunc: func(ctx context.Context, id shared.BattleID) (*battle.Battle, error) {
+					return existing, nil
+				},
+				saveFunc: func(ctx context.Context, b *battle.Battle) error {
+					saved = true
+					return nil
+				},
+			}
+			service := newTestService(repo, &mockMatchProvider{})
+
+			lobby, err := service.SetReady(ctx, "battle-1", tt.playerID, tt.ready)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SetReady() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if saved {
+					t.Error("Expected the battle not to be saved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetReady() error = %v", err)
+			}
+			if !saved {
+				t.Error("Expected the battle to be saved")
+			}
+			var ready []bool
+			for _, slot := range lobby.Slots {
+				ready = append(ready, slot.Ready)
+			}
+			if !reflect.DeepEqual(ready, tt.wantReady) || lobby.AllReady != tt.wantAll {
+				t.Errorf("SetReady() ready = %v all %v, want %v all %v", ready, lobby.AllReady, tt.wantReady, tt.wantAll)
+			}
+		})
+	}
+}
+
+func TestService_CanStart(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		players   []shared.PlayerID
+		ready     []shared.PlayerID
+		unready   []shared.PlayerID
+		cancelled bool
+		cmd       battles.CanStartCommand
+		want      bool
+		wantErr   error
+	}{
+		{name: "leader alone", cmd: battles.CanStartCommand{BattleID: "battle-1"}},
+		{name: "waiting on a player", players: []shared.PlayerID{"player-2", "player-3"}, ready: []shared.PlayerID{"player-2"}, cmd: battles.CanStartCommand{BattleID: "battle-1"}},
+		{name: "everyone ready", players: []shared.PlayerID{"player-2", "player-3"}, ready: []shared.PlayerID{"player-2", "player-3"}, cmd: battles.CanStartCommand{BattleID: "battle-1"}, want: true},
+		{name: "leader readiness ignored", players: []shared.PlayerID{"player-2"}, ready: []shared.PlayerID{"player-2"}, unready: []shared.PlayerID{"leader-1"}, cmd: battles.CanStartCommand{BattleID: "battle-1"}, want: true},
+		{name: "leader forces", players: []shared.PlayerID{"player-2"}, cmd: battles.CanStartCommand{BattleID: "battle-1", PlayerID: "leader-1", Force: true}, want: true},
+		{name: "player cannot force", players: []shared.PlayerID{"player-2"}, cmd: battles.CanStartCommand{BattleID: "battle-1", PlayerID: "player-2", Force: true}, wantErr: battle.ErrNotLeader},
+		{name: "cancelled", players: []shared.PlayerID{"player-2"}, ready: []shared.PlayerID{"player-2"}, cancelled: true, cmd: battles.CanStartCommand{BattleID: "battle-1"}},
+		{name: "cancelled forced", players: []shared.PlayerID{"player-2"}, cancelled: true, cmd: battles.CanStartCommand{BattleID: "battle-1", PlayerID: "leader-1", Force: true}},
+		{name: "missing battle", cmd: battles.CanStartCommand{BattleID: "missing"}, wantErr: shared.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing, err := battle.NewBattle("battle-1", "leader-1", "key-1", now)
+			if err != nil {
+				t.Fatalf("NewBattle() error = %v", err)
+			}
+			for _, id := range tt.players {
+				_ = existing.AddPlayer(id, now)
+			}
+			for _, id := range tt.ready {
+				_ = existing.MarkReady(id, true, now)
+			}
+			for _, id := range tt.unready {
+				_ = existing.MarkReady(id, false, now)
+			}
+			if tt.cancelled {
+				_ = existing.Cancel(now)
+			}
+			repo := &mockBattleRepo{
+				getFunc: func(ctx context.Context, id shared.BattleID) (*battle.Battle, error) {
+					if id == existing.ID {
+						return existing, nil
+					}
+					return nil, shared.ErrNotFound
+				},
+			}
+			service := newTestService(repo, &mockMatchProvider{})
+
+			got, err := service.CanStart(ctx, tt.cmd)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CanStart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CanStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_UpdateSnapshot(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	existing, err := battle.NewBattle("battle-1", "leader-1", "key-1", now)
+	if err != nil {
+		t.Fatalf("NewBattle() error = %v", err)
+	}
+	var stored []battle.MatchState
+	repo := &mockBattleRepo{
+		getFunc: func(ctx context.Context, id shared.BattleID) (*battle.Battle, error) {
+			if id == existing.ID {
+				return existing, nil
+			}
+			return nil, shared.ErrNotFound
+		},
+		storeSnapshotFunc: func(ctx context.Context, id shared.BattleID, state battle.MatchState) error {
+			stored = append(stored, state)
+			return nil
+		},
+	}
+	service := newTestService(repo, &mockMatchProvider{})
+
+	if err := service.UpdateSnapshot(ctx, "battle-1", battle.MatchState{Tick: 50, Payload: []byte(`{"tick":50}`)}); err != nil {
+		t.Fatalf("UpdateSnapshot() error = %v", err)
+	}
+	want := battle.MatchState{Tick: 50, Payload: []byte(`{"tick":50}`), UpdatedAt: now}
+	if len(stored) != 1 || !reflect.DeepEqual(stored[0], want) {
+		t.Errorf("stored = %+v, want %+v", stored, want)
+	}
+	if !reflect.DeepEqual(existing.StateSnapshot, want) {
+		t.Errorf("StateSnapshot = %+v, want %+v", existing.StateSnapshot, want)
+	}
+
+	if err := service.UpdateSnapshot(ctx, "missing", battle.MatchState{Tick: 1}); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("UpdateSnapshot() error = %v, wantErr %v", err, shared.ErrNotFound)
+	}
+	if err := service.UpdateSnapshot(ctx, "", battle.MatchState{Tick: 1}); err == nil {
+		t.Error("Expected an error for an empty battle id")
+	}
+}
+
+func TestService_ListPlayerBattles(t *testing.T) {
+	ctx := context.Background()
+	var gotLimit, gotOffset int
+	repo := &mockBattleRepo{
+		listByPlayerFunc: func(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*battle.Battle, error) {
+			gotLimit, gotOffset = limit, offset
+			return []*battle.Battle{{ID: "battle-1"}}, nil
+		},
+	}
+	service := newTestService(repo, &mockMatchProvider{})
+
+	got, err := service.ListPlayerBattles(ctx, battles.ListPlayerBattlesQuery{PlayerID: "player-1", Offset: -1})
+	if err != nil {
+		t.Fatalf("ListPlayerBattles() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "battle-1" {
+		t.Errorf("ListPlayerBattles() = %+v, want battle-1", got)
+	}
+	if gotLimit != 10 || gotOffset != 0 {
+		t.Errorf("Expected default paging 10/0, got %d/%d", gotLimit, gotOffset)
+	}
+
+	if _, err := service.ListPlayerBattles(ctx, battles.ListPlayerBattlesQuery{}); err == nil {
+		t.Error("Expected an error for an empty player id")
+	}
+}
@@ -3,8 +3,13 @@ package bot
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.uber.org/zap"
+
 	domain "github.com/heroiclabs/nakama/v3/src/domain/bot"
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
 )
@@ -18,25 +23,50 @@ type QueueProducer interface {
 	Enqueue(ctx context.Context, command *domain.Command) error
 }
 
+// Executor runs a synchronous command inline and returns its response.
+type Executor interface {
+	Execute(ctx context.Context, command *domain.Command) ([]byte, error)
+}
+
 // Notifier sends immediate acknowledgement to players or external systems.
 type Notifier interface {
 	Notify(ctx context.Context, playerID shared.PlayerID, payload map[string]any) error
 }
 
+// idempotencyScope namespaces bot command keys in a shared IdempotencyStore.
+const idempotencyScope = "bot"
+
 // Service manages bot command ingestion and acknowledgement.
 type Service struct {
 	Repo     Repository
 	Producer QueueProducer
 	Notifier Notifier
 	Clock    func() time.Time
+	// Idempotency dedupes commands by key. When nil, the repository's
+	// ReserveCommand is used instead.
+	Idempotency    shared.IdempotencyStore
+	IdempotencyTTL time.Duration
+	// Acks, when set, ensures each command is acknowledged at most once,
+	// including when a retry arrives after the command was handled.
+	Acks domain.AckStore
+	// Executor runs commands submitted with Sync. Without one, synchronous
+	// commands are rejected with domain.ErrSyncUnsupported.
+	Executor Executor
+	// Logger, when set, records failures that do not fail the command.
+	Logger *zap.Logger
+
+	// inFlight and pending track enqueues still running, for Drain.
+	inFlight sync.WaitGroup
+	pending  atomic.Int64
 }
 
 func NewService(repo Repository, producer QueueProducer, notifier Notifier) *Service {
 	return &Service{
-		Repo:     repo,
-		Producer: producer,
-		Notifier: notifier,
-		Clock:    func() time.Time { return time.Now().UTC() },
+		Repo:           repo,
+		Producer:       producer,
+		Notifier:       notifier,
+		Clock:          func() time.Time { return time.Now().UTC() },
+		IdempotencyTTL: shared.DefaultIdempotencyTTL,
 	}
 }
 
@@ -46,39 +76,252 @@ type CommandInput struct {
 	PlayerID       shared.PlayerID
 	Payload        []byte
 	IdempotencyKey shared.IdempotencyKey
+	// Sync runs the command inline through the Executor and returns its
+	// response, instead of enqueuing it for a worker.
+	Sync bool
 }
 
 type CommandResult struct {
 	Accepted bool
+	// Response is the executor's output for a synchronous command.
+	Response []byte
 }
 
+// Handle ingests a command. By default it is enqueued for a worker; with
+// input.Sync it is executed before Handle returns. Either way a repeated
+// idempotency key returns the first outcome without handling it again.
 func (s *Service) Handle(ctx context.Context, input CommandInput) (CommandResult, error) {
+	if input.Sync && s.Executor == nil {
+		return CommandResult{}, domain.ErrSyncUnsupported
+	}
 	now := s.Clock()
-	if existing, err := s.Repo.ReserveCommand(ctx, input.IdempotencyKey); err == nil {
-		if existing.State == domain.CommandStateCompleted {
-			return CommandResult{Accepted: true}, nil
-		}
-		return CommandResult{}, shared.ErrDuplicate
-	} else if !errors.Is(err, shared.ErrNotFound) {
+	handled, response, err := s.reserve(ctx, input.IdempotencyKey)
+	if err != nil {
 		return CommandResult{}, err
 	}
+	if handled {
+		s.acknowledge(ctx, input, false)
+		return CommandResult{Accepted: true, Response: response}, nil
+	}
 
 	cmd, err := domain.NewCommand(input.CommandID, input.Channel, input.Payload, input.IdempotencyKey, now)
 	if err != nil {
+		s.release(ctx, input.IdempotencyKey)
 		return CommandResult{}, err
 	}
+	cmd.PlayerID = input.PlayerID
 	if err := s.Repo.Save(ctx, cmd); err != nil {
+		s.release(ctx, input.IdempotencyKey)
 		return CommandResult{}, err
 	}
+	if input.Sync {
+		return s.execute(ctx, input, cmd)
+	}
 	if s.Producer != nil {
-		if err := s.Producer.Enqueue(ctx, cmd); err != nil {
+		if err := s.enqueue(ctx, cmd); err != nil {
 			cmd.MarkAttempt(now, err)
 			_ = s.Repo.Save(ctx, cmd)
+			s.release(ctx, input.IdempotencyKey)
 			return CommandResult{}, err
 		}
 	}
-	if s.Notifier != nil && input.PlayerID != "" {
-		_ = s.Notifier.Notify(ctx, input.PlayerID, map[string]any{"status": "accepted"})
-	}
+	s.complete(ctx, input.IdempotencyKey, nil)
+	s.acknowledge(ctx, input, true)
 	return CommandResult{Accepted: true}, nil
 }
+
+// enqueue hands cmd to the Producer, tracking it until the call returns.
+func (s *Service) enqueue(ctx context.Context, cmd *domain.Command) error {
+	s.inFlight.Add(1)
+	s.pending.Add(1)
+	defer func() {
+		s.pending.Add(-1)
+		s.inFlight.Done()
+	}()
+	return s.Producer.Enqueue(ctx, cmd)
+}
+
+// DrainResult counts the enqueues Drain waited for.
+type DrainResult struct {
+	// Drained enqueues finished before the deadline.
+	Drained int
+	// Abandoned enqueues were still running when the deadline passed.
+	Abandoned int
+}
+
+// Drain blocks until every enqueue in flight has returned, or until ctx is
+// done, in which case it returns ctx's error. Call it once no new commands
+// can arrive, such as after the HTTP server has shut down.
+func (s *Service) Drain(ctx context.Context) (DrainResult, error) {
+	outstanding := int(s.pending.Load())
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return DrainResult{Drained: outstanding}, nil
+	case <-ctx.Done():
+		abandoned := int(s.pending.Load())
+		return DrainResult{Drained: max(outstanding-abandoned, 0), Abandoned: abandoned}, ctx.Err()
+	}
+}
+
+// execute runs a saved command through the Executor and records the outcome.
+// A failed execution aborts the command, so no worker retries it, and frees
+// the idempotency key so the caller can retry.
+func (s *Service) execute(ctx context.Context, input CommandInput, cmd *domain.Command) (CommandResult, error) {
+	response, execErr := s.Executor.Execute(ctx, cmd)
+	if execErr != nil {
+		cmd.Abort(s.Clock(), execErr)
+	} else {
+		cmd.MarkAttempt(s.Clock(), nil)
+		cmd.Response = response
+	}
+	if err := s.Repo.Save(ctx, cmd); err != nil {
+		s.release(ctx, input.IdempotencyKey)
+		return CommandResult{}, err
+	}
+	if execErr != nil {
+		s.release(ctx, input.IdempotencyKey)
+		return CommandResult{}, fmt.Errorf("%w: %w", domain.ErrExecutionFailed, execErr)
+	}
+	s.complete(ctx, input.IdempotencyKey, response)
+	s.acknowledge(ctx, input, true)
+	return CommandResult{Accepted: true, Response: response}, nil
+}
+
+// GetStatus returns a command so clients can poll for its result.
+func (s *Service) GetStatus(ctx context.Context, commandID shared.BotCommandID) (*domain.Command, error) {
+	if err := commandID.Validate(); err != nil {
+		return nil, err
+	}
+	return s.Repo.Get(ctx, commandID)
+}
+
+// Complete records the worker's attempt at a command: a nil err completes it
+// and notifies the player, anything else marks it failed and schedules a retry
+// or dead-letters it. Reporting on a command that has already completed, been
+// dead-lettered or been aborted changes nothing, so a worker may safely repeat
+// the call. The command is addressed by its ID, as queued for the worker and
+// as in GetStatus. A failed notification is logged, since the command has
+// already been saved as completed.
+func (s *Service) Complete(ctx context.Context, commandID shared.BotCommandID, err error) (*domain.Command, error) {
+	cmd, getErr := s.GetStatus(ctx, commandID)
+	if getErr != nil {
+		return nil, getErr
+	}
+	switch cmd.State {
+	case domain.CommandStateCompleted, domain.CommandStateDeadLettered, domain.CommandStateAborted:
+		return cmd, nil
+	}
+	cmd.MarkAttempt(s.Clock(), err)
+	if saveErr := s.Repo.Save(ctx, cmd); saveErr != nil {
+		return nil, saveErr
+	}
+	if err == nil && s.Notifier != nil && cmd.PlayerID != "" {
+		if notifyErr := s.Notifier.Notify(ctx, cmd.PlayerID, map[string]any{"status": "completed"}); notifyErr != nil {
+			s.log().Warn("bot command completion not notified",
+				zap.String("command_id", string(cmd.ID)), zap.Error(notifyErr))
+		}
+	}
+	return cmd, nil
+}
+
+// DefaultRetryBatchSize is how many commands CommandsDueForRetry returns when
+// no limit is given.
+const DefaultRetryBatchSize = 100
+
+// CommandsDueForRetry returns failed commands whose retry is due at now, so a
+// worker pulls only commands it may attempt again. Dead-lettered commands are
+// never returned.
+func (s *Service) CommandsDueForRetry(ctx context.Context, now time.Time, limit int) ([]*domain.Command, error) {
+	if limit <= 0 {
+		limit = DefaultRetryBatchSize
+	}
+	return s.Repo.ListDueForRetry(ctx, now, limit)
+}
+
+// acknowledge notifies the player that the command was accepted. With an
+// AckStore the key is claimed first so retries never notify twice; a failed
+// notification releases the claim so the next retry can send it. Without one,
+// only the first handling of a command notifies.
+func (s *Service) acknowledge(ctx context.Context, input CommandInput, firstHandling bool) {
+	if s.Notifier == nil || input.PlayerID == "" {
+		return
+	}
+	if s.Acks == nil {
+		if firstHandling {
+			_ = s.Notifier.Notify(ctx, input.PlayerID, map[string]any{"status": "accepted"})
+		}
+		return
+	}
+	first, err := s.Acks.Claim(ctx, input.IdempotencyKey)
+	if err != nil || !first {
+		return
+	}
+	if err := s.Notifier.Notify(ctx, input.PlayerID, map[string]any{"status": "accepted"}); err != nil {
+		_ = s.Acks.Forget(ctx, input.IdempotencyKey)
+	}
+}
+
+// reserve claims the idempotency key. It reports true, with any synchronous
+// response, when the command was already handled, and
+// domain.ErrCommandInFlight while an earlier attempt is still being handled.
+// A key whose synchronous execution was aborted may be used again.
+func (s *Service) reserve(ctx context.Context, key shared.IdempotencyKey) (bool, []byte, error) {
+	if s.Idempotency != nil {
+		record, err := s.Idempotency.Reserve(ctx, idempotencyScope, key, s.IdempotencyTTL)
+		if errors.Is(err, shared.ErrDuplicate) {
+			if record.Completed() {
+				return true, record.Result, nil
+			}
+			return false, nil, domain.ErrCommandInFlight
+		}
+		return false, nil, err
+	}
+
+	existing, err := s.Repo.ReserveCommand(ctx, key)
+	if err == nil {
+		switch existing.State {
+		case domain.CommandStateCompleted:
+			return true, existing.Response, nil
+		case domain.CommandStateAborted:
+			return false, nil, nil
+		}
+		return false, nil, domain.ErrCommandInFlight
+	}
+	if !errors.Is(err, shared.ErrNotFound) {
+		return false, nil, err
+	}
+	return false, nil, nil
+}
+
+// complete records the command's outcome against its idempotency key. The
+// command was already accepted, so a failure is logged rather than returned:
+// until the reservation expires a retry is refused with ErrCommandInFlight
+// instead of handling the command twice.
+func (s *Service) complete(ctx context.Context, key shared.IdempotencyKey, response []byte) {
+	if s.Idempotency == nil {
+		return
+	}
+	if err := s.Idempotency.Complete(ctx, idempotencyScope, key, response); err != nil {
+		s.log().Warn("bot command not recorded as handled",
+			zap.String("idempotency_key", string(key)), zap.Error(err))
+	}
+}
+
+// log returns Logger, or a no-op logger when Logger is nil.
+func (s *Service) log() *zap.Logger {
+	if s.Logger == nil {
+		return zap.NewNop()
+	}
+	return s.Logger
+}
+
+func (s *Service) release(ctx context.Context, key shared.IdempotencyKey) {
+	if s.Idempotency != nil {
+		_ = s.Idempotency.Release(ctx, idempotencyScope, key)
+	}
+}
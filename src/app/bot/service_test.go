@@ -0,0 +1,512 @@
+package bot_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/heroiclabs/nakama/v3/src/app/bot"
+	domain "github.com/heroiclabs/nakama/v3/src/domain/bot"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraBot "github.com/heroiclabs/nakama/v3/src/infra/bot"
+	"github.com/heroiclabs/nakama/v3/src/infra/idempotency"
+)
+
+type mockBotRepo struct {
+	saved []*domain.Command
+}
+
+func (m *mockBotRepo) ReserveCommand(ctx context.Context, key shared.IdempotencyKey) (*domain.Command, error) {
+	return nil, errors.New("ReserveCommand should not be called when an idempotency store is configured")
+}
+
+func (m *mockBotRepo) Get(ctx context.Context, id shared.BotCommandID) (*domain.Command, error) {
+	for i := len(m.saved) - 1; i >= 0; i-- {
+		if m.saved[i].ID == id {
+			return m.saved[i], nil
+		}
+	}
+	return nil, domain.ErrCommandNotFound
+}
+
+func (m *mockBotRepo) Save(ctx context.Context, command *domain.Command) error {
+	m.saved = append(m.saved, command)
+	return nil
+}
+
+func (m *mockBotRepo) MarkProcessed(ctx context.Context, id shared.BotCommandID, state domain.CommandState) error {
+	return nil
+}
+
+func (m *mockBotRepo) ListDueForRetry(ctx context.Context, now time.Time, limit int) ([]*domain.Command, error) {
+	return nil, nil
+}
+
+type mockProducer struct {
+	mu        sync.Mutex
+	enqueued  int
+	enqueueFn func() error
+}
+
+func (m *mockProducer) Enqueue(ctx context.Context, command *domain.Command) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.enqueueFn != nil {
+		if err := m.enqueueFn(); err != nil {
+			return err
+		}
+	}
+	m.enqueued++
+	return nil
+}
+
+var input = bot.CommandInput{
+	CommandID:      "command-1",
+	Channel:        "discord",
+	PlayerID:       "player-123",
+	IdempotencyKey: "key-1",
+}
+
+func TestService_Handle_SharedIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	producer := &mockProducer{}
+	service := bot.NewService(&mockBotRepo{}, producer, nil)
+	service.Idempotency = idempotency.NewMemoryStore()
+
+	for i := 0; i < 2; i++ {
+		result, err := service.Handle(ctx, input)
+		if err != nil {
+			t.Fatalf("Handle() call %d error = %v", i, err)
+		}
+		if !result.Accepted {
+			t.Errorf("Handle() call %d not accepted", i)
+		}
+	}
+	if producer.enqueued != 1 {
+		t.Errorf("Expected duplicate command to be enqueued once, got %d", producer.enqueued)
+	}
+}
+
+// failingCompleteStore is a MemoryStore whose Complete always fails.
+type failingCompleteStore struct {
+	*idempotency.MemoryStore
+}
+
+func (s failingCompleteStore) Complete(ctx context.Context, scope string, key shared.IdempotencyKey, result []byte) error {
+	return errors.New("store unavailable")
+}
+
+func TestService_Handle_CompleteFailureLogged(t *testing.T) {
+	ctx := context.Background()
+	producer := &mockProducer{}
+	service := bot.NewService(&mockBotRepo{}, producer, nil)
+	service.Idempotency = failingCompleteStore{idempotency.NewMemoryStore()}
+	core, logs := observer.New(zapcore.WarnLevel)
+	service.Logger = zap.New(core)
+
+	result, err := service.Handle(ctx, input)
+	if err != nil || !result.Accepted {
+		t.Fatalf("Handle() = %+v, %v, want the command accepted", result, err)
+	}
+	if got := logs.FilterMessage("bot command not recorded as handled").Len(); got != 1 {
+		t.Errorf("Expected the Complete failure to be logged once, got %d", got)
+	}
+
+	// The key is still reserved, so a retry is refused rather than enqueued again.
+	if _, err := service.Handle(ctx, input); !errors.Is(err, domain.ErrCommandInFlight) {
+		t.Errorf("Handle() retry error = %v, want ErrCommandInFlight", err)
+	}
+	if producer.enqueued != 1 {
+		t.Errorf("Expected the command to be enqueued once, got %d", producer.enqueued)
+	}
+}
+
+func TestService_Handle_InFlightDuplicate(t *testing.T) {
+	ctx := context.Background()
+	store := idempotency.NewMemoryStore()
+	if _, err := store.Reserve(ctx, "bot", input.IdempotencyKey, shared.DefaultIdempotencyTTL); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+
+	service := bot.NewService(&mockBotRepo{}, &mockProducer{}, nil)
+	service.Idempotency = store
+
+	_, err := service.Handle(ctx, input)
+	if !errors.Is(err, domain.ErrCommandInFlight) || !errors.Is(err, shared.ErrDuplicate) {
+		t.Errorf("Handle() error = %v, want %v", err, domain.ErrCommandInFlight)
+	}
+}
+
+func TestService_Handle_FailureReleasesKey(t *testing.T) {
+	ctx := context.Background()
+	enqueueErr := errors.New("queue unavailable")
+	producer := &mockProducer{enqueueFn: func() error { return enqueueErr }}
+	service := bot.NewService(&mockBotRepo{}, producer, nil)
+	service.Idempotency = idempotency.NewMemoryStore()
+
+	if _, err := service.Handle(ctx, input); !errors.Is(err, enqueueErr) {
+		t.Fatalf("Handle() error = %v, want %v", err, enqueueErr)
+	}
+
+	producer.enqueueFn = nil
+	if _, err := service.Handle(ctx, input); err != nil {
+		t.Fatalf("Handle() retry error = %v", err)
+	}
+	if producer.enqueued != 1 {
+		t.Errorf("Expected retry to enqueue, got %d", producer.enqueued)
+	}
+}
+
+type mockNotifier struct {
+	mu       sync.Mutex
+	notified int
+	err      error
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, playerID shared.PlayerID, payload map[string]any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.notified++
+	return nil
+}
+
+func TestService_Handle_NotifiesOnce(t *testing.T) {
+	ctx := context.Background()
+	notifier := &mockNotifier{}
+	service := bot.NewService(&mockBotRepo{}, &mockProducer{}, notifier)
+	service.Idempotency = idempotency.NewMemoryStore()
+	service.Acks = infraBot.NewMemoryAckStore(infraBot.DefaultAckCapacity)
+
+	for i := 0; i < 3; i++ {
+		if _, err := service.Handle(ctx, input); err != nil {
+			t.Fatalf("Handle() call %d error = %v", i, err)
+		}
+	}
+	if notifier.notified != 1 {
+		t.Errorf("Expected the player to be notified once, got %d", notifier.notified)
+	}
+}
+
+func TestService_Handle_RetriesFailedNotification(t *testing.T) {
+	ctx := context.Background()
+	notifier := &mockNotifier{err: errors.New("discord unavailable")}
+	service := bot.NewService(&mockBotRepo{}, &mockProducer{}, notifier)
+	service.Idempotency = idempotency.NewMemoryStore()
+	service.Acks = infraBot.NewMemoryAckStore(infraBot.DefaultAckCapacity)
+
+	if _, err := service.Handle(ctx, input); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	notifier.err = nil
+	for i := 0; i < 2; i++ {
+		if _, err := service.Handle(ctx, input); err != nil {
+			t.Fatalf("Handle() retry %d error = %v", i, err)
+		}
+	}
+	if notifier.notified != 1 {
+		t.Errorf("Expected the retry to deliver exactly one notification, got %d", notifier.notified)
+	}
+}
+
+func TestService_Complete(t *testing.T) {
+	ctx := context.Background()
+	notifier := &mockNotifier{}
+	repo := &mockBotRepo{}
+	service := bot.NewService(repo, &mockProducer{}, notifier)
+	service.Idempotency = idempotency.NewMemoryStore()
+
+	if _, err := service.Handle(ctx, input); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	notifier.notified = 0
+
+	cmd, err := service.Complete(ctx, input.CommandID, errors.New("discord timeout"))
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if cmd.State != domain.CommandStateFailed || cmd.RetryCount != 1 || cmd.LastError != "discord timeout" {
+		t.Errorf("Complete() with error = %+v, want a failed command", cmd)
+	}
+	if notifier.notified != 0 {
+		t.Errorf("Expected no notification for a failed attempt, got %d", notifier.notified)
+	}
+
+	// A worker repeating the success report notifies the player only once.
+	for i := 0; i < 2; i++ {
+		if cmd, err = service.Complete(ctx, input.CommandID, nil); err != nil {
+			t.Fatalf("Complete() call %d error = %v", i, err)
+		}
+	}
+	if cmd.State != domain.CommandStateCompleted || cmd.LastError != "" {
+		t.Errorf("Complete() = %+v, want a completed command", cmd)
+	}
+	if notifier.notified != 1 {
+		t.Errorf("Expected the player to be notified once, got %d", notifier.notified)
+	}
+
+	status, err := service.GetStatus(ctx, input.CommandID)
+	if err != nil || status.State != domain.CommandStateCompleted {
+		t.Errorf("GetStatus() = %v, %v, want a completed command", status, err)
+	}
+	if _, err := service.Complete(ctx, "command-2", nil); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("Complete() on unknown command error = %v, want %v", err, shared.ErrNotFound)
+	}
+}
+
+func TestService_Complete_NotifyFailureLogged(t *testing.T) {
+	ctx := context.Background()
+	notifier := &mockNotifier{}
+	service := bot.NewService(&mockBotRepo{}, &mockProducer{}, notifier)
+	service.Idempotency = idempotency.NewMemoryStore()
+	core, logs := observer.New(zapcore.WarnLevel)
+	service.Logger = zap.New(core)
+
+	if _, err := service.Handle(ctx, input); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	notifier.err = errors.New("discord unavailable")
+	cmd, err := service.Complete(ctx, input.CommandID, nil)
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if cmd.State != domain.CommandStateCompleted {
+		t.Errorf("Complete() state = %s, want completed", cmd.State)
+	}
+	if got := logs.FilterMessage("bot command completion not notified").Len(); got != 1 {
+		t.Errorf("Expected the failed notification to be logged once, got %d", got)
+	}
+}
+
+func TestService_CommandsDueForRetry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := bot.NewService(infraBot.NewMemoryRepository(), &mockProducer{}, &mockNotifier{})
+	service.Idempotency = idempotency.NewMemoryStore()
+	service.Clock = func() time.Time { return now }
+
+	if _, err := service.Handle(ctx, input); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	// Each failure doubles the wait before the command is due again.
+	for i, wantDelay := range []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second} {
+		cmd, err := service.Complete(ctx, input.CommandID, errors.New("discord timeout"))
+		if err != nil {
+			t.Fatalf("Complete() failure %d error = %v", i, err)
+		}
+		if want := now.Add(wantDelay); !cmd.NextAttemptAt.Equal(want) {
+			t.Errorf("NextAttemptAt after failure %d = %v, want %v", i+1, cmd.NextAttemptAt, want)
+		}
+		if due, _ := service.CommandsDueForRetry(ctx, now, 0); len(due) != 0 {
+			t.Errorf("Expected nothing due before the backoff elapses, got %d commands", len(due))
+		}
+		now = now.Add(wantDelay)
+		due, err := service.CommandsDueForRetry(ctx, now, 0)
+		if err != nil || len(due) != 1 || due[0].ID != input.CommandID {
+			t.Fatalf("CommandsDueForRetry() = %v, %v, want %s", due, err, input.CommandID)
+		}
+	}
+
+	// Failing past MaxRetries dead-letters the command for good.
+	var cmd *domain.Command
+	for i := 0; i < domain.DefaultMaxRetries; i++ {
+		var err error
+		if cmd, err = service.Complete(ctx, input.CommandID, errors.New("discord timeout")); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+	}
+	if cmd.State != domain.CommandStateDeadLettered || cmd.CanRetry() {
+		t.Errorf("Complete() = %+v, want a dead-lettered command", cmd)
+	}
+	if due, _ := service.CommandsDueForRetry(ctx, now.Add(time.Hour), 0); len(due) != 0 {
+		t.Errorf("Expected a dead-lettered command never to be due, got %d commands", len(due))
+	}
+	if cmd, _ = service.Complete(ctx, input.CommandID, nil); cmd.State != domain.CommandStateDeadLettered {
+		t.Errorf("Complete() on a dead-lettered command changed its state to %s", cmd.State)
+	}
+}
+
+func TestCommand_NextRetryAt(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		retries int
+		want    time.Duration
+	}{
+		{retries: 0, want: domain.RetryBaseDelay},
+		{retries: 1, want: domain.RetryBaseDelay},
+		{retries: 2, want: 2 * domain.RetryBaseDelay},
+		{retries: 4, want: 8 * domain.RetryBaseDelay},
+		{retries: 30, want: domain.RetryMaxDelay},
+	}
+	for _, tt := range tests {
+		cmd := &domain.Command{RetryCount: tt.retries}
+		if got := cmd.NextRetryAt(now); !got.Equal(now.Add(tt.want)) {
+			t.Errorf("NextRetryAt() with %d retries = %v, want %v", tt.retries, got.Sub(now), tt.want)
+		}
+	}
+}
+
+type mockExecutor struct {
+	executed int
+	err      error
+}
+
+func (m *mockExecutor) Execute(ctx context.Context, command *domain.Command) ([]byte, error) {
+	m.executed++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []byte(`{"balance":42}`), nil
+}
+
+func TestService_Handle_Sync(t *testing.T) {
+	syncInput := input
+	syncInput.Sync = true
+
+	tests := []struct {
+		name        string
+		idempotency shared.IdempotencyStore
+	}{
+		{name: "idempotency store", idempotency: idempotency.NewMemoryStore()},
+		{name: "repository reservation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			producer := &mockProducer{}
+			executor := &mockExecutor{}
+			repo := infraBot.NewMemoryRepository()
+			service := bot.NewService(repo, producer, nil)
+			service.Idempotency = tt.idempotency
+			service.Executor = executor
+
+			// The duplicate is answered from the first execution.
+			for i := 0; i < 2; i++ {
+				result, err := service.Handle(ctx, syncInput)
+				if err != nil {
+					t.Fatalf("Handle() call %d error = %v", i, err)
+				}
+				if !result.Accepted || string(result.Response) != `{"balance":42}` {
+					t.Errorf("Handle() call %d = %+v, want the executor response", i, result)
+				}
+			}
+			if executor.executed != 1 || producer.enqueued != 0 {
+				t.Errorf("Expected 1 execution and no enqueues, got %d and %d", executor.executed, producer.enqueued)
+			}
+			cmd, err := repo.Get(ctx, input.CommandID)
+			if err != nil || cmd.State != domain.CommandStateCompleted {
+				t.Errorf("Get() = %v, %v, want a completed command", cmd, err)
+			}
+		})
+	}
+}
+
+func TestService_Handle_SyncFailureReleasesKey(t *testing.T) {
+	syncInput := input
+	syncInput.Sync = true
+
+	tests := []struct {
+		name        string
+		idempotency shared.IdempotencyStore
+	}{
+		{name: "idempotency store", idempotency: idempotency.NewMemoryStore()},
+		{name: "repository reservation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			executor := &mockExecutor{err: errors.New("wallet unavailable")}
+			service := bot.NewService(infraBot.NewMemoryRepository(), &mockProducer{}, nil)
+			service.Idempotency = tt.idempotency
+			service.Executor = executor
+
+			if _, err := service.Handle(ctx, syncInput); !errors.Is(err, domain.ErrExecutionFailed) || !errors.Is(err, executor.err) {
+				t.Fatalf("Handle() error = %v, want %v", err, domain.ErrExecutionFailed)
+			}
+			cmd, err := service.GetStatus(ctx, input.CommandID)
+			if err != nil || cmd.State != domain.CommandStateAborted || cmd.LastError != "wallet unavailable" {
+				t.Errorf("GetStatus() = %+v, %v, want an aborted command", cmd, err)
+			}
+			// The caller got the error, so no worker may run the command again.
+			due, err := service.CommandsDueForRetry(ctx, time.Now().Add(time.Hour), 0)
+			if err != nil || len(due) != 0 {
+				t.Errorf("CommandsDueForRetry() = %v, %v, want none", due, err)
+			}
+
+			executor.err = nil
+			result, err := service.Handle(ctx, syncInput)
+			if err != nil || string(result.Response) != `{"balance":42}` {
+				t.Errorf("Handle() retry = %+v, %v, want the executor response", result, err)
+			}
+			if executor.executed != 2 {
+				t.Errorf("Expected 2 executions, got %d", executor.executed)
+			}
+		})
+	}
+}
+
+func TestService_Handle_SyncWithoutExecutor(t *testing.T) {
+	syncInput := input
+	syncInput.Sync = true
+	service := bot.NewService(&mockBotRepo{}, &mockProducer{}, nil)
+	service.Idempotency = idempotency.NewMemoryStore()
+
+	if _, err := service.Handle(context.Background(), syncInput); !errors.Is(err, domain.ErrSyncUnsupported) {
+		t.Errorf("Handle() error = %v, want %v", err, domain.ErrSyncUnsupported)
+	}
+}
+
+func TestService_Drain(t *testing.T) {
+	ctx := context.Background()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	producer := &mockProducer{enqueueFn: func() error {
+		close(started)
+		<-release
+		return nil
+	}}
+	service := bot.NewService(&mockBotRepo{}, producer, nil)
+	service.Idempotency = idempotency.NewMemoryStore()
+
+	if result, err := service.Drain(ctx); err != nil || result != (bot.DrainResult{}) {
+		t.Errorf("Drain() with nothing in flight = %+v, %v, want nothing drained", result, err)
+	}
+
+	handled := make(chan error, 1)
+	go func() {
+		_, err := service.Handle(ctx, input)
+		handled <- err
+	}()
+	<-started
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	result, err := service.Drain(timeoutCtx)
+	if !errors.Is(err, context.DeadlineExceeded) || result != (bot.DrainResult{Abandoned: 1}) {
+		t.Errorf("Drain() past its deadline = %+v, %v, want one abandoned enqueue", result, err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	result, err = service.Drain(ctx)
+	if err != nil || result != (bot.DrainResult{Drained: 1}) {
+		t.Errorf("Drain() = %+v, %v, want one drained enqueue", result, err)
+	}
+	if err := <-handled; err != nil {
+		t.Errorf("Handle() error = %v", err)
+	}
+	if producer.enqueued != 1 {
+		t.Errorf("Expected the command to be enqueued, got %d", producer.enqueued)
+	}
+}
@@ -2,6 +2,8 @@ package groups
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/heroiclabs/nakama/v3/src/domain/group"
@@ -12,6 +14,7 @@ import (
 type Provider interface {
 	CreateGroup(ctx context.Context, payload CreateGroupPayload) (CreateGroupResult, error)
 	UpdateMetadata(ctx context.Context, groupID shared.GroupID, metadata map[string]any) error
+	ListGroups(ctx context.Context, filter ListGroupsFilter) (GroupList, error)
 }
 
 type Repository interface {
@@ -34,11 +37,41 @@ type CreateGroupResult struct {
 	Handle  string
 }
 
+// ListGroupsFilter narrows a Nakama group listing. An empty NamePrefix lists
+// every group; Cursor continues from a previous page.
+type ListGroupsFilter struct {
+	NamePrefix string
+	Limit      int
+	Cursor     string
+}
+
+// Matches reports whether a group name passes the filter.
+func (f ListGroupsFilter) Matches(name string) bool {
+	return strings.HasPrefix(name, f.NamePrefix)
+}
+
+// NakamaGroup is a group as Nakama reports it.
+type NakamaGroup struct {
+	ID        shared.GroupID
+	Name      string
+	CreatorID shared.PlayerID
+	Open      bool
+	EdgeCount int
+}
+
+// GroupList is one page of Nakama groups; Cursor is empty on the last page.
+type GroupList struct {
+	Groups []NakamaGroup
+	Cursor string
+}
+
 // Service coordinates group creation with domain invariants.
 type Service struct {
 	Repo     Repository
 	Provider Provider
 	Clock    func() time.Time
+	// Filter, when set, screens group names before anything is created.
+	Filter shared.ContentFilter
 }
 
 func NewService(repo Repository, provider Provider) *Service {
@@ -56,6 +89,8 @@ type CreateInput struct {
 	Open        bool
 	AvatarURL   string
 	LangTag     string
+	// MaxMembers caps the group's member count, owner included. Zero means unlimited.
+	MaxMembers int
 }
 
 type CreateOutput struct {
@@ -67,6 +102,13 @@ func (s *Service) CreateGroup(ctx context.Context, cmd CreateInput) (CreateOutpu
 	if err := cmd.CreatorID.Validate(); err != nil {
 		return CreateOutput{}, err
 	}
+	// Screen the name before Nakama creates a group we would then reject.
+	if err := shared.CheckContent(s.Filter, "name", cmd.Name); err != nil {
+		return CreateOutput{}, err
+	}
+	if cmd.MaxMembers < 0 {
+		return CreateOutput{}, group.ErrInvalidMaxMembers
+	}
 	now := s.Clock()
 	payload := CreateGroupPayload{
 		Name:        cmd.Name,
@@ -80,7 +122,7 @@ func (s *Service) CreateGroup(ctx context.Context, cmd CreateInput) (CreateOutpu
 	if err != nil {
 		return CreateOutput{}, err
 	}
-	aggregate, err := group.NewGroup(result.GroupID, cmd.Name, cmd.CreatorID, now)
+	aggregate, err := group.NewGroup(result.GroupID, cmd.Name, cmd.CreatorID, cmd.MaxMembers, now, s.Filter)
 	if err != nil {
 		return CreateOutput{}, err
 	}
@@ -90,3 +132,174 @@ func (s *Service) CreateGroup(ctx context.Context, cmd CreateInput) (CreateOutpu
 	}
 	return CreateOutput{GroupID: result.GroupID, Handle: result.Handle}, nil
 }
+
+// JoinGroup adds playerID to the group as a member. The group is saved under
+// its version, so concurrent joins cannot take it past MaxMembers.
+func (s *Service) JoinGroup(ctx context.Context, groupID shared.GroupID, playerID shared.PlayerID) (group.Membership, error) {
+	if err := groupID.Validate(); err != nil {
+		return group.Membership{}, err
+	}
+	var member group.Membership
+	_, err := s.update(ctx, groupID, func(g *group.Group) error {
+		var err error
+		member, err = g.AddMember(playerID, s.Clock())
+		return err
+	})
+	if err != nil {
+		return group.Membership{}, err
+	}
+	return member, nil
+}
+
+// LeaveGroup removes playerID from the group on behalf of callerID, who must
+// be playerID or a group owner or admin; anyone else gets group.ErrNotManager.
+// The owner gets group.ErrOwnerCannotLeave and must transfer ownership first.
+func (s *Service) LeaveGroup(ctx context.Context, groupID shared.GroupID, callerID, playerID shared.PlayerID) error {
+	if err := groupID.Validate(); err != nil {
+		return err
+	}
+	_, err := s.update(ctx, groupID, func(g *group.Group) error {
+		if callerID != playerID {
+			if err := g.CheckManager(callerID); err != nil {
+				return err
+			}
+		}
+		return g.RemoveMember(playerID, s.Clock())
+	})
+	return err
+}
+
+// TransferOwnership hands the group from its current owner to another member,
+// who must already have joined. The previous owner stays on as an admin.
+func (s *Service) TransferOwnership(ctx context.Context, groupID shared.GroupID, from, to shared.PlayerID) error {
+	if err := groupID.Validate(); err != nil {
+		return err
+	}
+	_, err := s.update(ctx, groupID, func(g *group.Group) error {
+		return g.TransferOwnership(from, to, s.Clock())
+	})
+	return err
+}
+
+// maxUpdateAttempts bounds how often update reloads a group that changed
+// between its read and its save.
+const maxUpdateAttempts = 5
+
+// update loads a group, applies mutate and saves it, reloading and applying
+// mutate again when the save loses a race with another writer. It gives up
+// with group.ErrConcurrentModification after maxUpdateAttempts.
+func (s *Service) update(ctx context.Context, id shared.GroupID, mutate func(*group.Group) error) (*group.Group, error) {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		g, err := s.Repo.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := mutate(g); err != nil {
+			return nil, err
+		}
+		err = s.Repo.Save(ctx, g)
+		if err == nil {
+			return g, nil
+		}
+		if !errors.Is(err, group.ErrConcurrentModification) {
+			return nil, err
+		}
+	}
+	return nil, group.ErrConcurrentModification
+}
+
+// UpdateMetadata replaces the group's Nakama metadata on behalf of playerID,
+// who must be the owner or an admin.
+func (s *Service) UpdateMetadata(ctx context.Context, groupID shared.GroupID, playerID shared.PlayerID, metadata map[string]any) error {
+	if err := groupID.Validate(); err != nil {
+		return err
+	}
+	g, err := s.Repo.Get(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if err := g.CheckManager(playerID); err != nil {
+		return err
+	}
+	if err := s.Provider.UpdateMetadata(ctx, groupID, metadata); err != nil {
+		return err
+	}
+	_, err = s.update(ctx, groupID, func(g *group.Group) error {
+		g.UpdatedAt = s.Clock()
+		return nil
+	})
+	return err
+}
+
+// DefaultListGroupsLimit is the page size used when reconciling against Nakama.
+const DefaultListGroupsLimit = 100
+
+// GroupReconciliation compares Nakama's groups with the local repository.
+type GroupReconciliation struct {
+	// Matched holds the IDs present in both stores.
+	Matched []shared.GroupID
+	// MissingLocally holds groups Nakama knows about but the repository does not.
+	MissingLocally []NakamaGroup
+	// MissingInNakama holds local groups Nakama no longer reports.
+	MissingInNakama []*group.Group
+}
+
+// InSync reports whether both stores hold the same groups.
+func (r GroupReconciliation) InSync() bool {
+	return len(r.MissingLocally) == 0 && len(r.MissingInNakama) == 0
+}
+
+// ListFromNakama pages through Nakama's groups and reconciles them against the
+// local repository, flagging groups present in only one of the two. Local groups
+// are narrowed by the same filter so a prefix listing is compared like for like.
+// Nothing is repaired; the result is meant for migrations and debugging.
+func (s *Service) ListFromNakama(ctx context.Context, filter ListGroupsFilter) (GroupReconciliation, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultListGroupsLimit
+	}
+	filter.Cursor = ""
+
+	remote := make(map[shared.GroupID]NakamaGroup)
+	var order []shared.GroupID
+	for {
+		page, err := s.Provider.ListGroups(ctx, filter)
+		if err != nil {
+			return GroupReconciliation{}, err
+		}
+		for _, g := range page.Groups {
+			if _, seen := remote[g.ID]; !seen {
+				order = append(order, g.ID)
+			}
+			remote[g.ID] = g
+		}
+		if page.Cursor == "" || page.Cursor == filter.Cursor {
+			break
+		}
+		filter.Cursor = page.Cursor
+	}
+
+	local, err := s.Repo.List(ctx)
+	if err != nil {
+		return GroupReconciliation{}, err
+	}
+
+	var result GroupReconciliation
+	known := make(map[shared.GroupID]bool, len(local))
+	for _, g := range local {
+		if !filter.Matches(g.Name) {
+			continue
+		}
+		known[g.ID] = true
+		if _, ok := remote[g.ID]; ok {
+			result.Matched = append(result.Matched, g.ID)
+			continue
+		}
+		result.MissingInNakama = append(result.MissingInNakama, g)
+	}
+	for _, id := range order {
+		if !known[id] {
+			result.MissingLocally = append(result.MissingLocally, remote[id])
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,499 @@
+package groups_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/app/groups"
+	"github.com/heroiclabs/nakama/v3/src/domain/group"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraGroup "github.com/heroiclabs/nakama/v3/src/infra/group"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+)
+
+type mockGroupRepo struct {
+	groups  []*group.Group
+	listErr error
+}
+
+func (m *mockGroupRepo) Get(ctx context.Context, id shared.GroupID) (*group.Group, error) {
+	for _, g := range m.groups {
+		if g.ID == id {
+			return g, nil
+		}
+	}
+	return nil, shared.ErrNotFound
+}
+
+func (m *mockGroupRepo) Save(ctx context.Context, g *group.Group) error {
+	m.groups = append(m.groups, g)
+	return nil
+}
+
+func (m *mockGroupRepo) AddMember(ctx context.Context, groupID shared.GroupID, member group.Membership) error {
+	return nil
+}
+
+func (m *mockGroupRepo) List(ctx context.Context) ([]*group.Group, error) {
+	return m.groups, m.listErr
+}
+
+type fakeGroupProvider struct {
+	pages   map[string]groups.GroupList
+	listErr error
+	filters []groups.ListGroupsFilter
+	created []groups.CreateGroupPayload
+	updated []map[string]any
+}
+
+func (p *fakeGroupProvider) CreateGroup(ctx context.Context, payload groups.CreateGroupPayload) (groups.CreateGroupResult, error) {
+	p.created = append(p.created, payload)
+	return groups.CreateGroupResult{GroupID: shared.GroupID("group-" + payload.Name)}, nil
+}
+
+func (p *fakeGroupProvider) UpdateMetadata(ctx context.Context, groupID shared.GroupID, metadata map[string]any) error {
+	p.updated = append(p.updated, metadata)
+	return nil
+}
+
+func (p *fakeGroupProvider) ListGroups(ctx context.Context, filter groups.ListGroupsFilter) (groups.GroupList, error) {
+	p.filters = append(p.filters, filter)
+	if p.listErr != nil {
+		return groups.GroupList{}, p.listErr
+	}
+	return p.pages[filter.Cursor], nil
+}
+
+func newGroup(t *testing.T, id shared.GroupID, name string) *group.Group {
+	t.Helper()
+	g, err := group.NewGroup(id, name, "owner-1", 0, testsupport.DefaultTime, nil)
+	if err != nil {
+		t.Fatalf("NewGroup() error = %v", err)
+	}
+	return g
+}
+
+func groupIDs(list []groups.NakamaGroup) []shared.GroupID {
+	var ids []shared.GroupID
+	for _, g := range list {
+		ids = append(ids, g.ID)
+	}
+	return ids
+}
+
+func TestService_ListFromNakama(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockGroupRepo{groups: []*group.Group{
+		newGroup(t, "group-1", "clan alpha"),
+		newGroup(t, "group-2", "clan beta"),
+		newGroup(t, "group-9", "guild local"),
+	}}
+	provider := &fakeGroupProvider{pages: map[string]groups.GroupList{
+		"": {Groups: []groups.NakamaGroup{
+			{ID: "group-1", Name: "clan alpha"},
+			{ID: "group-3", Name: "clan gamma"},
+		}, Cursor: "page-2"},
+		"page-2": {Groups: []groups.NakamaGroup{
+			{ID: "group-4", Name: "guild remote"},
+		}},
+	}}
+	service := groups.NewService(repo, provider)
+
+	result, err := service.ListFromNakama(ctx, groups.ListGroupsFilter{})
+	if err != nil {
+		t.Fatalf("ListFromNakama() error = %v", err)
+	}
+	if want := []shared.GroupID{"group-1"}; !reflect.DeepEqual(result.Matched, want) {
+		t.Errorf("Matched = %v, want %v", result.Matched, want)
+	}
+	if got, want := groupIDs(result.MissingLocally), []shared.GroupID{"group-3", "group-4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingLocally = %v, want %v", got, want)
+	}
+	if len(result.MissingInNakama) != 2 || result.MissingInNakama[0].ID != "group-2" || result.MissingInNakama[1].ID != "group-9" {
+		t.Errorf("MissingInNakama = %+v, want group-2 and group-9", result.MissingInNakama)
+	}
+	if result.InSync() {
+		t.Error("InSync() = true, want false")
+	}
+	if len(provider.filters) != 2 || provider.filters[0].Limit != groups.DefaultListGroupsLimit {
+		t.Errorf("Expected 2 pages of %d, got %+v", groups.DefaultListGroupsLimit, provider.filters)
+	}
+}
+
+func TestService_ListFromNakama_FiltersLocalGroups(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockGroupRepo{groups: []*group.Group{
+		newGroup(t, "group-1", "clan alpha"),
+		newGroup(t, "group-9", "guild local"),
+	}}
+	provider := &fakeGroupProvider{pages: map[string]groups.GroupList{
+		"": {Groups: []groups.NakamaGroup{{ID: "group-1", Name: "clan alpha"}}},
+	}}
+	service := groups.NewService(repo, provider)
+
+	result, err := service.ListFromNakama(ctx, groups.ListGroupsFilter{NamePrefix: "clan"})
+	if err != nil {
+		t.Fatalf("ListFromNakama() error = %v", err)
+	}
+	if !result.InSync() {
+		t.Errorf("InSync() = false, want true: %+v", result)
+	}
+	if provider.filters[0].NamePrefix != "clan" {
+		t.Errorf("Expected provider filter prefix %q, got %q", "clan", provider.filters[0].NamePrefix)
+	}
+}
+
+func TestService_ListFromNakama_Errors(t *testing.T) {
+	ctx := context.Background()
+	providerErr := errors.New("nakama unavailable")
+	listErr := errors.New("repository unavailable")
+
+	tests := []struct {
+		name     string
+		provider *fakeGroupProvider
+		repo     *mockGroupRepo
+		wantErr  error
+	}{
+		{name: "provider failure", provider: &fakeGroupProvider{listErr: providerErr}, repo: &mockGroupRepo{}, wantErr: providerErr},
+		{name: "repository failure", provider: &fakeGroupProvider{}, repo: &mockGroupRepo{listErr: listErr}, wantErr: listErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := groups.NewService(tt.repo, tt.provider)
+			if _, err := service.ListFromNakama(ctx, groups.ListGroupsFilter{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ListFromNakama() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_CreateGroup_ContentFilter(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		group   string
+		wantErr bool
+	}{
+		{name: "allowed name", group: "Night Owls"},
+		{name: "blocked name", group: "Darn Squad", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockGroupRepo{}
+			provider := &fakeGroupProvider{}
+			service := groups.NewService(repo, provider)
+			service.Clock = testsupport.NewFakeClock().Now
+			service.Filter = shared.NewWordListFilter([]string{"darn"})
+
+			_, err := service.CreateGroup(ctx, groups.CreateInput{CreatorID: "owner-1", Name: tt.group})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateGroup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, shared.ErrContentRejected) {
+					t.Errorf("CreateGroup() error = %v, want %v", err, shared.ErrContentRejected)
+				}
+				if len(provider.created) != 0 || len(repo.groups) != 0 {
+					t.Errorf("Expected nothing created, got %d provider calls and %d saved groups", len(provider.created), len(repo.groups))
+				}
+				return
+			}
+			if len(repo.groups) != 1 || repo.groups[0].Name != tt.group {
+				t.Errorf("Expected group %q saved, got %+v", tt.group, repo.groups)
+			}
+		})
+	}
+}
+
+func TestNewGroup_ContentFilter(t *testing.T) {
+	filter := shared.NewWordListFilter([]string{"darn"})
+	if _, err := group.NewGroup("group-1", "darn it", "owner-1", 0, testsupport.DefaultTime, filter); !errors.Is(err, shared.ErrContentRejected) {
+		t.Errorf("NewGroup() error = %v, want %v", err, shared.ErrContentRejected)
+	}
+	if _, err := group.NewGroup("group-1", "Night Owls", "owner-1", 0, testsupport.DefaultTime, filter); err != nil {
+		t.Errorf("NewGroup() error = %v", err)
+	}
+}
+
+func TestService_JoinLeaveGroup(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockGroupRepo{groups: []*group.Group{newGroup(t, "group-1", "Night Owls")}}
+	service := groups.NewService(repo, &fakeGroupProvider{})
+	service.Clock = testsupport.NewFakeClock().Now
+
+	member, err := service.JoinGroup(ctx, "group-1", "player-1")
+	if err != nil {
+		t.Fatalf("JoinGroup() error = %v", err)
+	}
+	if member.Role != group.RoleMember || !member.JoinedAt.Equal(testsupport.DefaultTime) {
+		t.Errorf("JoinGroup() = %+v, want a member joined at %v", member, testsupport.DefaultTime)
+	}
+	if _, ok := repo.groups[0].Members["player-1"]; !ok {
+		t.Error("Expected player-1 to be stored as a member")
+	}
+	if _, err := service.JoinGroup(ctx, "group-1", "player-3"); err != nil {
+		t.Fatalf("JoinGroup() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		join    bool
+		groupID shared.GroupID
+		caller  shared.PlayerID
+		player  shared.PlayerID
+		wantErr error
+	}{
+		{name: "join twice", join: true, groupID: "group-1", player: "player-1", wantErr: group.ErrAlreadyMember},
+		{name: "join unknown group", join: true, groupID: "group-2", player: "player-2", wantErr: shared.ErrNotFound},
+		{name: "owner leaves", groupID: "group-1", player: "owner-1", wantErr: group.ErrOwnerCannotLeave},
+		{name: "non-member leaves", groupID: "group-1", player: "player-2", wantErr: group.ErrMemberNotFound},
+		{name: "member removes another member", groupID: "group-1", caller: "player-3", player: "player-1", wantErr: group.ErrNotManager},
+		{name: "outsider removes a member", groupID: "group-1", caller: "player-2", player: "player-1", wantErr: group.ErrNotManager},
+		{name: "owner removes a member", groupID: "group-1", caller: "owner-1", player: "player-3"},
+		{name: "member leaves", groupID: "group-1", player: "player-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.join {
+				_, err = service.JoinGroup(ctx, tt.groupID, tt.player)
+			} else {
+				caller := tt.caller
+				if caller == "" {
+					caller = tt.player
+				}
+				err = service.LeaveGroup(ctx, tt.groupID, caller, tt.player)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, ok := repo.groups[0].Members["player-1"]; ok {
+		t.Error("Expected player-1 to be removed")
+	}
+	if _, ok := repo.groups[0].Members["player-3"]; ok {
+		t.Error("Expected player-3 to be removed by the owner")
+	}
+	if _, ok := repo.groups[0].Members["owner-1"]; !ok {
+		t.Error("Expected the owner to stay in the group")
+	}
+}
+
+func TestGroup_TransferOwnership(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    shared.PlayerID
+		to      shared.PlayerID
+		wantErr error
+	}{
+		{name: "owner to member", from: "owner-1", to: "player-1"},
+		{name: "owner to self", from: "owner-1", to: "owner-1"},
+		{name: "from non-owner", from: "player-1", to: "player-2", wantErr: group.ErrNotOwner},
+		{name: "from non-member", from: "player-3", to: "player-1", wantErr: group.ErrMemberNotFound},
+		{name: "to non-member", from: "owner-1", to: "player-3", wantErr: group.ErrMemberNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newGroup(t, "group-1", "Night Owls")
+			for _, id := range []shared.PlayerID{"player-1", "player-2"} {
+				if _, err := g.AddMember(id, testsupport.DefaultTime); err != nil {
+					t.Fatalf("AddMember() error = %v", err)
+				}
+			}
+
+			err := g.TransferOwnership(tt.from, tt.to, testsupport.DefaultTime)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TransferOwnership() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			wantOwner := tt.to
+			if tt.wantErr != nil {
+				wantOwner = "owner-1"
+			}
+			var owners []shared.PlayerID
+			for id, member := range g.Members {
+				if member.Role == group.RoleOwner {
+					owners = append(owners, id)
+				}
+			}
+			if len(owners) != 1 || owners[0] != wantOwner {
+				t.Errorf("owners = %v, want [%s]", owners, wantOwner)
+			}
+			if tt.wantErr == nil && tt.from != tt.to && g.Members[tt.from].Role != group.RoleAdmin {
+				t.Errorf("previous owner role = %s, want %s", g.Members[tt.from].Role, group.RoleAdmin)
+			}
+		})
+	}
+}
+
+func TestGroup_AssignRole_Owner(t *testing.T) {
+	g := newGroup(t, "group-1", "Night Owls")
+	if _, err := g.AddMember("player-1", testsupport.DefaultTime); err != nil {
+		t.Fatalf("AddMember() error = %v", err)
+	}
+	if err := g.AssignRole("player-1", group.RoleOwner, testsupport.DefaultTime); !errors.Is(err, group.ErrOwnerRoleReserved) {
+		t.Errorf("AssignRole(owner) error = %v, want %v", err, group.ErrOwnerRoleReserved)
+	}
+	if err := g.AssignRole("owner-1", group.RoleMember, testsupport.DefaultTime); !errors.Is(err, group.ErrOwnerRoleReserved) {
+		t.Errorf("AssignRole() on the owner error = %v, want %v", err, group.ErrOwnerRoleReserved)
+	}
+	if err := g.AssignRole("player-1", group.RoleAdmin, testsupport.DefaultTime); err != nil {
+		t.Errorf("AssignRole(admin) error = %v", err)
+	}
+}
+
+func TestService_TransferOwnership(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockGroupRepo{groups: []*group.Group{newGroup(t, "group-1", "Night Owls")}}
+	service := groups.NewService(repo, &fakeGroupProvider{})
+	service.Clock = testsupport.NewFakeClock().Now
+
+	if _, err := service.JoinGroup(ctx, "group-1", "player-1"); err != nil {
+		t.Fatalf("JoinGroup() error = %v", err)
+	}
+	if err := service.TransferOwnership(ctx, "group-1", "owner-1", "player-1"); err != nil {
+		t.Fatalf("TransferOwnership() error = %v", err)
+	}
+	// The previous owner may now leave.
+	if err := service.LeaveGroup(ctx, "group-1", "owner-1", "owner-1"); err != nil {
+		t.Errorf("LeaveGroup() after transfer error = %v", err)
+	}
+	if err := service.TransferOwnership(ctx, "group-2", "player-1", "owner-1"); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("TransferOwnership() on unknown group error = %v, want %v", err, shared.ErrNotFound)
+	}
+}
+
+func TestService_UpdateMetadata(t *testing.T) {
+	ctx := context.Background()
+	g := newGroup(t, "group-1", "Night Owls")
+	for _, id := range []shared.PlayerID{"admin-1", "player-1"} {
+		if _, err := g.AddMember(id, testsupport.DefaultTime); err != nil {
+			t.Fatalf("AddMember() error = %v", err)
+		}
+	}
+	if err := g.AssignRole("admin-1", group.RoleAdmin, testsupport.DefaultTime); err != nil {
+		t.Fatalf("AssignRole() error = %v", err)
+	}
+	clock := testsupport.NewFakeClock()
+	provider := &fakeGroupProvider{}
+	service := groups.NewService(&mockGroupRepo{groups: []*group.Group{g}}, provider)
+	service.Clock = clock.Now
+
+	tests := []struct {
+		name    string
+		player  shared.PlayerID
+		wantErr error
+	}{
+		{name: "owner", player: "owner-1"},
+		{name: "admin", player: "admin-1"},
+		{name: "member", player: "player-1", wantErr: group.ErrNotManager},
+		{name: "outsider", player: "player-2", wantErr: group.ErrNotManager},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider.updated = nil
+			clock.Advance(time.Minute)
+			metadata := map[string]any{"banner": "wolf"}
+
+			err := service.UpdateMetadata(ctx, "group-1", tt.player, metadata)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(provider.updated) != 0 {
+					t.Errorf("Expected no provider call, got %v", provider.updated)
+				}
+				return
+			}
+			if !reflect.DeepEqual(provider.updated, []map[string]any{metadata}) {
+				t.Errorf("provider updates = %v, want [%v]", provider.updated, metadata)
+			}
+			if !g.UpdatedAt.Equal(clock.Now()) {
+				t.Errorf("UpdatedAt = %v, want %v", g.UpdatedAt, clock.Now())
+			}
+		})
+	}
+}
+
+func TestService_MaxMembers(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeGroupProvider{}
+	repo := &mockGroupRepo{}
+	service := groups.NewService(repo, provider)
+
+	if _, err := service.CreateGroup(ctx, groups.CreateInput{CreatorID: "owner-1", Name: "Roster", MaxMembers: -1}); !errors.Is(err, group.ErrInvalidMaxMembers) {
+		t.Fatalf("CreateGroup() error = %v, want %v", err, group.ErrInvalidMaxMembers)
+	}
+	if len(provider.created) != 0 {
+		t.Errorf("Expected nothing created in Nakama, got %v", provider.created)
+	}
+
+	out, err := service.CreateGroup(ctx, groups.CreateInput{CreatorID: "owner-1", Name: "Roster", MaxMembers: 2})
+	if err != nil {
+		t.Fatalf("CreateGroup() error = %v", err)
+	}
+	// The owner takes one of the two slots.
+	if _, err := service.JoinGroup(ctx, out.GroupID, "player-1"); err != nil {
+		t.Fatalf("JoinGroup() error = %v", err)
+	}
+	if _, err := service.JoinGroup(ctx, out.GroupID, "player-2"); !errors.Is(err, group.ErrGroupFull) {
+		t.Errorf("JoinGroup() on a full group error = %v, want %v", err, group.ErrGroupFull)
+	}
+	if err := service.LeaveGroup(ctx, out.GroupID, "player-1", "player-1"); err != nil {
+		t.Fatalf("LeaveGroup() error = %v", err)
+	}
+	if _, err := service.JoinGroup(ctx, out.GroupID, "player-2"); err != nil {
+		t.Errorf("JoinGroup() after a slot freed error = %v", err)
+	}
+}
+
+func TestService_JoinGroup_ConcurrentRespectsMaxMembers(t *testing.T) {
+	ctx := context.Background()
+	repo := infraGroup.NewMemoryRepository()
+	g, err := group.NewGroup("group-1", "Roster", "owner-1", 3, testsupport.DefaultTime, nil)
+	if err != nil {
+		t.Fatalf("NewGroup() error = %v", err)
+	}
+	if err := repo.Save(ctx, g); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	service := groups.NewService(repo, &fakeGroupProvider{})
+	service.Clock = testsupport.NewFakeClock().Now
+
+	const players = 8
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		joined int
+	)
+	for i := 0; i < players; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := service.JoinGroup(ctx, "group-1", shared.PlayerID(fmt.Sprintf("player-%d", i)))
+			switch {
+			case err == nil:
+				mu.Lock()
+				joined++
+				mu.Unlock()
+			case !errors.Is(err, group.ErrGroupFull) && !errors.Is(err, group.ErrConcurrentModification):
+				t.Errorf("JoinGroup() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stored, err := repo.Get(ctx, "group-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	// The owner takes one of the three places.
+	if joined > 2 || len(stored.Members) != joined+1 {
+		t.Errorf("joined = %d, members = %d; want at most 2 joins, all stored", joined, len(stored.Members))
+	}
+}
@@ -2,8 +2,12 @@ package leaderboard
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"time"
 
+	"go.uber.org/zap"
+
 	domain "github.com/heroiclabs/nakama/v3/src/domain/leaderboard"
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
 )
@@ -12,16 +16,49 @@ type Repository interface {
 	domain.Repository
 }
 
+// idempotencyScope namespaces score submission keys in a shared IdempotencyStore.
+const idempotencyScope = "leaderboard"
+
+// submissionScope returns the idempotency scope for a season. Keys are
+// deduplicated per season, so a client reusing a key in a new season still
+// gets its submission recorded.
+func submissionScope(season shared.SeasonID) string {
+	return idempotencyScope + "/" + string(season)
+}
+
 // Service coordinates leaderboard submissions.
 type Service struct {
 	Repo  Repository
 	Clock func() time.Time
+	// Pending holds reserved submissions for the two-phase submit flow.
+	Pending domain.PendingRepository
+	// Idempotency, when set, acknowledges a repeated submission key within
+	// the same season without writing the score again.
+	Idempotency    shared.IdempotencyStore
+	IdempotencyTTL time.Duration
+	// Scores, when set, receives submissions instead of Repo. Each submission
+	// reads the stored score, applies its operator, and writes conditionally,
+	// retrying on conflict so a racing submission cannot overwrite a better score.
+	// Operator is the operator for submissions that do not name one.
+	Scores   domain.ScoreStore
+	Operator domain.Operator
+	// MaxSubmitAttempts bounds the conditional writes tried per submission.
+	MaxSubmitAttempts int
+	// Logger, when set, records failures that do not fail the submission.
+	Logger *zap.Logger
 }
 
+// DefaultMaxSubmitAttempts is the number of conditional writes tried before
+// Submit gives up with ErrScoreConflict.
+const DefaultMaxSubmitAttempts = 5
+
 func NewService(repo Repository) *Service {
 	return &Service{
-		Repo:  repo,
-		Clock: func() time.Time { return time.Now().UTC() },
+		Repo:              repo,
+		Clock:             func() time.Time { return time.Now().UTC() },
+		IdempotencyTTL:    shared.DefaultIdempotencyTTL,
+		Operator:          domain.OperatorBest,
+		MaxSubmitAttempts: DefaultMaxSubmitAttempts,
 	}
 }
 
@@ -30,6 +67,8 @@ type SubmitCommand struct {
 	SeasonID       shared.SeasonID
 	Score          int64
 	IdempotencyKey shared.IdempotencyKey
+	// Operator overrides the service's Operator for this submission.
+	Operator domain.Operator
 }
 
 type SubmitResult struct {
@@ -37,18 +76,320 @@ type SubmitResult struct {
 }
 
 func (s *Service) Submit(ctx context.Context, cmd SubmitCommand) (SubmitResult, error) {
+	submission, err := s.newSubmission(cmd)
+	if err != nil {
+		return SubmitResult{}, err
+	}
+	scope := submissionScope(submission.SeasonID)
+	if s.Idempotency != nil {
+		record, err := s.Idempotency.Reserve(ctx, scope, submission.IdempotencyKey, s.IdempotencyTTL)
+		if errors.Is(err, shared.ErrDuplicate) && record.Completed() {
+			return SubmitResult{Acknowledged: true}, nil
+		}
+		if err != nil {
+			return SubmitResult{}, err
+		}
+	}
+	// The season is checked after the idempotency reservation so a retry of
+	// a submission accepted before the season closed is still acknowledged.
+	err = s.checkSeason(ctx, submission.SeasonID, submission.SubmittedAt)
+	if err == nil {
+		err = s.write(ctx, submission)
+	}
+	if err != nil {
+		if s.Idempotency != nil {
+			_ = s.Idempotency.Release(ctx, scope, submission.IdempotencyKey)
+		}
+		return SubmitResult{}, err
+	}
+	if s.Idempotency != nil {
+		// The score is written, so a failure is only logged: until the
+		// reservation expires a retry is refused rather than applied twice.
+		if err := s.Idempotency.Complete(ctx, scope, submission.IdempotencyKey, nil); err != nil {
+			s.log().Warn("score submission not recorded as handled",
+				zap.String("idempotency_key", string(submission.IdempotencyKey)), zap.Error(err))
+		}
+	}
+	return SubmitResult{Acknowledged: true}, nil
+}
+
+// log returns Logger, or a no-op logger when Logger is nil.
+func (s *Service) log() *zap.Logger {
+	if s.Logger == nil {
+		return zap.NewNop()
+	}
+	return s.Logger
+}
+
+// checkSeason refuses a submission to a season that does not exist or is
+// outside its StartsAt/EndsAt window at now.
+func (s *Service) checkSeason(ctx context.Context, id shared.SeasonID, now time.Time) error {
+	season, err := s.Repo.GetSeason(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !season.ActiveAt(now) {
+		return domain.ErrSeasonInactive
+	}
+	return nil
+}
+
+// CreateSeasonCommand schedules a season.
+type CreateSeasonCommand struct {
+	SeasonID shared.SeasonID
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// CreateSeason stores a season that opens at StartsAt and closes at EndsAt.
+func (s *Service) CreateSeason(ctx context.Context, cmd CreateSeasonCommand) (*domain.Season, error) {
+	season, err := domain.NewSeason(cmd.SeasonID, cmd.StartsAt, cmd.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repo.CreateSeason(ctx, season); err != nil {
+		return nil, err
+	}
+	season.Activate(s.Clock())
+	return season, nil
+}
+
+// GetSeason returns a season with Active reflecting the current time.
+func (s *Service) GetSeason(ctx context.Context, id shared.SeasonID) (*domain.Season, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+	season, err := s.Repo.GetSeason(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	season.Activate(s.Clock())
+	return season, nil
+}
+
+// write records a submission, through Scores when configured.
+func (s *Service) write(ctx context.Context, submission domain.ScoreSubmission) error {
+	if s.Scores == nil {
+		return s.Repo.SubmitScore(ctx, submission)
+	}
+	attempts := s.MaxSubmitAttempts
+	if attempts <= 0 {
+		attempts = DefaultMaxSubmitAttempts
+	}
+	for i := 0; i < attempts; i++ {
+		err := s.applyScore(ctx, submission)
+		if !errors.Is(err, domain.ErrScoreConflict) {
+			return err
+		}
+	}
+	return domain.ErrScoreConflict
+}
+
+// applyScore makes one read-apply-write attempt. It skips the write when the
+// operator leaves the stored score unchanged.
+func (s *Service) applyScore(ctx context.Context, submission domain.ScoreSubmission) error {
+	current, err := s.Scores.GetScore(ctx, submission.SeasonID, submission.PlayerID)
+	exists := err == nil
+	if err != nil && !errors.Is(err, domain.ErrScoreNotFound) {
+		return err
+	}
+	value := submission.Operator.Apply(current.Value, exists, submission.Value)
+	if exists && value == current.Value {
+		return nil
+	}
+	return s.Scores.CompareAndSetScore(ctx, domain.PlayerScore{
+		PlayerID:  submission.PlayerID,
+		SeasonID:  submission.SeasonID,
+		Value:     value,
+		UpdatedAt: submission.SubmittedAt,
+	}, current.Version)
+}
+
+// Reset closes a season's current score window at resetAt, such as from the
+// admin season reset endpoint; the closed scores stay visible in all-time
+// listings. It returns the number of scores expired.
+func (s *Service) Reset(ctx context.Context, season shared.SeasonID, resetAt time.Time) (int, error) {
+	if s.Scores == nil {
+		return 0, domain.ErrScoresUnavailable
+	}
+	if err := season.Validate(); err != nil {
+		return 0, err
+	}
+	return s.Scores.ExpireScores(ctx, season, resetAt)
+}
+
+// Record listing limits. ListRecords uses DefaultRecordLimit when the
+// command sets no limit and caps larger limits at MaxRecordLimit.
+const (
+	DefaultRecordLimit = 10
+	MaxRecordLimit     = 100
+)
+
+// ListCommand selects one page of a season's leaderboard.
+type ListCommand struct {
+	SeasonID shared.SeasonID
+	// Window defaults to WindowCurrent. Only Scores keeps closed windows, so
+	// without it the current window is always listed.
+	Window domain.Window
+	Limit  int
+	Cursor string
+}
+
+// ListRecords returns a page of a season's records, best first, read from
+// Scores when configured and from Repo otherwise.
+func (s *Service) ListRecords(ctx context.Context, cmd ListCommand) (domain.RecordList, error) {
+	if err := cmd.SeasonID.Validate(); err != nil {
+		return domain.RecordList{}, err
+	}
+	if cmd.Window == "" {
+		cmd.Window = domain.WindowCurrent
+	}
+	if err := cmd.Window.Validate(); err != nil {
+		return domain.RecordList{}, err
+	}
+	if cmd.Limit <= 0 {
+		cmd.Limit = DefaultRecordLimit
+	}
+	if cmd.Limit > MaxRecordLimit {
+		cmd.Limit = MaxRecordLimit
+	}
+	if s.Scores == nil {
+		return s.Repo.ListRecords(ctx, cmd.SeasonID, cmd.Limit, cmd.Cursor)
+	}
+
+	// Scores has no native paging, so the cursor is the offset of the page.
+	offset := 0
+	if cmd.Cursor != "" {
+		n, err := strconv.Atoi(cmd.Cursor)
+		if err != nil || n < 0 {
+			return domain.RecordList{}, shared.NewValidationError("cursor", "invalid cursor")
+		}
+		offset = n
+	}
+	scores, err := s.Scores.ListScores(ctx, cmd.SeasonID, cmd.Window, offset+cmd.Limit+1)
+	if err != nil {
+		return domain.RecordList{}, err
+	}
+	list := domain.RecordList{Records: []domain.Record{}}
+	for i := offset; i < len(scores) && i < offset+cmd.Limit; i++ {
+		list.Records = append(list.Records, domain.Record{
+			OwnerID:   scores[i].PlayerID,
+			Score:     scores[i].Value,
+			Rank:      int64(i + 1),
+			ExpiresAt: scores[i].ExpiresAt,
+		})
+	}
+	if len(scores) > offset+cmd.Limit {
+		list.NextCursor = strconv.Itoa(offset + cmd.Limit)
+	}
+	return list, nil
+}
+
+// GetPlayerRank returns a player's rank in the season's current window. It
+// returns domain.ErrScoreNotFound when the player has no score.
+func (s *Service) GetPlayerRank(ctx context.Context, season shared.SeasonID, playerID shared.PlayerID) (domain.Rank, error) {
+	if err := season.Validate(); err != nil {
+		return domain.Rank{}, err
+	}
+	if err := playerID.Validate(); err != nil {
+		return domain.Rank{}, err
+	}
+	if s.Scores == nil {
+		return s.Repo.GetPlayerRank(ctx, season, playerID)
+	}
+	scores, err := s.Scores.ListScores(ctx, season, domain.WindowCurrent, 0)
+	if err != nil {
+		return domain.Rank{}, err
+	}
+	for i, score := range scores {
+		if score.PlayerID == playerID {
+			return domain.Rank{OwnerID: playerID, Score: score.Value, Rank: int64(i + 1)}, nil
+		}
+	}
+	return domain.Rank{}, domain.ErrScoreNotFound
+}
+
+// ReserveResult identifies a reserved submission for later confirmation.
+type ReserveResult struct {
+	IdempotencyKey shared.IdempotencyKey
+}
+
+// ReserveSubmission stores a score as pending without writing it to the
+// leaderboard. It counts only once ConfirmSubmission is called.
+func (s *Service) ReserveSubmission(ctx context.Context, cmd SubmitCommand) (ReserveResult, error) {
+	if s.Pending == nil {
+		return ReserveResult{}, domain.ErrReservationsUnavailable
+	}
+	submission, err := s.newSubmission(cmd)
+	if err != nil {
+		return ReserveResult{}, err
+	}
+	if err := s.checkSeason(ctx, submission.SeasonID, submission.SubmittedAt); err != nil {
+		return ReserveResult{}, err
+	}
+	if err := s.Pending.Reserve(ctx, submission); err != nil {
+		return ReserveResult{}, err
+	}
+	return ReserveResult{IdempotencyKey: submission.IdempotencyKey}, nil
+}
+
+// ConfirmSubmission writes a reserved score to the leaderboard and clears the
+// reservation. The reservation is claimed before the write, so concurrent
+// confirmations write the score once; if the write fails it is restored and
+// may be confirmed or rejected again.
+func (s *Service) ConfirmSubmission(ctx context.Context, key shared.IdempotencyKey) (SubmitResult, error) {
+	if s.Pending == nil {
+		return SubmitResult{}, domain.ErrReservationsUnavailable
+	}
+	if err := key.Validate(); err != nil {
+		return SubmitResult{}, err
+	}
+	submission, err := s.Pending.Take(ctx, key)
+	if err != nil {
+		return SubmitResult{}, err
+	}
+	// The season may have closed while the submission awaited confirmation.
+	err = s.checkSeason(ctx, submission.SeasonID, s.Clock())
+	if err == nil {
+		err = s.write(ctx, submission)
+	}
+	if err != nil {
+		if restoreErr := s.Pending.Reserve(ctx, submission); restoreErr != nil {
+			return SubmitResult{}, errors.Join(err, restoreErr)
+		}
+		return SubmitResult{}, err
+	}
+	return SubmitResult{Acknowledged: true}, nil
+}
+
+// RejectSubmission discards a reserved score without writing it.
+func (s *Service) RejectSubmission(ctx context.Context, key shared.IdempotencyKey) error {
+	if s.Pending == nil {
+		return domain.ErrReservationsUnavailable
+	}
+	if err := key.Validate(); err != nil {
+		return err
+	}
+	return s.Pending.Delete(ctx, key)
+}
+
+func (s *Service) newSubmission(cmd SubmitCommand) (domain.ScoreSubmission, error) {
 	submission := domain.ScoreSubmission{
 		PlayerID:       cmd.PlayerID,
 		SeasonID:       cmd.SeasonID,
 		Value:          cmd.Score,
 		IdempotencyKey: cmd.IdempotencyKey,
 		SubmittedAt:    s.Clock(),
+		Operator:       cmd.Operator,
 	}
-	if err := submission.Validate(); err != nil {
-		return SubmitResult{}, err
+	if submission.Operator == "" {
+		submission.Operator = s.Operator
 	}
-	if err := s.Repo.SubmitScore(ctx, submission); err != nil {
-		return SubmitResult{}, err
+	if submission.Operator == "" {
+		submission.Operator = domain.OperatorBest
 	}
-	return SubmitResult{Acknowledged: true}, nil
+	if err := submission.Validate(); err != nil {
+		return domain.ScoreSubmission{}, err
+	}
+	return submission, nil
 }
@@ -0,0 +1,784 @@
+package leaderboard_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/app/leaderboard"
+	domain "github.com/heroiclabs/nakama/v3/src/domain/leaderboard"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/infra/idempotency"
+	infraLeaderboard "github.com/heroiclabs/nakama/v3/src/infra/leaderboard"
+)
+
+type mockLeaderboardRepo struct {
+	submitted []domain.ScoreSubmission
+	seasons   map[shared.SeasonID]domain.Season
+	listFunc  func(ctx context.Context, season shared.SeasonID, limit int, cursor string) (domain.RecordList, error)
+	rankFunc  func(ctx context.Context, season shared.SeasonID, playerID shared.PlayerID) (domain.Rank, error)
+}
+
+func (m *mockLeaderboardRepo) SubmitScore(ctx context.Context, submission domain.ScoreSubmission) error {
+	m.submitted = append(m.submitted, submission)
+	return nil
+}
+
+func (m *mockLeaderboardRepo) CreateSeason(ctx context.Context, season *domain.Season) error {
+	if _, exists := m.seasons[season.ID]; exists {
+		return domain.ErrSeasonAlreadyExists
+	}
+	m.seasons[season.ID] = *season
+	return nil
+}
+
+func (m *mockLeaderboardRepo) GetSeason(ctx context.Context, id shared.SeasonID) (*domain.Season, error) {
+	season, exists := m.seasons[id]
+	if !exists {
+		return nil, domain.ErrSeasonNotFound
+	}
+	return &season, nil
+}
+
+func (m *mockLeaderboardRepo) ListRecords(ctx context.Context, season shared.SeasonID, limit int, cursor string) (domain.RecordList, error) {
+	if m.listFunc != nil {
+		return m.listFunc(ctx, season, limit, cursor)
+	}
+	return domain.RecordList{}, nil
+}
+
+func (m *mockLeaderboardRepo) GetPlayerRank(ctx context.Context, season shared.SeasonID, playerID shared.PlayerID) (domain.Rank, error) {
+	if m.rankFunc != nil {
+		return m.rankFunc(ctx, season, playerID)
+	}
+	return domain.Rank{}, domain.ErrScoreNotFound
+}
+
+// openSeason is the season-1 schedule newTestService stores, open for every
+// clock the tests use.
+var openSeason = domain.Season{
+	ID:       "season-1",
+	StartsAt: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	EndsAt:   time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+func newTestService() (*leaderboard.Service, *mockLeaderboardRepo) {
+	repo := &mockLeaderboardRepo{seasons: map[shared.SeasonID]domain.Season{openSeason.ID: openSeason}}
+	service := leaderboard.NewService(repo)
+	service.Pending = infraLeaderboard.NewMemoryPendingRepository()
+	service.Clock = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	return service, repo
+}
+
+var reserveCmd = leaderboard.SubmitCommand{
+	PlayerID:       "player-123",
+	SeasonID:       "season-1",
+	Score:          900,
+	IdempotencyKey: "submit-1",
+}
+
+func TestService_ReserveThenConfirm(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService()
+
+	reserved, err := service.ReserveSubmission(ctx, reserveCmd)
+	if err != nil {
+		t.Fatalf("ReserveSubmission() error = %v", err)
+	}
+	if len(repo.submitted) != 0 {
+		t.Fatalf("Expected pending score to stay off the leaderboard, got %d submissions", len(repo.submitted))
+	}
+
+	if _, err := service.ReserveSubmission(ctx, reserveCmd); !errors.Is(err, domain.ErrSubmissionAlreadyReserved) {
+		t.Errorf("ReserveSubmission() error = %v, want %v", err, domain.ErrSubmissionAlreadyReserved)
+	}
+
+	result, err := service.ConfirmSubmission(ctx, reserved.IdempotencyKey)
+	if err != nil {
+		t.Fatalf("ConfirmSubmission() error = %v", err)
+	}
+	if !result.Acknowledged {
+		t.Error("Expected confirmation to be acknowledged")
+	}
+	if len(repo.submitted) != 1 || repo.submitted[0].Value != reserveCmd.Score {
+		t.Fatalf("Expected confirmed score to be submitted, got %+v", repo.submitted)
+	}
+
+	if _, err := service.ConfirmSubmission(ctx, reserved.IdempotencyKey); !errors.Is(err, domain.ErrSubmissionNotFound) {
+		t.Errorf("second ConfirmSubmission() error = %v, want %v", err, domain.ErrSubmissionNotFound)
+	}
+}
+
+func TestService_ReserveThenReject(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService()
+
+	reserved, err := service.ReserveSubmission(ctx, reserveCmd)
+	if err != nil {
+		t.Fatalf("ReserveSubmission() error = %v", err)
+	}
+	if err := service.RejectSubmission(ctx, reserved.IdempotencyKey); err != nil {
+		t.Fatalf("RejectSubmission() error = %v", err)
+	}
+	if len(repo.submitted) != 0 {
+		t.Errorf("Expected rejected score not to be submitted, got %d submissions", len(repo.submitted))
+	}
+
+	if _, err := service.ConfirmSubmission(ctx, reserved.IdempotencyKey); !errors.Is(err, domain.ErrSubmissionNotFound) {
+		t.Errorf("ConfirmSubmission() after reject error = %v, want %v", err, domain.ErrSubmissionNotFound)
+	}
+}
+
+func TestService_ReserveSubmission_Unconfigured(t *testing.T) {
+	service := leaderboard.NewService(&mockLeaderboardRepo{})
+
+	if _, err := service.ReserveSubmission(context.Background(), reserveCmd); !errors.Is(err, domain.ErrReservationsUnavailable) {
+		t.Errorf("ReserveSubmission() error = %v, want %v", err, domain.ErrReservationsUnavailable)
+	}
+}
+
+func TestService_Submit_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService()
+	service.Idempotency = idempotency.NewMemoryStore()
+
+	for i := 0; i < 2; i++ {
+		result, err := service.Submit(ctx, reserveCmd)
+		if err != nil {
+			t.Fatalf("Submit() call %d error = %v", i, err)
+		}
+		if !result.Acknowledged {
+			t.Errorf("Submit() call %d not acknowledged", i)
+		}
+	}
+	if len(repo.submitted) != 1 {
+		t.Errorf("Expected duplicate submission to be written once, got %d", len(repo.submitted))
+	}
+}
+
+func TestService_Submit_IdempotencyScopedBySeason(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService()
+	service.Idempotency = idempotency.NewMemoryStore()
+
+	nextSeason := reserveCmd
+	nextSeason.SeasonID = "season-2"
+	repo.seasons["season-2"] = domain.Season{ID: "season-2", StartsAt: openSeason.StartsAt, EndsAt: openSeason.EndsAt}
+
+	for _, cmd := range []leaderboard.SubmitCommand{reserveCmd, nextSeason, reserveCmd, nextSeason} {
+		if _, err := service.Submit(ctx, cmd); err != nil {
+			t.Fatalf("Submit(%s) error = %v", cmd.SeasonID, err)
+		}
+	}
+
+	if len(repo.submitted) != 2 {
+		t.Fatalf("Expected one write per season, got %d", len(repo.submitted))
+	}
+	if repo.submitted[0].SeasonID != "season-1" || repo.submitted[1].SeasonID != "season-2" {
+		t.Errorf("Unexpected submissions %+v", repo.submitted)
+	}
+}
+
+// yieldingScoreStore widens the window between reading and writing a score so
+// concurrent submissions interleave, and counts the conflicts they hit.
+type yieldingScoreStore struct {
+	*infraLeaderboard.MemoryScoreStore
+	conflicts atomic.Int64
+}
+
+func (s *yieldingScoreStore) GetScore(ctx context.Context, season shared.SeasonID, playerID shared.PlayerID) (domain.PlayerScore, error) {
+	score, err := s.MemoryScoreStore.GetScore(ctx, season, playerID)
+	runtime.Gosched()
+	return score, err
+}
+
+func (s *yieldingScoreStore) CompareAndSetScore(ctx context.Context, score domain.PlayerScore, expectedVersion int64) error {
+	err := s.MemoryScoreStore.CompareAndSetScore(ctx, score, expectedVersion)
+	if errors.Is(err, domain.ErrScoreConflict) {
+		s.conflicts.Add(1)
+	}
+	return err
+}
+
+func TestService_Submit_ConcurrentKeepsBest(t *testing.T) {
+	const submitters = 16
+	ctx := context.Background()
+	store := &yieldingScoreStore{MemoryScoreStore: infraLeaderboard.NewMemoryScoreStore(infraLeaderboard.DefaultExpiredCapacity)}
+	service, repo := newTestService()
+	service.Scores = store
+	// Every failed attempt means another submitter's write landed, so one
+	// attempt per submitter is always enough.
+	service.MaxSubmitAttempts = submitters
+
+	var wg sync.WaitGroup
+	errs := make(chan error, submitters)
+	for i := 1; i <= submitters; i++ {
+		wg.Add(1)
+		go func(score int64) {
+			defer wg.Done()
+			_, err := service.Submit(ctx, leaderboard.SubmitCommand{
+				PlayerID:       "player-123",
+				SeasonID:       "season-1",
+				Score:          score * 100,
+				IdempotencyKey: shared.IdempotencyKey(fmt.Sprintf("submit-%d", score)),
+			})
+			errs <- err
+		}(int64(i))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	got, err := store.GetScore(ctx, "season-1", "player-123")
+	if err != nil {
+		t.Fatalf("GetScore() error = %v", err)
+	}
+	if got.Value != submitters*100 {
+		t.Errorf("Expected best score %d to survive, got %d (conflicts retried: %d)", submitters*100, got.Value, store.conflicts.Load())
+	}
+	if len(repo.submitted) != 0 {
+		t.Errorf("Expected submissions to go to the score store, got %d repository writes", len(repo.submitted))
+	}
+}
+
+type conflictingScoreStore struct {
+	attempts int
+}
+
+func (s *conflictingScoreStore) GetScore(ctx context.Context, season shared.SeasonID, playerID shared.PlayerID) (domain.PlayerScore, error) {
+	return domain.PlayerScore{}, domain.ErrScoreNotFound
+}
+
+func (s *conflictingScoreStore) CompareAndSetScore(ctx context.Context, score domain.PlayerScore, expectedVersion int64) error {
+	s.attempts++
+	return domain.ErrScoreConflict
+}
+
+func (s *conflictingScoreStore) ExpireScores(ctx context.Context, season shared.SeasonID, boundary time.Time) (int, error) {
+	return 0, nil
+}
+
+func (s *conflictingScoreStore) ListScores(ctx context.Context, season shared.SeasonID, window domain.Window, limit int) ([]domain.PlayerScore, error) {
+	return nil, nil
+}
+
+func TestService_Submit_ConflictRetriesExhausted(t *testing.T) {
+	store := &conflictingScoreStore{}
+	service, _ := newTestService()
+	service.Scores = store
+	service.MaxSubmitAttempts = 3
+
+	if _, err := service.Submit(context.Background(), reserveCmd); !errors.Is(err, domain.ErrScoreConflict) {
+		t.Errorf("Submit() error = %v, wantErr %v", err, domain.ErrScoreConflict)
+	}
+	if store.attempts != 3 {
+		t.Errorf("Expected 3 conditional writes, got %d", store.attempts)
+	}
+}
+
+func TestService_Submit_CommandOperator(t *testing.T) {
+	tests := []struct {
+		name      string
+		defaultOp domain.Operator
+		submits   []leaderboard.SubmitCommand
+		want      int64
+	}{
+		{
+			name:      "command overrides service default",
+			defaultOp: domain.OperatorBest,
+			submits:   []leaderboard.SubmitCommand{{Score: 500}, {Score: 200, Operator: domain.OperatorIncrement}},
+			want:      700,
+		},
+		{
+			name:    "empty operator means best",
+			submits: []leaderboard.SubmitCommand{{Score: 500}, {Score: 200}},
+			want:    500,
+		},
+		{
+			name:    "decrement subtracts",
+			submits: []leaderboard.SubmitCommand{{Score: 500, Operator: domain.OperatorSet}, {Score: 200, Operator: domain.OperatorDecrement}},
+			want:    300,
+		},
+		{
+			name:    "decrement stops at zero",
+			submits: []leaderboard.SubmitCommand{{Score: 100, Operator: domain.OperatorSet}, {Score: 300, Operator: domain.OperatorDecrement}},
+			want:    0,
+		},
+		{
+			name:    "first decrement stores zero",
+			submits: []leaderboard.SubmitCommand{{Score: 300, Operator: domain.OperatorDecrement}},
+			want:    0,
+		},
+		{
+			name:    "tournament spelling",
+			submits: []leaderboard.SubmitCommand{{Score: 500, Operator: "set"}, {Score: 200, Operator: "incr"}, {Score: 50, Operator: "decr"}},
+			want:    650,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := infraLeaderboard.NewMemoryScoreStore(infraLeaderboard.DefaultExpiredCapacity)
+			service, _ := newTestService()
+			service.Scores = store
+			service.Operator = tt.defaultOp
+
+			for i, cmd := range tt.submits {
+				cmd.PlayerID = reserveCmd.PlayerID
+				cmd.SeasonID = reserveCmd.SeasonID
+				cmd.IdempotencyKey = shared.IdempotencyKey(fmt.Sprintf("submit-%d", i))
+				if _, err := service.Submit(ctx, cmd); err != nil {
+					t.Fatalf("Submit() error = %v", err)
+				}
+			}
+			got, err := store.GetScore(ctx, reserveCmd.SeasonID, reserveCmd.PlayerID)
+			if err != nil {
+				t.Fatalf("GetScore() error = %v", err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("Expected score %d, got %d", tt.want, got.Value)
+			}
+		})
+	}
+}
+
+func TestService_Submit_OperatorValidation(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService()
+
+	cmd := reserveCmd
+	cmd.Operator = "max"
+	_, err := service.Submit(ctx, cmd)
+	if verr, ok := shared.AsValidationError(err); !ok || verr.Field != "operator" {
+		t.Fatalf("Submit() error = %v, want operator validation error", err)
+	}
+
+	if _, err := service.Submit(ctx, reserveCmd); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if len(repo.submitted) != 1 || repo.submitted[0].Operator != domain.OperatorBest {
+		t.Errorf("Expected the repository to receive operator %q, got %+v", domain.OperatorBest, repo.submitted)
+	}
+}
+
+func TestService_Submit_Operators(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator domain.Operator
+		scores   []int64
+		want     int64
+	}{
+		{name: "best keeps highest", operator: domain.OperatorBest, scores: []int64{500, 900, 700}, want: 900},
+		{name: "set keeps latest", operator: domain.OperatorSet, scores: []int64{500, 900, 700}, want: 700},
+		{name: "increment sums", operator: domain.OperatorIncrement, scores: []int64{500, 900, 700}, want: 2100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := infraLeaderboard.NewMemoryScoreStore(infraLeaderboard.DefaultExpiredCapacity)
+			service, _ := newTestService()
+			service.Scores = store
+			service.Operator = tt.operator
+
+			for i, score := range tt.scores {
+				cmd := reserveCmd
+				cmd.Score = score
+				cmd.IdempotencyKey = shared.IdempotencyKey(fmt.Sprintf("submit-%d", i))
+				if _, err := service.Submit(ctx, cmd); err != nil {
+					t.Fatalf("Submit() error = %v", err)
+				}
+			}
+			got, err := store.GetScore(ctx, reserveCmd.SeasonID, reserveCmd.PlayerID)
+			if err != nil {
+				t.Fatalf("GetScore() error = %v", err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("Expected score %d, got %d", tt.want, got.Value)
+			}
+		})
+	}
+}
+
+func TestService_ListRecords_Windows(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService()
+	service.Scores = infraLeaderboard.NewMemoryScoreStore(infraLeaderboard.DefaultExpiredCapacity)
+	service.Operator = domain.OperatorSet
+
+	firstReset := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	secondReset := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	submit := func(player shared.PlayerID, score int64, key string) {
+		t.Helper()
+		cmd := leaderboard.SubmitCommand{PlayerID: player, SeasonID: "season-1", Score: score, IdempotencyKey: shared.IdempotencyKey(key)}
+		if _, err := service.Submit(ctx, cmd); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	submit("player-1", 900, "w1-p1")
+	submit("player-2", 400, "w1-p2")
+	if n, err := service.Reset(ctx, "season-1", firstReset); err != nil || n != 2 {
+		t.Fatalf("Reset() = %d, %v, want 2, nil", n, err)
+	}
+	submit("player-1", 300, "w2-p1")
+	if n, err := service.Reset(ctx, "season-1", secondReset); err != nil || n != 1 {
+		t.Fatalf("Reset() = %d, %v, want 1, nil", n, err)
+	}
+	submit("player-2", 500, "w3-p2")
+
+	tests := []struct {
+		name   string
+		window domain.Window
+		want   []int64
+	}{
+		{name: "default is current window", want: []int64{500}},
+		{name: "current window", window: domain.WindowCurrent, want: []int64{500}},
+		{name: "all time", window: domain.WindowAllTime, want: []int64{900, 500, 400, 300}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := service.ListRecords(ctx, leaderboard.ListCommand{SeasonID: "season-1", Window: tt.window})
+			if err != nil {
+				t.Fatalf("ListRecords() error = %v", err)
+			}
+			var got []int64
+			for _, record := range list.Records {
+				got = append(got, record.Score)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ListRecords() scores = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	list, _ := service.ListRecords(ctx, leaderboard.ListCommand{SeasonID: "season-1", Window: domain.WindowAllTime})
+	for _, record := range list.Records {
+		var want time.Time
+		switch record.Score {
+		case 900, 400:
+			want = firstReset
+		case 300:
+			want = secondReset
+		}
+		if !record.ExpiresAt.Equal(want) {
+			t.Errorf("score %d ExpiresAt = %v, want %v", record.Score, record.ExpiresAt, want)
+		}
+	}
+}
+
+func TestService_ListRecords_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		cmd   leaderboard.ListCommand
+		field string
+	}{
+		{name: "missing season", cmd: leaderboard.ListCommand{}, field: "season_id"},
+		{name: "unknown window", cmd: leaderboard.ListCommand{SeasonID: "season-1", Window: "weekly"}, field: "window"},
+		{name: "malformed cursor", cmd: leaderboard.ListCommand{SeasonID: "season-1", Cursor: "abc"}, field: "cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService()
+			service.Scores = infraLeaderboard.NewMemoryScoreStore(infraLeaderboard.DefaultExpiredCapacity)
+			_, err := service.ListRecords(context.Background(), tt.cmd)
+			verr, ok := shared.AsValidationError(err)
+			if !ok {
+				t.Fatalf("ListRecords() error = %v, want validation error", err)
+			}
+			if verr.Field != tt.field {
+				t.Errorf("ListRecords() field = %q, want %q", verr.Field, tt.field)
+			}
+		})
+	}
+}
+
+func TestService_ListRecords_Pagination(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService()
+	service.Scores = infraLeaderboard.NewMemoryScoreStore(infraLeaderboard.DefaultExpiredCapacity)
+	for i, score := range []int64{100, 500, 300, 400, 200} {
+		cmd := leaderboard.SubmitCommand{
+			PlayerID:       shared.PlayerID(fmt.Sprintf("player-%d", i)),
+			SeasonID:       "season-1",
+			Score:          score,
+			IdempotencyKey: shared.IdempotencyKey(fmt.Sprintf("submit-%d", i)),
+		}
+		if _, err := service.Submit(ctx, cmd); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	var pages [][]string
+	cursor := ""
+	for {
+		list, err := service.ListRecords(ctx, leaderboard.ListCommand{SeasonID: "season-1", Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("ListRecords() error = %v", err)
+		}
+		var page []string
+		for _, record := range list.Records {
+			page = append(page, fmt.Sprintf("%d:%s:%d", record.Rank, record.OwnerID, record.Score))
+		}
+		pages = append(pages, page)
+		if list.NextCursor == "" {
+			break
+		}
+		cursor = list.NextCursor
+	}
+
+	want := [][]string{
+		{"1:player-1:500", "2:player-3:400"},
+		{"3:player-2:300", "4:player-4:200"},
+		{"5:player-0:100"},
+	}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("ListRecords() pages = %v, want %v", pages, want)
+	}
+}
+
+func TestService_ListRecords_Repo(t *testing.T) {
+	service, repo := newTestService()
+	var gotLimit int
+	var gotCursor string
+	repo.listFunc = func(ctx context.Context, season shared.SeasonID, limit int, cursor string) (domain.RecordList, error) {
+		gotLimit, gotCursor = limit, cursor
+		return domain.RecordList{Records: []domain.Record{{OwnerID: "player-1", Score: 900, Rank: 1}}, NextCursor: "next"}, nil
+	}
+
+	list, err := service.ListRecords(context.Background(), leaderboard.ListCommand{SeasonID: "season-1", Limit: 1000, Cursor: "page-2"})
+	if err != nil {
+		t.Fatalf("ListRecords() error = %v", err)
+	}
+	if gotLimit != leaderboard.MaxRecordLimit || gotCursor != "page-2" {
+		t.Errorf("Repo.ListRecords() limit = %d cursor = %q, want %d %q", gotLimit, gotCursor, leaderboard.MaxRecordLimit, "page-2")
+	}
+	if len(list.Records) != 1 || list.NextCursor != "next" {
+		t.Errorf("ListRecords() = %+v, want the repository page", list)
+	}
+}
+
+func TestService_GetPlayerRank(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService()
+	service.Scores = infraLeaderboard.NewMemoryScoreStore(infraLeaderboard.DefaultExpiredCapacity)
+	for i, score := range []int64{300, 900, 600} {
+		cmd := leaderboard.SubmitCommand{
+			PlayerID:       shared.PlayerID(fmt.Sprintf("player-%d", i)),
+			SeasonID:       "season-1",
+			Score:          score,
+			IdempotencyKey: shared.IdempotencyKey(fmt.Sprintf("submit-%d", i)),
+		}
+		if _, err := service.Submit(ctx, cmd); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		playerID shared.PlayerID
+		want     domain.Rank
+		wantErr  error
+	}{
+		{name: "top", playerID: "player-1", want: domain.Rank{OwnerID: "player-1", Score: 900, Rank: 1}},
+		{name: "last", playerID: "player-0", want: domain.Rank{OwnerID: "player-0", Score: 300, Rank: 3}},
+		{name: "no score", playerID: "player-9", wantErr: domain.ErrScoreNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.GetPlayerRank(ctx, "season-1", tt.playerID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetPlayerRank() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPlayerRank() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetPlayerRank() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Submit_SeasonWindow(t *testing.T) {
+	ctx := context.Background()
+	startsAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(7 * 24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		season  shared.SeasonID
+		now     time.Time
+		wantErr error
+	}{
+		{name: "before start", season: "season-1", now: startsAt.Add(-time.Second), wantErr: domain.ErrSeasonInactive},
+		{name: "at start", season: "season-1", now: startsAt},
+		{name: "at end", season: "season-1", now: endsAt, wantErr: domain.ErrSeasonInactive},
+		{name: "unknown season", season: "season-2", now: startsAt, wantErr: domain.ErrSeasonNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService()
+			delete(repo.seasons, "season-1")
+			service.Clock = func() time.Time { return tt.now }
+			if _, err := service.CreateSeason(ctx, leaderboard.CreateSeasonCommand{SeasonID: "season-1", StartsAt: startsAt, EndsAt: endsAt}); err != nil {
+				t.Fatalf("CreateSeason() error = %v", err)
+			}
+
+			cmd := reserveCmd
+			cmd.SeasonID = tt.season
+			_, err := service.Submit(ctx, cmd)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Submit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if written := len(repo.submitted) == 1; written != (tt.wantErr == nil) {
+				t.Errorf("Expected submission written = %v, got %d submissions", tt.wantErr == nil, len(repo.submitted))
+			}
+			if _, err := service.ReserveSubmission(ctx, cmd); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReserveSubmission() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_CreateSeason(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService()
+	delete(repo.seasons, "season-1")
+	now := service.Clock()
+
+	tests := []struct {
+		name      string
+		cmd       leaderboard.CreateSeasonCommand
+		wantErr   error
+		wantField string
+	}{
+		{name: "active season", cmd: leaderboard.CreateSeasonCommand{SeasonID: "season-1", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}},
+		{name: "duplicate", cmd: leaderboard.CreateSeasonCommand{SeasonID: "season-1", StartsAt: now, EndsAt: now.Add(time.Hour)}, wantErr: domain.ErrSeasonAlreadyExists},
+		{name: "missing id", cmd: leaderboard.CreateSeasonCommand{StartsAt: now, EndsAt: now.Add(time.Hour)}, wantField: "season_id"},
+		{name: "missing start", cmd: leaderboard.CreateSeasonCommand{SeasonID: "season-2", EndsAt: now}, wantField: "starts_at"},
+		{name: "ends before start", cmd: leaderboard.CreateSeasonCommand{SeasonID: "season-2", StartsAt: now, EndsAt: now}, wantField: "ends_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			season, err := service.CreateSeason(ctx, tt.cmd)
+			if tt.wantField != "" {
+				verr, ok := shared.AsValidationError(err)
+				if !ok || verr.Field != tt.wantField {
+					t.Fatalf("CreateSeason() error = %v, want validation error on %q", err, tt.wantField)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateSeason() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !season.Active {
+				t.Errorf("Expected season %q to be active", season.ID)
+			}
+		})
+	}
+
+	service.Clock = func() time.Time { return now.Add(2 * time.Hour) }
+	season, err := service.GetSeason(ctx, "season-1")
+	if err != nil {
+		t.Fatalf("GetSeason() error = %v", err)
+	}
+	if season.Active {
+		t.Error("Expected season to have closed")
+	}
+	if _, err := service.GetSeason(ctx, "missing"); !errors.Is(err, domain.ErrSeasonNotFound) {
+		t.Errorf("GetSeason() error = %v, wantErr %v", err, domain.ErrSeasonNotFound)
+	}
+}
+
+func TestService_ConfirmSubmission_SeasonClosed(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newTestService()
+	reservedAt := service.Clock()
+	repo.seasons["season-1"] = domain.Season{ID: "season-1", StartsAt: reservedAt.Add(-time.Hour), EndsAt: reservedAt.Add(time.Hour)}
+
+	if _, err := service.ReserveSubmission(ctx, reserveCmd); err != nil {
+		t.Fatalf("ReserveSubmission() error = %v", err)
+	}
+	service.Clock = func() time.Time { return reservedAt.Add(2 * time.Hour) }
+	if _, err := service.ConfirmSubmission(ctx, reserveCmd.IdempotencyKey); !errors.Is(err, domain.ErrSeasonInactive) {
+		t.Fatalf("ConfirmSubmission() error = %v, wantErr %v", err, domain.ErrSeasonInactive)
+	}
+	if len(repo.submitted) != 0 {
+		t.Errorf("Expected no score written after the season closed, got %d", len(repo.submitted))
+	}
+}
+
+func TestService_ConfirmSubmission_ConcurrentWritesOnce(t *testing.T) {
+	const confirmers = 8
+	ctx := context.Background()
+	store := &yieldingScoreStore{MemoryScoreStore: infraLeaderboard.NewMemoryScoreStore(infraLeaderboard.DefaultExpiredCapacity)}
+	service, _ := newTestService()
+	service.Scores = store
+
+	cmd := reserveCmd
+	cmd.Operator = domain.OperatorIncrement
+	if _, err := service.ReserveSubmission(ctx, cmd); err != nil {
+		t.Fatalf("ReserveSubmission() error = %v", err)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		confirmed atomic.Int32
+	)
+	for i := 0; i < confirmers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.ConfirmSubmission(ctx, cmd.IdempotencyKey)
+			switch {
+			case err == nil:
+				confirmed.Add(1)
+			case !errors.Is(err, domain.ErrSubmissionNotFound):
+				t.Errorf("ConfirmSubmission() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if confirmed.Load() != 1 {
+		t.Errorf("Expected exactly one confirmation to succeed, got %d", confirmed.Load())
+	}
+	got, err := store.GetScore(ctx, "season-1", "player-123")
+	if err != nil {
+		t.Fatalf("GetScore() error = %v", err)
+	}
+	if got.Value != cmd.Score {
+		t.Errorf("Expected the incremented score to be written once (%d), got %d", cmd.Score, got.Value)
+	}
+}
+
+func TestService_ConfirmSubmission_FailedWriteRestoresReservation(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService()
+	service.Scores = &conflictingScoreStore{}
+
+	if _, err := service.ReserveSubmission(ctx, reserveCmd); err != nil {
+		t.Fatalf("ReserveSubmission() error = %v", err)
+	}
+	if _, err := service.ConfirmSubmission(ctx, reserveCmd.IdempotencyKey); !errors.Is(err, domain.ErrScoreConflict) {
+		t.Fatalf("ConfirmSubmission() error = %v, wantErr %v", err, domain.ErrScoreConflict)
+	}
+	if err := service.RejectSubmission(ctx, reserveCmd.IdempotencyKey); err != nil {
+		t.Errorf("RejectSubmission() after a failed confirm error = %v, want the reservation restored", err)
+	}
+}
@@ -0,0 +1,156 @@
+package tournaments
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+)
+
+// reconcilePageSize is how many local tournaments Reconcile reads at a time.
+const reconcilePageSize = 100
+
+// ReconcileAction names what Reconcile did, or would do, about a divergence.
+type ReconcileAction string
+
+const (
+	// ReconcileRecreated is a live local tournament missing from Nakama that
+	// was created there again.
+	ReconcileRecreated ReconcileAction = "recreated"
+	// ReconcileOrphaned is a local tournament Nakama no longer has that was
+	// left in place for an operator to review.
+	ReconcileOrphaned ReconcileAction = "orphaned"
+	// ReconcileRemoved is a local tournament Nakama no longer has that was
+	// deleted along with its participants.
+	ReconcileRemoved ReconcileAction = "removed"
+	// ReconcileDeleted is a cancelled local tournament Nakama still had that
+	// was deleted there.
+	ReconcileDeleted ReconcileAction = "deleted"
+)
+
+// ReconcileCommand controls how Reconcile resolves divergences.
+type ReconcileCommand struct {
+	// DryRun reports the actions that would be taken without changing either side.
+	DryRun bool
+	// RemoveOrphans deletes local tournaments that are missing from Nakama
+	// and cannot be recreated, instead of only flagging them.
+	RemoveOrphans bool
+}
+
+// ReconcileEntry is the outcome for a single divergent tournament.
+type ReconcileEntry struct {
+	TournamentID shared.TournamentID
+	Action       ReconcileAction
+	Err          error
+}
+
+// ReconcileReport lists every divergence Reconcile found, in tournament ID order.
+type ReconcileReport struct {
+	DryRun  bool
+	Entries []ReconcileEntry
+}
+
+// Failed returns the entries whose action could not be applied.
+func (r ReconcileReport) Failed() []ReconcileEntry {
+	var failed []ReconcileEntry
+	for _, entry := range r.Entries {
+		if entry.Err != nil {
+			failed = append(failed, entry)
+		}
+	}
+	return failed
+}
+
+// Reconcile compares local tournaments with the ones Nakama has and repairs
+// the difference. Live local tournaments missing from Nakama are recreated
+// there. Local tournaments that have ended, or whose end time has passed,
+// cannot be recreated and are flagged as orphaned, or removed when
+// cmd.RemoveOrphans is set. Cancelled tournaments are meant to be gone from
+// Nakama, so any Nakama still has are deleted there. Failures on individual
+// tournaments are recorded
+// in the report; the returned error covers only failures to list either side.
+func (s *Service) Reconcile(ctx context.Context, cmd ReconcileCommand) (ReconcileReport, error) {
+	remoteIDs, err := s.Provider.ListTournamentIDs(ctx)
+	if err != nil {
+		return ReconcileReport{}, err
+	}
+	remote := make(map[shared.TournamentID]struct{}, len(remoteIDs))
+	for _, id := range remoteIDs {
+		remote[id] = struct{}{}
+	}
+
+	local, err := s.listAllTournaments(ctx)
+	if err != nil {
+		return ReconcileReport{}, err
+	}
+
+	now := s.Clock()
+	report := ReconcileReport{DryRun: cmd.DryRun}
+	for _, t := range local {
+		_, onRemote := remote[t.ID]
+		if t.State == tournament.StateCancelled {
+			if onRemote {
+				entry := ReconcileEntry{TournamentID: t.ID, Action: ReconcileDeleted}
+				if !cmd.DryRun {
+					entry.Err = s.Provider.DeleteTournament(ctx, t.ID)
+				}
+				report.Entries = append(report.Entries, entry)
+			}
+			continue
+		}
+		if onRemote {
+			continue
+		}
+		entry := ReconcileEntry{TournamentID: t.ID, Action: ReconcileOrphaned}
+		switch {
+		case isLive(t, now):
+			entry.Action = ReconcileRecreated
+			if !cmd.DryRun {
+				entry.Err = s.Provider.CreateTournament(ctx, createParams(t))
+			}
+		case cmd.RemoveOrphans:
+			entry.Action = ReconcileRemoved
+			if !cmd.DryRun {
+				entry.Err = s.removeLocal(ctx, t.ID)
+			}
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		return report.Entries[i].TournamentID < report.Entries[j].TournamentID
+	})
+	return report, nil
+}
+
+// isLive reports whether a tournament is one Nakama should still be running.
+func isLive(t *tournament.Tournament, now time.Time) bool {
+	if !t.IsActive() {
+		return false
+	}
+	end, ok := t.CalculateEndTime()
+	return !ok || end.After(now)
+}
+
+func (s *Service) listAllTournaments(ctx context.Context) ([]*tournament.Tournament, error) {
+	var all []*tournament.Tournament
+	for offset := 0; ; offset += reconcilePageSize {
+		page, err := s.Repo.List(ctx, tournament.ListFilter{IncludeCancelled: true}, reconcilePageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < reconcilePageSize {
+			return all, nil
+		}
+	}
+}
+
+func (s *Service) removeLocal(ctx context.Context, id shared.TournamentID) error {
+	if err := s.Repo.Delete(ctx, id); err != nil && !errors.Is(err, tournament.ErrTournamentNotFound) {
+		return err
+	}
+	return s.Participants.DeleteByTournament(ctx, id)
+}
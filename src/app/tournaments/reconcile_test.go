@@ -0,0 +1,168 @@
+package tournaments_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/app/tournaments"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+	infraTournament "github.com/heroiclabs/nakama/v3/src/infra/tournament"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+)
+
+// reconcileFixture holds one tournament per divergence case: "synced" exists
+// on both sides, "missing" is live but absent from Nakama, "ended" and
+// "expired" are absent from Nakama and can no longer run there.
+type reconcileFixture struct {
+	service      *tournaments.Service
+	repo         *infraTournament.MemoryRepository
+	participants *infraTournament.MemoryParticipantRepository
+	provider     *testsupport.FakeNakamaProvider
+}
+
+func newReconcileFixture(t *testing.T) reconcileFixture {
+	t.Helper()
+	ctx := context.Background()
+	f := reconcileFixture{
+		repo:         infraTournament.NewMemoryRepository(),
+		participants: infraTournament.NewMemoryParticipantRepository(),
+		provider:     testsupport.NewFakeNakamaProvider(),
+	}
+	f.service = tournaments.NewService(f.repo, f.participants, f.provider)
+	f.service.Clock = func() time.Time { return testsupport.DefaultTime.Add(2 * time.Hour) }
+
+	testsupport.SaveTournament(t, f.repo, "synced")
+	if err := f.provider.CreateTournament(ctx, tournaments.CreateTournamentParams{ID: "synced"}); err != nil {
+		t.Fatalf("CreateTournament() error = %v", err)
+	}
+	testsupport.SaveTournament(t, f.repo, "missing", testsupport.WithTitle("Missing Cup"))
+	testsupport.SaveTournament(t, f.repo, "ended", func(tt *tournament.Tournament) {
+		tt.State = tournament.StateEnded
+	})
+	testsupport.SaveTournament(t, f.repo, "expired", func(tt *tournament.Tournament) {
+		tt.Duration = time.Hour
+	})
+
+	participant, err := tournament.NewParticipant("ended", "player-1", testsupport.DefaultTime)
+	if err != nil {
+		t.Fatalf("NewParticipant() error = %v", err)
+	}
+	if err := f.participants.Save(ctx, participant); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	return f
+}
+
+func TestService_Reconcile(t *testing.T) {
+	tests := []struct {
+		name          string
+		cmd           tournaments.ReconcileCommand
+		wantEntries   []tournaments.ReconcileEntry
+		wantRecreated bool
+		wantRemoved   bool
+	}{
+		{
+			name: "flags orphans by default",
+			cmd:  tournaments.ReconcileCommand{},
+			wantEntries: []tournaments.ReconcileEntry{
+				{TournamentID: "ended", Action: tournaments.ReconcileOrphaned},
+				{TournamentID: "expired", Action: tournaments.ReconcileOrphaned},
+				{TournamentID: "missing", Action: tournaments.ReconcileRecreated},
+			},
+			wantRecreated: true,
+		},
+		{
+			name: "removes orphans",
+			cmd:  tournaments.ReconcileCommand{RemoveOrphans: true},
+			wantEntries: []tournaments.ReconcileEntry{
+				{TournamentID: "ended", Action: tournaments.ReconcileRemoved},
+				{TournamentID: "expired", Action: tournaments.ReconcileRemoved},
+				{TournamentID: "missing", Action: tournaments.ReconcileRecreated},
+			},
+			wantRecreated: true,
+			wantRemoved:   true,
+		},
+		{
+			name: "dry run changes nothing",
+			cmd:  tournaments.ReconcileCommand{DryRun: true, RemoveOrphans: true},
+			wantEntries: []tournaments.ReconcileEntry{
+				{TournamentID: "ended", Action: tournaments.ReconcileRemoved},
+				{TournamentID: "expired", Action: tournaments.ReconcileRemoved},
+				{TournamentID: "missing", Action: tournaments.ReconcileRecreated},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newReconcileFixture(t)
+
+			report, err := f.service.Reconcile(ctx, tt.cmd)
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if report.DryRun != tt.cmd.DryRun {
+				t.Errorf("DryRun = %v, want %v", report.DryRun, tt.cmd.DryRun)
+			}
+			if !reflect.DeepEqual(report.Entries, tt.wantEntries) {
+				t.Errorf("Entries = %+v, want %+v", report.Entries, tt.wantEntries)
+			}
+
+			params, recreated := f.provider.Created("missing")
+			if recreated != tt.wantRecreated {
+				t.Errorf("missing recreated = %v, want %v", recreated, tt.wantRecreated)
+			}
+			if recreated && params.Title != "Missing Cup" {
+				t.Errorf("recreated Title = %q, want Missing Cup", params.Title)
+			}
+
+			_, err = f.repo.Get(ctx, "ended")
+			if removed := errors.Is(err, tournament.ErrTournamentNotFound); removed != tt.wantRemoved {
+				t.Errorf("ended removed = %v, want %v", removed, tt.wantRemoved)
+			}
+			participants, err := f.participants.ListByTournament(ctx, "ended")
+			if err != nil {
+				t.Fatalf("ListByTournament() error = %v", err)
+			}
+			wantParticipants := 1
+			if tt.wantRemoved {
+				wantParticipants = 0
+			}
+			if len(participants) != wantParticipants {
+				t.Errorf("ended participants = %d, want %d", len(participants), wantParticipants)
+			}
+			if _, err := f.repo.Get(ctx, "synced"); err != nil {
+				t.Errorf("synced tournament should be untouched, Get() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestService_Reconcile_CreateFailure(t *testing.T) {
+	f := newReconcileFixture(t)
+	createErr := errors.New("nakama unavailable")
+	f.provider.CreateErr = createErr
+
+	report, err := f.service.Reconcile(context.Background(), tournaments.ReconcileCommand{})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].TournamentID != "missing" || !errors.Is(failed[0].Err, createErr) {
+		t.Errorf("Failed() = %+v, want the recreation of missing to fail", failed)
+	}
+}
+
+func TestService_Reconcile_ListFailure(t *testing.T) {
+	f := newReconcileFixture(t)
+	listErr := errors.New("nakama unavailable")
+	f.provider.ListErr = listErr
+
+	if _, err := f.service.Reconcile(context.Background(), tournaments.ReconcileCommand{}); !errors.Is(err, listErr) {
+		t.Errorf("Reconcile() error = %v, want %v", err, listErr)
+	}
+}
@@ -2,8 +2,12 @@ package tournaments
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"go.uber.org/zap"
+
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
 	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
 )
@@ -13,6 +17,33 @@ type NakamaProvider interface {
 	CreateTournament(ctx context.Context, params CreateTournamentParams) error
 	DeleteTournament(ctx context.Context, id shared.TournamentID) error
 	AddAttempt(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID, count int) error
+	// JoinTournament registers a player with Nakama, which only accepts
+	// records from joined players in join-required tournaments.
+	JoinTournament(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error
+	ListRecords(ctx context.Context, tournamentID shared.TournamentID, limit int, cursor string) (RecordList, error)
+	// ListTournamentIDs returns the IDs of every tournament Nakama has that
+	// has not yet ended.
+	ListTournamentIDs(ctx context.Context) ([]shared.TournamentID, error)
+}
+
+// Record is a single ranked score in a Nakama tournament.
+type Record struct {
+	OwnerID  string
+	Username string
+	Score    int64
+	Subscore int64
+	NumScore int
+	Rank     int64
+	// Attempts is the player's granted attempts. Nakama does not report it;
+	// GetStandings fills it in from Participants.
+	Attempts int
+}
+
+// RecordList is one page of tournament records along with the cursors around it.
+type RecordList struct {
+	Records    []Record
+	NextCursor string
+	PrevCursor string
 }
 
 // CreateTournamentParams encapsulates Nakama tournament creation parameters.
@@ -31,6 +62,7 @@ type CreateTournamentParams struct {
 	MaxSize       int
 	MaxNumScore   int
 	JoinRequired  bool
+	EnableRanks   bool
 }
 
 // Service coordinates tournament operations.
@@ -39,6 +71,12 @@ type Service struct {
 	Participants tournament.ParticipantRepository
 	Provider     NakamaProvider
 	Clock        func() time.Time
+	// Snapshots stores final standings when a tournament ends. Optional;
+	// SnapshotStandings fails without it.
+	Snapshots tournament.SnapshotRepository
+	// Logger, when set, records creation outcomes tagged with the request's
+	// correlation id.
+	Logger *zap.Logger
 }
 
 // NewService creates a new tournament service.
@@ -67,6 +105,12 @@ type CreateTournamentCommand struct {
 	StartTime     time.Time
 	EndTime       *time.Time
 	Duration      time.Duration
+	// EnableRanks turns on Nakama rank tracking for the tournament's records.
+	EnableRanks bool
+	// JoinOpensAt and JoinClosesAt optionally narrow when players may join.
+	// Unset bounds default to the tournament's active period.
+	JoinOpensAt  *time.Time
+	JoinClosesAt *time.Time
 }
 
 // CreateTournamentResult contains the created tournament ID.
@@ -98,45 +142,160 @@ func (s *Service) CreateTournament(ctx context.Context, cmd CreateTournamentComm
 	if err != nil {
 		return CreateTournamentResult{}, err
 	}
+	if cmd.JoinOpensAt != nil || cmd.JoinClosesAt != nil {
+		if err := t.SetJoinWindow(cmd.JoinOpensAt, cmd.JoinClosesAt, now); err != nil {
+			return CreateTournamentResult{}, err
+		}
+	}
 
 	// Save to repository
+	logger := s.log(ctx).With(zap.String("tournament_id", string(t.ID)))
 	if err := s.Repo.Save(ctx, t); err != nil {
+		logger.Error("tournament save failed", zap.Error(err))
 		return CreateTournamentResult{}, err
 	}
 
 	// Create in Nakama
-	params := CreateTournamentParams{
-		ID:            string(cmd.ID),
-		Authoritative: cmd.Authoritative,
-		SortOrder:     string(cmd.SortOrder),
-		Operator:      string(cmd.Operator),
-		ResetSchedule: cmd.ResetSchedule,
-		Title:         cmd.Title,
-		Description:   cmd.Description,
-		Category:      cmd.Category,
-		StartTime:     int(cmd.StartTime.Unix()),
-		Duration:      int(cmd.Duration.Seconds()),
-		MaxSize:       cmd.MaxSize,
-		MaxNumScore:   cmd.MaxNumScore,
-		JoinRequired:  cmd.JoinRequired,
-	}
+	params := createParams(t)
+	params.EnableRanks = cmd.EnableRanks
 	if cmd.EndTime != nil {
 		params.EndTime = int(cmd.EndTime.Unix())
 	}
 
 	if err := s.Provider.CreateTournament(ctx, params); err != nil {
+		logger.Error("nakama tournament create failed, local record kept", zap.Error(err))
 		return CreateTournamentResult{}, err
 	}
 
+	logger.Info("tournament created")
 	return CreateTournamentResult{TournamentID: t.ID}, nil
 }
 
+// ValidateTournament runs CreateTournament's checks without creating anything
+// and returns every invalid field, or nil when cmd would be accepted.
+func (s *Service) ValidateTournament(cmd CreateTournamentCommand) shared.ValidationErrors {
+	draft := tournament.Draft{
+		ID:          cmd.ID,
+		Title:       cmd.Title,
+		Category:    cmd.Category,
+		SortOrder:   cmd.SortOrder,
+		Operator:    cmd.Operator,
+		MaxSize:     cmd.MaxSize,
+		MaxNumScore: cmd.MaxNumScore,
+		StartTime:   cmd.StartTime,
+		Duration:    cmd.Duration,
+	}
+	errs := draft.Validate()
+	errs.Add("join_closes_at", tournament.ValidateJoinWindow(cmd.JoinOpensAt, cmd.JoinClosesAt))
+	return errs
+}
+
+// log returns Logger tagged with the request's correlation id, or a no-op
+// logger when Logger is nil.
+func (s *Service) log(ctx context.Context) *zap.Logger {
+	if s.Logger == nil {
+		return zap.NewNop()
+	}
+	if id := shared.CorrelationID(ctx); id != "" {
+		return s.Logger.With(zap.String("request_id", id))
+	}
+	return s.Logger
+}
+
+// createParams maps a tournament aggregate onto Nakama's creation parameters.
+// Rank tracking is not stored on the aggregate and is left off.
+func createParams(t *tournament.Tournament) CreateTournamentParams {
+	params := CreateTournamentParams{
+		ID:            string(t.ID),
+		Authoritative: t.Authoritative,
+		SortOrder:     string(t.SortOrder),
+		Operator:      string(t.Operator),
+		ResetSchedule: t.ResetSchedule,
+		Title:         t.Title,
+		Description:   t.Description,
+		Category:      t.Category,
+		StartTime:     int(t.StartTime.Unix()),
+		Duration:      int(t.Duration.Seconds()),
+		MaxSize:       t.MaxSize,
+		MaxNumScore:   t.MaxNumScore,
+		JoinRequired:  t.JoinRequired,
+	}
+	if t.EndTime != nil {
+		params.EndTime = int(t.EndTime.Unix())
+	}
+	return params
+}
+
+// UpdateTournamentCommand contains a partial update guarded by the version the caller last read.
+type UpdateTournamentCommand struct {
+	TournamentID shared.TournamentID
+	Version      int64
+	Changes      tournament.Changes
+}
+
+// UpdateTournament applies a partial update. It returns ErrConcurrentModification
+// when the tournament changed since the caller read it, so clients can re-read and retry.
+func (s *Service) UpdateTournament(ctx context.Context, cmd UpdateTournamentCommand) (*tournament.Tournament, error) {
+	if err := cmd.TournamentID.Validate(); err != nil {
+		return nil, err
+	}
+
+	t, err := s.Repo.Get(ctx, cmd.TournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if t.Version != cmd.Version {
+		return nil, tournament.ErrConcurrentModification
+	}
+
+	if err := t.ApplyChanges(cmd.Changes, s.Clock()); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.Save(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// UpdateResetScheduleCommand contains parameters for changing a tournament's reset schedule.
+type UpdateResetScheduleCommand struct {
+	TournamentID  shared.TournamentID
+	ResetSchedule string
+}
+
+// UpdateResetSchedule validates and stores a new cron reset schedule. Nakama's
+// runtime has no call to change the schedule of an existing tournament, so the
+// new schedule is held on the aggregate and applies to Nakama only when the
+// tournament is recreated.
+func (s *Service) UpdateResetSchedule(ctx context.Context, cmd UpdateResetScheduleCommand) (*tournament.Tournament, error) {
+	if err := cmd.TournamentID.Validate(); err != nil {
+		return nil, err
+	}
+
+	t, err := s.Repo.Get(ctx, cmd.TournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.SetResetSchedule(cmd.ResetSchedule, s.Clock()); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.Save(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
 // DeleteTournamentCommand contains parameters for deleting a tournament.
 type DeleteTournamentCommand struct {
 	TournamentID shared.TournamentID
 }
 
-// DeleteTournament removes a tournament.
+// DeleteTournament removes a tournament and its participant records.
 func (s *Service) DeleteTournament(ctx context.Context, cmd DeleteTournamentCommand) error {
 	if err := cmd.TournamentID.Validate(); err != nil {
 		return err
@@ -152,9 +311,142 @@ func (s *Service) DeleteTournament(ctx context.Context, cmd DeleteTournamentComm
 		return err
 	}
 
+	// Remove participants so they are not orphaned
+	if err := s.Participants.DeleteByTournament(ctx, cmd.TournamentID); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// CancelTournamentCommand contains parameters for cancelling a tournament.
+type CancelTournamentCommand struct {
+	TournamentID shared.TournamentID
+	Reason       string
+}
+
+// CancelTournamentResult holds the cancelled tournament and the players who
+// had joined it, so their entries can be refunded.
+type CancelTournamentResult struct {
+	Tournament *tournament.Tournament
+	Refunds    []*tournament.Participant
+}
+
+// CancelTournament calls off a tournament that has not ended and deletes it
+// from Nakama. Unlike DeleteTournament the tournament and its participants
+// are kept, marked cancelled, so the event is not mistaken for a completed
+// one and its players can be refunded. The cancelled state is saved before
+// the Nakama delete; if the delete fails it is logged and left to Reconcile,
+// which deletes cancelled tournaments Nakama still has.
+func (s *Service) CancelTournament(ctx context.Context, cmd CancelTournamentCommand) (CancelTournamentResult, error) {
+	if err := cmd.TournamentID.Validate(); err != nil {
+		return CancelTournamentResult{}, err
+	}
+
+	t, err := s.Repo.Get(ctx, cmd.TournamentID)
+	if err != nil {
+		return CancelTournamentResult{}, err
+	}
+	if err := t.Cancel(cmd.Reason, s.Clock()); err != nil {
+		return CancelTournamentResult{}, err
+	}
+
+	participants, err := s.Participants.ListByTournament(ctx, cmd.TournamentID)
+	if err != nil {
+		return CancelTournamentResult{}, err
+	}
+
+	if err := s.Repo.Save(ctx, t); err != nil {
+		return CancelTournamentResult{}, err
+	}
+
+	if err := s.Provider.DeleteTournament(ctx, cmd.TournamentID); err != nil {
+		s.log(ctx).Warn("tournament cancelled but not deleted from nakama, reconcile will retry",
+			zap.String("tournament_id", string(cmd.TournamentID)), zap.Error(err))
+	}
+
+	return CancelTournamentResult{Tournament: t, Refunds: participants}, nil
+}
+
+// JoinTournamentCommand contains parameters for joining a tournament.
+type JoinTournamentCommand struct {
+	TournamentID shared.TournamentID
+	PlayerID     shared.PlayerID
+}
+
+// JoinTournament registers a player in a tournament, here and in Nakama.
+// Joins outside the tournament's join window are rejected with
+// ErrJoinWindowClosed, repeat joins with ErrParticipantAlreadyJoined, and
+// joins past MaxSize with ErrTournamentFull. The place is taken on the
+// tournament itself under its version, so concurrent joins cannot overfill it.
+func (s *Service) JoinTournament(ctx context.Context, cmd JoinTournamentCommand) (*tournament.Participant, error) {
+	if err := cmd.TournamentID.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cmd.PlayerID.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Participants.Get(ctx, cmd.TournamentID, cmd.PlayerID); err == nil {
+		return nil, tournament.ErrParticipantAlreadyJoined
+	} else if !errors.Is(err, tournament.ErrParticipantNotFound) {
+		return nil, err
+	}
+
+	now := s.Clock()
+	if err := s.updateTournament(ctx, cmd.TournamentID, func(t *tournament.Tournament) error {
+		return t.Join(now)
+	}); err != nil {
+		return nil, err
+	}
+
+	participant, err := tournament.NewParticipant(cmd.TournamentID, cmd.PlayerID, now)
+	if err == nil {
+		// Nakama joins are idempotent, so joining there first lets a failed
+		// save be retried without the local record reporting the player as
+		// joined.
+		err = s.Provider.JoinTournament(ctx, cmd.TournamentID, cmd.PlayerID)
+	}
+	if err == nil {
+		// A concurrent join for the same player may have passed the check
+		// above, so only the first insert wins and the others give their
+		// place back.
+		err = s.Participants.Create(ctx, participant)
+	}
+	if err != nil {
+		// Give the place back so a retry, or another player, can take it.
+		releaseErr := s.updateTournament(ctx, cmd.TournamentID, func(t *tournament.Tournament) error {
+			t.ReleasePlace(now)
+			return nil
+		})
+		return nil, errors.Join(err, releaseErr)
+	}
+	return participant, nil
+}
+
+// maxUpdateAttempts bounds how often updateTournament reloads a tournament
+// after losing a version race.
+const maxUpdateAttempts = 5
+
+// updateTournament loads a tournament, applies mutate and saves it, starting
+// over when a concurrent save wins. It gives up with ErrConcurrentModification
+// after maxUpdateAttempts.
+func (s *Service) updateTournament(ctx context.Context, id shared.TournamentID, mutate func(*tournament.Tournament) error) error {
+	for attempt := 1; ; attempt++ {
+		t, err := s.Repo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := mutate(t); err != nil {
+			return err
+		}
+		err = s.Repo.Save(ctx, t)
+		if !errors.Is(err, tournament.ErrConcurrentModification) || attempt == maxUpdateAttempts {
+			return err
+		}
+	}
+}
+
 // AddAttemptCommand contains parameters for adding tournament attempts.
 type AddAttemptCommand struct {
 	TournamentID shared.TournamentID
@@ -207,6 +499,67 @@ func (s *Service) AddAttempt(ctx context.Context, cmd AddAttemptCommand) error {
 	return nil
 }
 
+// PlayerCount is one player's share of a bulk attempt grant.
+type PlayerCount struct {
+	PlayerID shared.PlayerID
+	Count    int
+}
+
+// AttemptResult is the outcome of granting attempts to one player. Err is nil
+// when the attempts were added.
+type AttemptResult struct {
+	PlayerID shared.PlayerID
+	Count    int
+	Err      error
+}
+
+// BulkAttemptResult holds one result per requested player, in request order.
+type BulkAttemptResult struct {
+	Results []AttemptResult
+}
+
+// Succeeded returns the number of players whose attempts were added.
+func (r BulkAttemptResult) Succeeded() int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the results that carry an error.
+func (r BulkAttemptResult) Failed() []AttemptResult {
+	var failed []AttemptResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// AddAttemptsBulk grants attempts to several players, such as an event reward.
+// Each player goes through AddAttempt on its own, so one invalid or rejected
+// player is reported in its result without stopping the others. The returned
+// error is reserved for problems with the request as a whole.
+func (s *Service) AddAttemptsBulk(ctx context.Context, tournamentID shared.TournamentID, grants []PlayerCount) (BulkAttemptResult, error) {
+	if err := tournamentID.Validate(); err != nil {
+		return BulkAttemptResult{}, err
+	}
+	results := make([]AttemptResult, 0, len(grants))
+	for _, grant := range grants {
+		err := s.AddAttempt(ctx, AddAttemptCommand{
+			TournamentID: tournamentID,
+			PlayerID:     grant.PlayerID,
+			Count:        grant.Count,
+		})
+		results = append(results, AttemptResult{PlayerID: grant.PlayerID, Count: grant.Count, Err: err})
+	}
+	return BulkAttemptResult{Results: results}, nil
+}
+
 // GetTournamentQuery contains parameters for retrieving a tournament.
 type GetTournamentQuery struct {
 	TournamentID shared.TournamentID
@@ -225,10 +578,63 @@ func (s *Service) GetTournament(ctx context.Context, query GetTournamentQuery) (
 type ListTournamentsQuery struct {
 	Limit  int
 	Offset int
+	// IncludeCancelled lists cancelled tournaments too; they are left out by default.
+	IncludeCancelled bool
+}
+
+// ListTournaments retrieves a paginated list of tournaments along with the total count.
+func (s *Service) ListTournaments(ctx context.Context, query ListTournamentsQuery) (shared.Page[*tournament.Tournament], error) {
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	filter := tournament.ListFilter{IncludeCancelled: query.IncludeCancelled}
+	items, err := s.Repo.List(ctx, filter, query.Limit, query.Offset)
+	if err != nil {
+		return shared.Page[*tournament.Tournament]{}, err
+	}
+
+	total, err := s.Repo.Count(ctx, filter)
+	if err != nil {
+		return shared.Page[*tournament.Tournament]{}, err
+	}
+
+	return shared.NewPage(items, total, query.Limit, query.Offset), nil
 }
 
-// ListTournaments retrieves a paginated list of tournaments.
-func (s *Service) ListTournaments(ctx context.Context, query ListTournamentsQuery) ([]*tournament.Tournament, error) {
+// ListPlayerTournamentsQuery contains parameters for listing a player's tournaments.
+type ListPlayerTournamentsQuery struct {
+	PlayerID shared.PlayerID
+	Limit    int
+	Offset   int
+}
+
+// PlayerTournament pairs a tournament with the player's participation in it.
+type PlayerTournament struct {
+	Tournament *tournament.Tournament
+	Attempts   int
+	JoinedAt   time.Time
+}
+
+// ErrStorageFailed is returned when a repository fails for a reason other
+// than a missing record.
+var ErrStorageFailed = errors.New("tournament storage failed")
+
+// playerTournamentsBatch is how many participations ListPlayerTournaments
+// reads at a time.
+const playerTournamentsBatch = 100
+
+// ListPlayerTournaments retrieves a page of the tournaments a player has
+// joined, in join order. Participations whose tournament no longer exists are
+// skipped, so Offset and Total count only tournaments that still exist and a
+// page is only short when it is the last one.
+func (s *Service) ListPlayerTournaments(ctx context.Context, query ListPlayerTournamentsQuery) (shared.Page[PlayerTournament], error) {
+	if err := query.PlayerID.Validate(); err != nil {
+		return shared.Page[PlayerTournament]{}, err
+	}
 	if query.Limit <= 0 {
 		query.Limit = 10
 	}
@@ -236,5 +642,186 @@ func (s *Service) ListTournaments(ctx context.Context, query ListTournamentsQuer
 		query.Offset = 0
 	}
 
-	return s.Repo.List(ctx, query.Limit, query.Offset)
+	items := make([]PlayerTournament, 0, query.Limit)
+	total := 0
+	for scanned := 0; ; scanned += playerTournamentsBatch {
+		participants, err := s.Participants.ListByPlayer(ctx, query.PlayerID, playerTournamentsBatch, scanned)
+		if err != nil {
+			return shared.Page[PlayerTournament]{}, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+		}
+
+		for _, p := range participants {
+			t, err := s.Repo.Get(ctx, p.TournamentID)
+			if err != nil {
+				if errors.Is(err, tournament.ErrTournamentNotFound) {
+					continue
+				}
+				return shared.Page[PlayerTournament]{}, fmt.Errorf("%w: %v", ErrStorageFailed, err)
+			}
+			if total >= query.Offset && len(items) < query.Limit {
+				items = append(items, PlayerTournament{
+					Tournament: t,
+					Attempts:   p.Attempts,
+					JoinedAt:   p.JoinedAt,
+				})
+			}
+			total++
+		}
+
+		if len(participants) < playerTournamentsBatch {
+			break
+		}
+	}
+
+	return shared.NewPage(items, total, query.Limit, query.Offset), nil
+}
+
+// MaxStandingsLimit is the largest page of records Nakama returns in one call.
+const MaxStandingsLimit = 100
+
+// MaxStandingsTop is the largest Top a standings query may ask for.
+const MaxStandingsTop = 1000
+
+// GetStandingsQuery contains parameters for reading a tournament's standings.
+type GetStandingsQuery struct {
+	TournamentID shared.TournamentID
+	Limit        int
+	Cursor       string
+	// Top, when positive, returns the best Top records in one list, paging
+	// through Nakama as needed. Limit and Cursor are ignored, and NextCursor
+	// continues after the last record returned.
+	Top int
+}
+
+// GetStandings retrieves the current ranked records for a tournament, with
+// each player's attempt count.
+func (s *Service) GetStandings(ctx context.Context, query GetStandingsQuery) (RecordList, error) {
+	if err := query.TournamentID.Validate(); err != nil {
+		return RecordList{}, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+	if query.Limit > MaxStandingsLimit {
+		query.Limit = MaxStandingsLimit
+	}
+	if query.Top > MaxStandingsTop {
+		query.Top = MaxStandingsTop
+	}
+
+	if _, err := s.Repo.Get(ctx, query.TournamentID); err != nil {
+		return RecordList{}, err
+	}
+
+	var list RecordList
+	var err error
+	if query.Top > 0 {
+		list, err = s.topRecords(ctx, query.TournamentID, query.Top)
+	} else {
+		list, err = s.Provider.ListRecords(ctx, query.TournamentID, query.Limit, query.Cursor)
+	}
+	if err != nil {
+		return RecordList{}, err
+	}
+
+	participants, err := s.Participants.ListByTournament(ctx, query.TournamentID)
+	if err != nil {
+		return RecordList{}, err
+	}
+	attempts := make(map[string]int, len(participants))
+	for _, p := range participants {
+		attempts[string(p.PlayerID)] = p.Attempts
+	}
+	for i := range list.Records {
+		list.Records[i].Attempts = attempts[list.Records[i].OwnerID]
+	}
+	return list, nil
+}
+
+// topRecords reads the best n records, a page of at most MaxStandingsLimit at
+// a time.
+func (s *Service) topRecords(ctx context.Context, tournamentID shared.TournamentID, n int) (RecordList, error) {
+	list := RecordList{Records: make([]Record, 0, n)}
+	cursor := ""
+	for len(list.Records) < n {
+		page, err := s.Provider.ListRecords(ctx, tournamentID, min(n-len(list.Records), MaxStandingsLimit), cursor)
+		if err != nil {
+			return RecordList{}, err
+		}
+		list.Records = append(list.Records, page.Records...)
+		list.NextCursor = page.NextCursor
+		if page.NextCursor == "" || page.NextCursor == cursor || len(page.Records) == 0 {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(list.Records) > n {
+		list.Records = list.Records[:n]
+	}
+	return list, nil
+}
+
+// ErrSnapshotsNotConfigured is returned when snapshotting without a snapshot repository.
+var ErrSnapshotsNotConfigured = errors.New("standings snapshots are not configured")
+
+// SnapshotStandings captures a tournament's full ranked standings so they
+// survive Nakama records rolling over. It is meant to run from the tournament
+// end callback; running it again replaces the earlier snapshot.
+func (s *Service) SnapshotStandings(ctx context.Context, tournamentID shared.TournamentID) (*tournament.StandingsSnapshot, error) {
+	if err := tournamentID.Validate(); err != nil {
+		return nil, err
+	}
+	if s.Snapshots == nil {
+		return nil, ErrSnapshotsNotConfigured
+	}
+
+	t, err := s.Repo.Get(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &tournament.StandingsSnapshot{
+		TournamentID: tournamentID,
+		Title:        t.Title,
+		Standings:    []tournament.Standing{},
+	}
+	cursor := ""
+	for {
+		page, err := s.Provider.ListRecords(ctx, tournamentID, MaxStandingsLimit, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range page.Records {
+			snapshot.Standings = append(snapshot.Standings, tournament.Standing{
+				Rank:     record.Rank,
+				OwnerID:  record.OwnerID,
+				Username: record.Username,
+				Score:    record.Score,
+				Subscore: record.Subscore,
+				NumScore: record.NumScore,
+			})
+		}
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	snapshot.TakenAt = s.Clock()
+
+	if err := s.Snapshots.Save(ctx, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// GetSnapshot returns the final standings captured when a tournament ended.
+// It does not require the tournament to still exist.
+func (s *Service) GetSnapshot(ctx context.Context, tournamentID shared.TournamentID) (*tournament.StandingsSnapshot, error) {
+	if err := tournamentID.Validate(); err != nil {
+		return nil, err
+	}
+	if s.Snapshots == nil {
+		return nil, ErrSnapshotsNotConfigured
+	}
+	return s.Snapshots.Get(ctx, tournamentID)
 }
@@ -3,12 +3,21 @@ package tournaments_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/heroiclabs/nakama/v3/src/app/tournaments"
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
 	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+	infraTournament "github.com/heroiclabs/nakama/v3/src/infra/tournament"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // Mock implementations
@@ -17,6 +26,7 @@ type mockTournamentRepo struct {
 	getFunc    func(ctx context.Context, id shared.TournamentID) (*tournament.Tournament, error)
 	deleteFunc func(ctx context.Context, id shared.TournamentID) error
 	listFunc   func(ctx context.Context, limit, offset int) ([]*tournament.Tournament, error)
+	countFunc  func(ctx context.Context) (int, error)
 }
 
 func (m *mockTournamentRepo) Save(ctx context.Context, t *tournament.Tournament) error {
@@ -40,18 +50,28 @@ func (m *mockTournamentRepo) Delete(ctx context.Context, id shared.TournamentID)
 	return nil
 }
 
-func (m *mockTournamentRepo) List(ctx context.Context, limit, offset int) ([]*tournament.Tournament, error) {
+func (m *mockTournamentRepo) List(ctx context.Context, filter tournament.ListFilter, limit, offset int) ([]*tournament.Tournament, error) {
 	if m.listFunc != nil {
 		return m.listFunc(ctx, limit, offset)
 	}
 	return []*tournament.Tournament{}, nil
 }
 
+func (m *mockTournamentRepo) Count(ctx context.Context, filter tournament.ListFilter) (int, error) {
+	if m.countFunc != nil {
+		return m.countFunc(ctx)
+	}
+	return 0, nil
+}
+
 type mockParticipantRepo struct {
-	saveFunc           func(ctx context.Context, p *tournament.Participant) error
-	getFunc            func(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) (*tournament.Participant, error)
-	listByTournamentFunc func(ctx context.Context, tournamentID shared.TournamentID) ([]*tournament.Participant, error)
-	deleteFunc         func(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error
+	saveFunc               func(ctx context.Context, p *tournament.Participant) error
+	createFunc             func(ctx context.Context, p *tournament.Participant) error
+	getFunc                func(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) (*tournament.Participant, error)
+	listByTournamentFunc   func(ctx context.Context, tournamentID shared.TournamentID) ([]*tournament.Participant, error)
+	listByPlayerFunc       func(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*tournament.Participant, error)
+	deleteFunc             func(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error
+	deleteByTournamentFunc func(ctx context.Context, tournamentID shared.TournamentID) error
 }
 
 func (m *mockParticipantRepo) Save(ctx context.Context, p *tournament.Participant) error {
@@ -61,6 +81,13 @@ func (m *mockParticipantRepo) Save(ctx context.Context, p *tournament.Participan
 	return nil
 }
 
+func (m *mockParticipantRepo) Create(ctx context.Context, p *tournament.Participant) error {
+	if m.createFunc != nil {
+		return m.createFunc(ctx, p)
+	}
+	return nil
+}
+
 func (m *mockParticipantRepo) Get(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) (*tournament.Participant, error) {
 	if m.getFunc != nil {
 		return m.getFunc(ctx, tournamentID, playerID)
@@ -75,6 +102,13 @@ func (m *mockParticipantRepo) ListByTournament(ctx context.Context, tournamentID
 	return []*tournament.Participant{}, nil
 }
 
+func (m *mockParticipantRepo) ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*tournament.Participant, error) {
+	if m.listByPlayerFunc != nil {
+		return m.listByPlayerFunc(ctx, playerID, limit, offset)
+	}
+	return []*tournament.Participant{}, nil
+}
+
 func (m *mockParticipantRepo) Delete(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error {
 	if m.deleteFunc != nil {
 		return m.deleteFunc(ctx, tournamentID, playerID)
@@ -82,10 +116,20 @@ func (m *mockParticipantRepo) Delete(ctx context.Context, tournamentID shared.To
 	return nil
 }
 
+func (m *mockParticipantRepo) DeleteByTournament(ctx context.Context, tournamentID shared.TournamentID) error {
+	if m.deleteByTournamentFunc != nil {
+		return m.deleteByTournamentFunc(ctx, tournamentID)
+	}
+	return nil
+}
+
 type mockNakamaProvider struct {
-	createFunc     func(ctx context.Context, params tournaments.CreateTournamentParams) error
-	deleteFunc     func(ctx context.Context, id shared.TournamentID) error
-	addAttemptFunc func(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID, count int) error
+	createFunc      func(ctx context.Context, params tournaments.CreateTournamentParams) error
+	deleteFunc      func(ctx context.Context, id shared.TournamentID) error
+	addAttemptFunc  func(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID, count int) error
+	joinFunc        func(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error
+	listRecordsFunc func(ctx context.Context, tournamentID shared.TournamentID, limit int, cursor string) (tournaments.RecordList, error)
+	listIDsFunc     func(ctx context.Context) ([]shared.TournamentID, error)
 }
 
 func (m *mockNakamaProvider) CreateTournament(ctx context.Context, params tournaments.CreateTournamentParams) error {
@@ -109,6 +153,27 @@ func (m *mockNakamaProvider) AddAttempt(ctx context.Context, tournamentID shared
 	return nil
 }
 
+func (m *mockNakamaProvider) JoinTournament(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error {
+	if m.joinFunc != nil {
+		return m.joinFunc(ctx, tournamentID, playerID)
+	}
+	return nil
+}
+
+func (m *mockNakamaProvider) ListRecords(ctx context.Context, tournamentID shared.TournamentID, limit int, cursor string) (tournaments.RecordList, error) {
+	if m.listRecordsFunc != nil {
+		return m.listRecordsFunc(ctx, tournamentID, limit, cursor)
+	}
+	return tournaments.RecordList{}, nil
+}
+
+func (m *mockNakamaProvider) ListTournamentIDs(ctx context.Context) ([]shared.TournamentID, error) {
+	if m.listIDsFunc != nil {
+		return m.listIDsFunc(ctx)
+	}
+	return nil, nil
+}
+
 func TestService_CreateTournament(t *testing.T) {
 	ctx := context.Background()
 	now := time.Now()
@@ -206,6 +271,85 @@ func TestService_CreateTournament(t *testing.T) {
 	}
 }
 
+func TestService_CreateTournament_EnableRanks(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, enableRanks := range []bool{false, true} {
+		var got tournaments.CreateTournamentParams
+		provider := &mockNakamaProvider{
+			createFunc: func(ctx context.Context, params tournaments.CreateTournamentParams) error {
+				got = params
+				return nil
+			},
+		}
+
+		service := tournaments.NewService(&mockTournamentRepo{}, &mockParticipantRepo{}, provider)
+		_, err := service.CreateTournament(ctx, tournaments.CreateTournamentCommand{
+			ID:          "tournament-123",
+			Title:       "Ranked Tournament",
+			StartTime:   now.Add(1 * time.Hour),
+			Duration:    24 * time.Hour,
+			EnableRanks: enableRanks,
+		})
+		if err != nil {
+			t.Fatalf("CreateTournament() error = %v", err)
+		}
+		if got.EnableRanks != enableRanks {
+			t.Errorf("Expected EnableRanks %v to reach provider, got %v", enableRanks, got.EnableRanks)
+		}
+	}
+}
+
+func TestService_CreateTournament_Logging(t *testing.T) {
+	errUnavailable := errors.New("nakama unavailable")
+	tests := []struct {
+		name      string
+		saveErr   error
+		createErr error
+		wantLevel zapcore.Level
+		wantMsg   string
+	}{
+		{name: "created", wantLevel: zapcore.InfoLevel, wantMsg: "tournament created"},
+		{name: "save failure", saveErr: errors.New("db down"), wantLevel: zapcore.ErrorLevel, wantMsg: "tournament save failed"},
+		{name: "nakama failure", createErr: errUnavailable, wantLevel: zapcore.ErrorLevel, wantMsg: "nakama tournament create failed, local record kept"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			repo := &mockTournamentRepo{
+				saveFunc: func(ctx context.Context, t *tournament.Tournament) error { return tt.saveErr },
+			}
+			provider := &mockNakamaProvider{
+				createFunc: func(ctx context.Context, params tournaments.CreateTournamentParams) error { return tt.createErr },
+			}
+			service := tournaments.NewService(repo, &mockParticipantRepo{}, provider)
+			service.Logger = zap.New(core)
+
+			ctx := shared.WithCorrelationID(context.Background(), "req-1")
+			_, _ = service.CreateTournament(ctx, tournaments.CreateTournamentCommand{
+				ID:        "tournament-123",
+				Title:     "Weekly",
+				StartTime: time.Now().Add(time.Hour),
+				Duration:  time.Hour,
+			})
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("Expected 1 log line, got %d", len(entries))
+			}
+			entry := entries[0]
+			if entry.Level != tt.wantLevel || entry.Message != tt.wantMsg {
+				t.Errorf("log = %s %q, want %s %q", entry.Level, entry.Message, tt.wantLevel, tt.wantMsg)
+			}
+			fields := entry.ContextMap()
+			if fields["request_id"] != "req-1" || fields["tournament_id"] != "tournament-123" {
+				t.Errorf("Unexpected fields %v", fields)
+			}
+		})
+	}
+}
+
 func TestService_DeleteTournament(t *testing.T) {
 	ctx := context.Background()
 
@@ -266,17 +410,75 @@ func TestService_DeleteTournament(t *testing.T) {
 	}
 }
 
+func TestService_DeleteTournament_RemovesParticipants(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	participants := infraTournament.NewMemoryParticipantRepository()
+	for _, entry := range []struct {
+		tournamentID shared.TournamentID
+		playerID     shared.PlayerID
+	}{
+		{"tournament-123", "player-1"},
+		{"tournament-123", "player-2"},
+		{"tournament-456", "player-1"},
+	} {
+		p, err := tournament.NewParticipant(entry.tournamentID, entry.playerID, now)
+		if err != nil {
+			t.Fatalf("NewParticipant() error = %v", err)
+		}
+		if err := participants.Save(ctx, p); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	service := tournaments.NewService(&mockTournamentRepo{}, participants, &mockNakamaProvider{})
+	if err := service.DeleteTournament(ctx, tournaments.DeleteTournamentCommand{TournamentID: "tournament-123"}); err != nil {
+		t.Fatalf("DeleteTournament() error = %v", err)
+	}
+
+	remaining, err := participants.ListByTournament(ctx, "tournament-123")
+	if err != nil {
+		t.Fatalf("ListByTournament() error = %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("Expected no participants for deleted tournament, got %d", len(remaining))
+	}
+
+	others, err := participants.ListByTournament(ctx, "tournament-456")
+	if err != nil {
+		t.Fatalf("ListByTournament() error = %v", err)
+	}
+	if len(others) != 1 {
+		t.Errorf("Expected participants of other tournaments to be kept, got %d", len(others))
+	}
+}
+
+func TestService_DeleteTournament_ParticipantCleanupFailure(t *testing.T) {
+	participants := &mockParticipantRepo{
+		deleteByTournamentFunc: func(ctx context.Context, tournamentID shared.TournamentID) error {
+			return errors.New("cleanup failed")
+		},
+	}
+
+	service := tournaments.NewService(&mockTournamentRepo{}, participants, &mockNakamaProvider{})
+	err := service.DeleteTournament(context.Background(), tournaments.DeleteTournamentCommand{TournamentID: "tournament-123"})
+	if err == nil {
+		t.Error("Expected participant cleanup error to be returned")
+	}
+}
+
 func TestService_AddAttempt(t *testing.T) {
 	ctx := context.Background()
 	now := time.Now()
 
 	tests := []struct {
-		name               string
-		cmd                tournaments.AddAttemptCommand
+		name                string
+		cmd                 tournaments.AddAttemptCommand
 		existingParticipant *tournament.Participant
-		getErr             error
-		providerErr        error
-		wantErr            bool
+		getErr              error
+		providerErr         error
+		wantErr             bool
 	}{
 		{
 			name: "successful attempt addition for existing participant",
@@ -354,3 +556,934 @@ func TestService_AddAttempt(t *testing.T) {
 		})
 	}
 }
+
+func TestService_AddAttemptsBulk(t *testing.T) {
+	ctx := context.Background()
+	errAttemptCap := errors.New("max attempts reached")
+
+	participants := infraTournament.NewMemoryParticipantRepository()
+	var granted []shared.PlayerID
+	provider := &mockNakamaProvider{
+		addAttemptFunc: func(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID, count int) error {
+			if playerID == "player-capped" {
+				return errAttemptCap
+			}
+			granted = append(granted, playerID)
+			return nil
+		},
+	}
+	service := tournaments.NewService(&mockTournamentRepo{}, participants, provider)
+
+	result, err := service.AddAttemptsBulk(ctx, "tournament-123", []tournaments.PlayerCount{
+		{PlayerID: "player-1", Count: 2},
+		{PlayerID: "", Count: 2},
+		{PlayerID: "player-capped", Count: 1},
+		{PlayerID: "player-2", Count: 0},
+		{PlayerID: "player-3", Count: 3},
+	})
+	if err != nil {
+		t.Fatalf("AddAttemptsBulk() error = %v", err)
+	}
+	if len(result.Results) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(result.Results))
+	}
+
+	for i, want := range map[int]error{0: nil, 2: errAttemptCap, 3: tournament.ErrInvalidAttemptCount, 4: nil} {
+		if got := result.Results[i].Err; !errors.Is(got, want) {
+			t.Errorf("Results[%d].Err = %v, want %v", i, got, want)
+		}
+	}
+	if _, ok := shared.AsValidationError(result.Results[1].Err); !ok {
+		t.Errorf("Results[1].Err = %v, want validation error", result.Results[1].Err)
+	}
+	if result.Succeeded() != 2 || len(result.Failed()) != 3 {
+		t.Errorf("Succeeded() = %d, Failed() = %d; want 2 and 3", result.Succeeded(), len(result.Failed()))
+	}
+	if !reflect.DeepEqual(granted, []shared.PlayerID{"player-1", "player-3"}) {
+		t.Errorf("Provider granted %v, want player-1 and player-3", granted)
+	}
+	participant, err := participants.Get(ctx, "tournament-123", "player-3")
+	if err != nil || participant.Attempts != 3 {
+		t.Errorf("player-3 participant = %+v, %v; want 3 attempts", participant, err)
+	}
+
+	if _, err := service.AddAttemptsBulk(ctx, "", []tournaments.PlayerCount{{PlayerID: "player-1", Count: 1}}); err == nil {
+		t.Error("Expected error for empty tournament id")
+	}
+}
+
+func TestService_ListTournaments(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	all := make([]*tournament.Tournament, 0, 25)
+	for i := 0; i < 25; i++ {
+		all = append(all, &tournament.Tournament{
+			ID:        shared.TournamentID(fmt.Sprintf("tournament-%02d", i)),
+			Title:     "Test Tournament",
+			StartTime: now,
+		})
+	}
+
+	repo := &mockTournamentRepo{
+		listFunc: func(ctx context.Context, limit, offset int) ([]*tournament.Tournament, error) {
+			if offset > len(all) {
+				return []*tournament.Tournament{}, nil
+			}
+			end := offset + limit
+			if end > len(all) {
+				end = len(all)
+			}
+			return all[offset:end], nil
+		},
+		countFunc: func(ctx context.Context) (int, error) {
+			return len(all), nil
+		},
+	}
+
+	tests := []struct {
+		name      string
+		query     tournaments.ListTournamentsQuery
+		wantItems int
+		wantLimit int
+		wantMore  bool
+	}{
+		{
+			name:      "first page",
+			query:     tournaments.ListTournamentsQuery{Limit: 10, Offset: 0},
+			wantItems: 10,
+			wantLimit: 10,
+			wantMore:  true,
+		},
+		{
+			name:      "last partial page",
+			query:     tournaments.ListTournamentsQuery{Limit: 10, Offset: 20},
+			wantItems: 5,
+			wantLimit: 10,
+			wantMore:  false,
+		},
+		{
+			name:      "past the end",
+			query:     tournaments.ListTournamentsQuery{Limit: 10, Offset: 40},
+			wantItems: 0,
+			wantLimit: 10,
+			wantMore:  false,
+		},
+		{
+			name:      "default limit",
+			query:     tournaments.ListTournamentsQuery{},
+			wantItems: 10,
+			wantLimit: 10,
+			wantMore:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := tournaments.NewService(repo, &mockParticipantRepo{}, &mockNakamaProvider{})
+			page, err := service.ListTournaments(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("ListTournaments() error = %v", err)
+			}
+
+			if page.Total != len(all) {
+				t.Errorf("Expected total %d, got %d", len(all), page.Total)
+			}
+			if len(page.Items) != tt.wantItems {
+				t.Errorf("Expected %d items, got %d", tt.wantItems, len(page.Items))
+			}
+			if page.Limit != tt.wantLimit {
+				t.Errorf("Expected limit %d, got %d", tt.wantLimit, page.Limit)
+			}
+			if page.HasMore() != tt.wantMore {
+				t.Errorf("Expected HasMore %v, got %v", tt.wantMore, page.HasMore())
+			}
+		})
+	}
+}
+
+func TestService_ListTournaments_CountError(t *testing.T) {
+	repo := &mockTournamentRepo{
+		countFunc: func(ctx context.Context) (int, error) {
+			return 0, errors.New("count failed")
+		},
+	}
+
+	service := tournaments.NewService(repo, &mockParticipantRepo{}, &mockNakamaProvider{})
+	if _, err := service.ListTournaments(context.Background(), tournaments.ListTournamentsQuery{}); err == nil {
+		t.Error("Expected count error to be returned")
+	}
+}
+
+func TestService_ListPlayerTournaments(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	repo := infraTournament.NewMemoryRepository()
+	participants := infraTournament.NewMemoryParticipantRepository()
+
+	for i, id := range []shared.TournamentID{"tournament-a", "tournament-b", "tournament-c"} {
+		tour, err := tournament.NewTournament(id, "Tournament", "", 0, tournament.SortOrderDescending, tournament.OperatorBest, "", true, false, 0, 0, now, time.Hour, now)
+		if err != nil {
+			t.Fatalf("NewTournament() error = %v", err)
+		}
+		if err := repo.Save(ctx, tour); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if id == "tournament-c" {
+			continue
+		}
+		p, err := tournament.NewParticipant(id, "player-1", now.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("NewParticipant() error = %v", err)
+		}
+		if err := participants.Save(ctx, p); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	service := tournaments.NewService(repo, participants, &mockNakamaProvider{})
+
+	t.Run("player in multiple tournaments", func(t *testing.T) {
+		results, err := service.ListPlayerTournaments(ctx, tournaments.ListPlayerTournamentsQuery{PlayerID: "player-1"})
+		if err != nil {
+			t.Fatalf("ListPlayerTournaments() error = %v", err)
+		}
+		if len(results.Items) != 2 || results.Total != 2 {
+			t.Fatalf("Expected 2 of 2 tournaments, got %d of %d", len(results.Items), results.Total)
+		}
+		if results.Items[0].Tournament.ID != "tournament-a" || results.Items[1].Tournament.ID != "tournament-b" {
+			t.Errorf("Expected tournaments in join order, got %v and %v", results.Items[0].Tournament.ID, results.Items[1].Tournament.ID)
+		}
+	})
+
+	t.Run("player in no tournaments", func(t *testing.T) {
+		results, err := service.ListPlayerTournaments(ctx, tournaments.ListPlayerTournamentsQuery{PlayerID: "player-2"})
+		if err != nil {
+			t.Fatalf("ListPlayerTournaments() error = %v", err)
+		}
+		if len(results.Items) != 0 || results.Total != 0 {
+			t.Errorf("Expected no tournaments, got %d of %d", len(results.Items), results.Total)
+		}
+	})
+
+	t.Run("empty player id", func(t *testing.T) {
+		if _, err := service.ListPlayerTournaments(ctx, tournaments.ListPlayerTournamentsQuery{}); err == nil {
+			t.Error("Expected validation error for empty player id")
+		}
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		failing := tournaments.NewService(repo, &mockParticipantRepo{
+			listByPlayerFunc: func(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*tournament.Participant, error) {
+				return nil, errors.New("connection refused")
+			},
+		}, &mockNakamaProvider{})
+		if _, err := failing.ListPlayerTournaments(ctx, tournaments.ListPlayerTournamentsQuery{PlayerID: "player-1"}); !errors.Is(err, tournaments.ErrStorageFailed) {
+			t.Errorf("ListPlayerTournaments() error = %v, want ErrStorageFailed", err)
+		}
+	})
+}
+
+func TestService_ListPlayerTournaments_SkipsMissingTournaments(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	repo := infraTournament.NewMemoryRepository()
+	participants := infraTournament.NewMemoryParticipantRepository()
+
+	// Every other participation points at a tournament that no longer exists.
+	for i := 0; i < 10; i++ {
+		id := shared.TournamentID(fmt.Sprintf("tournament-%d", i))
+		if i%2 == 0 {
+			tour, err := tournament.NewTournament(id, "Tournament", "", 0, tournament.SortOrderDescending, tournament.OperatorBest, "", true, false, 0, 0, now, time.Hour, now)
+			if err != nil {
+				t.Fatalf("NewTournament() error = %v", err)
+			}
+			if err := repo.Save(ctx, tour); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+		}
+		p, err := tournament.NewParticipant(id, "player-1", now.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("NewParticipant() error = %v", err)
+		}
+		if err := participants.Save(ctx, p); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	service := tournaments.NewService(repo, participants, &mockNakamaProvider{})
+
+	page, err := service.ListPlayerTournaments(ctx, tournaments.ListPlayerTournamentsQuery{PlayerID: "player-1", Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListPlayerTournaments() error = %v", err)
+	}
+	if len(page.Items) != 2 || page.Total != 5 || !page.HasMore() {
+		t.Fatalf("Expected a full page of 2 of 5 tournaments with more to come, got %d of %d", len(page.Items), page.Total)
+	}
+	if page.Items[0].Tournament.ID != "tournament-2" || page.Items[1].Tournament.ID != "tournament-4" {
+		t.Errorf("Expected tournament-2 and tournament-4, got %v and %v", page.Items[0].Tournament.ID, page.Items[1].Tournament.ID)
+	}
+
+	last, err := service.ListPlayerTournaments(ctx, tournaments.ListPlayerTournamentsQuery{PlayerID: "player-1", Limit: 2, Offset: 4})
+	if err != nil {
+		t.Fatalf("ListPlayerTournaments() error = %v", err)
+	}
+	if len(last.Items) != 1 || last.HasMore() {
+		t.Errorf("Expected a last page of 1 tournament, got %d", len(last.Items))
+	}
+}
+
+func TestService_UpdateTournament(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	newTitle := "Updated Title"
+	emptyTitle := ""
+
+	tests := []struct {
+		name    string
+		version int64
+		changes tournament.Changes
+		wantErr bool
+		wantIs  error
+	}{
+		{
+			name:    "current version",
+			version: 1,
+			changes: tournament.Changes{Title: &newTitle},
+		},
+		{
+			name:    "stale version",
+			version: 0,
+			changes: tournament.Changes{Title: &newTitle},
+			wantErr: true,
+			wantIs:  tournament.ErrConcurrentModification,
+		},
+		{
+			name:    "invalid change",
+			version: 1,
+			changes: tournament.Changes{Title: &emptyTitle},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := infraTournament.NewMemoryRepository()
+			tour, err := tournament.NewTournament("tournament-123", "Original", "", 0, tournament.SortOrderDescending, tournament.OperatorBest, "", true, false, 0, 0, now, time.Hour, now)
+			if err != nil {
+				t.Fatalf("NewTournament() error = %v", err)
+			}
+			if err := repo.Save(ctx, tour); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			service := tournaments.NewService(repo, &mockParticipantRepo{}, &mockNakamaProvider{})
+			updated, err := service.UpdateTournament(ctx, tournaments.UpdateTournamentCommand{
+				TournamentID: "tournament-123",
+				Version:      tt.version,
+				Changes:      tt.changes,
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateTournament() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Fatalf("UpdateTournament() error = %v, want %v", err, tt.wantIs)
+			}
+			if tt.wantErr {
+				return
+			}
+			if updated.Title != newTitle {
+				t.Errorf("Expected title %q, got %q", newTitle, updated.Title)
+			}
+			if updated.Version != 2 {
+				t.Errorf("Expected version 2, got %d", updated.Version)
+			}
+		})
+	}
+}
+
+func TestService_GetStandings(t *testing.T) {
+	ctx := context.Background()
+	records := []tournaments.Record{
+		{OwnerID: "player-1", Username: "alice", Score: 300, Rank: 1},
+		{OwnerID: "player-2", Username: "bob", Score: 200, Rank: 2},
+	}
+
+	tests := []struct {
+		name        string
+		query       tournaments.GetStandingsQuery
+		getErr      error
+		providerErr error
+		wantLimit   int
+		wantRecords int
+		wantErr     error
+	}{
+		{
+			name:        "returns provider records",
+			query:       tournaments.GetStandingsQuery{TournamentID: "tournament-123", Limit: 2, Cursor: "abc"},
+			wantLimit:   2,
+			wantRecords: 2,
+		},
+		{
+			name:        "defaults limit",
+			query:       tournaments.GetStandingsQuery{TournamentID: "tournament-123"},
+			wantLimit:   10,
+			wantRecords: 2,
+		},
+		{
+			name:        "caps limit",
+			query:       tournaments.GetStandingsQuery{TournamentID: "tournament-123", Limit: 500},
+			wantLimit:   tournaments.MaxStandingsLimit,
+			wantRecords: 2,
+		},
+		{
+			name:    "unknown tournament",
+			query:   tournaments.GetStandingsQuery{TournamentID: "missing"},
+			getErr:  tournament.ErrTournamentNotFound,
+			wantErr: tournament.ErrTournamentNotFound,
+		},
+		{
+			name:        "provider failure",
+			query:       tournaments.GetStandingsQuery{TournamentID: "tournament-123"},
+			providerErr: errors.New("nakama unavailable"),
+			wantLimit:   10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockTournamentRepo{
+				getFunc: func(ctx context.Context, id shared.TournamentID) (*tournament.Tournament, error) {
+					if tt.getErr != nil {
+						return nil, tt.getErr
+					}
+					return &tournament.Tournament{ID: id}, nil
+				},
+			}
+
+			var gotLimit int
+			var gotCursor string
+			provider := &mockNakamaProvider{
+				listRecordsFunc: func(ctx context.Context, tournamentID shared.TournamentID, limit int, cursor string) (tournaments.RecordList, error) {
+					gotLimit, gotCursor = limit, cursor
+					if tt.providerErr != nil {
+						return tournaments.RecordList{}, tt.providerErr
+					}
+					return tournaments.RecordList{Records: records, NextCursor: "next"}, nil
+				},
+			}
+
+			service := tournaments.NewService(repo, &mockParticipantRepo{}, provider)
+			result, err := service.GetStandings(ctx, tt.query)
+
+			wantErr := tt.wantErr
+			if wantErr == nil {
+				wantErr = tt.providerErr
+			}
+			if !errors.Is(err, wantErr) {
+				t.Fatalf("GetStandings() error = %v, wantErr %v", err, wantErr)
+			}
+			if gotLimit != tt.wantLimit {
+				t.Errorf("Expected provider limit %d, got %d", tt.wantLimit, gotLimit)
+			}
+			if err != nil {
+				return
+			}
+			if gotCursor != tt.query.Cursor {
+				t.Errorf("Expected cursor %q, got %q", tt.query.Cursor, gotCursor)
+			}
+			if len(result.Records) != tt.wantRecords {
+				t.Errorf("Expected %d records, got %d", tt.wantRecords, len(result.Records))
+			}
+			if result.NextCursor != "next" {
+				t.Errorf("Expected next cursor %q, got %q", "next", result.NextCursor)
+			}
+		})
+	}
+}
+
+func TestService_GetStandings_TopAndAttempts(t *testing.T) {
+	ctx := context.Background()
+	// 250 ranked records served from an offset cursor, as Nakama pages them.
+	var records []tournaments.Record
+	for i := 1; i <= 250; i++ {
+		records = append(records, tournaments.Record{OwnerID: fmt.Sprintf("player-%d", i), Score: int64(1000 - i), Rank: int64(i)})
+	}
+	var limits []int
+	provider := &mockNakamaProvider{
+		listRecordsFunc: func(ctx context.Context, tournamentID shared.TournamentID, limit int, cursor string) (tournaments.RecordList, error) {
+			limits = append(limits, limit)
+			offset, _ := strconv.Atoi(cursor)
+			end := min(offset+limit, len(records))
+			list := tournaments.RecordList{Records: append([]tournaments.Record(nil), records[offset:end]...)}
+			if end < len(records) {
+				list.NextCursor = strconv.Itoa(end)
+			}
+			return list, nil
+		},
+	}
+	repo := &mockTournamentRepo{
+		getFunc: func(ctx context.Context, id shared.TournamentID) (*tournament.Tournament, error) {
+			return &tournament.Tournament{ID: id}, nil
+		},
+	}
+	participants := &mockParticipantRepo{
+		listByTournamentFunc: func(ctx context.Context, tournamentID shared.TournamentID) ([]*tournament.Participant, error) {
+			return []*tournament.Participant{
+				{TournamentID: tournamentID, PlayerID: "player-1", Attempts: 3},
+				{TournamentID: tournamentID, PlayerID: "player-150", Attempts: 7},
+			}, nil
+		},
+	}
+	service := tournaments.NewService(repo, participants, provider)
+
+	tests := []struct {
+		name       string
+		top        int
+		wantLimits []int
+		wantCount  int
+		wantCursor string
+	}{
+		{name: "within one page", top: 5, wantLimits: []int{5}, wantCount: 5, wantCursor: "5"},
+		{name: "across pages", top: 150, wantLimits: []int{100, 50}, wantCount: 150, wantCursor: "150"},
+		{name: "more than exist", top: 400, wantLimits: []int{100, 100, 100}, wantCount: 250},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits = nil
+			result, err := service.GetStandings(ctx, tournaments.GetStandingsQuery{TournamentID: "tournament-123", Top: tt.top, Cursor: "ignored"})
+			if err != nil {
+				t.Fatalf("GetStandings() error = %v", err)
+			}
+			if !reflect.DeepEqual(limits, tt.wantLimits) {
+				t.Errorf("Expected provider limits %v, got %v", tt.wantLimits, limits)
+			}
+			if len(result.Records) != tt.wantCount {
+				t.Fatalf("Expected %d records, got %d", tt.wantCount, len(result.Records))
+			}
+			if result.NextCursor != tt.wantCursor {
+				t.Errorf("Expected next cursor %q, got %q", tt.wantCursor, result.NextCursor)
+			}
+			if result.Records[0].Attempts != 3 {
+				t.Errorf("Expected player-1 to have 3 attempts, got %d", result.Records[0].Attempts)
+			}
+			if len(result.Records) > 149 && result.Records[149].Attempts != 7 {
+				t.Errorf("Expected player-150 to have 7 attempts, got %d", result.Records[149].Attempts)
+			}
+			if result.Records[1].Attempts != 0 {
+				t.Errorf("Expected a player without a participant record to have 0 attempts, got %d", result.Records[1].Attempts)
+			}
+		})
+	}
+}
+func TestService_UpdateResetSchedule(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		schedule     string
+		wantErr      error
+		wantSchedule string
+	}{
+		{name: "daily schedule", schedule: "0 0 * * *", wantSchedule: "0 0 * * *"},
+		{name: "weekly alias", schedule: "@weekly", wantSchedule: "@weekly"},
+		{name: "clear schedule", schedule: "", wantSchedule: ""},
+		{name: "invalid expression", schedule: "not a cron", wantErr: tournament.ErrInvalidResetSchedule, wantSchedule: "0 12 * * *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := infraTournament.NewMemoryRepository()
+			testsupport.SaveTournament(t, repo, "tournament-123", testsupport.WithResetSchedule("0 12 * * *"))
+
+			clock := testsupport.NewFakeClock()
+			clock.Advance(time.Hour)
+			service := tournaments.NewService(repo, infraTournament.NewMemoryParticipantRepository(), testsupport.NewFakeNakamaProvider())
+			service.Clock = clock.Now
+
+			_, err := service.UpdateResetSchedule(ctx, tournaments.UpdateResetScheduleCommand{
+				TournamentID:  "tournament-123",
+				ResetSchedule: tt.schedule,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateResetSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			stored, err := repo.Get(ctx, "tournament-123")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if stored.ResetSchedule != tt.wantSchedule {
+				t.Errorf("Expected reset schedule %q, got %q", tt.wantSchedule, stored.ResetSchedule)
+			}
+		})
+	}
+}
+
+func TestService_JoinTournament(t *testing.T) {
+	ctx := context.Background()
+	opens := testsupport.DefaultTime.Add(-time.Hour)
+	closes := testsupport.DefaultTime.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		opts    []testsupport.TournamentOption
+		advance time.Duration
+		wantErr error
+	}{
+		{name: "before window", opts: []testsupport.TournamentOption{testsupport.WithJoinWindow(&opens, &closes)}, advance: -2 * time.Hour, wantErr: tournament.ErrJoinWindowClosed},
+		{name: "in window", opts: []testsupport.TournamentOption{testsupport.WithJoinWindow(&opens, &closes)}, advance: -30 * time.Minute},
+		{name: "after window", opts: []testsupport.TournamentOption{testsupport.WithJoinWindow(&opens, &closes)}, advance: 2 * time.Hour, wantErr: tournament.ErrJoinWindowClosed},
+		{name: "default window before start", advance: -time.Minute, wantErr: tournament.ErrJoinWindowClosed},
+		{name: "default window after start", advance: 48 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := infraTournament.NewMemoryRepository()
+			participants := infraTournament.NewMemoryParticipantRepository()
+			testsupport.SaveTournament(t, repo, "tournament-123", tt.opts...)
+
+			clock := testsupport.NewFakeClock()
+			clock.Advance(tt.advance)
+			service := tournaments.NewService(repo, participants, testsupport.NewFakeNakamaProvider())
+			service.Clock = clock.Now
+
+			participant, err := service.JoinTournament(ctx, tournaments.JoinTournamentCommand{TournamentID: "tournament-123", PlayerID: "player-1"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("JoinTournament() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			_, getErr := participants.Get(ctx, "tournament-123", "player-1")
+			if tt.wantErr != nil {
+				if !errors.Is(getErr, tournament.ErrParticipantNotFound) {
+					t.Errorf("Expected no participant to be stored, got %v", getErr)
+				}
+				return
+			}
+			if getErr != nil {
+				t.Fatalf("Get() error = %v", getErr)
+			}
+			if !participant.JoinedAt.Equal(clock.Now()) {
+				t.Errorf("JoinedAt = %v, want %v", participant.JoinedAt, clock.Now())
+			}
+		})
+	}
+}
+
+func TestService_JoinTournament_Limits(t *testing.T) {
+	ctx := context.Background()
+	repo := infraTournament.NewMemoryRepository()
+	testsupport.SaveTournament(t, repo, "tournament-123", testsupport.WithMaxSize(1))
+
+	clock := testsupport.NewFakeClock()
+	service := tournaments.NewService(repo, infraTournament.NewMemoryParticipantRepository(), testsupport.NewFakeNakamaProvider())
+	service.Clock = clock.Now
+
+	join := func(player shared.PlayerID) error {
+		_, err := service.JoinTournament(ctx, tournaments.JoinTournamentCommand{TournamentID: "tournament-123", PlayerID: player})
+		return err
+	}
+	if err := join("player-1"); err != nil {
+		t.Fatalf("JoinTournament() error = %v", err)
+	}
+	if err := join("player-1"); !errors.Is(err, tournament.ErrParticipantAlreadyJoined) {
+		t.Errorf("JoinTournament() repeat error = %v, want %v", err, tournament.ErrParticipantAlreadyJoined)
+	}
+	if err := join("player-2"); !errors.Is(err, tournament.ErrTournamentFull) {
+		t.Errorf("JoinTournament() full error = %v, want %v", err, tournament.ErrTournamentFull)
+	}
+}
+
+func TestService_JoinTournament_ConcurrentRespectsMaxSize(t *testing.T) {
+	ctx := context.Background()
+	repo := infraTournament.NewMemoryRepository()
+	testsupport.SaveTournament(t, repo, "tournament-123", testsupport.WithMaxSize(2))
+	participants := infraTournament.NewMemoryParticipantRepository()
+	service := tournaments.NewService(repo, participants, testsupport.NewFakeNakamaProvider())
+	service.Clock = testsupport.NewFakeClock().Now
+
+	const players = 8
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		joined int
+	)
+	for i := 0; i < players; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := service.JoinTournament(ctx, tournaments.JoinTournamentCommand{TournamentID: "tournament-123", PlayerID: shared.PlayerID(fmt.Sprintf("player-%d", i))})
+			switch {
+			case err == nil:
+				mu.Lock()
+				joined++
+				mu.Unlock()
+			case !errors.Is(err, tournament.ErrTournamentFull) && !errors.Is(err, tournament.ErrConcurrentModification):
+				t.Errorf("JoinTournament() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stored, err := repo.Get(ctx, "tournament-123")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	list, err := participants.ListByTournament(ctx, "tournament-123")
+	if err != nil {
+		t.Fatalf("ListByTournament() error = %v", err)
+	}
+	if joined > 2 || len(list) != joined || stored.Joined != joined {
+		t.Errorf("joined = %d, participants = %d, places taken = %d; want at most 2, all equal", joined, len(list), stored.Joined)
+	}
+}
+
+func TestService_JoinTournament_ConcurrentSamePlayer(t *testing.T) {
+	ctx := context.Background()
+	repo := infraTournament.NewMemoryRepository()
+	testsupport.SaveTournament(t, repo, "tournament-123")
+	participants := infraTournament.NewMemoryParticipantRepository()
+	service := tournaments.NewService(repo, participants, testsupport.NewFakeNakamaProvider())
+	service.Clock = testsupport.NewFakeClock().Now
+
+	const attempts = 8
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		joined int
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.JoinTournament(ctx, tournaments.JoinTournamentCommand{TournamentID: "tournament-123", PlayerID: "player-1"})
+			switch {
+			case err == nil:
+				mu.Lock()
+				joined++
+				mu.Unlock()
+			case !errors.Is(err, tournament.ErrParticipantAlreadyJoined) && !errors.Is(err, tournament.ErrConcurrentModification):
+				t.Errorf("JoinTournament() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stored, err := repo.Get(ctx, "tournament-123")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if joined > 1 || stored.Joined != joined {
+		t.Errorf("joined = %d, places taken = %d; want at most one join holding one place", joined, stored.Joined)
+	}
+}
+
+func TestService_JoinTournament_Provider(t *testing.T) {
+	ctx := context.Background()
+	repo := infraTournament.NewMemoryRepository()
+	testsupport.SaveTournament(t, repo, "tournament-123")
+	participants := infraTournament.NewMemoryParticipantRepository()
+	provider := testsupport.NewFakeNakamaProvider()
+	service := tournaments.NewService(repo, participants, provider)
+	service.Clock = testsupport.NewFakeClock().Now
+	cmd := tournaments.JoinTournamentCommand{TournamentID: "tournament-123", PlayerID: "player-1"}
+
+	provider.JoinErr = errors.New("nakama unavailable")
+	if _, err := service.JoinTournament(ctx, cmd); !errors.Is(err, provider.JoinErr) {
+		t.Fatalf("JoinTournament() error = %v, wantErr %v", err, provider.JoinErr)
+	}
+	if _, err := participants.Get(ctx, "tournament-123", "player-1"); !errors.Is(err, tournament.ErrParticipantNotFound) {
+		t.Errorf("Expected no participant after a failed Nakama join, got %v", err)
+	}
+
+	provider.JoinErr = nil
+	if _, err := service.JoinTournament(ctx, cmd); err != nil {
+		t.Fatalf("JoinTournament() retry error = %v", err)
+	}
+	if !provider.Joined("tournament-123", "player-1") {
+		t.Error("Expected the player to be joined in Nakama")
+	}
+}
+
+func TestService_CancelTournament(t *testing.T) {
+	ctx := context.Background()
+	repo := infraTournament.NewMemoryRepository()
+	testsupport.SaveTournament(t, repo, "tournament-123")
+	testsupport.SaveTournament(t, repo, "tournament-456")
+	provider := testsupport.NewFakeNakamaProvider()
+	service := tournaments.NewService(repo, infraTournament.NewMemoryParticipantRepository(), provider)
+	service.Clock = testsupport.NewFakeClock().Now
+
+	if _, err := service.JoinTournament(ctx, tournaments.JoinTournamentCommand{TournamentID: "tournament-123", PlayerID: "player-1"}); err != nil {
+		t.Fatalf("JoinTournament() error = %v", err)
+	}
+
+	cancel := tournaments.CancelTournamentCommand{TournamentID: "tournament-123", Reason: "scoring bug"}
+	result, err := service.CancelTournament(ctx, cancel)
+	if err != nil {
+		t.Fatalf("CancelTournament() error = %v", err)
+	}
+	if result.Tournament.State != tournament.StateCancelled || result.Tournament.CancelReason != "scoring bug" {
+		t.Errorf("Expected cancelled tournament with reason, got %v %q", result.Tournament.State, result.Tournament.CancelReason)
+	}
+	if len(result.Refunds) != 1 || result.Refunds[0].PlayerID != "player-1" {
+		t.Errorf("Expected player-1 to be refunded, got %v", result.Refunds)
+	}
+	if provider.Joined("tournament-123", "player-1") {
+		t.Error("Expected tournament to be deleted from Nakama")
+	}
+	if _, err := service.CancelTournament(ctx, cancel); !errors.Is(err, tournament.ErrTournamentCancelled) {
+		t.Errorf("CancelTournament() twice error = %v, want %v", err, tournament.ErrTournamentCancelled)
+	}
+
+	page, err := service.ListTournaments(ctx, tournaments.ListTournamentsQuery{})
+	if err != nil {
+		t.Fatalf("ListTournaments() error = %v", err)
+	}
+	if page.Total != 1 || len(page.Items) != 1 || page.Items[0].ID != "tournament-456" {
+		t.Errorf("Expected only tournament-456 listed, got %d items of %d", len(page.Items), page.Total)
+	}
+	page, err = service.ListTournaments(ctx, tournaments.ListTournamentsQuery{IncludeCancelled: true})
+	if err != nil {
+		t.Fatalf("ListTournaments() error = %v", err)
+	}
+	if page.Total != 2 || len(page.Items) != 2 {
+		t.Errorf("Expected both tournaments with IncludeCancelled, got %d items of %d", len(page.Items), page.Total)
+	}
+}
+
+func TestService_CancelTournament_DeleteFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := infraTournament.NewMemoryRepository()
+	testsupport.SaveTournament(t, repo, "tournament-123")
+	provider := testsupport.NewFakeNakamaProvider()
+	if err := provider.CreateTournament(ctx, tournaments.CreateTournamentParams{ID: "tournament-123"}); err != nil {
+		t.Fatalf("CreateTournament() error = %v", err)
+	}
+	service := tournaments.NewService(repo, infraTournament.NewMemoryParticipantRepository(), provider)
+	service.Clock = testsupport.NewFakeClock().Now
+
+	provider.DeleteErr = errors.New("nakama down")
+	if _, err := service.CancelTournament(ctx, tournaments.CancelTournamentCommand{TournamentID: "tournament-123", Reason: "scoring bug"}); err != nil {
+		t.Fatalf("CancelTournament() error = %v", err)
+	}
+	if stored, _ := repo.Get(ctx, "tournament-123"); stored.State != tournament.StateCancelled {
+		t.Fatalf("Expected state %v after the failed delete, got %v", tournament.StateCancelled, stored.State)
+	}
+
+	// Reconcile finishes the delete instead of recreating the tournament.
+	provider.DeleteErr = nil
+	report, err := service.Reconcile(ctx, tournaments.ReconcileCommand{})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	want := []tournaments.ReconcileEntry{{TournamentID: "tournament-123", Action: tournaments.ReconcileDeleted}}
+	if !reflect.DeepEqual(report.Entries, want) {
+		t.Errorf("Entries = %+v, want %+v", report.Entries, want)
+	}
+	if _, ok := provider.Created("tournament-123"); ok {
+		t.Error("Expected Reconcile to delete the cancelled tournament from Nakama")
+	}
+}
+
+func TestService_SnapshotStandings_SurvivesDeletion(t *testing.T) {
+	ctx := context.Background()
+	repo := infraTournament.NewMemoryRepository()
+	testsupport.SaveTournament(t, repo, "tournament-123", testsupport.WithTitle("Weekly Cup"))
+	provider := testsupport.NewFakeNakamaProvider()
+	provider.Records["tournament-123"] = tournaments.RecordList{Records: []tournaments.Record{
+		{OwnerID: "player-1", Username: "alice", Score: 300, Rank: 1},
+		{OwnerID: "player-2", Username: "bob", Score: 200, Rank: 2},
+	}}
+	clock := testsupport.NewFakeClock()
+
+	service := tournaments.NewService(repo, infraTournament.NewMemoryParticipantRepository(), provider)
+	service.Clock = clock.Now
+	service.Snapshots = infraTournament.NewMemorySnapshotRepository()
+
+	snapshot, err := service.SnapshotStandings(ctx, "tournament-123")
+	if err != nil {
+		t.Fatalf("SnapshotStandings() error = %v", err)
+	}
+	if len(snapshot.Standings) != 2 || snapshot.Title != "Weekly Cup" || !snapshot.TakenAt.Equal(clock.Now()) {
+		t.Fatalf("Unexpected snapshot %+v", snapshot)
+	}
+
+	if err := service.DeleteTournament(ctx, tournaments.DeleteTournamentCommand{TournamentID: "tournament-123"}); err != nil {
+		t.Fatalf("DeleteTournament() error = %v", err)
+	}
+	provider.Records["tournament-123"] = tournaments.RecordList{}
+
+	got, err := service.GetSnapshot(ctx, "tournament-123")
+	if err != nil {
+		t.Fatalf("GetSnapshot() error = %v", err)
+	}
+	if len(got.Standings) != 2 || got.Standings[0].OwnerID != "player-1" || got.Standings[1].Rank != 2 {
+		t.Errorf("Unexpected standings %+v", got.Standings)
+	}
+
+	if _, err := service.GetSnapshot(ctx, "other"); !errors.Is(err, tournament.ErrSnapshotNotFound) {
+		t.Errorf("GetSnapshot() error = %v, wantErr %v", err, tournament.ErrSnapshotNotFound)
+	}
+}
+
+func TestService_SnapshotStandings_PagesThroughRecords(t *testing.T) {
+	ctx := context.Background()
+	repo := infraTournament.NewMemoryRepository()
+	testsupport.SaveTournament(t, repo, "tournament-123")
+
+	pages := map[string]tournaments.RecordList{
+		"":       {Records: []tournaments.Record{{OwnerID: "player-1", Rank: 1}}, NextCursor: "page-2"},
+		"page-2": {Records: []tournaments.Record{{OwnerID: "player-2", Rank: 2}}},
+	}
+	var limits []int
+	provider := &mockNakamaProvider{
+		listRecordsFunc: func(ctx context.Context, tournamentID shared.TournamentID, limit int, cursor string) (tournaments.RecordList, error) {
+			limits = append(limits, limit)
+			return pages[cursor], nil
+		},
+	}
+
+	service := tournaments.NewService(repo, &mockParticipantRepo{}, provider)
+	service.Snapshots = infraTournament.NewMemorySnapshotRepository()
+
+	snapshot, err := service.SnapshotStandings(ctx, "tournament-123")
+	if err != nil {
+		t.Fatalf("SnapshotStandings() error = %v", err)
+	}
+	if len(snapshot.Standings) != 2 || snapshot.Standings[1].OwnerID != "player-2" {
+		t.Errorf("Unexpected standings %+v", snapshot.Standings)
+	}
+	if len(limits) != 2 || limits[0] != tournaments.MaxStandingsLimit {
+		t.Errorf("Expected 2 pages of %d, got %v", tournaments.MaxStandingsLimit, limits)
+	}
+}
+
+func TestService_SnapshotStandings_Errors(t *testing.T) {
+	ctx := context.Background()
+	repo := infraTournament.NewMemoryRepository()
+	testsupport.SaveTournament(t, repo, "tournament-123")
+	provider := testsupport.NewFakeNakamaProvider()
+
+	service := tournaments.NewService(repo, &mockParticipantRepo{}, provider)
+	if _, err := service.SnapshotStandings(ctx, "tournament-123"); !errors.Is(err, tournaments.ErrSnapshotsNotConfigured) {
+		t.Errorf("SnapshotStandings() error = %v, wantErr %v", err, tournaments.ErrSnapshotsNotConfigured)
+	}
+
+	service.Snapshots = infraTournament.NewMemorySnapshotRepository()
+	if _, err := service.SnapshotStandings(ctx, "missing"); !errors.Is(err, tournament.ErrTournamentNotFound) {
+		t.Errorf("SnapshotStandings() error = %v, wantErr %v", err, tournament.ErrTournamentNotFound)
+	}
+
+	provider.ListErr = errors.New("nakama unavailable")
+	if _, err := service.SnapshotStandings(ctx, "tournament-123"); !errors.Is(err, provider.ListErr) {
+		t.Errorf("SnapshotStandings() error = %v, wantErr %v", err, provider.ListErr)
+	}
+	if _, err := service.GetSnapshot(ctx, "tournament-123"); !errors.Is(err, tournament.ErrSnapshotNotFound) {
+		t.Errorf("GetSnapshot() error = %v, wantErr %v", err, tournament.ErrSnapshotNotFound)
+	}
+}
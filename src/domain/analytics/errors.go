@@ -1,10 +1,34 @@
 package analytics
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrSessionNotFound    = errors.New("session not found")
 	ErrSessionAlreadyEnded = errors.New("session already ended")
+	ErrTooManySessions    = errors.New("too many concurrent sessions")
 	ErrInvalidEvent       = errors.New("invalid event")
 	ErrDispatchFailed     = errors.New("failed to dispatch events")
+	ErrInvalidExperiment  = errors.New("experiment needs a key and uniquely named variants with positive weights")
 )
+
+// DispatchError describes a batch the analytics backend rejected. It matches
+// ErrDispatchFailed with errors.Is.
+type DispatchError struct {
+	StatusCode int
+	// Body is the start of the backend's response, truncated by the dispatcher.
+	Body string
+}
+
+func (e *DispatchError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s: status %d", ErrDispatchFailed, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: status %d: %s", ErrDispatchFailed, e.StatusCode, e.Body)
+}
+
+func (e *DispatchError) Unwrap() error {
+	return ErrDispatchFailed
+}
@@ -2,6 +2,7 @@ package analytics
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
@@ -21,6 +22,8 @@ type EventName string
 const (
 	EventNameStart EventName = "start"
 	EventNameEnd   EventName = "end"
+	// EventNameExperimentExposure records that a user was shown an experiment variant.
+	EventNameExperimentExposure EventName = "experiment_exposure"
 )
 
 // Context represents metadata attached to every event.
@@ -47,15 +50,20 @@ type OSInfo struct {
 	Version string
 }
 
-// Event is the domain aggregate for analytics events.
+// Event is the domain aggregate for analytics events. Properties carry
+// free-form attributes of a track event, such as the level reached or the
+// currency spent; Traits describe the user on an identify event, such as
+// email or plan.
 type Event struct {
-	Type      EventType
-	UserID    shared.PlayerID
-	Name      EventName
-	Context   Context
-	App       *AppInfo
-	OS        *OSInfo
-	Timestamp time.Time
+	Type       EventType
+	UserID     shared.PlayerID
+	Name       EventName
+	Context    Context
+	App        *AppInfo
+	OS         *OSInfo
+	Properties map[string]any
+	Traits     map[string]any
+	Timestamp  time.Time
 }
 
 // NewIdentifyEvent creates an identity event.
@@ -112,6 +120,37 @@ func (e *Event) WithOSInfo(name, version string) *Event {
 	return e
 }
 
+// WithProperty attaches an event-specific attribute, such as the variant of
+// an experiment exposure.
+func (e *Event) WithProperty(key string, value any) *Event {
+	if e.Properties == nil {
+		e.Properties = make(map[string]any)
+	}
+	e.Properties[key] = value
+	return e
+}
+
+// WithProperties merges properties into the event's attributes, overwriting
+// keys that are already set.
+func (e *Event) WithProperties(properties map[string]any) *Event {
+	for key, value := range properties {
+		e.WithProperty(key, value)
+	}
+	return e
+}
+
+// WithTraits merges traits into the user description of an identify event,
+// overwriting keys that are already set.
+func (e *Event) WithTraits(traits map[string]any) *Event {
+	if len(traits) > 0 && e.Traits == nil {
+		e.Traits = make(map[string]any, len(traits))
+	}
+	for key, value := range traits {
+		e.Traits[key] = value
+	}
+	return e
+}
+
 // Validate ensures the event is well-formed.
 func (e *Event) Validate() error {
 	if e.Type == "" {
@@ -126,5 +165,17 @@ func (e *Event) Validate() error {
 	if e.Timestamp.IsZero() {
 		return errors.New("timestamp is required")
 	}
+	if err := validateKeys("properties", e.Properties); err != nil {
+		return err
+	}
+	return validateKeys("traits", e.Traits)
+}
+
+func validateKeys(field string, values map[string]any) error {
+	for key := range values {
+		if strings.TrimSpace(key) == "" {
+			return shared.NewValidationError(field, field+" keys cannot be empty")
+		}
+	}
 	return nil
 }
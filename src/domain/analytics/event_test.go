@@ -150,6 +150,49 @@ func TestEvent_WithOSInfo(t *testing.T) {
 	}
 }
 
+func TestEvent_WithProperty(t *testing.T) {
+	ctx := analytics.Context{Direct: true}
+	event, _ := analytics.NewTrackEvent("player-123", analytics.EventNameExperimentExposure, ctx, time.Now())
+
+	event.WithProperty("experiment", "checkout_button").WithProperty("variant", "green")
+
+	if event.Properties["experiment"] != "checkout_button" {
+		t.Errorf("Expected experiment checkout_button, got %v", event.Properties["experiment"])
+	}
+	if event.Properties["variant"] != "green" {
+		t.Errorf("Expected variant green, got %v", event.Properties["variant"])
+	}
+}
+
+func TestEvent_WithProperties(t *testing.T) {
+	ctx := analytics.Context{Direct: true}
+	event, _ := analytics.NewTrackEvent("player-123", "level_complete", ctx, time.Now())
+
+	event.WithProperty("level", 3).WithProperties(map[string]any{"level": 4, "score": 1200.5})
+
+	if event.Properties["level"] != 4 {
+		t.Errorf("Expected level 4, got %v", event.Properties["level"])
+	}
+	if event.Properties["score"] != 1200.5 {
+		t.Errorf("Expected score 1200.5, got %v", event.Properties["score"])
+	}
+}
+
+func TestEvent_WithTraits(t *testing.T) {
+	ctx := analytics.Context{Direct: true}
+	event, _ := analytics.NewIdentifyEvent("player-123", ctx, time.Now())
+
+	event.WithTraits(nil)
+	if event.Traits != nil {
+		t.Errorf("Expected no traits, got %v", event.Traits)
+	}
+
+	event.WithTraits(map[string]any{"email": "player@example.com", "plan": "pro"})
+	if event.Traits["email"] != "player@example.com" || event.Traits["plan"] != "pro" {
+		t.Errorf("Unexpected traits %v", event.Traits)
+	}
+}
+
 func TestEvent_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -192,6 +235,27 @@ func TestEvent_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "empty property key",
+			event: &analytics.Event{
+				Type:       analytics.EventTypeTrack,
+				UserID:     "player-123",
+				Name:       analytics.EventNameStart,
+				Properties: map[string]any{"": 3},
+				Timestamp:  time.Now(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "blank trait key",
+			event: &analytics.Event{
+				Type:      analytics.EventTypeIdentify,
+				UserID:    "player-123",
+				Traits:    map[string]any{" ": "pro"},
+				Timestamp: time.Now(),
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,39 @@
+package analytics
+
+// Variant is one arm of an experiment. Weight is its share of users relative
+// to the other variants.
+type Variant struct {
+	Name   string
+	Weight int
+}
+
+// Experiment describes an A/B test and how users are split across its variants.
+type Experiment struct {
+	Key      string
+	Variants []Variant
+}
+
+// TotalWeight sums the variant weights.
+func (e Experiment) TotalWeight() int {
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	return total
+}
+
+// Validate ensures the experiment can be bucketed: it needs a key and at least
+// one uniquely named variant, and every weight must be positive.
+func (e Experiment) Validate() error {
+	if e.Key == "" || len(e.Variants) == 0 {
+		return ErrInvalidExperiment
+	}
+	seen := make(map[string]bool, len(e.Variants))
+	for _, v := range e.Variants {
+		if v.Name == "" || v.Weight <= 0 || seen[v.Name] {
+			return ErrInvalidExperiment
+		}
+		seen[v.Name] = true
+	}
+	return nil
+}
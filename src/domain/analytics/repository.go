@@ -2,11 +2,14 @@ package analytics
 
 import (
 	"context"
+	"time"
 
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
 )
 
-// EventDispatcher sends events to external analytics services.
+// EventDispatcher sends events to external analytics services. An empty or
+// nil batch succeeds without contacting the service, so callers that filter
+// or sample events need not check for an empty result first.
 type EventDispatcher interface {
 	Dispatch(ctx context.Context, events []*Event) error
 }
@@ -17,3 +20,28 @@ type SessionRepository interface {
 	Get(ctx context.Context, userID shared.PlayerID) (*Session, error)
 	Delete(ctx context.Context, userID shared.PlayerID) error
 }
+
+// SessionHistory keeps every session a user has started, identified by its
+// ID or start time, so sessions other than the latest can still be ended.
+type SessionHistory interface {
+	Record(ctx context.Context, session *Session) error
+	ListActive(ctx context.Context, userID shared.PlayerID) ([]*Session, error)
+	// Get returns the session with the given ID, or ErrSessionNotFound.
+	Get(ctx context.Context, userID shared.PlayerID, sessionID string) (*Session, error)
+}
+
+// FailedBatch is an event batch that could not be delivered and is kept for replay.
+type FailedBatch struct {
+	ID        string
+	Events    []*Event
+	FailedAt  time.Time
+	Attempts  int
+	LastError string
+}
+
+// FailedBatchStore keeps undelivered batches so they survive until a replay succeeds.
+type FailedBatchStore interface {
+	Save(ctx context.Context, batch FailedBatch) error
+	List(ctx context.Context) ([]FailedBatch, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -17,6 +17,9 @@ const (
 
 // Session aggregate tracks user session lifecycle.
 type Session struct {
+	// ID identifies one of a user's sessions, such as the Nakama session ID.
+	// It is empty for sessions started without one.
+	ID        string
 	UserID    shared.PlayerID
 	State     SessionState
 	Version   string
@@ -58,6 +61,18 @@ func (s *Session) End(endedAt time.Time) error {
 	return nil
 }
 
+// SameAs reports whether other refers to the same session. Sessions are
+// matched by ID when both have one and by start time otherwise.
+func (s *Session) SameAs(other *Session) bool {
+	if s.UserID != other.UserID {
+		return false
+	}
+	if s.ID != "" && other.ID != "" {
+		return s.ID == other.ID
+	}
+	return s.StartedAt.Equal(other.StartedAt)
+}
+
 // IsActive checks if the session is currently active.
 func (s *Session) IsActive() bool {
 	return s.State == SessionStateActive
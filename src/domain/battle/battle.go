@@ -20,15 +20,35 @@ type PlayerSlot struct {
 	Ready    bool
 }
 
+// Status represents the battle lifecycle state.
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusCancelled Status = "cancelled"
+	// StatusFinished marks a battle whose match has ended.
+	StatusFinished Status = "finished"
+)
+
+// DefaultMaxSlots is the capacity of a battle started without a preset.
+const DefaultMaxSlots = 4
+
 // Battle aggregate orchestrates match lifecycle around Nakama matches.
 type Battle struct {
 	ID             shared.BattleID
+	MatchID        string
 	Leader         shared.PlayerID
+	Status         Status
 	Slots          []PlayerSlot
 	StateSnapshot  MatchState
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 	IdempotencyKey shared.IdempotencyKey
+	// MaxSlots caps len(Slots). Zero means unlimited.
+	MaxSlots int
+	// Version is incremented by the repository on every successful save and
+	// must match the stored version for a save to succeed.
+	Version int64
 }
 
 func NewBattle(id shared.BattleID, leader shared.PlayerID, key shared.IdempotencyKey, now time.Time) (*Battle, error) {
@@ -44,6 +64,7 @@ func NewBattle(id shared.BattleID, leader shared.PlayerID, key shared.Idempotenc
 	return &Battle{
 		ID:             id,
 		Leader:         leader,
+		Status:         StatusActive,
 		Slots:          []PlayerSlot{{PlayerID: leader, JoinedAt: now, Ready: true}},
 		CreatedAt:      now,
 		UpdatedAt:      now,
@@ -51,18 +72,39 @@ func NewBattle(id shared.BattleID, leader shared.PlayerID, key shared.Idempotenc
 	}, nil
 }
 
+// AddPlayer gives player a slot in the battle. Cancelled, finished and full
+// battles accept no new players.
 func (b *Battle) AddPlayer(player shared.PlayerID, now time.Time) error {
-	for _, slot := range b.Slots {
-		if slot.PlayerID == player {
-			return ErrPlayerAlreadyJoined
-		}
+	if err := b.checkActive(); err != nil {
+		return err
+	}
+	if b.HasPlayer(player) {
+		return ErrPlayerAlreadyJoined
+	}
+	if b.MaxSlots > 0 && len(b.Slots) >= b.MaxSlots {
+		return ErrBattleFull
 	}
 	b.Slots = append(b.Slots, PlayerSlot{PlayerID: player, JoinedAt: now})
 	b.UpdatedAt = now
 	return nil
 }
 
+// HasPlayer reports whether player holds a slot in the battle.
+func (b *Battle) HasPlayer(player shared.PlayerID) bool {
+	for _, slot := range b.Slots {
+		if slot.PlayerID == player {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkReady sets a player's ready flag. Cancelled and finished battles cannot
+// be readied.
 func (b *Battle) MarkReady(player shared.PlayerID, ready bool, now time.Time) error {
+	if err := b.checkActive(); err != nil {
+		return err
+	}
 	for i, slot := range b.Slots {
 		if slot.PlayerID == player {
 			slot.Ready = ready
@@ -81,3 +123,58 @@ func (b *Battle) UpdateSnapshot(state MatchState) {
 	b.StateSnapshot = state
 	b.UpdatedAt = state.UpdatedAt
 }
+
+// AllReady reports whether every slot is marked ready.
+func (b *Battle) AllReady() bool {
+	for _, slot := range b.Slots {
+		if !slot.Ready {
+			return false
+		}
+	}
+	return len(b.Slots) > 0
+}
+
+// ReadyToStart reports whether an active battle has at least two slots and
+// every player other than the leader is ready.
+func (b *Battle) ReadyToStart() bool {
+	if b.Status != StatusActive || len(b.Slots) < 2 {
+		return false
+	}
+	for _, slot := range b.Slots {
+		if slot.PlayerID != b.Leader && !slot.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// Cancel ends a battle before it completes.
+func (b *Battle) Cancel(now time.Time) error {
+	if err := b.checkActive(); err != nil {
+		return err
+	}
+	b.Status = StatusCancelled
+	b.UpdatedAt = now
+	return nil
+}
+
+// Finish records that the battle's match has ended.
+func (b *Battle) Finish(now time.Time) error {
+	if err := b.checkActive(); err != nil {
+		return err
+	}
+	b.Status = StatusFinished
+	b.UpdatedAt = now
+	return nil
+}
+
+// checkActive returns the error for acting on a battle that is over.
+func (b *Battle) checkActive() error {
+	switch b.Status {
+	case StatusCancelled:
+		return ErrBattleCancelled
+	case StatusFinished:
+		return ErrBattleFinished
+	}
+	return nil
+}
@@ -5,4 +5,11 @@ import "errors"
 var (
 	ErrPlayerAlreadyJoined = errors.New("player already joined battle")
 	ErrPlayerNotFound      = errors.New("player not in battle")
+	ErrBattleCancelled     = errors.New("battle already cancelled")
+	ErrNotLeader           = errors.New("player is not the battle leader")
+	ErrBattleFull          = errors.New("battle has no free slots")
+	ErrBattleFinished      = errors.New("battle already finished")
+	// ErrConcurrentModification is returned by Save when the battle changed
+	// since it was read.
+	ErrConcurrentModification = errors.New("battle was modified concurrently")
 )
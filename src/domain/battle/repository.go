@@ -6,6 +6,27 @@ import "github.com/heroiclabs/nakama/v3/src/domain/shared"
 
 type Repository interface {
 	Get(ctx context.Context, id shared.BattleID) (*Battle, error)
+	// GetByIdempotencyKey returns the battle started with key, or
+	// shared.ErrNotFound.
+	GetByIdempotencyKey(ctx context.Context, key shared.IdempotencyKey) (*Battle, error)
+	// Save stores a battle using optimistic locking: the battle's Version must
+	// match the stored one (zero for a new battle), or Save fails with
+	// ErrConcurrentModification. On success Version is incremented.
 	Save(ctx context.Context, battle *Battle) error
+	// StoreSnapshot replaces only the match state of a stored battle, so it
+	// never overwrites slots or status saved concurrently. It increments the
+	// stored Version.
 	StoreSnapshot(ctx context.Context, id shared.BattleID, state MatchState) error
+	// ListByPlayer returns a page of the battles the player holds a slot in,
+	// newest first.
+	ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*Battle, error)
+}
+
+// ActiveIndex tracks the IDs of battles that are still running so they can be
+// found by leader. It holds no battle state: callers load each battle from the
+// Repository before acting on it.
+type ActiveIndex interface {
+	Add(ctx context.Context, battle *Battle) error
+	ListByLeader(ctx context.Context, leader shared.PlayerID) ([]shared.BattleID, error)
+	Remove(ctx context.Context, id shared.BattleID) error
 }
@@ -12,6 +12,21 @@ const (
 	CommandStatePending   CommandState = "pending"
 	CommandStateCompleted CommandState = "completed"
 	CommandStateFailed    CommandState = "failed"
+	// CommandStateDeadLettered is terminal: the command failed more than
+	// MaxRetries times and is not retried again.
+	CommandStateDeadLettered CommandState = "dead_lettered"
+	// CommandStateAborted is terminal: a synchronous execution failed and the
+	// error went back to the caller, who decides whether to submit it again.
+	CommandStateAborted CommandState = "aborted"
+)
+
+const (
+	// DefaultMaxRetries is how many times a failed command is retried.
+	DefaultMaxRetries = 5
+	// RetryBaseDelay is the wait before the first retry; each further retry
+	// doubles it, up to RetryMaxDelay.
+	RetryBaseDelay = 5 * time.Second
+	RetryMaxDelay  = 10 * time.Minute
 )
 
 // Command aggregate tracks dedupe and retry policies for bot automation.
@@ -26,6 +41,16 @@ type Command struct {
 	RetryCount     int
 	LastError      string
 	CreatedAt      time.Time
+	// PlayerID is who issued the command and is told when it completes.
+	PlayerID shared.PlayerID
+	// MaxRetries is how many failed attempts are retried before the command
+	// is dead-lettered.
+	MaxRetries int
+	// NextAttemptAt is when a failed command becomes due for its retry.
+	NextAttemptAt time.Time
+	// Response is the executor's output for a synchronous command, kept so a
+	// duplicate request can be answered without running it again.
+	Response []byte
 }
 
 func NewCommand(id shared.BotCommandID, channel string, payload []byte, key shared.IdempotencyKey, now time.Time) (*Command, error) {
@@ -42,18 +67,55 @@ func NewCommand(id shared.BotCommandID, channel string, payload []byte, key shar
 		IdempotencyKey: key,
 		State:          CommandStatePending,
 		CreatedAt:      now,
+		MaxRetries:     DefaultMaxRetries,
 	}, nil
 }
 
+// MarkAttempt records the outcome of an attempt. A failure schedules a retry
+// at NextRetryAt, or dead-letters the command once RetryCount exceeds
+// MaxRetries.
 func (c *Command) MarkAttempt(now time.Time, err error) {
 	c.AttemptedAt = now
 	if err != nil {
 		c.RetryCount++
-		c.State = CommandStateFailed
 		c.LastError = err.Error()
+		if c.RetryCount > c.MaxRetries {
+			c.State = CommandStateDeadLettered
+			c.NextAttemptAt = time.Time{}
+			return
+		}
+		c.State = CommandStateFailed
+		c.NextAttemptAt = c.NextRetryAt(now)
 		return
 	}
 	c.State = CommandStateCompleted
 	c.CompletedAt = now
 	c.LastError = ""
+	c.NextAttemptAt = time.Time{}
+}
+
+// Abort records a failed synchronous execution. Unlike MarkAttempt it never
+// schedules a retry, since the caller already has the error and may retry
+// itself.
+func (c *Command) Abort(now time.Time, err error) {
+	c.AttemptedAt = now
+	c.LastError = err.Error()
+	c.State = CommandStateAborted
+	c.NextAttemptAt = time.Time{}
+}
+
+// CanRetry reports whether the command failed and has retries left.
+func (c *Command) CanRetry() bool {
+	return c.State == CommandStateFailed && c.RetryCount <= c.MaxRetries
+}
+
+// NextRetryAt returns when the next retry is due after a failure at now:
+// RetryBaseDelay after the first failure, doubling with each further one up
+// to RetryMaxDelay.
+func (c *Command) NextRetryAt(now time.Time) time.Time {
+	delay := RetryBaseDelay
+	for i := 1; i < c.RetryCount && delay < RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return now.Add(min(delay, RetryMaxDelay))
 }
@@ -0,0 +1,25 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// ErrCommandInFlight is returned when a command arrives while an earlier
+// attempt with the same idempotency key is still being handled. It wraps
+// shared.ErrDuplicate.
+var ErrCommandInFlight = fmt.Errorf("%w: bot command is already being handled", shared.ErrDuplicate)
+
+// ErrCommandNotFound is returned by Repository.Get for an unknown command. It
+// wraps shared.ErrNotFound.
+var ErrCommandNotFound = fmt.Errorf("%w: bot command", shared.ErrNotFound)
+
+// ErrSyncUnsupported is returned for a synchronous command when no executor
+// is configured to run it inline.
+var ErrSyncUnsupported = errors.New("synchronous bot commands are not supported")
+
+// ErrExecutionFailed wraps the executor's error when a synchronous command
+// fails.
+var ErrExecutionFailed = errors.New("bot command execution failed")
@@ -1,11 +1,26 @@
 package bot
 
 import "context"
+import "time"
 
 import "github.com/heroiclabs/nakama/v3/src/domain/shared"
 
 type Repository interface {
 	ReserveCommand(ctx context.Context, key shared.IdempotencyKey) (*Command, error)
+	// Get returns the command with the given ID, or ErrCommandNotFound.
+	Get(ctx context.Context, id shared.BotCommandID) (*Command, error)
 	Save(ctx context.Context, command *Command) error
 	MarkProcessed(ctx context.Context, id shared.BotCommandID, state CommandState) error
+	// ListDueForRetry returns up to limit commands that CanRetry and whose
+	// NextAttemptAt is not after now, soonest due first.
+	ListDueForRetry(ctx context.Context, now time.Time, limit int) ([]*Command, error)
+}
+
+// AckStore records which commands have already been acknowledged to the player.
+type AckStore interface {
+	// Claim marks the key as acknowledged. It reports false when the key was
+	// already claimed, in which case no acknowledgement should be sent.
+	Claim(ctx context.Context, key shared.IdempotencyKey) (bool, error)
+	// Forget releases a claim so a failed acknowledgement can be retried.
+	Forget(ctx context.Context, key shared.IdempotencyKey) error
 }
@@ -5,4 +5,19 @@ import "errors"
 var (
 	ErrNameRequired   = errors.New("group name required")
 	ErrMemberNotFound = errors.New("group member not found")
+	ErrAlreadyMember  = errors.New("player is already a group member")
+	// ErrOwnerCannotLeave is returned when the owner tries to leave; ownership
+	// has to be transferred first so the group is never left without one.
+	ErrOwnerCannotLeave = errors.New("group owner must transfer ownership before leaving")
+	ErrNotOwner         = errors.New("player is not the group owner")
+	ErrNotManager       = errors.New("player is not a group owner or admin")
+	// ErrOwnerRoleReserved is returned by AssignRole for changes that would
+	// leave the group with no owner or several; use TransferOwnership instead.
+	ErrOwnerRoleReserved = errors.New("group owner can only change through an ownership transfer")
+	ErrGroupFull         = errors.New("group is full")
+	// ErrInvalidMaxMembers is returned for a negative member cap.
+	ErrInvalidMaxMembers = errors.New("max members must be non-negative")
+	// ErrConcurrentModification is returned by Save when the group changed
+	// since it was read.
+	ErrConcurrentModification = errors.New("group was modified concurrently")
 )
@@ -28,9 +28,17 @@ type Group struct {
 	Members     map[shared.PlayerID]Membership
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	// MaxMembers caps the member count, owner included. Zero means unlimited.
+	MaxMembers int
+	// Version is incremented by the repository on every successful save and
+	// must match the stored version for a save to succeed.
+	Version int64
 }
 
-func NewGroup(id shared.GroupID, name string, owner shared.PlayerID, now time.Time) (*Group, error) {
+// NewGroup creates a group owned by owner holding at most maxMembers members,
+// or any number when maxMembers is zero. When filter is non-nil the name is
+// screened with it and a *shared.ContentRejectedError is returned if blocked.
+func NewGroup(id shared.GroupID, name string, owner shared.PlayerID, maxMembers int, now time.Time, filter shared.ContentFilter) (*Group, error) {
 	if err := id.Validate(); err != nil {
 		return nil, err
 	}
@@ -40,25 +48,102 @@ func NewGroup(id shared.GroupID, name string, owner shared.PlayerID, now time.Ti
 	if name == "" {
 		return nil, ErrNameRequired
 	}
+	if maxMembers < 0 {
+		return nil, ErrInvalidMaxMembers
+	}
+	if err := shared.CheckContent(filter, "name", name); err != nil {
+		return nil, err
+	}
 	g := &Group{
-		ID:        id,
-		Name:      name,
-		Members:   make(map[shared.PlayerID]Membership),
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:         id,
+		Name:       name,
+		Members:    make(map[shared.PlayerID]Membership),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		MaxMembers: maxMembers,
 	}
 	g.Members[owner] = Membership{PlayerID: owner, Role: RoleOwner, JoinedAt: now}
 	return g, nil
 }
 
+// CheckManager returns ErrNotManager unless playerID is the owner or an admin.
+func (g *Group) CheckManager(playerID shared.PlayerID) error {
+	switch g.Members[playerID].Role {
+	case RoleOwner, RoleAdmin:
+		return nil
+	}
+	return ErrNotManager
+}
+
+// AssignRole changes a member's role. The owner role is never assigned or
+// taken away here, so the group always has exactly one owner.
 func (g *Group) AssignRole(playerID shared.PlayerID, role Role, now time.Time) error {
-	if _, ok := g.Members[playerID]; !ok {
+	member, ok := g.Members[playerID]
+	if !ok {
 		return ErrMemberNotFound
 	}
-	member := g.Members[playerID]
+	if role == RoleOwner || member.Role == RoleOwner {
+		return ErrOwnerRoleReserved
+	}
 	member.Role = role
-	member.JoinedAt = member.JoinedAt
 	g.Members[playerID] = member
 	g.UpdatedAt = now
 	return nil
 }
+
+// AddMember gives playerID the member role in the group, failing with
+// ErrGroupFull once MaxMembers is reached.
+func (g *Group) AddMember(playerID shared.PlayerID, now time.Time) (Membership, error) {
+	if err := playerID.Validate(); err != nil {
+		return Membership{}, err
+	}
+	if _, ok := g.Members[playerID]; ok {
+		return Membership{}, ErrAlreadyMember
+	}
+	if g.MaxMembers > 0 && len(g.Members) >= g.MaxMembers {
+		return Membership{}, ErrGroupFull
+	}
+	member := Membership{PlayerID: playerID, Role: RoleMember, JoinedAt: now}
+	g.Members[playerID] = member
+	g.UpdatedAt = now
+	return member, nil
+}
+
+// RemoveMember takes playerID out of the group. The owner cannot be removed.
+func (g *Group) RemoveMember(playerID shared.PlayerID, now time.Time) error {
+	member, ok := g.Members[playerID]
+	if !ok {
+		return ErrMemberNotFound
+	}
+	if member.Role == RoleOwner {
+		return ErrOwnerCannotLeave
+	}
+	delete(g.Members, playerID)
+	g.UpdatedAt = now
+	return nil
+}
+
+// TransferOwnership makes member to the owner and demotes from, who must be
+// the current owner, to admin.
+func (g *Group) TransferOwnership(from, to shared.PlayerID, now time.Time) error {
+	owner, ok := g.Members[from]
+	if !ok {
+		return ErrMemberNotFound
+	}
+	if owner.Role != RoleOwner {
+		return ErrNotOwner
+	}
+	successor, ok := g.Members[to]
+	if !ok {
+		return ErrMemberNotFound
+	}
+	if from == to {
+		return nil
+	}
+	owner.Role = RoleAdmin
+	successor.Role = RoleOwner
+	g.Members[from] = owner
+	g.Members[to] = successor
+	g.UpdatedAt = now
+	return nil
+}
@@ -1,11 +1,18 @@
 package group
 
-import "context"
+import (
+	"context"
 
-import "github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
 
 type Repository interface {
 	Get(ctx context.Context, id shared.GroupID) (*Group, error)
+	// Save stores a group using optimistic locking: the group's Version must
+	// match the stored one (zero for a new group), or Save fails with
+	// ErrConcurrentModification. On success Version is incremented.
 	Save(ctx context.Context, group *Group) error
 	AddMember(ctx context.Context, groupID shared.GroupID, member Membership) error
+	// List returns every locally stored group.
+	List(ctx context.Context) ([]*Group, error)
 }
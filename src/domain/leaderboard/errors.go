@@ -0,0 +1,15 @@
+package leaderboard
+
+import "errors"
+
+var (
+	ErrSubmissionNotFound        = errors.New("pending submission not found")
+	ErrSubmissionAlreadyReserved = errors.New("submission already reserved")
+	ErrReservationsUnavailable   = errors.New("submission reservations are not configured")
+	ErrScoreNotFound             = errors.New("leaderboard score not found")
+	ErrScoreConflict             = errors.New("leaderboard score was modified concurrently")
+	ErrScoresUnavailable         = errors.New("leaderboard score store is not configured")
+	ErrSeasonNotFound            = errors.New("season not found")
+	ErrSeasonAlreadyExists       = errors.New("season already exists")
+	ErrSeasonInactive            = errors.New("season is not active")
+)
@@ -13,9 +13,14 @@ type ScoreSubmission struct {
 	Value          int64
 	IdempotencyKey shared.IdempotencyKey
 	SubmittedAt    time.Time
+	// Operator is how Value combines with the stored score. Repositories
+	// writing to Nakama must pass it through rather than overwrite.
+	Operator Operator
 }
 
-// Season aggregates leaderboard policy.
+// Season aggregates leaderboard policy. A season accepts submissions from
+// StartsAt until EndsAt, so it opens and closes on schedule without anyone
+// toggling Active.
 type Season struct {
 	ID       shared.SeasonID
 	StartsAt time.Time
@@ -23,8 +28,31 @@ type Season struct {
 	Active   bool
 }
 
+// NewSeason creates a season scheduled for [startsAt, endsAt).
+func NewSeason(id shared.SeasonID, startsAt, endsAt time.Time) (*Season, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+	if startsAt.IsZero() {
+		return nil, shared.NewValidationError("starts_at", "season start is required")
+	}
+	if endsAt.IsZero() {
+		return nil, shared.NewValidationError("ends_at", "season end is required")
+	}
+	if !endsAt.After(startsAt) {
+		return nil, shared.NewValidationError("ends_at", "season must end after it starts")
+	}
+	return &Season{ID: id, StartsAt: startsAt, EndsAt: endsAt}, nil
+}
+
+// ActiveAt reports whether now falls within [StartsAt, EndsAt).
+func (s *Season) ActiveAt(now time.Time) bool {
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+// Activate refreshes Active for now.
 func (s *Season) Activate(now time.Time) {
-	s.Active = now.After(s.StartsAt) && now.Before(s.EndsAt)
+	s.Active = s.ActiveAt(now)
 }
 
 func (submission ScoreSubmission) Validate() error {
@@ -37,5 +65,8 @@ func (submission ScoreSubmission) Validate() error {
 	if err := submission.IdempotencyKey.Validate(); err != nil {
 		return err
 	}
+	if err := submission.Operator.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
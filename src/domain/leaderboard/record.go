@@ -0,0 +1,32 @@
+package leaderboard
+
+import (
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// Record is a ranked score on a season's leaderboard.
+type Record struct {
+	OwnerID  shared.PlayerID
+	Score    int64
+	Rank     int64
+	Metadata map[string]any
+	// ExpiresAt is set on records from windows closed by a reset, which only
+	// all-time listings include.
+	ExpiresAt time.Time
+}
+
+// RecordList is one page of leaderboard records. NextCursor is empty on the
+// last page.
+type RecordList struct {
+	Records    []Record
+	NextCursor string
+}
+
+// Rank is a player's position on a season's leaderboard.
+type Rank struct {
+	OwnerID shared.PlayerID
+	Score   int64
+	Rank    int64
+}
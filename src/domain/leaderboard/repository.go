@@ -6,5 +6,25 @@ import "github.com/heroiclabs/nakama/v3/src/domain/shared"
 
 type Repository interface {
 	SubmitScore(ctx context.Context, submission ScoreSubmission) error
+	// CreateSeason stores a new season schedule, or returns
+	// ErrSeasonAlreadyExists.
+	CreateSeason(ctx context.Context, season *Season) error
+	// GetSeason returns a season, or ErrSeasonNotFound.
 	GetSeason(ctx context.Context, id shared.SeasonID) (*Season, error)
+	// ListRecords returns up to limit records of a season, best first,
+	// starting at cursor. An empty cursor starts at the top.
+	ListRecords(ctx context.Context, season shared.SeasonID, limit int, cursor string) (RecordList, error)
+	// GetPlayerRank returns a player's rank, or ErrScoreNotFound when the
+	// player has no record.
+	GetPlayerRank(ctx context.Context, season shared.SeasonID, playerID shared.PlayerID) (Rank, error)
+}
+
+// PendingRepository holds reserved submissions awaiting confirmation. Pending
+// submissions are never written to the leaderboard, so they stay out of listings.
+type PendingRepository interface {
+	Reserve(ctx context.Context, submission ScoreSubmission) error
+	// Take removes and returns a pending submission in one step, so only one
+	// of several concurrent callers gets it. Others see ErrSubmissionNotFound.
+	Take(ctx context.Context, key shared.IdempotencyKey) (ScoreSubmission, error)
+	Delete(ctx context.Context, key shared.IdempotencyKey) error
 }
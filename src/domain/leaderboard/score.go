@@ -0,0 +1,122 @@
+package leaderboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// Operator decides how a submitted score combines with the stored one. The
+// values are spelled like tournament operators.
+type Operator string
+
+const (
+	// OperatorBest keeps the higher of the stored and submitted scores.
+	OperatorBest Operator = "best"
+	// OperatorSet replaces the stored score.
+	OperatorSet Operator = "set"
+	// OperatorIncrement adds the submitted score to the stored one.
+	OperatorIncrement Operator = "incr"
+	// OperatorDecrement subtracts the submitted score from the stored one,
+	// stopping at zero.
+	OperatorDecrement Operator = "decr"
+)
+
+// Validate rejects operators outside the known set. The empty operator is
+// valid and means OperatorBest.
+func (o Operator) Validate() error {
+	switch o {
+	case "", OperatorBest, OperatorSet, OperatorIncrement, OperatorDecrement:
+		return nil
+	}
+	return shared.NewValidationError("operator", "must be best, set, incr or decr")
+}
+
+// Apply returns the score to store when submitted arrives for a player whose
+// stored score is current. exists is false for the player's first submission,
+// which is stored as submitted, or as zero when decrementing.
+func (o Operator) Apply(current int64, exists bool, submitted int64) int64 {
+	if !exists {
+		if o == OperatorDecrement {
+			return 0
+		}
+		return submitted
+	}
+	switch o {
+	case OperatorSet:
+		return submitted
+	case OperatorIncrement:
+		return current + submitted
+	case OperatorDecrement:
+		return max(current-submitted, 0)
+	default:
+		if submitted > current {
+			return submitted
+		}
+		return current
+	}
+}
+
+// PlayerScore is a player's stored score for a season. Version increases on
+// every write and is used for conditional updates.
+type PlayerScore struct {
+	PlayerID  shared.PlayerID
+	SeasonID  shared.SeasonID
+	Value     int64
+	Version   int64
+	UpdatedAt time.Time
+	// ExpiresAt is the reset boundary that closed the window the score was
+	// written in. It is zero while that window is still current.
+	ExpiresAt time.Time
+}
+
+// Expired reports whether a reset has closed the score's window.
+func (s PlayerScore) Expired() bool {
+	return !s.ExpiresAt.IsZero()
+}
+
+// Window selects which scores a listing includes.
+type Window string
+
+const (
+	// WindowCurrent lists only scores written since the last reset.
+	WindowCurrent Window = "current"
+	// WindowAllTime also lists scores from windows closed by earlier resets.
+	WindowAllTime Window = "all_time"
+)
+
+// Validate rejects unknown windows.
+func (w Window) Validate() error {
+	switch w {
+	case WindowCurrent, WindowAllTime:
+		return nil
+	}
+	return shared.NewValidationError("window", "must be current or all_time")
+}
+
+// Includes reports whether a listing of w should contain score.
+func (w Window) Includes(score PlayerScore) bool {
+	return w == WindowAllTime || !score.Expired()
+}
+
+// ScoreStore persists per-player scores with optimistic concurrency.
+// Implementations backed by SQL should map a failed conditional update (for
+// example, an UPDATE ... WHERE version = ? that touched no rows, or a unique
+// violation on first insert) to ErrScoreConflict.
+type ScoreStore interface {
+	// GetScore returns the stored score, or ErrScoreNotFound.
+	GetScore(ctx context.Context, season shared.SeasonID, playerID shared.PlayerID) (PlayerScore, error)
+	// CompareAndSetScore stores score only if the stored version still equals
+	// expectedVersion, where 0 means no score is stored yet. It returns
+	// ErrScoreConflict otherwise.
+	CompareAndSetScore(ctx context.Context, score PlayerScore, expectedVersion int64) error
+	// ExpireScores closes the current window of a season at boundary: every
+	// current score is kept with ExpiresAt set to boundary, and GetScore no
+	// longer returns it. It returns the number of scores expired.
+	ExpireScores(ctx context.Context, season shared.SeasonID, boundary time.Time) (int, error)
+	// ListScores returns up to limit scores of a season in window, highest
+	// first, or every score when limit is zero. All-time listings hold one
+	// score per player per window.
+	ListScores(ctx context.Context, season shared.SeasonID, window Window, limit int) ([]PlayerScore, error)
+}
@@ -1,6 +1,7 @@
 package player
 
 import (
+	"sort"
 	"time"
 
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
@@ -66,6 +67,44 @@ func (p *PlayerAccount) RegisterDevice(device DeviceFingerprint) error {
 	return nil
 }
 
+// ListDevices returns the registered devices, most recently seen first. Ties
+// are broken by device ID so the order is stable.
+func (p *PlayerAccount) ListDevices() []DeviceFingerprint {
+	devices := make([]DeviceFingerprint, 0, len(p.Devices))
+	for _, device := range p.Devices {
+		devices = append(devices, device)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		if !devices[i].LastSeen.Equal(devices[j].LastSeen) {
+			return devices[i].LastSeen.After(devices[j].LastSeen)
+		}
+		return devices[i].ID < devices[j].ID
+	})
+	return devices
+}
+
+// RevokeDevice removes a registered device. It returns ErrDeviceInvalid when
+// the device is not registered to the account.
+func (p *PlayerAccount) RevokeDevice(id string, now time.Time) error {
+	return p.RemoveDevice(id, false, now)
+}
+
+// RemoveDevice deregisters a device, for example after the phone was lost.
+// It returns ErrDeviceInvalid when the device is not registered to the
+// account, and ErrLastDevice when requireOne is set and it is the only
+// device left.
+func (p *PlayerAccount) RemoveDevice(id string, requireOne bool, now time.Time) error {
+	if _, ok := p.Devices[id]; !ok {
+		return ErrDeviceInvalid
+	}
+	if requireOne && len(p.Devices) == 1 {
+		return ErrLastDevice
+	}
+	delete(p.Devices, id)
+	p.UpdatedAt = now
+	return nil
+}
+
 func (p *PlayerAccount) RecordSession(session SessionMetadata) {
 	if session.SessionID == "" {
 		return
@@ -77,6 +116,11 @@ func (p *PlayerAccount) RecordSession(session SessionMetadata) {
 	p.UpdatedAt = time.Now().UTC()
 }
 
+func (p *PlayerAccount) SetDisplayName(name string) {
+	p.DisplayName = name
+	p.UpdatedAt = time.Now().UTC()
+}
+
 func (p *PlayerAccount) Suspend(message string) {
 	p.Suspended = true
 	p.SuspensionMsg = message
@@ -89,6 +133,14 @@ func (p *PlayerAccount) Reinstate() {
 	p.UpdatedAt = time.Now().UTC()
 }
 
+// CheckActive returns a SuspendedError when the account is suspended.
+func (p *PlayerAccount) CheckActive() error {
+	if p.Suspended {
+		return &SuspendedError{Message: p.SuspensionMsg}
+	}
+	return nil
+}
+
 func (p *PlayerAccount) CanStartBattle(key shared.IdempotencyKey) error {
 	if p.Suspended {
 		return ErrAccountSuspended
@@ -0,0 +1,97 @@
+package player_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+)
+
+func TestPlayerAccount_ListDevices(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	account, err := player.NewPlayerAccount("player-1", "player@example.com", "player", now)
+	if err != nil {
+		t.Fatalf("NewPlayerAccount() error = %v", err)
+	}
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "tablet", LastSeen: now.Add(-time.Hour)})
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "phone-b", LastSeen: now})
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "phone-a", LastSeen: now})
+
+	devices := account.ListDevices()
+	want := []string{"phone-a", "phone-b", "tablet"}
+	if len(devices) != len(want) {
+		t.Fatalf("ListDevices() returned %d devices, want %d", len(devices), len(want))
+	}
+	for i, id := range want {
+		if devices[i].ID != id {
+			t.Errorf("ListDevices()[%d] = %q, want %q", i, devices[i].ID, id)
+		}
+	}
+}
+
+func TestPlayerAccount_RevokeDevice(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	account, err := player.NewPlayerAccount("player-1", "player@example.com", "player", now)
+	if err != nil {
+		t.Fatalf("NewPlayerAccount() error = %v", err)
+	}
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "phone", LastSeen: now})
+
+	tests := []struct {
+		name     string
+		deviceID string
+		wantErr  error
+	}{
+		{name: "registered device", deviceID: "phone"},
+		{name: "already revoked", deviceID: "phone", wantErr: player.ErrDeviceInvalid},
+		{name: "unknown device", deviceID: "laptop", wantErr: player.ErrDeviceInvalid},
+	}
+	revokedAt := now.Add(time.Hour)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := account.RevokeDevice(tt.deviceID, revokedAt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RevokeDevice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if len(account.Devices) != 0 {
+		t.Errorf("Devices = %+v, want empty", account.Devices)
+	}
+	if !account.UpdatedAt.Equal(revokedAt) {
+		t.Errorf("UpdatedAt = %v, want %v", account.UpdatedAt, revokedAt)
+	}
+}
+
+func TestPlayerAccount_RemoveDevice_RequireOne(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	account, err := player.NewPlayerAccount("player-1", "player@example.com", "player", now)
+	if err != nil {
+		t.Fatalf("NewPlayerAccount() error = %v", err)
+	}
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "phone", LastSeen: now})
+	_ = account.RegisterDevice(player.DeviceFingerprint{ID: "tablet", LastSeen: now})
+
+	tests := []struct {
+		name       string
+		deviceID   string
+		requireOne bool
+		wantErr    error
+	}{
+		{name: "one of two devices", deviceID: "phone", requireOne: true},
+		{name: "last device protected", deviceID: "tablet", requireOne: true, wantErr: player.ErrLastDevice},
+		{name: "last device without policy", deviceID: "tablet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := account.RemoveDevice(tt.deviceID, tt.requireOne, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RemoveDevice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if len(account.Devices) != 0 {
+		t.Errorf("Devices = %+v, want empty", account.Devices)
+	}
+}
@@ -1,9 +1,33 @@
 package player
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrEmailRequired    = errors.New("player email is required")
 	ErrAccountSuspended = errors.New("player account suspended")
+	ErrAccountBanned    = errors.New("player account banned")
+	ErrBadCredentials   = errors.New("invalid credentials")
 	ErrDeviceInvalid    = errors.New("device fingerprint invalid")
+	ErrLastDevice       = errors.New("cannot remove the last device")
+	ErrSearchQueryEmpty = errors.New("search query is required")
 )
+
+// SuspendedError reports that an account is suspended, carrying the moderator
+// message. It matches ErrAccountSuspended with errors.Is.
+type SuspendedError struct {
+	Message string
+}
+
+func (e *SuspendedError) Error() string {
+	if e.Message == "" {
+		return ErrAccountSuspended.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrAccountSuspended, e.Message)
+}
+
+func (e *SuspendedError) Unwrap() error {
+	return ErrAccountSuspended
+}
@@ -1,11 +1,56 @@
 package player
 
-import "context"
+import (
+	"context"
+	"strings"
 
-import "github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
 
 type Repository interface {
 	GetByID(ctx context.Context, id shared.PlayerID) (*PlayerAccount, error)
 	Save(ctx context.Context, account *PlayerAccount) error
 	AppendSession(ctx context.Context, id shared.PlayerID, session SessionMetadata) error
+	SearchByDisplayName(ctx context.Context, query SearchQuery, limit int) ([]*PlayerAccount, error)
+}
+
+// SearchMode controls how a display name search matches.
+type SearchMode string
+
+const (
+	SearchPrefix    SearchMode = "prefix"
+	SearchSubstring SearchMode = "substring"
+)
+
+// SearchQuery is a case-insensitive display name search.
+type SearchQuery struct {
+	Text string
+	Mode SearchMode
+}
+
+// Matches reports whether displayName satisfies the query.
+func (q SearchQuery) Matches(displayName string) bool {
+	name := strings.ToLower(displayName)
+	text := strings.ToLower(q.Text)
+	if q.Mode == SearchSubstring {
+		return strings.Contains(name, text)
+	}
+	return strings.HasPrefix(name, text)
+}
+
+// AccountChanges lists the account fields to push to Nakama. Nil or empty
+// fields are left untouched; Metadata keys are merged into the existing metadata.
+type AccountChanges struct {
+	DisplayName *string
+	Metadata    map[string]any
+}
+
+// IsEmpty reports whether there is nothing to update.
+func (c AccountChanges) IsEmpty() bool {
+	return c.DisplayName == nil && len(c.Metadata) == 0
+}
+
+// AccountProvider propagates account changes to Nakama.
+type AccountProvider interface {
+	UpdateAccount(ctx context.Context, id shared.PlayerID, changes AccountChanges) error
 }
@@ -0,0 +1,97 @@
+package shared
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// ErrContentRejected is matched by every ContentRejectedError.
+var ErrContentRejected = errors.New("content rejected")
+
+// ContentFilter screens user-chosen text such as group and display names.
+type ContentFilter interface {
+	// Check returns a *ContentRejectedError when text is not allowed in field.
+	Check(field, text string) error
+}
+
+// ContentRejectedError reports text refused by a ContentFilter. It matches
+// both ErrContentRejected and a ValidationError for Field. Term is the
+// blocked entry that matched; it is kept out of Error so it is not echoed
+// back to clients.
+type ContentRejectedError struct {
+	Field string
+	Term  string
+}
+
+func (e *ContentRejectedError) Error() string {
+	return fmt.Sprintf("%s contains blocked content", e.Field)
+}
+
+func (e *ContentRejectedError) Unwrap() []error {
+	return []error{ErrContentRejected, NewValidationError(e.Field, e.Error())}
+}
+
+// CheckContent runs filter over text, treating a nil filter as allowing everything.
+func CheckContent(filter ContentFilter, field, text string) error {
+	if filter == nil {
+		return nil
+	}
+	return filter.Check(field, text)
+}
+
+// WordListFilter rejects text containing any listed word. Matching ignores
+// case and compares whole words, so "class" is not caught by "ass".
+type WordListFilter struct {
+	words map[string]struct{}
+}
+
+// NewWordListFilter builds a filter from words, ignoring blanks and case.
+func NewWordListFilter(words []string) *WordListFilter {
+	f := &WordListFilter{words: make(map[string]struct{}, len(words))}
+	for _, word := range words {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			f.words[word] = struct{}{}
+		}
+	}
+	return f
+}
+
+// Check implements ContentFilter.
+func (f *WordListFilter) Check(field, text string) error {
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, token := range tokens {
+		if _, blocked := f.words[token]; blocked {
+			return &ContentRejectedError{Field: field, Term: token}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of blocked words.
+func (f *WordListFilter) Len() int {
+	return len(f.words)
+}
+
+// ParseWordList reads one word per line, skipping blank lines and lines
+// starting with '#'.
+func ParseWordList(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
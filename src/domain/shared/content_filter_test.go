@@ -0,0 +1,69 @@
+package shared_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+func TestWordListFilter_Check(t *testing.T) {
+	filter := shared.NewWordListFilter([]string{"Darn", " heck ", ""})
+
+	tests := []struct {
+		name     string
+		text     string
+		wantTerm string
+	}{
+		{name: "allowed", text: "Night Owls"},
+		{name: "blocked word", text: "darn squad", wantTerm: "darn"},
+		{name: "case insensitive", text: "The HECK Crew", wantTerm: "heck"},
+		{name: "punctuation separated", text: "what_the-heck!", wantTerm: "heck"},
+		{name: "word inside another word", text: "Darnell's Team"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := filter.Check("name", tt.text)
+			if tt.wantTerm == "" {
+				if err != nil {
+					t.Fatalf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+			var rejected *shared.ContentRejectedError
+			if !errors.As(err, &rejected) {
+				t.Fatalf("Check() error = %v, want *ContentRejectedError", err)
+			}
+			if rejected.Term != tt.wantTerm || rejected.Field != "name" {
+				t.Errorf("Check() rejected %+v, want term %q for field name", rejected, tt.wantTerm)
+			}
+			if !errors.Is(err, shared.ErrContentRejected) {
+				t.Errorf("Check() error = %v, want it to match ErrContentRejected", err)
+			}
+			if verr, ok := shared.AsValidationError(err); !ok || verr.Field != "name" {
+				t.Errorf("Check() error = %v, want validation error for name", err)
+			}
+			if strings.Contains(err.Error(), tt.wantTerm) {
+				t.Errorf("Check() error %q echoes the blocked term", err)
+			}
+		})
+	}
+}
+
+func TestCheckContent_NilFilter(t *testing.T) {
+	if err := shared.CheckContent(nil, "name", "anything"); err != nil {
+		t.Errorf("CheckContent() error = %v, want nil", err)
+	}
+}
+
+func TestParseWordList(t *testing.T) {
+	words, err := shared.ParseWordList(strings.NewReader("# blocked words\ndarn\n\n  heck  \n"))
+	if err != nil {
+		t.Fatalf("ParseWordList() error = %v", err)
+	}
+	if want := []string{"darn", "heck"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("ParseWordList() = %v, want %v", words, want)
+	}
+}
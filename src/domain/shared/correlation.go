@@ -0,0 +1,18 @@
+package shared
+
+import "context"
+
+type correlationKey struct{}
+
+// WithCorrelationID returns a context carrying the id of the request it
+// serves, so services can tag their logs with it.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the request id stored by WithCorrelationID, or an
+// empty string.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
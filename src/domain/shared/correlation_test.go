@@ -0,0 +1,18 @@
+package shared_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+func TestCorrelationID(t *testing.T) {
+	if got := shared.CorrelationID(context.Background()); got != "" {
+		t.Errorf("CorrelationID() = %q, want empty", got)
+	}
+	ctx := shared.WithCorrelationID(context.Background(), "req-1")
+	if got := shared.CorrelationID(ctx); got != "req-1" {
+		t.Errorf("CorrelationID() = %q, want req-1", got)
+	}
+}
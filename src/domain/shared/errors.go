@@ -7,4 +7,5 @@ var (
 	ErrNotFound     = errors.New("entity not found")
 	ErrConflict     = errors.New("entity conflict")
 	ErrInvalidState = errors.New("invalid state transition")
+	ErrRateLimited  = errors.New("rate limited")
 )
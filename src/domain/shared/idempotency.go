@@ -0,0 +1,45 @@
+package shared
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long a completed key is remembered by default.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyState tracks where a keyed operation is in its lifecycle.
+type IdempotencyState string
+
+const (
+	IdempotencyStatePending   IdempotencyState = "pending"
+	IdempotencyStateCompleted IdempotencyState = "completed"
+)
+
+// IdempotencyRecord is the stored outcome of an operation guarded by an idempotency key.
+type IdempotencyRecord struct {
+	Scope     string
+	Key       IdempotencyKey
+	State     IdempotencyState
+	Result    []byte
+	ExpiresAt time.Time
+}
+
+// Completed reports whether the guarded operation finished successfully.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.State == IdempotencyStateCompleted
+}
+
+// IdempotencyStore dedupes operations by idempotency key. Keys are namespaced
+// by scope so services can share one store without colliding.
+type IdempotencyStore interface {
+	// Reserve claims the key for ttl. If the key is already held, it returns
+	// the existing record together with ErrDuplicate.
+	Reserve(ctx context.Context, scope string, key IdempotencyKey, ttl time.Duration) (*IdempotencyRecord, error)
+	// Complete marks a reserved key as finished and stores its result.
+	Complete(ctx context.Context, scope string, key IdempotencyKey, result []byte) error
+	// Release drops a reservation so the operation can be retried.
+	Release(ctx context.Context, scope string, key IdempotencyKey) error
+	// Lookup returns the record for a key, or ErrNotFound.
+	Lookup(ctx context.Context, scope string, key IdempotencyKey) (*IdempotencyRecord, error)
+}
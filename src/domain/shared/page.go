@@ -0,0 +1,28 @@
+package shared
+
+// Page is a single window over a larger result set.
+type Page[T any] struct {
+	Items  []T
+	Total  int
+	Limit  int
+	Offset int
+	Cursor string
+}
+
+// NewPage builds a page for an offset-based query.
+func NewPage[T any](items []T, total, limit, offset int) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{
+		Items:  items,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+}
+
+// HasMore reports whether items exist beyond this page.
+func (p Page[T]) HasMore() bool {
+	return p.Offset+len(p.Items) < p.Total
+}
@@ -1,9 +1,6 @@
 package shared
 
-import (
-	"errors"
-	"strings"
-)
+import "strings"
 
 // ID types keep domain entities distinct while remaining simple strings at runtime.
 type (
@@ -19,49 +16,49 @@ type (
 // Validate ensures IDs are not blank and normalized.
 func (id PlayerID) Validate() error {
 	if strings.TrimSpace(string(id)) == "" {
-		return errors.New("player id is required")
+		return NewValidationError("player_id", "player id is required")
 	}
 	return nil
 }
 
 func (id GroupID) Validate() error {
 	if strings.TrimSpace(string(id)) == "" {
-		return errors.New("group id is required")
+		return NewValidationError("group_id", "group id is required")
 	}
 	return nil
 }
 
 func (id BattleID) Validate() error {
 	if strings.TrimSpace(string(id)) == "" {
-		return errors.New("battle id is required")
+		return NewValidationError("battle_id", "battle id is required")
 	}
 	return nil
 }
 
 func (id SeasonID) Validate() error {
 	if strings.TrimSpace(string(id)) == "" {
-		return errors.New("season id is required")
+		return NewValidationError("season_id", "season id is required")
 	}
 	return nil
 }
 
 func (id BotCommandID) Validate() error {
 	if strings.TrimSpace(string(id)) == "" {
-		return errors.New("bot command id is required")
+		return NewValidationError("command_id", "bot command id is required")
 	}
 	return nil
 }
 
 func (id TournamentID) Validate() error {
 	if strings.TrimSpace(string(id)) == "" {
-		return errors.New("tournament id is required")
+		return NewValidationError("tournament_id", "tournament id is required")
 	}
 	return nil
 }
 
 func (key IdempotencyKey) Validate() error {
 	if strings.TrimSpace(string(key)) == "" {
-		return errors.New("idempotency key is required")
+		return NewValidationError("idempotency_key", "idempotency key is required")
 	}
 	return nil
 }
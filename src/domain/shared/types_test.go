@@ -0,0 +1,55 @@
+package shared_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+func TestIDValidate_ValidationError(t *testing.T) {
+	tests := []struct {
+		name      string
+		validate  func() error
+		wantField string
+		wantMsg   string
+	}{
+		{"player id", shared.PlayerID("").Validate, "player_id", "player id is required"},
+		{"group id", shared.GroupID(" ").Validate, "group_id", "group id is required"},
+		{"battle id", shared.BattleID("").Validate, "battle_id", "battle id is required"},
+		{"season id", shared.SeasonID("").Validate, "season_id", "season id is required"},
+		{"bot command id", shared.BotCommandID("").Validate, "command_id", "bot command id is required"},
+		{"tournament id", shared.TournamentID("").Validate, "tournament_id", "tournament id is required"},
+		{"idempotency key", shared.IdempotencyKey("").Validate, "idempotency_key", "idempotency key is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate()
+			if err == nil {
+				t.Fatal("Expected validation error")
+			}
+			if err.Error() != tt.wantMsg {
+				t.Errorf("Expected message %q, got %q", tt.wantMsg, err.Error())
+			}
+
+			verr, ok := shared.AsValidationError(fmt.Errorf("wrapped: %w", err))
+			if !ok {
+				t.Fatalf("Expected ValidationError, got %T", err)
+			}
+			if verr.Field != tt.wantField {
+				t.Errorf("Expected field %q, got %q", tt.wantField, verr.Field)
+			}
+		})
+	}
+}
+
+func TestIDValidate_Valid(t *testing.T) {
+	if err := shared.PlayerID("player-123").Validate(); err != nil {
+		t.Errorf("Expected valid player id, got %v", err)
+	}
+	if _, ok := shared.AsValidationError(errors.New("plain")); ok {
+		t.Error("Expected plain error not to be a ValidationError")
+	}
+}
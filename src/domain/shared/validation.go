@@ -0,0 +1,76 @@
+package shared
+
+import (
+	"errors"
+	"strings"
+)
+
+// ValidationError reports an invalid value for a named request field.
+type ValidationError struct {
+	Field   string
+	Message string
+	// Err is the domain error behind the message, if any, so errors.Is
+	// still matches it.
+	Err error
+}
+
+// NewValidationError creates a validation error attributed to field.
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Field: field, Message: message}
+}
+
+// FieldError attributes an existing domain error to field.
+func FieldError(field string, err error) *ValidationError {
+	return &ValidationError{Field: field, Message: err.Error(), Err: err}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors collects every invalid field of a request, for callers
+// that report all problems at once rather than stopping at the first.
+type ValidationErrors []*ValidationError
+
+// Add appends err, attributing it to field unless it already names one. A
+// nil err is ignored.
+func (errs *ValidationErrors) Add(field string, err error) {
+	if err == nil {
+		return
+	}
+	if verr, ok := AsValidationError(err); ok {
+		*errs = append(*errs, verr)
+		return
+	}
+	*errs = append(*errs, FieldError(field, err))
+}
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap exposes each field error to errors.Is and errors.As.
+func (errs ValidationErrors) Unwrap() []error {
+	unwrapped := make([]error, len(errs))
+	for i, err := range errs {
+		unwrapped[i] = err
+	}
+	return unwrapped
+}
+
+// AsValidationError extracts a ValidationError from an error chain.
+func AsValidationError(err error) (*ValidationError, bool) {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr, true
+	}
+	return nil, false
+}
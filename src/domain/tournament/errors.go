@@ -6,8 +6,17 @@ var (
 	ErrTournamentNotFound      = errors.New("tournament not found")
 	ErrTournamentAlreadyExists = errors.New("tournament already exists")
 	ErrTournamentAlreadyEnded  = errors.New("tournament already ended")
+	ErrTournamentCancelled     = errors.New("tournament cancelled")
 	ErrParticipantNotFound     = errors.New("participant not found")
 	ErrParticipantAlreadyJoined = errors.New("participant already joined")
 	ErrTournamentFull          = errors.New("tournament is full")
 	ErrInvalidAttemptCount     = errors.New("invalid attempt count")
+	ErrConcurrentModification  = errors.New("tournament was modified concurrently")
+	ErrInvalidResetSchedule    = errors.New("invalid reset schedule")
+	ErrInvalidJoinWindow       = errors.New("join window must close after it opens")
+	ErrJoinWindowClosed        = errors.New("tournament join window is closed")
+	ErrSnapshotNotFound        = errors.New("standings snapshot not found")
+	ErrUnknownSortOrder        = errors.New("unknown sort order")
+	ErrUnknownOperator         = errors.New("unknown operator")
+	ErrIncompatibleScoring     = errors.New("incompatible operator and sort order")
 )
@@ -0,0 +1,75 @@
+package tournament
+
+import "time"
+
+// SetJoinWindow restricts joins to [opensAt, closesAt). Either bound may be nil
+// to fall back to the active period.
+func (t *Tournament) SetJoinWindow(opensAt, closesAt *time.Time, now time.Time) error {
+	if err := ValidateJoinWindow(opensAt, closesAt); err != nil {
+		return err
+	}
+	t.JoinOpensAt = opensAt
+	t.JoinClosesAt = closesAt
+	t.UpdatedAt = now
+	return nil
+}
+
+// ValidateJoinWindow checks that a join window closes after it opens. Either
+// bound may be nil.
+func ValidateJoinWindow(opensAt, closesAt *time.Time) error {
+	if opensAt != nil && closesAt != nil && !closesAt.After(*opensAt) {
+		return ErrInvalidJoinWindow
+	}
+	return nil
+}
+
+// JoinWindow returns the effective join bounds. A zero close time means joins
+// stay open for as long as the tournament is active.
+func (t *Tournament) JoinWindow() (opensAt, closesAt time.Time) {
+	opensAt = t.StartTime
+	if t.JoinOpensAt != nil {
+		opensAt = *t.JoinOpensAt
+	}
+	closesAt, _ = t.CalculateEndTime()
+	if t.JoinClosesAt != nil {
+		closesAt = *t.JoinClosesAt
+	}
+	return opensAt, closesAt
+}
+
+// CanJoin reports whether a player may join at now.
+func (t *Tournament) CanJoin(now time.Time) error {
+	if !t.IsActive() {
+		return ErrJoinWindowClosed
+	}
+	opensAt, closesAt := t.JoinWindow()
+	if now.Before(opensAt) {
+		return ErrJoinWindowClosed
+	}
+	if !closesAt.IsZero() && !now.Before(closesAt) {
+		return ErrJoinWindowClosed
+	}
+	return nil
+}
+
+// Join takes one of the tournament's MaxSize places for a player joining at
+// now. It returns ErrTournamentFull when every place is taken.
+func (t *Tournament) Join(now time.Time) error {
+	if err := t.CanJoin(now); err != nil {
+		return err
+	}
+	if t.MaxSize > 0 && t.Joined >= t.MaxSize {
+		return ErrTournamentFull
+	}
+	t.Joined++
+	t.UpdatedAt = now
+	return nil
+}
+
+// ReleasePlace gives back a place taken by Join whose join did not complete.
+func (t *Tournament) ReleasePlace(now time.Time) {
+	if t.Joined > 0 {
+		t.Joined--
+		t.UpdatedAt = now
+	}
+}
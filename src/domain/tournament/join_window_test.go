@@ -0,0 +1,63 @@
+package tournament_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+)
+
+func TestTournament_CanJoin(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	opens := start.Add(-time.Hour)
+	closes := start.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		duration time.Duration
+		opensAt  *time.Time
+		closesAt *time.Time
+		now      time.Time
+		wantErr  bool
+	}{
+		{name: "default before start", now: start.Add(-time.Minute), wantErr: true},
+		{name: "default open-ended", now: start.Add(24 * time.Hour)},
+		{name: "default within duration", duration: 2 * time.Hour, now: start.Add(time.Hour)},
+		{name: "default after duration", duration: 2 * time.Hour, now: start.Add(2 * time.Hour), wantErr: true},
+		{name: "early window before start", opensAt: &opens, closesAt: &closes, now: start.Add(-30 * time.Minute)},
+		{name: "before window", opensAt: &opens, closesAt: &closes, now: opens.Add(-time.Second), wantErr: true},
+		{name: "at close", opensAt: &opens, closesAt: &closes, now: closes, wantErr: true},
+		{name: "close only", closesAt: &closes, now: start.Add(30 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tour, err := tournament.NewTournament("tournament-123", "Cup", "", 1, tournament.SortOrderDescending, tournament.OperatorBest, "", true, false, 0, 0, start, tt.duration, start)
+			if err != nil {
+				t.Fatalf("NewTournament() error = %v", err)
+			}
+			if err := tour.SetJoinWindow(tt.opensAt, tt.closesAt, start); err != nil {
+				t.Fatalf("SetJoinWindow() error = %v", err)
+			}
+			err = tour.CanJoin(tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CanJoin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tournament.ErrJoinWindowClosed) {
+				t.Errorf("Expected ErrJoinWindowClosed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTournament_SetJoinWindow_Invalid(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tour, err := tournament.NewTournament("tournament-123", "Cup", "", 1, tournament.SortOrderDescending, tournament.OperatorBest, "", true, false, 0, 0, start, 0, start)
+	if err != nil {
+		t.Fatalf("NewTournament() error = %v", err)
+	}
+	if err := tour.SetJoinWindow(&start, &start, start); !errors.Is(err, tournament.ErrInvalidJoinWindow) {
+		t.Errorf("SetJoinWindow() error = %v, want %v", err, tournament.ErrInvalidJoinWindow)
+	}
+}
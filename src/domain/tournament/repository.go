@@ -11,13 +11,30 @@ type Repository interface {
 	Save(ctx context.Context, tournament *Tournament) error
 	Get(ctx context.Context, id shared.TournamentID) (*Tournament, error)
 	Delete(ctx context.Context, id shared.TournamentID) error
-	List(ctx context.Context, limit, offset int) ([]*Tournament, error)
+	List(ctx context.Context, filter ListFilter, limit, offset int) ([]*Tournament, error)
+	Count(ctx context.Context, filter ListFilter) (int, error)
+}
+
+// ListFilter narrows List and Count. The zero value leaves out cancelled
+// tournaments.
+type ListFilter struct {
+	IncludeCancelled bool
+}
+
+// Matches reports whether t passes the filter.
+func (f ListFilter) Matches(t *Tournament) bool {
+	return f.IncludeCancelled || t.State != StateCancelled
 }
 
 // ParticipantRepository manages participant persistence.
 type ParticipantRepository interface {
 	Save(ctx context.Context, participant *Participant) error
+	// Create stores a participant only if the player has not joined the
+	// tournament yet, returning ErrParticipantAlreadyJoined otherwise.
+	Create(ctx context.Context, participant *Participant) error
 	Get(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) (*Participant, error)
 	ListByTournament(ctx context.Context, tournamentID shared.TournamentID) ([]*Participant, error)
+	ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*Participant, error)
 	Delete(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error
+	DeleteByTournament(ctx context.Context, tournamentID shared.TournamentID) error
 }
@@ -0,0 +1,32 @@
+package tournament
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/internal/cronexpr"
+)
+
+// NextReset returns the first reset after the given time for a cron reset
+// schedule, using the same parser Nakama applies to leaderboards. An empty
+// schedule never resets and yields the zero time.
+func NextReset(schedule string, after time.Time) (time.Time, error) {
+	if schedule == "" {
+		return time.Time{}, nil
+	}
+	expr, err := cronexpr.Parse(schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidResetSchedule, err)
+	}
+	return expr.Next(after), nil
+}
+
+// SetResetSchedule replaces the reset schedule after validating it.
+func (t *Tournament) SetResetSchedule(schedule string, now time.Time) error {
+	if _, err := NextReset(schedule, now); err != nil {
+		return err
+	}
+	t.ResetSchedule = schedule
+	t.UpdatedAt = now
+	return nil
+}
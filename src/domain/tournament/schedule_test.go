@@ -0,0 +1,40 @@
+package tournament_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+)
+
+func TestNextReset(t *testing.T) {
+	after := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule string
+		want     time.Time
+		wantErr  bool
+	}{
+		{name: "no schedule", schedule: "", want: time.Time{}},
+		{name: "daily at midnight", schedule: "0 0 * * *", want: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "hourly", schedule: "0 * * * *", want: time.Date(2024, 3, 1, 13, 0, 0, 0, time.UTC)},
+		{name: "invalid", schedule: "every tuesday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tournament.NextReset(tt.schedule, after)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NextReset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tournament.ErrInvalidResetSchedule) {
+				t.Errorf("Expected ErrInvalidResetSchedule, got %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("NextReset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package tournament
+
+import (
+	"context"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// Standing is one ranked entry in a standings snapshot.
+type Standing struct {
+	Rank     int64
+	OwnerID  string
+	Username string
+	Score    int64
+	Subscore int64
+	NumScore int
+}
+
+// StandingsSnapshot preserves a tournament's final standings after Nakama's
+// records roll over or the tournament is deleted.
+type StandingsSnapshot struct {
+	TournamentID shared.TournamentID
+	Title        string
+	Standings    []Standing
+	TakenAt      time.Time
+}
+
+// SnapshotRepository stores final standings snapshots, one per tournament.
+type SnapshotRepository interface {
+	Save(ctx context.Context, snapshot *StandingsSnapshot) error
+	Get(ctx context.Context, tournamentID shared.TournamentID) (*StandingsSnapshot, error)
+}
@@ -0,0 +1,42 @@
+package tournament
+
+import "time"
+
+// StateScheduled is reported by Status for tournaments that have not started yet.
+// It is never stored on the aggregate.
+const StateScheduled TournamentState = "scheduled"
+
+// Status reconciles the stored state with the clock: a tournament is
+// scheduled before its start time and ended once its end time has passed,
+// even if the stored state has not caught up yet. Cancelled tournaments stay
+// cancelled.
+func (t *Tournament) Status(now time.Time) TournamentState {
+	if t.State == StateEnded || t.State == StateCancelled {
+		return t.State
+	}
+	if now.Before(t.StartTime) {
+		return StateScheduled
+	}
+	if end, ok := t.CalculateEndTime(); ok && !now.Before(end) {
+		return StateEnded
+	}
+	return t.State
+}
+
+// TimeRemaining returns how long the tournament still runs. A scheduled
+// tournament reports its full length. It returns zero once the tournament has
+// ended or when it has no end time.
+func (t *Tournament) TimeRemaining(now time.Time) time.Duration {
+	if t.Status(now) == StateEnded {
+		return 0
+	}
+	end, ok := t.CalculateEndTime()
+	if !ok {
+		return 0
+	}
+	from := now
+	if from.Before(t.StartTime) {
+		from = t.StartTime
+	}
+	return end.Sub(from)
+}
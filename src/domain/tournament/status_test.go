@@ -0,0 +1,55 @@
+package tournament_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+)
+
+func TestTournament_StatusAndTimeRemaining(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		duration      time.Duration
+		endAfter      time.Duration
+		ended         bool
+		now           time.Time
+		wantStatus    tournament.TournamentState
+		wantRemaining time.Duration
+	}{
+		{name: "scheduled", duration: 2 * time.Hour, now: start.Add(-time.Hour), wantStatus: tournament.StateScheduled, wantRemaining: 2 * time.Hour},
+		{name: "active", duration: 2 * time.Hour, now: start.Add(30 * time.Minute), wantStatus: tournament.StateActive, wantRemaining: 90 * time.Minute},
+		{name: "active past end", duration: 2 * time.Hour, now: start.Add(3 * time.Hour), wantStatus: tournament.StateEnded},
+		{name: "exactly at end", duration: 2 * time.Hour, now: start.Add(2 * time.Hour), wantStatus: tournament.StateEnded},
+		{name: "stored as ended", duration: 2 * time.Hour, ended: true, now: start.Add(time.Hour), wantStatus: tournament.StateEnded},
+		{name: "open-ended", now: start.Add(48 * time.Hour), wantStatus: tournament.StateActive},
+		{name: "explicit end running", endAfter: 3 * time.Hour, now: start.Add(time.Hour), wantStatus: tournament.StateActive, wantRemaining: 2 * time.Hour},
+		{name: "explicit end passed", endAfter: 3 * time.Hour, now: start.Add(3 * time.Hour), wantStatus: tournament.StateEnded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tour, err := tournament.NewTournament("tournament-123", "Cup", "", 1, tournament.SortOrderDescending, tournament.OperatorBest, "", true, false, 0, 0, start, tt.duration, start)
+			if err != nil {
+				t.Fatalf("NewTournament() error = %v", err)
+			}
+			if tt.endAfter > 0 {
+				end := start.Add(tt.endAfter)
+				tour.EndTime = &end
+			}
+			if tt.ended {
+				if err := tour.End(start.Add(time.Minute)); err != nil {
+					t.Fatalf("End() error = %v", err)
+				}
+			}
+			if got := tour.Status(tt.now); got != tt.wantStatus {
+				t.Errorf("Status() = %q, want %q", got, tt.wantStatus)
+			}
+			if got := tour.TimeRemaining(tt.now); got != tt.wantRemaining {
+				t.Errorf("TimeRemaining() = %v, want %v", got, tt.wantRemaining)
+			}
+		})
+	}
+}
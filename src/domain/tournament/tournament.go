@@ -2,6 +2,7 @@ package tournament
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
@@ -25,6 +26,43 @@ const (
 	OperatorDecrement  Operator = "decr"
 )
 
+// Valid reports whether o is a recognized operator.
+func (o Operator) Valid() bool {
+	switch o {
+	case OperatorBest, OperatorSet, OperatorIncrement, OperatorDecrement:
+		return true
+	}
+	return false
+}
+
+// Valid reports whether s is a recognized sort order.
+func (s SortOrder) Valid() bool {
+	return s == SortOrderAscending || s == SortOrderDescending
+}
+
+// incompatibleSortOrders lists the sort order that makes no sense for an
+// operator: accumulated scores only grow with incr, so ranking the smallest
+// first would reward the least active players, and likewise for decr.
+var incompatibleSortOrders = map[Operator]SortOrder{
+	OperatorIncrement: SortOrderAscending,
+	OperatorDecrement: SortOrderDescending,
+}
+
+// ValidateScoring checks that the sort order and operator are recognized and
+// form a sensible pair.
+func ValidateScoring(sortOrder SortOrder, operator Operator) error {
+	if !sortOrder.Valid() {
+		return fmt.Errorf("%w %q: must be %q or %q", ErrUnknownSortOrder, sortOrder, SortOrderAscending, SortOrderDescending)
+	}
+	if !operator.Valid() {
+		return fmt.Errorf("%w %q: must be one of %q, %q, %q or %q", ErrUnknownOperator, operator, OperatorBest, OperatorSet, OperatorIncrement, OperatorDecrement)
+	}
+	if incompatibleSortOrders[operator] == sortOrder {
+		return fmt.Errorf("%w: operator %q cannot be ranked in %q order", ErrIncompatibleScoring, operator, sortOrder)
+	}
+	return nil
+}
+
 // TournamentState represents the lifecycle state.
 type TournamentState string
 
@@ -32,6 +70,9 @@ const (
 	StateActive   TournamentState = "active"
 	StateEnded    TournamentState = "ended"
 	StateReset    TournamentState = "reset"
+	// StateCancelled marks a tournament called off before it ended. It does
+	// not count as a completed tournament.
+	StateCancelled TournamentState = "cancelled"
 )
 
 // Tournament aggregate represents a competitive event.
@@ -51,11 +92,33 @@ type Tournament struct {
 	EndTime       *time.Time
 	Duration      time.Duration
 	State         TournamentState
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	// JoinOpensAt and JoinClosesAt bound when players may join. A nil bound
+	// falls back to the start or end of the active period.
+	JoinOpensAt  *time.Time
+	JoinClosesAt *time.Time
+	// Joined counts the players holding one of the MaxSize places. It is
+	// saved with the tournament, so the version check serializes joins.
+	Joined int
+	// Version is incremented by the repository on every successful save and
+	// used to reject writes based on a stale read.
+	Version   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// CancelReason records why the tournament was cancelled.
+	CancelReason string
+}
+
+// Changes describes a partial update; nil fields are left untouched.
+type Changes struct {
+	Title       *string
+	Description *string
+	Category    *int
+	MaxSize     *int
+	MaxNumScore *int
 }
 
-// NewTournament creates a new tournament aggregate.
+// NewTournament creates a new tournament aggregate. An empty sort order or
+// operator defaults to descending best-score ranking.
 func NewTournament(
 	id shared.TournamentID,
 	title, description string,
@@ -69,27 +132,21 @@ func NewTournament(
 	duration time.Duration,
 	now time.Time,
 ) (*Tournament, error) {
-	if err := id.Validate(); err != nil {
-		return nil, err
-	}
-	if title == "" {
-		return nil, errors.New("title is required")
-	}
-	if category < 0 {
-		return nil, errors.New("category must be non-negative")
+	draft := Draft{
+		ID:          id,
+		Title:       title,
+		Category:    category,
+		SortOrder:   sortOrder,
+		Operator:    operator,
+		MaxSize:     maxSize,
+		MaxNumScore: maxNumScore,
+		StartTime:   startTime,
+		Duration:    duration,
 	}
-	if maxSize < 0 {
-		return nil, errors.New("max size must be non-negative")
-	}
-	if maxNumScore < 0 {
-		return nil, errors.New("max num score must be non-negative")
-	}
-	if startTime.IsZero() {
-		return nil, errors.New("start time is required")
-	}
-	if duration < 0 {
-		return nil, errors.New("duration must be non-negative")
+	if errs := draft.Validate(); len(errs) > 0 {
+		return nil, errs[0]
 	}
+	sortOrder, operator = draft.scoring()
 
 	return &Tournament{
 		ID:            id,
@@ -116,6 +173,9 @@ func (t *Tournament) End(endTime time.Time) error {
 	if t.State == StateEnded {
 		return ErrTournamentAlreadyEnded
 	}
+	if t.State == StateCancelled {
+		return ErrTournamentCancelled
+	}
 	if endTime.Before(t.StartTime) {
 		return errors.New("end time cannot be before start time")
 	}
@@ -135,20 +195,81 @@ func (t *Tournament) Reset(resetTime time.Time) error {
 	return nil
 }
 
+// Cancel calls the tournament off before it ends, recording why. Ended
+// tournaments cannot be cancelled.
+func (t *Tournament) Cancel(reason string, now time.Time) error {
+	switch t.State {
+	case StateEnded:
+		return ErrTournamentAlreadyEnded
+	case StateCancelled:
+		return ErrTournamentCancelled
+	}
+	if reason == "" {
+		return errors.New("cancellation reason is required")
+	}
+	t.State = StateCancelled
+	t.CancelReason = reason
+	t.UpdatedAt = now
+	return nil
+}
+
+// ApplyChanges updates the provided fields after validating them.
+func (t *Tournament) ApplyChanges(changes Changes, now time.Time) error {
+	if changes.Title != nil && *changes.Title == "" {
+		return errors.New("title is required")
+	}
+	if changes.Category != nil && *changes.Category < 0 {
+		return errors.New("category must be non-negative")
+	}
+	if changes.MaxSize != nil && *changes.MaxSize < 0 {
+		return errors.New("max size must be non-negative")
+	}
+	if changes.MaxNumScore != nil && *changes.MaxNumScore < 0 {
+		return errors.New("max num score must be non-negative")
+	}
+
+	if changes.Title != nil {
+		t.Title = *changes.Title
+	}
+	if changes.Description != nil {
+		t.Description = *changes.Description
+	}
+	if changes.Category != nil {
+		t.Category = *changes.Category
+	}
+	if changes.MaxSize != nil {
+		t.MaxSize = *changes.MaxSize
+	}
+	if changes.MaxNumScore != nil {
+		t.MaxNumScore = *changes.MaxNumScore
+	}
+	t.UpdatedAt = now
+	return nil
+}
+
 // IsActive checks if the tournament is currently active.
 func (t *Tournament) IsActive() bool {
 	return t.State == StateActive
 }
 
-// CalculateEndTime computes the end time based on start time and duration.
-func (t *Tournament) CalculateEndTime() time.Time {
+// HasEndTime reports whether the tournament has a duration or explicit end
+// time. Tournaments without either are open-ended and run until ended by hand.
+func (t *Tournament) HasEndTime() bool {
+	return t.Duration > 0 || t.EndTime != nil
+}
+
+// CalculateEndTime computes the end time from the start time and duration,
+// falling back to the explicit end time. ok is false for open-ended
+// tournaments, whose end time is the zero time and must not be compared
+// against the clock.
+func (t *Tournament) CalculateEndTime() (end time.Time, ok bool) {
 	if t.Duration > 0 {
-		return t.StartTime.Add(t.Duration)
+		return t.StartTime.Add(t.Duration), true
 	}
 	if t.EndTime != nil {
-		return *t.EndTime
+		return *t.EndTime, true
 	}
-	return time.Time{}
+	return time.Time{}, false
 }
 
 // Validate ensures the tournament is well-formed.
@@ -1,6 +1,7 @@
 package tournament_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -110,6 +111,105 @@ func TestNewTournament(t *testing.T) {
 	}
 }
 
+func TestNewTournament_Scoring(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		sortOrder     tournament.SortOrder
+		operator      tournament.Operator
+		wantErr       error
+		wantSortOrder tournament.SortOrder
+		wantOperator  tournament.Operator
+	}{
+		{name: "best descending", sortOrder: tournament.SortOrderDescending, operator: tournament.OperatorBest, wantSortOrder: tournament.SortOrderDescending, wantOperator: tournament.OperatorBest},
+		{name: "best ascending", sortOrder: tournament.SortOrderAscending, operator: tournament.OperatorBest, wantSortOrder: tournament.SortOrderAscending, wantOperator: tournament.OperatorBest},
+		{name: "incr descending", sortOrder: tournament.SortOrderDescending, operator: tournament.OperatorIncrement, wantSortOrder: tournament.SortOrderDescending, wantOperator: tournament.OperatorIncrement},
+		{name: "decr ascending", sortOrder: tournament.SortOrderAscending, operator: tournament.OperatorDecrement, wantSortOrder: tournament.SortOrderAscending, wantOperator: tournament.OperatorDecrement},
+		{name: "defaults", wantSortOrder: tournament.SortOrderDescending, wantOperator: tournament.OperatorBest},
+		{name: "unknown sort order", sortOrder: "random", operator: tournament.OperatorBest, wantErr: tournament.ErrUnknownSortOrder},
+		{name: "unknown operator", sortOrder: tournament.SortOrderDescending, operator: "max", wantErr: tournament.ErrUnknownOperator},
+		{name: "incr ascending", sortOrder: tournament.SortOrderAscending, operator: tournament.OperatorIncrement, wantErr: tournament.ErrIncompatibleScoring},
+		{name: "decr descending", sortOrder: tournament.SortOrderDescending, operator: tournament.OperatorDecrement, wantErr: tournament.ErrIncompatibleScoring},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tour, err := tournament.NewTournament("tournament-123", "Cup", "", 1, tt.sortOrder, tt.operator, "", true, false, 0, 0, now, time.Hour, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewTournament() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if tour.SortOrder != tt.wantSortOrder || tour.Operator != tt.wantOperator {
+				t.Errorf("scoring = %s/%s, want %s/%s", tour.SortOrder, tour.Operator, tt.wantSortOrder, tt.wantOperator)
+			}
+		})
+	}
+}
+
+func TestParseScoring(t *testing.T) {
+	tests := []struct {
+		name          string
+		sortOrder     string
+		operator      string
+		wantErr       error
+		wantSortOrder tournament.SortOrder
+		wantOperator  tournament.Operator
+	}{
+		{name: "defaults", wantSortOrder: tournament.SortOrderDescending, wantOperator: tournament.OperatorBest},
+		{name: "asc", sortOrder: "asc", wantSortOrder: tournament.SortOrderAscending, wantOperator: tournament.OperatorBest},
+		{name: "desc", sortOrder: "desc", wantSortOrder: tournament.SortOrderDescending, wantOperator: tournament.OperatorBest},
+		{name: "best", operator: "best", wantSortOrder: tournament.SortOrderDescending, wantOperator: tournament.OperatorBest},
+		{name: "set", operator: "set", wantSortOrder: tournament.SortOrderDescending, wantOperator: tournament.OperatorSet},
+		{name: "incr", operator: "incr", wantSortOrder: tournament.SortOrderDescending, wantOperator: tournament.OperatorIncrement},
+		{name: "decr", sortOrder: "asc", operator: "decr", wantSortOrder: tournament.SortOrderAscending, wantOperator: tournament.OperatorDecrement},
+		{name: "spelled out sort order", sortOrder: "descending", wantErr: tournament.ErrUnknownSortOrder},
+		{name: "wrong case operator", operator: "Best", wantErr: tournament.ErrUnknownOperator},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortOrder, operator, err := tournament.ParseScoring(tt.sortOrder, tt.operator)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseScoring() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sortOrder != tt.wantSortOrder || operator != tt.wantOperator {
+				t.Errorf("ParseScoring() = %s/%s, want %s/%s", sortOrder, operator, tt.wantSortOrder, tt.wantOperator)
+			}
+		})
+	}
+}
+
+func TestDraft_Validate(t *testing.T) {
+	draft := tournament.Draft{
+		ID:        "weekly",
+		Category:  -1,
+		SortOrder: "sideways",
+		Duration:  -time.Second,
+	}
+	errs := draft.Validate()
+
+	wantFields := []string{"title", "category", "start_time", "duration", "sort_order"}
+	if len(errs) != len(wantFields) {
+		t.Fatalf("Validate() = %v, want %d errors", errs, len(wantFields))
+	}
+	for i, field := range wantFields {
+		if errs[i].Field != field {
+			t.Errorf("Validate()[%d].Field = %q, want %q", i, errs[i].Field, field)
+		}
+	}
+	if !errors.Is(errs, tournament.ErrUnknownSortOrder) {
+		t.Errorf("Validate() error = %v, want wrapping %v", errs, tournament.ErrUnknownSortOrder)
+	}
+
+	draft = tournament.Draft{ID: "weekly", Title: "Weekly Cup", StartTime: time.Now()}
+	if errs := draft.Validate(); len(errs) != 0 {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+}
+
 func TestTournament_End(t *testing.T) {
 	now := time.Now()
 	startTime := now.Add(1 * time.Hour)
@@ -229,32 +329,122 @@ func TestTournament_Reset(t *testing.T) {
 	}
 }
 
+func TestTournament_Cancel(t *testing.T) {
+	now := time.Now()
+	newTour := func() *tournament.Tournament {
+		tour, err := tournament.NewTournament(
+			"tournament-123", "Test Tournament", "", 0,
+			tournament.SortOrderDescending, tournament.OperatorBest, "",
+			true, false, 0, 0, now, time.Hour, now,
+		)
+		if err != nil {
+			t.Fatalf("NewTournament() error = %v", err)
+		}
+		return tour
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(*tournament.Tournament)
+		reason  string
+		wantErr error
+	}{
+		{name: "active", reason: "scoring bug"},
+		{name: "missing reason", wantErr: errors.New("cancellation reason is required")},
+		{
+			name:    "already ended",
+			setup:   func(tour *tournament.Tournament) { _ = tour.End(now.Add(time.Minute)) },
+			reason:  "scoring bug",
+			wantErr: tournament.ErrTournamentAlreadyEnded,
+		},
+		{
+			name:    "already cancelled",
+			setup:   func(tour *tournament.Tournament) { _ = tour.Cancel("first", now) },
+			reason:  "scoring bug",
+			wantErr: tournament.ErrTournamentCancelled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tour := newTour()
+			if tt.setup != nil {
+				tt.setup(tour)
+			}
+			cancelledAt := now.Add(2 * time.Minute)
+			err := tour.Cancel(tt.reason, cancelledAt)
+			if tt.wantErr != nil {
+				if err == nil || (!errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) {
+					t.Errorf("Cancel() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Cancel() error = %v", err)
+			}
+			if tour.State != tournament.StateCancelled || tour.CancelReason != tt.reason || !tour.UpdatedAt.Equal(cancelledAt) {
+				t.Errorf("Expected cancelled with reason %q at %v, got %v %q at %v", tt.reason, cancelledAt, tour.State, tour.CancelReason, tour.UpdatedAt)
+			}
+			if err := tour.End(cancelledAt); !errors.Is(err, tournament.ErrTournamentCancelled) {
+				t.Errorf("End() after Cancel error = %v, want %v", err, tournament.ErrTournamentCancelled)
+			}
+			if status := tour.Status(now.Add(2 * time.Hour)); status != tournament.StateCancelled {
+				t.Errorf("Status() after end time = %v, want %v", status, tournament.StateCancelled)
+			}
+		})
+	}
+}
+
 func TestTournament_CalculateEndTime(t *testing.T) {
 	now := time.Now()
 	startTime := now.Add(1 * time.Hour)
-	duration := 24 * time.Hour
+	explicitEnd := startTime.Add(6 * time.Hour)
 
-	tour, _ := tournament.NewTournament(
-		"tournament-123",
-		"Test Tournament",
-		"Description",
-		1,
-		tournament.SortOrderDescending,
-		tournament.OperatorBest,
-		"",
-		true,
-		false,
-		100,
-		10,
-		startTime,
-		duration,
-		now,
-	)
+	tests := []struct {
+		name     string
+		duration time.Duration
+		endTime  *time.Time
+		wantEnd  time.Time
+		wantOK   bool
+	}{
+		{name: "duration-based", duration: 24 * time.Hour, wantEnd: startTime.Add(24 * time.Hour), wantOK: true},
+		{name: "explicit end", endTime: &explicitEnd, wantEnd: explicitEnd, wantOK: true},
+		{name: "duration wins over explicit end", duration: time.Hour, endTime: &explicitEnd, wantEnd: startTime.Add(time.Hour), wantOK: true},
+		{name: "open-ended", wantOK: false},
+	}
 
-	endTime := tour.CalculateEndTime()
-	expected := startTime.Add(duration)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tour, err := tournament.NewTournament(
+				"tournament-123",
+				"Test Tournament",
+				"Description",
+				1,
+				tournament.SortOrderDescending,
+				tournament.OperatorBest,
+				"",
+				true,
+				false,
+				100,
+				10,
+				startTime,
+				tt.duration,
+				now,
+			)
+			if err != nil {
+				t.Fatalf("NewTournament() error = %v", err)
+			}
+			tour.EndTime = tt.endTime
 
-	if !endTime.Equal(expected) {
-		t.Errorf("Expected end time %v, got %v", expected, endTime)
+			endTime, ok := tour.CalculateEndTime()
+			if ok != tt.wantOK {
+				t.Errorf("CalculateEndTime() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !endTime.Equal(tt.wantEnd) {
+				t.Errorf("Expected end time %v, got %v", tt.wantEnd, endTime)
+			}
+			if tour.HasEndTime() != tt.wantOK {
+				t.Errorf("HasEndTime() = %v, want %v", tour.HasEndTime(), tt.wantOK)
+			}
+		})
 	}
 }
@@ -0,0 +1,80 @@
+package tournament
+
+import (
+	"errors"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// Draft holds the fields NewTournament validates, so a request can be checked
+// without building or storing a tournament.
+type Draft struct {
+	ID          shared.TournamentID
+	Title       string
+	Category    int
+	SortOrder   SortOrder
+	Operator    Operator
+	MaxSize     int
+	MaxNumScore int
+	StartTime   time.Time
+	Duration    time.Duration
+}
+
+// Validate reports every invalid field of d, in the order NewTournament checks
+// them. An empty sort order or operator takes the NewTournament default.
+func (d Draft) Validate() shared.ValidationErrors {
+	var errs shared.ValidationErrors
+	errs.Add("tournament_id", d.ID.Validate())
+	if d.Title == "" {
+		errs.Add("title", errors.New("title is required"))
+	}
+	if d.Category < 0 {
+		errs.Add("category", errors.New("category must be non-negative"))
+	}
+	if d.MaxSize < 0 {
+		errs.Add("max_size", errors.New("max size must be non-negative"))
+	}
+	if d.MaxNumScore < 0 {
+		errs.Add("max_num_score", errors.New("max num score must be non-negative"))
+	}
+	if d.StartTime.IsZero() {
+		errs.Add("start_time", errors.New("start time is required"))
+	}
+	if d.Duration < 0 {
+		errs.Add("duration", errors.New("duration must be non-negative"))
+	}
+
+	sortOrder, operator := d.scoring()
+	if err := ValidateScoring(sortOrder, operator); err != nil {
+		field := "operator"
+		if errors.Is(err, ErrUnknownSortOrder) {
+			field = "sort_order"
+		}
+		errs.Add(field, err)
+	}
+	return errs
+}
+
+// ParseScoring converts raw sort order and operator values, as they arrive in
+// RPC payloads, applying the NewTournament defaults to empty ones. It returns
+// ValidateScoring's error, naming the rejected value and the accepted set.
+func ParseScoring(sortOrder, operator string) (SortOrder, Operator, error) {
+	s, o := Draft{SortOrder: SortOrder(sortOrder), Operator: Operator(operator)}.scoring()
+	if err := ValidateScoring(s, o); err != nil {
+		return "", "", err
+	}
+	return s, o, nil
+}
+
+// scoring returns the sort order and operator with defaults applied.
+func (d Draft) scoring() (SortOrder, Operator) {
+	sortOrder, operator := d.SortOrder, d.Operator
+	if sortOrder == "" {
+		sortOrder = SortOrderDescending
+	}
+	if operator == "" {
+		operator = OperatorBest
+	}
+	return sortOrder, operator
+}
@@ -0,0 +1,235 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+)
+
+// ErrDispatcherClosed is returned by Dispatch and Flush once Close has been called.
+var ErrDispatcherClosed = errors.New("analytics dispatcher closed")
+
+// DefaultAsyncBufferSize is the number of pending batches an AsyncDispatcher holds.
+const DefaultAsyncBufferSize = 256
+
+// DefaultAsyncBatchSize is the largest batch a batching AsyncDispatcher sends
+// when no size is given.
+const DefaultAsyncBatchSize = 100
+
+// DefaultFlushInterval is how often a batching AsyncDispatcher sends its
+// pending events when no interval is given.
+const DefaultFlushInterval = 5 * time.Second
+
+// AsyncDispatcher implements EventDispatcher on a best-effort basis: Dispatch
+// queues the batch and returns immediately while a background worker forwards
+// it to the wrapped dispatcher. Batches are dropped when the buffer is full.
+type AsyncDispatcher struct {
+	next  analytics.EventDispatcher
+	queue chan asyncItem
+
+	// batchSize and interval are set when batching; pending is only touched
+	// by the worker.
+	batchSize int
+	interval  time.Duration
+	pending   []*analytics.Event
+
+	// mu guards closed so that no batch is queued once Close has started.
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	done      chan struct{}
+
+	dropped   atomic.Int64
+	aboveMark atomic.Bool
+
+	// OnError, when set, receives errors returned by the wrapped dispatcher.
+	OnError func(err error)
+	// HighWaterMark is the buffer depth at which OnHighWater fires. Zero disables it.
+	HighWaterMark int
+	// OnHighWater, when set, is called once each time the buffer depth rises to
+	// HighWaterMark. It fires again only after the depth has fallen back below it.
+	OnHighWater func(depth, capacity int)
+}
+
+type asyncItem struct {
+	events []*analytics.Event
+	flush  chan struct{}
+}
+
+// NewAsyncDispatcher creates an AsyncDispatcher and starts its worker. Each
+// queued batch is forwarded as it was dispatched.
+func NewAsyncDispatcher(next analytics.EventDispatcher, bufferSize int) *AsyncDispatcher {
+	return newAsyncDispatcher(next, bufferSize, 0, 0)
+}
+
+// NewBatchingAsyncDispatcher creates an AsyncDispatcher whose worker combines
+// queued events and forwards them in batches of at most batchSize, once
+// batchSize events are pending or every interval, whichever comes first. A
+// non-positive batchSize falls back to DefaultAsyncBatchSize and a non-positive
+// interval to DefaultFlushInterval.
+func NewBatchingAsyncDispatcher(next analytics.EventDispatcher, bufferSize, batchSize int, interval time.Duration) *AsyncDispatcher {
+	if batchSize <= 0 {
+		batchSize = DefaultAsyncBatchSize
+	}
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	return newAsyncDispatcher(next, bufferSize, batchSize, interval)
+}
+
+func newAsyncDispatcher(next analytics.EventDispatcher, bufferSize, batchSize int, interval time.Duration) *AsyncDispatcher {
+	if bufferSize <= 0 {
+		bufferSize = DefaultAsyncBufferSize
+	}
+	d := &AsyncDispatcher{
+		next:      next,
+		queue:     make(chan asyncItem, bufferSize),
+		done:      make(chan struct{}),
+		batchSize: batchSize,
+		interval:  interval,
+	}
+	go d.run()
+	return d
+}
+
+// Dispatch queues events for background delivery without waiting for them to
+// be sent. It returns ErrDispatcherClosed after Close.
+func (d *AsyncDispatcher) Dispatch(ctx context.Context, events []*analytics.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+	select {
+	case d.queue <- asyncItem{events: events}:
+		d.checkHighWater()
+	default:
+		d.dropped.Add(int64(len(events)))
+	}
+	return nil
+}
+
+// Depth returns the number of batches waiting in the buffer.
+func (d *AsyncDispatcher) Depth() int {
+	return len(d.queue)
+}
+
+// Capacity returns the maximum number of batches the buffer holds.
+func (d *AsyncDispatcher) Capacity() int {
+	return cap(d.queue)
+}
+
+// Dropped returns the number of events discarded because the buffer was full.
+func (d *AsyncDispatcher) Dropped() int64 {
+	return d.dropped.Load()
+}
+
+func (d *AsyncDispatcher) checkHighWater() {
+	if d.HighWaterMark <= 0 {
+		return
+	}
+	depth := len(d.queue)
+	if depth < d.HighWaterMark {
+		d.aboveMark.Store(false)
+		return
+	}
+	if d.aboveMark.CompareAndSwap(false, true) && d.OnHighWater != nil {
+		d.OnHighWater(depth, cap(d.queue))
+	}
+}
+
+// Flush blocks until every batch queued before the call, and every event
+// pending in a partial batch, has been handed to the wrapped dispatcher, or
+// ctx is done.
+func (d *AsyncDispatcher) Flush(ctx context.Context) error {
+	d.mu.RLock()
+	closed := d.closed
+	d.mu.RUnlock()
+	if closed {
+		return ErrDispatcherClosed
+	}
+	return d.flush(ctx)
+}
+
+func (d *AsyncDispatcher) flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+	select {
+	case d.queue <- asyncItem{flush: flushed}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting batches, flushes those already queued and stops the
+// worker. Calling it again is a no-op.
+func (d *AsyncDispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	closed := d.closed
+	d.closed = true
+	d.mu.Unlock()
+	if closed {
+		return nil
+	}
+	err := d.flush(ctx)
+	d.closeOnce.Do(func() { close(d.done) })
+	return err
+}
+
+func (d *AsyncDispatcher) run() {
+	var tick <-chan time.Time
+	if d.interval > 0 {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case item := <-d.queue:
+			if item.flush != nil {
+				d.send()
+				close(item.flush)
+				continue
+			}
+			d.pending = append(d.pending, item.events...)
+			if d.batchSize <= 0 || len(d.pending) >= d.batchSize {
+				d.send()
+			}
+			if d.HighWaterMark > 0 && len(d.queue) < d.HighWaterMark {
+				d.aboveMark.Store(false)
+			}
+		case <-tick:
+			d.send()
+		case <-d.done:
+			return
+		}
+	}
+}
+
+// send forwards the pending events, in batches of at most batchSize when
+// batching.
+func (d *AsyncDispatcher) send() {
+	for len(d.pending) > 0 {
+		n := len(d.pending)
+		if d.batchSize > 0 && n > d.batchSize {
+			n = d.batchSize
+		}
+		if err := d.next.Dispatch(context.Background(), d.pending[:n]); err != nil && d.OnError != nil {
+			d.OnError(err)
+		}
+		d.pending = d.pending[n:]
+	}
+	d.pending = nil
+}
@@ -0,0 +1,243 @@
+package analytics_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	appAnalytics "github.com/heroiclabs/nakama/v3/src/app/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	infraAnalytics "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+)
+
+type blockingDispatcher struct {
+	release chan struct{}
+	started chan struct{}
+
+	mu     sync.Mutex
+	events int
+}
+
+func (d *blockingDispatcher) Dispatch(ctx context.Context, events []*analytics.Event) error {
+	if d.started != nil {
+		select {
+		case d.started <- struct{}{}:
+		default:
+		}
+	}
+	<-d.release
+	d.mu.Lock()
+	d.events += len(events)
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *blockingDispatcher) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.events
+}
+
+func TestAsyncDispatcher_TrackEventDoesNotBlock(t *testing.T) {
+	ctx := context.Background()
+	next := &blockingDispatcher{release: make(chan struct{})}
+	async := infraAnalytics.NewAsyncDispatcher(next, 8)
+	service := appAnalytics.NewService(async, infraAnalytics.NewMemorySessionRepository())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- service.TrackEvent(ctx, appAnalytics.TrackEventCommand{UserID: "player-1", Name: "level_complete"})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("TrackEvent() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TrackEvent blocked on the underlying dispatcher")
+	}
+	if got := next.count(); got != 0 {
+		t.Errorf("Expected no events delivered yet, got %d", got)
+	}
+
+	close(next.release)
+	flushCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := service.Flush(flushCtx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := next.count(); got != 1 {
+		t.Errorf("Expected 1 event delivered after flush, got %d", got)
+	}
+	if err := async.Close(flushCtx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestAsyncDispatcher_BufferStatsAndHighWater(t *testing.T) {
+	ctx := context.Background()
+	next := &blockingDispatcher{release: make(chan struct{}), started: make(chan struct{}, 1)}
+	async := infraAnalytics.NewAsyncDispatcher(next, 4)
+	async.HighWaterMark = 3
+
+	var warnings []int
+	async.OnHighWater = func(depth, capacity int) {
+		if capacity != 4 {
+			t.Errorf("OnHighWater capacity = %d, want 4", capacity)
+		}
+		warnings = append(warnings, depth)
+	}
+
+	batch := []*analytics.Event{{Name: "a"}, {Name: "b"}}
+	_ = async.Dispatch(ctx, batch)
+	<-next.started // worker holds the first batch, the buffer is empty again
+
+	for i := 0; i < 6; i++ {
+		_ = async.Dispatch(ctx, batch)
+	}
+
+	if got := async.Depth(); got != 4 {
+		t.Errorf("Depth() = %d, want 4", got)
+	}
+	if got := async.Capacity(); got != 4 {
+		t.Errorf("Capacity() = %d, want 4", got)
+	}
+	if got := async.Dropped(); got != 4 {
+		t.Errorf("Dropped() = %d, want 4 events from two rejected batches", got)
+	}
+	if len(warnings) != 1 || warnings[0] != 3 {
+		t.Errorf("Expected a single high-water warning at depth 3, got %v", warnings)
+	}
+
+	close(next.release)
+	flushCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := async.Close(flushCtx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := async.Depth(); got != 0 {
+		t.Errorf("Depth() after drain = %d, want 0", got)
+	}
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBatchingAsyncDispatcher_FlushesWhenFull(t *testing.T) {
+	ctx := context.Background()
+	next := &testsupport.FakeDispatcher{}
+	async := infraAnalytics.NewBatchingAsyncDispatcher(next, 8, 3, time.Hour)
+	defer async.Close(ctx)
+
+	for _, name := range []analytics.EventName{"a", "b"} {
+		_ = async.Dispatch(ctx, []*analytics.Event{{Name: name}})
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := len(next.Batches()); got != 0 {
+		t.Fatalf("Expected no batches before the batch fills, got %d", got)
+	}
+
+	_ = async.Dispatch(ctx, []*analytics.Event{{Name: "c"}})
+	waitFor(t, func() bool { return len(next.Batches()) == 1 })
+	if got := next.BatchSizes(); !reflect.DeepEqual(got, []int{3}) {
+		t.Errorf("BatchSizes() = %v, want [3]", got)
+	}
+}
+
+func TestBatchingAsyncDispatcher_FlushesOnInterval(t *testing.T) {
+	ctx := context.Background()
+	next := &testsupport.FakeDispatcher{}
+	async := infraAnalytics.NewBatchingAsyncDispatcher(next, 8, 100, 10*time.Millisecond)
+	defer async.Close(ctx)
+
+	_ = async.Dispatch(ctx, []*analytics.Event{{Name: "a"}, {Name: "b"}})
+	waitFor(t, func() bool { return len(next.Events()) == 2 })
+}
+
+func TestBatchingAsyncDispatcher_CloseSendsPending(t *testing.T) {
+	ctx := context.Background()
+	next := &testsupport.FakeDispatcher{}
+	async := infraAnalytics.NewBatchingAsyncDispatcher(next, 8, 2, time.Hour)
+
+	// Five events pending at once are sent in batches of at most two.
+	_ = async.Dispatch(ctx, []*analytics.Event{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}})
+	_ = async.Dispatch(ctx, []*analytics.Event{{Name: "f"}})
+	closeCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := async.Close(closeCtx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := len(next.Events()); got != 6 {
+		t.Fatalf("Expected 6 events delivered, got %d", got)
+	}
+	for _, size := range next.BatchSizes() {
+		if size > 2 {
+			t.Errorf("Batch of %d exceeds size 2", size)
+		}
+	}
+}
+
+func TestAsyncDispatcher_DispatchAfterClose(t *testing.T) {
+	ctx := context.Background()
+	next := &testsupport.FakeDispatcher{}
+	async := infraAnalytics.NewAsyncDispatcher(next, 8)
+
+	closeCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := async.Close(closeCtx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := async.Dispatch(ctx, []*analytics.Event{{Name: "late"}}); !errors.Is(err, infraAnalytics.ErrDispatcherClosed) {
+		t.Errorf("Dispatch() error = %v, want ErrDispatcherClosed", err)
+	}
+	if err := async.Flush(closeCtx); !errors.Is(err, infraAnalytics.ErrDispatcherClosed) {
+		t.Errorf("Flush() error = %v, want ErrDispatcherClosed", err)
+	}
+	if err := async.Close(closeCtx); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if got := len(next.Events()); got != 0 {
+		t.Errorf("Expected no events delivered after Close, got %d", got)
+	}
+}
+
+func TestBatchingAsyncDispatcher_OnError(t *testing.T) {
+	ctx := context.Background()
+	errDown := errors.New("segment down")
+	next := &testsupport.FakeDispatcher{Err: errDown}
+	async := infraAnalytics.NewBatchingAsyncDispatcher(next, 8, 10, time.Hour)
+
+	var mu sync.Mutex
+	var got []error
+	async.OnError = func(err error) {
+		mu.Lock()
+		got = append(got, err)
+		mu.Unlock()
+	}
+
+	_ = async.Dispatch(ctx, []*analytics.Event{{Name: "a"}})
+	closeCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := async.Close(closeCtx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || !errors.Is(got[0], errDown) {
+		t.Errorf("OnError received %v, want [%v]", got, errDown)
+	}
+}
@@ -0,0 +1,64 @@
+package analytics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	infraAnalytics "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+	"go.uber.org/zap"
+)
+
+// TestDispatchers_EmptyBatch checks that every dispatcher accepts an empty
+// batch without sending anything downstream.
+func TestDispatchers_EmptyBatch(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	// next fails every batch, so a forwarded empty batch would surface as an
+	// error or a spilled batch.
+	next := &testsupport.FakeDispatcher{Err: analytics.ErrDispatchFailed}
+	store := infraAnalytics.NewMemoryFailedBatchStore()
+	async := infraAnalytics.NewAsyncDispatcher(next, 1)
+	defer async.Close(context.Background())
+
+	dispatchers := map[string]analytics.EventDispatcher{
+		"segment":   infraAnalytics.NewSegmentDispatcher("write-key", server.URL),
+		"async":     async,
+		"spillover": infraAnalytics.NewSpilloverDispatcher(next, store),
+		"logging":   infraAnalytics.NewLoggingDispatcher(zap.NewNop()),
+		"noop":      infraAnalytics.NoopDispatcher{},
+	}
+	for name, dispatcher := range dispatchers {
+		t.Run(name, func(t *testing.T) {
+			for _, events := range [][]*analytics.Event{nil, {}} {
+				if err := dispatcher.Dispatch(context.Background(), events); err != nil {
+					t.Errorf("Dispatch(%v) error = %v", events, err)
+				}
+			}
+		})
+	}
+
+	if err := async.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("Expected no Segment requests, got %d", n)
+	}
+	if batches := next.Batches(); len(batches) != 0 {
+		t.Errorf("Expected no batches forwarded, got %d", len(batches))
+	}
+	if depth := async.Depth(); depth != 0 {
+		t.Errorf("Expected an empty async buffer, got depth %d", depth)
+	}
+	if spilled, err := store.List(context.Background()); err != nil || len(spilled) != 0 {
+		t.Errorf("Expected no spilled batches, got %v (err %v)", spilled, err)
+	}
+}
@@ -0,0 +1,104 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+)
+
+const failedBatchExt = ".json"
+
+// FileFailedBatchStore implements FailedBatchStore with one JSON file per batch
+// in a directory, so undelivered events survive a restart.
+type FileFailedBatchStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileFailedBatchStore creates the directory if needed and returns a store backed by it.
+func NewFileFailedBatchStore(dir string) (*FileFailedBatchStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating failed batch directory: %w", err)
+	}
+	return &FileFailedBatchStore{dir: dir}, nil
+}
+
+// Save writes the batch atomically, replacing any earlier copy.
+func (s *FileFailedBatchStore) Save(ctx context.Context, batch analytics.FailedBatch) error {
+	if batch.ID == "" || strings.ContainsAny(batch.ID, `/\`) {
+		return fmt.Errorf("invalid failed batch id %q", batch.ID)
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, "batch-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(batch.ID))
+}
+
+// List reads every stored batch, oldest first.
+func (s *FileFailedBatchStore) List(ctx context.Context) ([]analytics.FailedBatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var batches []analytics.FailedBatch
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != failedBatchExt {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var batch analytics.FailedBatch
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return nil, fmt.Errorf("decoding failed batch %s: %w", entry.Name(), err)
+		}
+		batches = append(batches, batch)
+	}
+	sortFailedBatches(batches)
+	return batches, nil
+}
+
+// Delete removes a batch. Deleting an unknown batch is not an error.
+func (s *FileFailedBatchStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *FileFailedBatchStore) path(id string) string {
+	return filepath.Join(s.dir, id+failedBatchExt)
+}
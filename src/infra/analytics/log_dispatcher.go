@@ -0,0 +1,52 @@
+package analytics
+
+import (
+	"context"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"go.uber.org/zap"
+)
+
+// NoopDispatcher implements EventDispatcher by discarding every event. It is
+// useful in tests and wherever analytics should be switched off entirely.
+type NoopDispatcher struct{}
+
+// Dispatch discards events.
+func (NoopDispatcher) Dispatch(ctx context.Context, events []*analytics.Event) error {
+	return nil
+}
+
+// LoggingDispatcher implements EventDispatcher by writing one structured log
+// line per event instead of sending it anywhere. It lets local development
+// run the analytics flow without a Segment write key.
+type LoggingDispatcher struct {
+	logger *zap.Logger
+}
+
+// NewLoggingDispatcher creates a LoggingDispatcher that logs at info level.
+// A nil logger discards the output.
+func NewLoggingDispatcher(logger *zap.Logger) *LoggingDispatcher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &LoggingDispatcher{logger: logger}
+}
+
+// Dispatch logs each event after validating it, so malformed events fail the
+// same way they would against Segment.
+func (d *LoggingDispatcher) Dispatch(ctx context.Context, events []*analytics.Event) error {
+	for _, event := range events {
+		if err := event.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, event := range events {
+		d.logger.Info("analytics event",
+			zap.String("type", string(event.Type)),
+			zap.String("user_id", string(event.UserID)),
+			zap.String("name", string(event.Name)),
+			zap.Time("timestamp", event.Timestamp),
+		)
+	}
+	return nil
+}
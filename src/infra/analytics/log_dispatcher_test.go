@@ -0,0 +1,63 @@
+package analytics_test
+
+import (
+	"context"
+	"testing"
+
+	appAnalytics "github.com/heroiclabs/nakama/v3/src/app/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	infraAnalytics "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggingDispatcher_Dispatch(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	dispatcher := infraAnalytics.NewLoggingDispatcher(zap.New(core))
+
+	identify, _ := analytics.NewIdentifyEvent("player-1", analytics.Context{}, testsupport.DefaultTime)
+	track, _ := analytics.NewTrackEvent("player-1", "purchase", analytics.Context{}, testsupport.DefaultTime)
+	if err := dispatcher.Dispatch(context.Background(), []*analytics.Event{identify, track}); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d", len(entries))
+	}
+	fields := entries[1].ContextMap()
+	if fields["type"] != "track" || fields["user_id"] != "player-1" || fields["name"] != "purchase" {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+}
+
+func TestLoggingDispatcher_InvalidEvent(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	dispatcher := infraAnalytics.NewLoggingDispatcher(zap.New(core))
+
+	valid, _ := analytics.NewTrackEvent("player-1", "purchase", analytics.Context{}, testsupport.DefaultTime)
+	invalid := &analytics.Event{Type: analytics.EventTypeTrack, UserID: "player-1", Timestamp: testsupport.DefaultTime}
+	if err := dispatcher.Dispatch(context.Background(), []*analytics.Event{valid, invalid}); err == nil {
+		t.Fatal("Dispatch() error = nil, want validation error")
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected nothing logged for a rejected batch, got %d lines", logs.Len())
+	}
+}
+
+func TestNoopDispatcher_ServiceFlow(t *testing.T) {
+	service := appAnalytics.NewService(infraAnalytics.NoopDispatcher{}, infraAnalytics.NewMemorySessionRepository())
+	ctx := context.Background()
+
+	if err := service.StartSession(ctx, appAnalytics.StartSessionCommand{UserID: "player-1", Version: "1.0.0"}); err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	if err := service.TrackEvent(ctx, appAnalytics.TrackEventCommand{UserID: "player-1", Name: "purchase"}); err != nil {
+		t.Fatalf("TrackEvent() error = %v", err)
+	}
+	if err := service.EndSession(ctx, appAnalytics.EndSessionCommand{UserID: "player-1"}); err != nil {
+		t.Fatalf("EndSession() error = %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+)
+
+// MemoryFailedBatchStore implements FailedBatchStore using in-memory storage.
+// Batches are lost when the process exits; use FileFailedBatchStore to keep them.
+type MemoryFailedBatchStore struct {
+	mu      sync.RWMutex
+	batches map[string]analytics.FailedBatch
+}
+
+// NewMemoryFailedBatchStore creates a new in-memory failed batch store.
+func NewMemoryFailedBatchStore() *MemoryFailedBatchStore {
+	return &MemoryFailedBatchStore{
+		batches: make(map[string]analytics.FailedBatch),
+	}
+}
+
+// Save stores or replaces a failed batch.
+func (s *MemoryFailedBatchStore) Save(ctx context.Context, batch analytics.FailedBatch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches[batch.ID] = batch
+	return nil
+}
+
+// List returns the stored batches, oldest first.
+func (s *MemoryFailedBatchStore) List(ctx context.Context) ([]analytics.FailedBatch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	batches := make([]analytics.FailedBatch, 0, len(s.batches))
+	for _, batch := range s.batches {
+		batches = append(batches, batch)
+	}
+	sortFailedBatches(batches)
+	return batches, nil
+}
+
+// Delete removes a batch. Deleting an unknown batch is not an error.
+func (s *MemoryFailedBatchStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.batches, id)
+	return nil
+}
+
+func sortFailedBatches(batches []analytics.FailedBatch) {
+	sort.Slice(batches, func(i, j int) bool {
+		if !batches[i].FailedAt.Equal(batches[j].FailedAt) {
+			return batches[i].FailedAt.Before(batches[j].FailedAt)
+		}
+		return batches[i].ID < batches[j].ID
+	})
+}
@@ -0,0 +1,82 @@
+package analytics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// DefaultIdentifyTTL is how long an identify is remembered before it is re-sent.
+const DefaultIdentifyTTL = 24 * time.Hour
+
+// DefaultIdentifyCapacity is the number of users a MemoryIdentifyCache remembers.
+const DefaultIdentifyCapacity = 100000
+
+// MemoryIdentifyCache implements IdentifyCache using in-memory storage. It
+// holds at most capacity users and forgets the one added first when full, so
+// that user's next identify is sent again.
+type MemoryIdentifyCache struct {
+	mu       sync.RWMutex
+	ttl      time.Duration
+	capacity int
+	entries  map[shared.PlayerID]identifyEntry
+	order    []shared.PlayerID
+}
+
+type identifyEntry struct {
+	traitsHash string
+	sentAt     time.Time
+}
+
+// NewMemoryIdentifyCache creates an identify cache whose entries expire after
+// ttl and which holds up to capacity users.
+func NewMemoryIdentifyCache(ttl time.Duration, capacity int) *MemoryIdentifyCache {
+	if ttl <= 0 {
+		ttl = DefaultIdentifyTTL
+	}
+	if capacity <= 0 {
+		capacity = DefaultIdentifyCapacity
+	}
+	return &MemoryIdentifyCache{
+		ttl:      ttl,
+		capacity: capacity,
+		entries:  make(map[shared.PlayerID]identifyEntry),
+	}
+}
+
+// Seen reports whether userID was identified with traitsHash within the TTL.
+func (c *MemoryIdentifyCache) Seen(userID shared.PlayerID, traitsHash string, now time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.entries[userID]
+	if !exists {
+		return false
+	}
+	return entry.traitsHash == traitsHash && now.Sub(entry.sentAt) < c.ttl
+}
+
+// Remember records that userID was identified with traitsHash at now,
+// evicting the oldest user when the cache is full.
+func (c *MemoryIdentifyCache) Remember(userID shared.PlayerID, traitsHash string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[userID]; !ok {
+		for len(c.entries) >= c.capacity && len(c.order) > 0 {
+			oldest := c.order[0]
+			c.order = c.order[1:]
+			delete(c.entries, oldest)
+		}
+		c.order = append(c.order, userID)
+	}
+	c.entries[userID] = identifyEntry{traitsHash: traitsHash, sentAt: now}
+}
+
+// Len returns the number of users currently held.
+func (c *MemoryIdentifyCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
@@ -0,0 +1,33 @@
+package analytics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraAnalytics "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+)
+
+func TestMemoryIdentifyCache_Bounded(t *testing.T) {
+	cache := infraAnalytics.NewMemoryIdentifyCache(time.Hour, 2)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, user := range []shared.PlayerID{"player-1", "player-2", "player-3"} {
+		cache.Remember(user, "traits", now)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+	if cache.Seen("player-1", "traits", now) {
+		t.Error("Expected player-1 to have been evicted")
+	}
+	if !cache.Seen("player-3", "traits", now) {
+		t.Error("Expected player-3 to be remembered")
+	}
+
+	// Identifying a remembered user again does not evict anyone.
+	cache.Remember("player-2", "new-traits", now)
+	if !cache.Seen("player-2", "new-traits", now) || !cache.Seen("player-3", "traits", now) {
+		t.Error("Expected player-2 and player-3 to both be remembered")
+	}
+}
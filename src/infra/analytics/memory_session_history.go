@@ -0,0 +1,98 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// DefaultSessionHistoryLimit is the number of sessions a MemorySessionHistory
+// keeps per user.
+const DefaultSessionHistoryLimit = 50
+
+// MemorySessionHistory implements SessionHistory using in-memory storage. It
+// keeps only active sessions, at most limit per user, dropping the oldest
+// started when a user goes over.
+type MemorySessionHistory struct {
+	mu       sync.RWMutex
+	limit    int
+	sessions map[shared.PlayerID][]analytics.Session
+}
+
+// NewMemorySessionHistory creates an in-memory session history keeping up to
+// limit active sessions per user.
+func NewMemorySessionHistory(limit int) *MemorySessionHistory {
+	if limit <= 0 {
+		limit = DefaultSessionHistoryLimit
+	}
+	return &MemorySessionHistory{
+		limit:    limit,
+		sessions: make(map[shared.PlayerID][]analytics.Session),
+	}
+}
+
+// Record stores a copy of the session, replacing any entry for the same
+// session. An ended session is removed instead, since only active sessions
+// are ever listed.
+func (h *MemorySessionHistory) Record(ctx context.Context, session *analytics.Session) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sessions := h.sessions[session.UserID]
+	kept := sessions[:0]
+	for _, existing := range sessions {
+		if !existing.SameAs(session) {
+			kept = append(kept, existing)
+		}
+	}
+	if session.IsActive() {
+		kept = append(kept, *session)
+	}
+	if len(kept) > h.limit {
+		sort.SliceStable(kept, func(i, j int) bool {
+			return kept[i].StartedAt.Before(kept[j].StartedAt)
+		})
+		kept = append([]analytics.Session(nil), kept[len(kept)-h.limit:]...)
+	}
+	if len(kept) == 0 {
+		delete(h.sessions, session.UserID)
+		return nil
+	}
+	h.sessions[session.UserID] = kept
+	return nil
+}
+
+// ListActive returns copies of the user's active sessions, oldest first.
+func (h *MemorySessionHistory) ListActive(ctx context.Context, userID shared.PlayerID) ([]*analytics.Session, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var active []*analytics.Session
+	for _, session := range h.sessions[userID] {
+		if session.IsActive() {
+			s := session
+			active = append(active, &s)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].StartedAt.Before(active[j].StartedAt)
+	})
+	return active, nil
+}
+
+// Get returns a copy of the user's session with the given ID.
+func (h *MemorySessionHistory) Get(ctx context.Context, userID shared.PlayerID, sessionID string) (*analytics.Session, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, session := range h.sessions[userID] {
+		if sessionID != "" && session.ID == sessionID {
+			s := session
+			return &s, nil
+		}
+	}
+	return nil, analytics.ErrSessionNotFound
+}
@@ -0,0 +1,58 @@
+package analytics_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	infraAnalytics "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+)
+
+func TestMemorySessionHistory_Bounded(t *testing.T) {
+	ctx := context.Background()
+	history := infraAnalytics.NewMemorySessionHistory(2)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var sessions []*analytics.Session
+	for i, id := range []string{"phone", "tablet", "desktop"} {
+		session, err := analytics.NewSession("player-123", "1.0.0", "", start.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("NewSession() error = %v", err)
+		}
+		session.ID = id
+		sessions = append(sessions, session)
+		if err := history.Record(ctx, session); err != nil {
+			t.Fatalf("Record(%s) error = %v", id, err)
+		}
+	}
+
+	activeIDs := func() []string {
+		active, err := history.ListActive(ctx, "player-123")
+		if err != nil {
+			t.Fatalf("ListActive() error = %v", err)
+		}
+		var ids []string
+		for _, session := range active {
+			ids = append(ids, session.ID)
+		}
+		return ids
+	}
+	if got, want := activeIDs(), []string{"tablet", "desktop"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("active sessions = %v, want the newest %v", got, want)
+	}
+
+	if err := sessions[2].End(start.Add(time.Hour)); err != nil {
+		t.Fatalf("End() error = %v", err)
+	}
+	if err := history.Record(ctx, sessions[2]); err != nil {
+		t.Fatalf("Record(desktop) error = %v", err)
+	}
+	if _, err := history.Get(ctx, "player-123", "desktop"); err != analytics.ErrSessionNotFound {
+		t.Errorf("Get() of an ended session error = %v, want ErrSessionNotFound", err)
+	}
+	if got, want := activeIDs(), []string{"tablet"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("active sessions = %v, want %v", got, want)
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
 )
 
+// maxErrorBodyBytes caps how much of a failed response is kept in a DispatchError.
+const maxErrorBodyBytes = 512
+
 // SegmentDispatcher implements EventDispatcher for Segment.io.
 type SegmentDispatcher struct {
 	APIKey     string
@@ -37,14 +42,28 @@ func (d *SegmentDispatcher) WithHTTPClient(client *http.Client) *SegmentDispatch
 	return d
 }
 
+// segmentContext maps the event context to Segment's context object. direct
+// is always sent, since false marks a server-forwarded event.
+func segmentContext(ctx analytics.Context) map[string]interface{} {
+	return map[string]interface{}{
+		"direct": ctx.Direct,
+		"library": map[string]string{
+			"name":    ctx.Library.Name,
+			"version": ctx.Library.Version,
+		},
+	}
+}
+
 // segmentEvent represents the Segment API event format.
 type segmentEvent struct {
-	Type    string                 `json:"type"`
-	UserID  string                 `json:"userId"`
-	Event   string                 `json:"event,omitempty"`
-	Context map[string]interface{} `json:"context"`
-	App     *segmentApp            `json:"app,omitempty"`
-	OS      *segmentOS             `json:"os,omitempty"`
+	Type       string                 `json:"type"`
+	UserID     string                 `json:"userId"`
+	Event      string                 `json:"event,omitempty"`
+	Properties map[string]any         `json:"properties,omitempty"`
+	Traits     map[string]any         `json:"traits,omitempty"`
+	Context    map[string]interface{} `json:"context"`
+	App        *segmentApp            `json:"app,omitempty"`
+	OS         *segmentOS             `json:"os,omitempty"`
 }
 
 type segmentApp struct {
@@ -74,19 +93,17 @@ func (d *SegmentDispatcher) Dispatch(ctx context.Context, events []*analytics.Ev
 		}
 
 		se := segmentEvent{
-			Type:   string(event.Type),
-			UserID: string(event.UserID),
-			Context: map[string]interface{}{
-				"direct": event.Context.Direct,
-				"library": map[string]string{
-					"name":    event.Context.Library.Name,
-					"version": event.Context.Library.Version,
-				},
-			},
+			Type:    string(event.Type),
+			UserID:  string(event.UserID),
+			Context: segmentContext(event.Context),
 		}
 
 		if event.Type == analytics.EventTypeTrack {
 			se.Event = string(event.Name)
+			se.Properties = event.Properties
+		}
+		if event.Type == analytics.EventTypeIdentify {
+			se.Traits = event.Traits
 		}
 
 		if event.App != nil {
@@ -127,7 +144,12 @@ func (d *SegmentDispatcher) Dispatch(ctx context.Context, events []*analytics.Ev
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return analytics.ErrDispatchFailed
+		// The read is best effort; a partial body is still worth reporting.
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return &analytics.DispatchError{
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(body)),
+		}
 	}
 
 	return nil
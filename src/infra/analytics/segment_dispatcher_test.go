@@ -0,0 +1,149 @@
+package analytics_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	infraAnalytics "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+)
+
+func TestSegmentDispatcher_Dispatch(t *testing.T) {
+	event, err := analytics.NewTrackEvent("player-1", "purchase", analytics.Context{}, testsupport.DefaultTime)
+	if err != nil {
+		t.Fatalf("NewTrackEvent() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantErr  bool
+		wantBody string
+	}{
+		{name: "accepted", status: http.StatusOK, body: `{"success":true}`},
+		{name: "rejected with reason", status: http.StatusBadRequest, body: `{"error":"invalid userId"}` + "\n", wantErr: true, wantBody: `{"error":"invalid userId"}`},
+		{name: "long body truncated", status: http.StatusInternalServerError, body: strings.Repeat("x", 2000), wantErr: true, wantBody: strings.Repeat("x", 512)},
+		{name: "empty body", status: http.StatusUnauthorized, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			dispatcher := infraAnalytics.NewSegmentDispatcher("write-key", server.URL)
+			err := dispatcher.Dispatch(context.Background(), []*analytics.Event{event})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Dispatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+			if !errors.Is(err, analytics.ErrDispatchFailed) {
+				t.Errorf("Dispatch() error = %v, want it to match ErrDispatchFailed", err)
+			}
+			var dispatchErr *analytics.DispatchError
+			if !errors.As(err, &dispatchErr) {
+				t.Fatalf("Dispatch() error = %v, want *DispatchError", err)
+			}
+			if dispatchErr.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", dispatchErr.StatusCode, tt.status)
+			}
+			if dispatchErr.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", dispatchErr.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSegmentDispatcher_DirectFlag(t *testing.T) {
+	for _, direct := range []bool{true, false} {
+		t.Run(fmt.Sprintf("direct=%t", direct), func(t *testing.T) {
+			var payload struct {
+				Batch []struct {
+					Context map[string]json.RawMessage `json:"context"`
+				} `json:"batch"`
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Errorf("decoding payload: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			event, err := analytics.NewTrackEvent("player-1", "purchase", analytics.Context{Direct: direct}, testsupport.DefaultTime)
+			if err != nil {
+				t.Fatalf("NewTrackEvent() error = %v", err)
+			}
+			dispatcher := infraAnalytics.NewSegmentDispatcher("write-key", server.URL)
+			if err := dispatcher.Dispatch(context.Background(), []*analytics.Event{event}); err != nil {
+				t.Fatalf("Dispatch() error = %v", err)
+			}
+
+			if len(payload.Batch) != 1 {
+				t.Fatalf("Expected 1 event in payload, got %d", len(payload.Batch))
+			}
+			raw, ok := payload.Batch[0].Context["direct"]
+			if !ok {
+				t.Fatal("Expected context.direct to be present")
+			}
+			if want := fmt.Sprintf("%t", direct); string(raw) != want {
+				t.Errorf("context.direct = %s, want %s", raw, want)
+			}
+		})
+	}
+}
+
+func TestSegmentDispatcher_PropertiesAndTraits(t *testing.T) {
+	var payload struct {
+		Batch []map[string]json.RawMessage `json:"batch"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	identify, err := analytics.NewIdentifyEvent("player-1", analytics.Context{}, testsupport.DefaultTime)
+	if err != nil {
+		t.Fatalf("NewIdentifyEvent() error = %v", err)
+	}
+	identify.WithTraits(map[string]any{"plan": "pro"})
+	track, err := analytics.NewTrackEvent("player-1", "level_complete", analytics.Context{}, testsupport.DefaultTime)
+	if err != nil {
+		t.Fatalf("NewTrackEvent() error = %v", err)
+	}
+	track.WithProperties(map[string]any{"level": 3, "currency": "gems"})
+
+	dispatcher := infraAnalytics.NewSegmentDispatcher("write-key", server.URL)
+	if err := dispatcher.Dispatch(context.Background(), []*analytics.Event{identify, track}); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+
+	if len(payload.Batch) != 2 {
+		t.Fatalf("Expected 2 events in payload, got %d", len(payload.Batch))
+	}
+	if got := string(payload.Batch[0]["traits"]); got != `{"plan":"pro"}` {
+		t.Errorf("identify traits = %s, want {\"plan\":\"pro\"}", got)
+	}
+	if _, ok := payload.Batch[0]["properties"]; ok {
+		t.Error("Expected identify event to carry no properties")
+	}
+	if got := string(payload.Batch[1]["properties"]); got != `{"currency":"gems","level":3}` {
+		t.Errorf("track properties = %s, want {\"currency\":\"gems\",\"level\":3}", got)
+	}
+	if _, ok := payload.Batch[1]["traits"]; ok {
+		t.Error("Expected track event to carry no traits")
+	}
+}
@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+)
+
+// SpilloverDispatcher wraps an EventDispatcher and stores batches it fails to
+// deliver in a FailedBatchStore so ReplayPending can resend them later.
+type SpilloverDispatcher struct {
+	Next  analytics.EventDispatcher
+	Store analytics.FailedBatchStore
+	Clock func() time.Time
+
+	// OnSpill, when set, is called after a failed batch has been stored.
+	OnSpill func(batch analytics.FailedBatch, cause error)
+}
+
+// NewSpilloverDispatcher creates a SpilloverDispatcher.
+func NewSpilloverDispatcher(next analytics.EventDispatcher, store analytics.FailedBatchStore) *SpilloverDispatcher {
+	return &SpilloverDispatcher{
+		Next:  next,
+		Store: store,
+		Clock: func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Dispatch forwards events to the wrapped dispatcher. A failed batch is
+// stored instead of being dropped and Dispatch reports success; it only
+// returns an error when the batch could not be stored either.
+func (d *SpilloverDispatcher) Dispatch(ctx context.Context, events []*analytics.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	err := d.Next.Dispatch(ctx, events)
+	if err == nil {
+		return nil
+	}
+
+	batch := analytics.FailedBatch{
+		ID:        uuid.Must(uuid.NewV4()).String(),
+		Events:    events,
+		FailedAt:  d.Clock(),
+		Attempts:  1,
+		LastError: err.Error(),
+	}
+	if saveErr := d.Store.Save(ctx, batch); saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+	if d.OnSpill != nil {
+		d.OnSpill(batch, err)
+	}
+	return nil
+}
+
+// ReplayPending resends stored batches oldest first, deleting each one once it
+// is delivered. It stops at the first batch that fails again, recording the
+// attempt, and returns how many batches were delivered.
+func (d *SpilloverDispatcher) ReplayPending(ctx context.Context) (int, error) {
+	batches, err := d.Store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var replayed int
+	for _, batch := range batches {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+		if err := d.Next.Dispatch(ctx, batch.Events); err != nil {
+			batch.Attempts++
+			batch.LastError = err.Error()
+			return replayed, errors.Join(err, d.Store.Save(ctx, batch))
+		}
+		if err := d.Store.Delete(ctx, batch.ID); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// RunReplay calls ReplayPending every interval until ctx is done. Errors are
+// passed to onError, if set, and the loop keeps going.
+func (d *SpilloverDispatcher) RunReplay(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.ReplayPending(ctx); err != nil && onError != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package analytics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	infraAnalytics "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+)
+
+// flakyDispatcher fails while err is set and records delivered batches otherwise.
+type flakyDispatcher struct {
+	err       error
+	delivered [][]*analytics.Event
+}
+
+func (d *flakyDispatcher) Dispatch(ctx context.Context, events []*analytics.Event) error {
+	if d.err != nil {
+		return d.err
+	}
+	d.delivered = append(d.delivered, events)
+	return nil
+}
+
+func failedBatchStores(t *testing.T) map[string]func() analytics.FailedBatchStore {
+	return map[string]func() analytics.FailedBatchStore{
+		"memory": func() analytics.FailedBatchStore { return infraAnalytics.NewMemoryFailedBatchStore() },
+		"file": func() analytics.FailedBatchStore {
+			store, err := infraAnalytics.NewFileFailedBatchStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewFileFailedBatchStore() error = %v", err)
+			}
+			return store
+		},
+	}
+}
+
+func TestSpilloverDispatcher_SpillAndReplay(t *testing.T) {
+	for name, newStore := range failedBatchStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore()
+			outage := errors.New("segment unavailable")
+			next := &flakyDispatcher{err: outage}
+			clock := testsupport.NewFakeClock()
+			spill := infraAnalytics.NewSpilloverDispatcher(next, store)
+			spill.Clock = clock.Now
+
+			var spilled int
+			spill.OnSpill = func(batch analytics.FailedBatch, cause error) {
+				if !errors.Is(cause, outage) {
+					t.Errorf("OnSpill cause = %v, want %v", cause, outage)
+				}
+				spilled++
+			}
+
+			for _, name := range []string{"first", "second"} {
+				if err := spill.Dispatch(ctx, []*analytics.Event{{Name: analytics.EventName(name), UserID: "player-1"}}); err != nil {
+					t.Fatalf("Dispatch() error = %v", err)
+				}
+				clock.Advance(1)
+			}
+
+			pending, err := store.List(ctx)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(pending) != 2 || spilled != 2 {
+				t.Fatalf("Expected 2 spilled batches, got %d stored and %d callbacks", len(pending), spilled)
+			}
+			if pending[0].LastError != outage.Error() || pending[0].Attempts != 1 {
+				t.Errorf("Unexpected stored batch %+v", pending[0])
+			}
+
+			// Replay while still down records the attempt and keeps the batch.
+			if n, err := spill.ReplayPending(ctx); !errors.Is(err, outage) || n != 0 {
+				t.Errorf("ReplayPending() = %d, %v, want 0, %v", n, err, outage)
+			}
+			pending, _ = store.List(ctx)
+			if len(pending) != 2 || pending[0].Attempts != 2 {
+				t.Errorf("Expected the failed replay to be recorded, got %+v", pending)
+			}
+
+			next.err = nil
+			n, err := spill.ReplayPending(ctx)
+			if err != nil {
+				t.Fatalf("ReplayPending() error = %v", err)
+			}
+			if n != 2 {
+				t.Errorf("ReplayPending() = %d, want 2", n)
+			}
+			if len(next.delivered) != 2 || next.delivered[0][0].Name != "first" || next.delivered[1][0].Name != "second" {
+				t.Errorf("Expected batches replayed oldest first, got %v", next.delivered)
+			}
+			if pending, _ := store.List(ctx); len(pending) != 0 {
+				t.Errorf("Expected store to be empty after replay, got %d", len(pending))
+			}
+		})
+	}
+}
+
+func TestSpilloverDispatcher_StoreFailure(t *testing.T) {
+	outage := errors.New("segment unavailable")
+	store, err := infraAnalytics.NewFileFailedBatchStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileFailedBatchStore() error = %v", err)
+	}
+	spill := infraAnalytics.NewSpilloverDispatcher(&flakyDispatcher{err: outage}, &brokenStore{store})
+
+	if err := spill.Dispatch(context.Background(), []*analytics.Event{{Name: "a"}}); !errors.Is(err, outage) {
+		t.Errorf("Dispatch() error = %v, want %v", err, outage)
+	}
+}
+
+type brokenStore struct {
+	analytics.FailedBatchStore
+}
+
+func (s *brokenStore) Save(ctx context.Context, batch analytics.FailedBatch) error {
+	return errors.New("disk full")
+}
+
+func TestFileFailedBatchStore_SurvivesReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := infraAnalytics.NewFileFailedBatchStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileFailedBatchStore() error = %v", err)
+	}
+	batch := analytics.FailedBatch{
+		ID:       "batch-1",
+		Events:   []*analytics.Event{{Type: analytics.EventTypeTrack, UserID: "player-1", Name: "level_complete", Timestamp: testsupport.DefaultTime}},
+		FailedAt: testsupport.DefaultTime,
+		Attempts: 1,
+	}
+	if err := store.Save(ctx, batch); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reopened, err := infraAnalytics.NewFileFailedBatchStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileFailedBatchStore() error = %v", err)
+	}
+	pending, err := reopened.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != "batch-1" || pending[0].Events[0].Name != "level_complete" || !pending[0].Events[0].Timestamp.Equal(testsupport.DefaultTime) {
+		t.Errorf("Unexpected batches after reopen: %+v", pending)
+	}
+	if err := reopened.Delete(ctx, "batch-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := reopened.Delete(ctx, "batch-1"); err != nil {
+		t.Errorf("Delete() of a missing batch error = %v", err)
+	}
+	if err := reopened.Save(ctx, analytics.FailedBatch{ID: "../escape"}); err == nil {
+		t.Error("Expected an invalid batch id to be rejected")
+	}
+}
@@ -0,0 +1,100 @@
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// sessionTableSchema holds one row per user, matching the one-current-session
+// semantics of SessionRepository. It is valid for both PostgreSQL and
+// CockroachDB.
+const sessionTableSchema = `
+CREATE TABLE IF NOT EXISTS sandai_analytics_sessions (
+	user_id    VARCHAR(128) PRIMARY KEY,
+	session_id VARCHAR(128) NOT NULL DEFAULT '',
+	state      VARCHAR(16)  NOT NULL,
+	version    VARCHAR(64)  NOT NULL,
+	variant    VARCHAR(64)  NOT NULL DEFAULT '',
+	started_at TIMESTAMPTZ  NOT NULL,
+	ended_at   TIMESTAMPTZ
+)`
+
+// CreateSessionTable creates the table SQLSessionRepository uses if it does
+// not exist yet. It is safe to call on every start.
+func CreateSessionTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, sessionTableSchema)
+	return err
+}
+
+// SQLSessionRepository implements SessionRepository on the database Nakama
+// hands to the runtime, so sessions survive a process restart.
+type SQLSessionRepository struct {
+	db *sql.DB
+}
+
+// NewSQLSessionRepository creates a repository over db. Call
+// CreateSessionTable before first use.
+func NewSQLSessionRepository(db *sql.DB) *SQLSessionRepository {
+	return &SQLSessionRepository{db: db}
+}
+
+// Save stores a session, replacing the user's previous one.
+func (r *SQLSessionRepository) Save(ctx context.Context, session *analytics.Session) error {
+	var endedAt sql.NullTime
+	if session.EndedAt != nil {
+		endedAt = sql.NullTime{Time: session.EndedAt.UTC(), Valid: true}
+	}
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO sandai_analytics_sessions (user_id, session_id, state, version, variant, started_at, ended_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id) DO UPDATE SET
+	session_id = EXCLUDED.session_id,
+	state = EXCLUDED.state,
+	version = EXCLUDED.version,
+	variant = EXCLUDED.variant,
+	started_at = EXCLUDED.started_at,
+	ended_at = EXCLUDED.ended_at`,
+		string(session.UserID), session.ID, string(session.State), session.Version, session.Variant,
+		session.StartedAt.UTC(), endedAt,
+	)
+	return err
+}
+
+// Get retrieves a session by user ID.
+func (r *SQLSessionRepository) Get(ctx context.Context, userID shared.PlayerID) (*analytics.Session, error) {
+	var (
+		session analytics.Session
+		state   string
+		endedAt sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx, `
+SELECT session_id, state, version, variant, started_at, ended_at
+FROM sandai_analytics_sessions
+WHERE user_id = $1`, string(userID),
+	).Scan(&session.ID, &state, &session.Version, &session.Variant, &session.StartedAt, &endedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, analytics.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session.UserID = userID
+	session.State = analytics.SessionState(state)
+	session.StartedAt = session.StartedAt.UTC()
+	if endedAt.Valid {
+		t := endedAt.Time.UTC()
+		session.EndedAt = &t
+	}
+	return &session, nil
+}
+
+// Delete removes a session. Deleting a missing session is not an error.
+func (r *SQLSessionRepository) Delete(ctx context.Context, userID shared.PlayerID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM sandai_analytics_sessions WHERE user_id = $1`, string(userID))
+	return err
+}
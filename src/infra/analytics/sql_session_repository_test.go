@@ -0,0 +1,102 @@
+package analytics_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraAnalytics "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+)
+
+// openTestDB returns a fake database that understands just the statements
+// SQLSessionRepository issues, keeping one row per user in memory.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	rows := make(map[driver.Value][]driver.Value)
+	fake := &testsupport.FakeSQL{}
+	fake.OnExec("CREATE TABLE IF NOT EXISTS sandai_analytics_sessions", func(args []driver.Value) (int64, error) {
+		return 0, nil
+	})
+	fake.OnExec("INSERT INTO sandai_analytics_sessions", func(args []driver.Value) (int64, error) {
+		rows[args[0]] = args[1:]
+		return 1, nil
+	})
+	fake.OnExec("DELETE FROM sandai_analytics_sessions WHERE user_id = $1", func(args []driver.Value) (int64, error) {
+		delete(rows, args[0])
+		return 1, nil
+	})
+	fake.OnQuery("FROM sandai_analytics_sessions WHERE user_id = $1", 6, func(args []driver.Value) ([][]driver.Value, error) {
+		if row, ok := rows[args[0]]; ok {
+			return [][]driver.Value{row}, nil
+		}
+		return nil, nil
+	})
+	return fake.Open(t)
+}
+
+func TestSQLSessionRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	if err := infraAnalytics.CreateSessionTable(ctx, db); err != nil {
+		t.Fatalf("CreateSessionTable() error = %v", err)
+	}
+	if err := infraAnalytics.CreateSessionTable(ctx, db); err != nil {
+		t.Fatalf("CreateSessionTable() second call error = %v", err)
+	}
+	repo := infraAnalytics.NewSQLSessionRepository(db)
+	userID := shared.PlayerID(fmt.Sprintf("player-%d", time.Now().UnixNano()))
+	t.Cleanup(func() { _ = repo.Delete(ctx, userID) })
+
+	if _, err := repo.Get(ctx, userID); !errors.Is(err, analytics.ErrSessionNotFound) {
+		t.Fatalf("Get() error = %v, want ErrSessionNotFound", err)
+	}
+
+	session, err := analytics.NewSession(userID, "1.0.0", "beta", testsupport.DefaultTime)
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	session.ID = "phone"
+	if err := repo.Save(ctx, session); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := repo.Get(ctx, userID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.ID != "phone" || got.State != analytics.SessionStateActive || got.Version != "1.0.0" || got.Variant != "beta" {
+		t.Errorf("Get() = %+v, want the saved session", got)
+	}
+	if !got.StartedAt.Equal(testsupport.DefaultTime) || got.EndedAt != nil {
+		t.Errorf("Get() times = %v/%v, want %v/nil", got.StartedAt, got.EndedAt, testsupport.DefaultTime)
+	}
+
+	// Saving again overwrites the row rather than failing on the primary key.
+	endedAt := testsupport.DefaultTime.Add(time.Hour)
+	if err := session.End(endedAt); err != nil {
+		t.Fatalf("End() error = %v", err)
+	}
+	if err := repo.Save(ctx, session); err != nil {
+		t.Fatalf("Save() upsert error = %v", err)
+	}
+	got, err = repo.Get(ctx, userID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.State != analytics.SessionStateEnded || got.EndedAt == nil || !got.EndedAt.Equal(endedAt) {
+		t.Errorf("Get() after upsert = %+v, want ended at %v", got, endedAt)
+	}
+
+	if err := repo.Delete(ctx, userID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.Get(ctx, userID); !errors.Is(err, analytics.ErrSessionNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrSessionNotFound", err)
+	}
+}
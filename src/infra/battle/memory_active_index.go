@@ -0,0 +1,57 @@
+package battle
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// MemoryActiveIndex implements battle.ActiveIndex using in-memory storage.
+type MemoryActiveIndex struct {
+	mu      sync.RWMutex
+	leaders map[shared.BattleID]shared.PlayerID
+}
+
+// NewMemoryActiveIndex creates a new in-memory active battle index.
+func NewMemoryActiveIndex() *MemoryActiveIndex {
+	return &MemoryActiveIndex{
+		leaders: make(map[shared.BattleID]shared.PlayerID),
+	}
+}
+
+// Add indexes a running battle by its leader.
+func (i *MemoryActiveIndex) Add(ctx context.Context, b *battle.Battle) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.leaders[b.ID] = b.Leader
+	return nil
+}
+
+// ListByLeader returns the IDs of the running battles led by the given
+// player, in ID order.
+func (i *MemoryActiveIndex) ListByLeader(ctx context.Context, leader shared.PlayerID) ([]shared.BattleID, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var result []shared.BattleID
+	for id, l := range i.leaders {
+		if l == leader {
+			result = append(result, id)
+		}
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a] < result[b] })
+	return result, nil
+}
+
+// Remove drops a battle from the index.
+func (i *MemoryActiveIndex) Remove(ctx context.Context, id shared.BattleID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.leaders, id)
+	return nil
+}
@@ -0,0 +1,121 @@
+package battle
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// MemoryRepository implements battle.Repository using in-memory storage.
+type MemoryRepository struct {
+	mu      sync.RWMutex
+	battles map[shared.BattleID]*battle.Battle
+}
+
+// NewMemoryRepository creates a new in-memory battle repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		battles: make(map[shared.BattleID]*battle.Battle),
+	}
+}
+
+// Get returns a copy of the battle with the given ID.
+func (r *MemoryRepository) Get(ctx context.Context, id shared.BattleID) (*battle.Battle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	b, ok := r.battles[id]
+	if !ok {
+		return nil, shared.ErrNotFound
+	}
+	return copyBattle(b), nil
+}
+
+// GetByIdempotencyKey returns a copy of the battle started with key.
+func (r *MemoryRepository) GetByIdempotencyKey(ctx context.Context, key shared.IdempotencyKey) (*battle.Battle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, b := range r.battles {
+		if b.IdempotencyKey == key {
+			return copyBattle(b), nil
+		}
+	}
+	return nil, shared.ErrNotFound
+}
+
+// Save stores a copy of the battle using optimistic locking. The battle's
+// Version must match the stored version; on success it is incremented.
+func (r *MemoryRepository) Save(ctx context.Context, b *battle.Battle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stored int64
+	if existing, ok := r.battles[b.ID]; ok {
+		stored = existing.Version
+	}
+	if b.Version != stored {
+		return battle.ErrConcurrentModification
+	}
+	b.Version++
+	r.battles[b.ID] = copyBattle(b)
+	return nil
+}
+
+// StoreSnapshot replaces the match state of a stored battle.
+func (r *MemoryRepository) StoreSnapshot(ctx context.Context, id shared.BattleID, state battle.MatchState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.battles[id]
+	if !ok {
+		return shared.ErrNotFound
+	}
+	b.StateSnapshot = state
+	b.UpdatedAt = state.UpdatedAt
+	b.Version++
+	return nil
+}
+
+// ListByPlayer returns a page of the battles the player holds a slot in,
+// newest first.
+func (r *MemoryRepository) ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*battle.Battle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	battles := make([]*battle.Battle, 0)
+	for _, b := range r.battles {
+		if b.HasPlayer(playerID) {
+			battles = append(battles, copyBattle(b))
+		}
+	}
+
+	sort.Slice(battles, func(i, j int) bool {
+		if !battles[i].CreatedAt.Equal(battles[j].CreatedAt) {
+			return battles[i].CreatedAt.After(battles[j].CreatedAt)
+		}
+		return battles[i].ID < battles[j].ID
+	})
+
+	// Apply pagination
+	start := offset
+	if start > len(battles) {
+		return []*battle.Battle{}, nil
+	}
+
+	end := start + limit
+	if end > len(battles) {
+		end = len(battles)
+	}
+
+	return battles[start:end], nil
+}
+
+func copyBattle(b *battle.Battle) *battle.Battle {
+	copied := *b
+	copied.Slots = append([]battle.PlayerSlot(nil), b.Slots...)
+	return &copied
+}
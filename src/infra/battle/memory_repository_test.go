@@ -0,0 +1,151 @@
+package battle_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraBattle "github.com/heroiclabs/nakama/v3/src/infra/battle"
+)
+
+func TestMemoryRepository_ListByPlayer(t *testing.T) {
+	ctx := context.Background()
+	repo := infraBattle.NewMemoryRepository()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// player-1 leads battle-1, joins battle-2 and is absent from battle-3.
+	fixtures := []struct {
+		id      shared.BattleID
+		leader  shared.PlayerID
+		players []shared.PlayerID
+	}{
+		{id: "battle-1", leader: "player-1", players: []shared.PlayerID{"player-2"}},
+		{id: "battle-2", leader: "player-2", players: []shared.PlayerID{"player-1"}},
+		{id: "battle-3", leader: "player-3"},
+	}
+	for i, f := range fixtures {
+		now := start.Add(time.Duration(i) * time.Minute)
+		b, err := battle.NewBattle(f.id, f.leader, shared.IdempotencyKey("key-"+string(f.id)), now)
+		if err != nil {
+			t.Fatalf("NewBattle() error = %v", err)
+		}
+		for _, p := range f.players {
+			_ = b.AddPlayer(p, now)
+		}
+		if err := repo.Save(ctx, b); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		playerID shared.PlayerID
+		limit    int
+		offset   int
+		want     []shared.BattleID
+	}{
+		{name: "leader and member, newest first", playerID: "player-1", limit: 10, want: []shared.BattleID{"battle-2", "battle-1"}},
+		{name: "limit", playerID: "player-1", limit: 1, want: []shared.BattleID{"battle-2"}},
+		{name: "offset", playerID: "player-1", limit: 10, offset: 1, want: []shared.BattleID{"battle-1"}},
+		{name: "offset past end", playerID: "player-1", limit: 10, offset: 5, want: []shared.BattleID{}},
+		{name: "no battles", playerID: "player-4", limit: 10, want: []shared.BattleID{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.ListByPlayer(ctx, tt.playerID, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("ListByPlayer() error = %v", err)
+			}
+			ids := make([]shared.BattleID, 0, len(got))
+			for _, b := range got {
+				ids = append(ids, b.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("ListByPlayer() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryRepository_GetReturnsCopy(t *testing.T) {
+	ctx := context.Background()
+	repo := infraBattle.NewMemoryRepository()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b, _ := battle.NewBattle("battle-1", "player-1", "key-1", now)
+	if err := repo.Save(ctx, b); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := repo.Get(ctx, "battle-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	_ = got.AddPlayer("player-2", now)
+	if again, _ := repo.Get(ctx, "battle-1"); len(again.Slots) != 1 {
+		t.Errorf("Expected stored battle to be unaffected by caller changes, got %d slots", len(again.Slots))
+	}
+
+	if _, err := repo.Get(ctx, "missing"); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, shared.ErrNotFound)
+	}
+}
+
+func TestMemoryRepository_GetByIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	repo := infraBattle.NewMemoryRepository()
+	b, _ := battle.NewBattle("battle-1", "player-1", "key-1", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err := repo.Save(ctx, b); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := repo.GetByIdempotencyKey(ctx, "key-1")
+	if err != nil {
+		t.Fatalf("GetByIdempotencyKey() error = %v", err)
+	}
+	if got.ID != "battle-1" {
+		t.Errorf("GetByIdempotencyKey() id = %q, want %q", got.ID, "battle-1")
+	}
+	if _, err := repo.GetByIdempotencyKey(ctx, "key-2"); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("GetByIdempotencyKey() error = %v, want %v", err, shared.ErrNotFound)
+	}
+}
+
+func TestMemoryRepository_SaveOptimisticLocking(t *testing.T) {
+	ctx := context.Background()
+	repo := infraBattle.NewMemoryRepository()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	b, err := battle.NewBattle("battle-1", "player-1", "key-1", now)
+	if err != nil {
+		t.Fatalf("NewBattle() error = %v", err)
+	}
+	if err := repo.Save(ctx, b); err != nil || b.Version != 1 {
+		t.Fatalf("Save() = %v with version %d, want version 1", err, b.Version)
+	}
+
+	first, _ := repo.Get(ctx, "battle-1")
+	second, _ := repo.Get(ctx, "battle-1")
+	_ = first.AddPlayer("player-2", now)
+	_ = second.AddPlayer("player-3", now)
+	if err := repo.Save(ctx, first); err != nil {
+		t.Fatalf("Save() first writer error = %v", err)
+	}
+	if err := repo.Save(ctx, second); !errors.Is(err, battle.ErrConcurrentModification) {
+		t.Errorf("Save() stale writer error = %v, want %v", err, battle.ErrConcurrentModification)
+	}
+
+	if err := repo.StoreSnapshot(ctx, "battle-1", battle.MatchState{Tick: 7, UpdatedAt: now}); err != nil {
+		t.Fatalf("StoreSnapshot() error = %v", err)
+	}
+	if err := repo.Save(ctx, first); !errors.Is(err, battle.ErrConcurrentModification) {
+		t.Errorf("Save() after a snapshot error = %v, want %v", err, battle.ErrConcurrentModification)
+	}
+	stored, _ := repo.Get(ctx, "battle-1")
+	if stored.StateSnapshot.Tick != 7 || len(stored.Slots) != 2 {
+		t.Errorf("Get() = %+v, want the snapshot and both slots kept", stored)
+	}
+}
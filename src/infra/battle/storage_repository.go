@@ -0,0 +1,195 @@
+package battle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// StorageCollection holds one system-owned storage object per battle, keyed
+// by battle ID. Every writer of battles must use it, so the match handler
+// finds the battles the API started.
+const StorageCollection = "sandai_battles"
+
+// maxSnapshotAttempts bounds how often StoreSnapshot retries after another
+// writer changed the battle between its read and write.
+const maxSnapshotAttempts = 5
+
+// StorageRepository implements battle.Repository on Nakama's storage engine.
+// Writes are conditional on the storage object version, so a save never
+// overwrites a battle changed since it was read.
+type StorageRepository struct {
+	nk runtime.NakamaModule
+}
+
+// NewStorageRepository creates a repository backed by nk's storage engine.
+func NewStorageRepository(nk runtime.NakamaModule) *StorageRepository {
+	return &StorageRepository{nk: nk}
+}
+
+// Get returns the battle with the given ID.
+func (r *StorageRepository) Get(ctx context.Context, id shared.BattleID) (*battle.Battle, error) {
+	b, _, err := r.read(ctx, id)
+	return b, err
+}
+
+// Save stores the battle using optimistic locking. The battle's Version must
+// match the stored one (zero for a new battle), and the write is rejected if
+// the object changed after it was checked. On success Version is incremented.
+func (r *StorageRepository) Save(ctx context.Context, b *battle.Battle) error {
+	storageVersion := "*"
+	stored, version, err := r.read(ctx, b.ID)
+	switch {
+	case err == nil:
+		if stored.Version != b.Version {
+			return battle.ErrConcurrentModification
+		}
+		storageVersion = version
+	case errors.Is(err, shared.ErrNotFound):
+		if b.Version != 0 {
+			return battle.ErrConcurrentModification
+		}
+	default:
+		return err
+	}
+
+	next := *b
+	next.Version++
+	if err := r.write(ctx, &next, storageVersion); err != nil {
+		return err
+	}
+	b.Version = next.Version
+	return nil
+}
+
+// StoreSnapshot replaces the match state of a stored battle. Storage objects
+// cannot be partially updated, so it rewrites the whole battle conditionally
+// and reloads it when a concurrent save wins the race.
+func (r *StorageRepository) StoreSnapshot(ctx context.Context, id shared.BattleID, state battle.MatchState) error {
+	for attempt := 1; ; attempt++ {
+		b, version, err := r.read(ctx, id)
+		if err != nil {
+			return err
+		}
+		b.StateSnapshot = state
+		b.UpdatedAt = state.UpdatedAt
+		b.Version++
+		err = r.write(ctx, b, version)
+		if !errors.Is(err, battle.ErrConcurrentModification) || attempt == maxSnapshotAttempts {
+			return err
+		}
+	}
+}
+
+// ListByPlayer scans every stored battle, since storage cannot be queried by
+// slot.
+func (r *StorageRepository) ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*battle.Battle, error) {
+	var battles []*battle.Battle
+	err := r.scan(ctx, func(b *battle.Battle) bool {
+		if b.HasPlayer(playerID) {
+			battles = append(battles, b)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(battles, func(i, j int) bool {
+		return battles[i].CreatedAt.After(battles[j].CreatedAt)
+	})
+	if offset >= len(battles) {
+		return []*battle.Battle{}, nil
+	}
+	end := offset + limit
+	if end > len(battles) {
+		end = len(battles)
+	}
+	return battles[offset:end], nil
+}
+
+// GetByIdempotencyKey scans stored battles for the one started with key.
+func (r *StorageRepository) GetByIdempotencyKey(ctx context.Context, key shared.IdempotencyKey) (*battle.Battle, error) {
+	var found *battle.Battle
+	err := r.scan(ctx, func(b *battle.Battle) bool {
+		if b.IdempotencyKey == key {
+			found = b
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, shared.ErrNotFound
+	}
+	return found, nil
+}
+
+// read returns the stored battle and the storage object version to make a
+// conditional write against.
+func (r *StorageRepository) read(ctx context.Context, id shared.BattleID) (*battle.Battle, string, error) {
+	objects, err := r.nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: StorageCollection, Key: string(id)}})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(objects) == 0 {
+		return nil, "", shared.ErrNotFound
+	}
+	var b battle.Battle
+	if err := json.Unmarshal([]byte(objects[0].GetValue()), &b); err != nil {
+		return nil, "", err
+	}
+	return &b, objects[0].GetVersion(), nil
+}
+
+// write stores b if the storage object still has version, or does not exist
+// yet when version is "*". A rejected version becomes
+// battle.ErrConcurrentModification.
+func (r *StorageRepository) write(ctx context.Context, b *battle.Battle, version string) error {
+	value, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	_, err = r.nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+		Collection: StorageCollection,
+		Key:        string(b.ID),
+		Value:      string(value),
+		Version:    version,
+	}})
+	if errors.Is(err, runtime.ErrStorageRejectedVersion) {
+		return battle.ErrConcurrentModification
+	}
+	return err
+}
+
+// scan calls fn with every stored battle until fn returns false.
+func (r *StorageRepository) scan(ctx context.Context, fn func(*battle.Battle) bool) error {
+	cursor := ""
+	for {
+		objects, next, err := r.nk.StorageList(ctx, "", "", StorageCollection, 100, cursor)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			var b battle.Battle
+			if err := json.Unmarshal([]byte(object.GetValue()), &b); err != nil {
+				return err
+			}
+			if !fn(&b) {
+				return nil
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
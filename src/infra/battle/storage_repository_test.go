@@ -0,0 +1,152 @@
+package battle_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraBattle "github.com/heroiclabs/nakama/v3/src/infra/battle"
+)
+
+// fakeStorage implements the storage calls used by the repository, with
+// Nakama's conditional write semantics. beforeWrite, when set, runs before
+// each write is checked, to simulate a concurrent writer.
+type fakeStorage struct {
+	runtime.NakamaModule
+
+	mu          sync.Mutex
+	objects     map[string]*api.StorageObject
+	revision    int
+	beforeWrite func()
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{objects: make(map[string]*api.StorageObject)}
+}
+
+func (s *fakeStorage) StorageRead(ctx context.Context, reads []*runtime.StorageRead) ([]*api.StorageObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var objects []*api.StorageObject
+	for _, read := range reads {
+		if object, ok := s.objects[read.Collection+"/"+read.Key]; ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+func (s *fakeStorage) StorageWrite(ctx context.Context, writes []*runtime.StorageWrite) ([]*api.StorageObjectAck, error) {
+	if hook := s.beforeWrite; hook != nil {
+		s.beforeWrite = nil
+		hook()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, write := range writes {
+		existing, ok := s.objects[write.Collection+"/"+write.Key]
+		switch {
+		case write.Version == "*" && ok,
+			write.Version != "" && write.Version != "*" && (!ok || existing.Version != write.Version):
+			return nil, runtime.ErrStorageRejectedVersion
+		}
+	}
+	for _, write := range writes {
+		s.revision++
+		s.objects[write.Collection+"/"+write.Key] = &api.StorageObject{
+			Collection: write.Collection,
+			Key:        write.Key,
+			Value:      write.Value,
+			Version:    strconv.Itoa(s.revision),
+		}
+	}
+	return nil, nil
+}
+
+func TestStorageRepository_SaveOptimisticLocking(t *testing.T) {
+	ctx := context.Background()
+	repo := infraBattle.NewStorageRepository(newFakeStorage())
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	b, err := battle.NewBattle("battle-1", "player-1", "key-1", now)
+	if err != nil {
+		t.Fatalf("NewBattle() error = %v", err)
+	}
+	if err := repo.Save(ctx, b); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if b.Version != 1 {
+		t.Fatalf("Version = %d after create, want 1", b.Version)
+	}
+
+	first, _ := repo.Get(ctx, "battle-1")
+	second, _ := repo.Get(ctx, "battle-1")
+	_ = first.AddPlayer("player-2", now)
+	if err := repo.Save(ctx, first); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	_ = second.AddPlayer("player-3", now)
+	if err := repo.Save(ctx, second); !errors.Is(err, battle.ErrConcurrentModification) {
+		t.Fatalf("Save() of stale battle error = %v, want ErrConcurrentModification", err)
+	}
+
+	again, _ := battle.NewBattle("battle-1", "player-4", "key-2", now)
+	if err := repo.Save(ctx, again); !errors.Is(err, battle.ErrConcurrentModification) {
+		t.Errorf("Save() of a second new battle error = %v, want ErrConcurrentModification", err)
+	}
+
+	stored, err := repo.Get(ctx, "battle-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if stored.Version != 2 || !stored.HasPlayer("player-2") || stored.HasPlayer("player-3") {
+		t.Errorf("stored battle = %+v, want version 2 with only player-2 joined", stored)
+	}
+}
+
+func TestStorageRepository_StoreSnapshot(t *testing.T) {
+	ctx := context.Background()
+	storage := newFakeStorage()
+	repo := infraBattle.NewStorageRepository(storage)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := repo.StoreSnapshot(ctx, "missing", battle.MatchState{Tick: 1}); !errors.Is(err, shared.ErrNotFound) {
+		t.Fatalf("StoreSnapshot() of unknown battle error = %v, want ErrNotFound", err)
+	}
+
+	b, _ := battle.NewBattle("battle-1", "player-1", "key-1", now)
+	if err := repo.Save(ctx, b); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A join saved between the snapshot's read and write must survive.
+	storage.beforeWrite = func() {
+		joined, _ := repo.Get(ctx, "battle-1")
+		_ = joined.AddPlayer("player-2", now)
+		if err := repo.Save(ctx, joined); err != nil {
+			t.Errorf("concurrent Save() error = %v", err)
+		}
+	}
+	if err := repo.StoreSnapshot(ctx, "battle-1", battle.MatchState{Tick: 50, UpdatedAt: now}); err != nil {
+		t.Fatalf("StoreSnapshot() error = %v", err)
+	}
+
+	stored, err := repo.Get(ctx, "battle-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !stored.HasPlayer("player-2") {
+		t.Error("Expected the concurrent join to be kept")
+	}
+	if stored.StateSnapshot.Tick != 50 || stored.Version != 3 {
+		t.Errorf("stored battle = tick %d version %d, want tick 50 version 3", stored.StateSnapshot.Tick, stored.Version)
+	}
+}
@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// DefaultAckCapacity is the number of acknowledged keys a MemoryAckStore remembers.
+const DefaultAckCapacity = 10000
+
+// MemoryAckStore implements AckStore in memory. It holds at most capacity keys
+// and evicts the oldest claim first, so it only guards retries that arrive
+// within the last capacity commands.
+type MemoryAckStore struct {
+	mu       sync.Mutex
+	capacity int
+	claimed  map[shared.IdempotencyKey]struct{}
+	order    []shared.IdempotencyKey
+}
+
+// NewMemoryAckStore creates a MemoryAckStore holding up to capacity keys.
+func NewMemoryAckStore(capacity int) *MemoryAckStore {
+	if capacity <= 0 {
+		capacity = DefaultAckCapacity
+	}
+	return &MemoryAckStore{
+		capacity: capacity,
+		claimed:  make(map[shared.IdempotencyKey]struct{}, capacity),
+	}
+}
+
+// Claim records the key, evicting the oldest claim when the store is full.
+func (s *MemoryAckStore) Claim(ctx context.Context, key shared.IdempotencyKey) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.claimed[key]; ok {
+		return false, nil
+	}
+	for len(s.claimed) >= s.capacity && len(s.order) > 0 {
+		oldest := s.order[0]
+		s.order = s.order[1:]
+		delete(s.claimed, oldest)
+	}
+	s.claimed[key] = struct{}{}
+	s.order = append(s.order, key)
+	return true, nil
+}
+
+// Forget removes a claim.
+func (s *MemoryAckStore) Forget(ctx context.Context, key shared.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.claimed[key]; !ok {
+		return nil
+	}
+	delete(s.claimed, key)
+	for i, k := range s.order {
+		if k == key {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Len returns the number of keys currently held.
+func (s *MemoryAckStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.claimed)
+}
@@ -0,0 +1,34 @@
+package bot_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraBot "github.com/heroiclabs/nakama/v3/src/infra/bot"
+)
+
+func TestMemoryAckStore_Bounded(t *testing.T) {
+	ctx := context.Background()
+	store := infraBot.NewMemoryAckStore(2)
+
+	for _, key := range []shared.IdempotencyKey{"key-1", "key-2", "key-3"} {
+		if first, err := store.Claim(ctx, key); err != nil || !first {
+			t.Fatalf("Claim(%s) = %v, %v, want true", key, first, err)
+		}
+	}
+	if store.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", store.Len())
+	}
+	if first, _ := store.Claim(ctx, "key-3"); first {
+		t.Error("Expected key-3 to still be claimed")
+	}
+	if first, _ := store.Claim(ctx, "key-1"); !first {
+		t.Error("Expected key-1 to have been evicted")
+	}
+
+	_ = store.Forget(ctx, "key-1")
+	if first, _ := store.Claim(ctx, "key-1"); !first {
+		t.Error("Expected key-1 to be claimable after Forget")
+	}
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	domain "github.com/heroiclabs/nakama/v3/src/domain/bot"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// MemoryRepository implements bot.Repository in memory. Commands are stored as
+// copies, so callers must Save to persist changes.
+type MemoryRepository struct {
+	mu       sync.RWMutex
+	commands map[shared.BotCommandID]domain.Command
+	byKey    map[shared.IdempotencyKey]shared.BotCommandID
+}
+
+// NewMemoryRepository creates an empty in-memory command repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		commands: make(map[shared.BotCommandID]domain.Command),
+		byKey:    make(map[shared.IdempotencyKey]shared.BotCommandID),
+	}
+}
+
+// ReserveCommand returns the command already stored under key, or
+// shared.ErrNotFound when the key is new.
+func (r *MemoryRepository) ReserveCommand(ctx context.Context, key shared.IdempotencyKey) (*domain.Command, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byKey[key]
+	if !ok {
+		return nil, shared.ErrNotFound
+	}
+	cmd := r.commands[id]
+	return &cmd, nil
+}
+
+// Get returns a copy of the stored command.
+func (r *MemoryRepository) Get(ctx context.Context, id shared.BotCommandID) (*domain.Command, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cmd, ok := r.commands[id]
+	if !ok {
+		return nil, domain.ErrCommandNotFound
+	}
+	return &cmd, nil
+}
+
+// Save stores a copy of command, replacing any earlier version.
+func (r *MemoryRepository) Save(ctx context.Context, command *domain.Command) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.commands[command.ID] = *command
+	r.byKey[command.IdempotencyKey] = command.ID
+	return nil
+}
+
+// MarkProcessed sets the state of a stored command.
+func (r *MemoryRepository) MarkProcessed(ctx context.Context, id shared.BotCommandID, state domain.CommandState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cmd, ok := r.commands[id]
+	if !ok {
+		return domain.ErrCommandNotFound
+	}
+	cmd.State = state
+	r.commands[id] = cmd
+	return nil
+}
+
+// ListDueForRetry returns up to limit retryable commands due at now, soonest
+// first with ties broken by ID.
+func (r *MemoryRepository) ListDueForRetry(ctx context.Context, now time.Time, limit int) ([]*domain.Command, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	due := make([]*domain.Command, 0)
+	for _, cmd := range r.commands {
+		if cmd.CanRetry() && !cmd.NextAttemptAt.After(now) {
+			due = append(due, &cmd)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if limit >= 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
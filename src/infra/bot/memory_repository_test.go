@@ -0,0 +1,54 @@
+package bot_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	domain "github.com/heroiclabs/nakama/v3/src/domain/bot"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraBot "github.com/heroiclabs/nakama/v3/src/infra/bot"
+)
+
+func TestMemoryRepository_ListDueForRetry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := infraBot.NewMemoryRepository()
+
+	// command-0 and command-1 are due, command-2 is not yet, command-3 completed.
+	for i, delay := range []time.Duration{-time.Second, 0, time.Minute, 0} {
+		id := shared.BotCommandID(fmt.Sprintf("command-%d", i))
+		cmd, err := domain.NewCommand(id, "discord", nil, shared.IdempotencyKey(fmt.Sprintf("key-%d", i)), now)
+		if err != nil {
+			t.Fatalf("NewCommand() error = %v", err)
+		}
+		cmd.MarkAttempt(now, errors.New("discord timeout"))
+		cmd.NextAttemptAt = now.Add(delay)
+		if i == 3 {
+			cmd.MarkAttempt(now, nil)
+		}
+		if err := repo.Save(ctx, cmd); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	due, err := repo.ListDueForRetry(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("ListDueForRetry() error = %v", err)
+	}
+	if len(due) != 2 || due[0].ID != "command-0" || due[1].ID != "command-1" {
+		t.Errorf("ListDueForRetry() = %v, want command-0 and command-1", due)
+	}
+	if due, _ = repo.ListDueForRetry(ctx, now, 1); len(due) != 1 || due[0].ID != "command-0" {
+		t.Errorf("ListDueForRetry() with limit 1 = %v, want command-0", due)
+	}
+
+	if cmd, err := repo.ReserveCommand(ctx, "key-2"); err != nil || cmd.ID != "command-2" {
+		t.Errorf("ReserveCommand() = %v, %v, want command-2", cmd, err)
+	}
+	if _, err := repo.ReserveCommand(ctx, "key-9"); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("ReserveCommand() unknown key error = %v, want %v", err, shared.ErrNotFound)
+	}
+}
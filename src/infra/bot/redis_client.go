@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisTimeout bounds dialing and each command when no timeout is
+// configured.
+const DefaultRedisTimeout = 5 * time.Second
+
+// RedisConfig configures the client created by NewGoRedisClient.
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	Timeout  time.Duration
+	// TLS connects to Redis over TLS.
+	TLS bool
+}
+
+// GoRedisClient implements RedisClient with a go-redis client, which pools
+// connections and reconnects on its own.
+type GoRedisClient struct {
+	Client redis.UniversalClient
+}
+
+// NewGoRedisClient creates a client for cfg. No connection is made until the
+// first command.
+func NewGoRedisClient(cfg RedisConfig) *GoRedisClient {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultRedisTimeout
+	}
+	opts := &redis.Options{
+		Addr:         cfg.Addr,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		DialTimeout:  cfg.Timeout,
+		ReadTimeout:  cfg.Timeout,
+		WriteTimeout: cfg.Timeout,
+	}
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return &GoRedisClient{Client: redis.NewClient(opts)}
+}
+
+// RPush appends value to the tail of the list at key.
+func (c *GoRedisClient) RPush(ctx context.Context, key string, value []byte) error {
+	return c.Client.RPush(ctx, key, value).Err()
+}
+
+// Close closes the client's connection pool.
+func (c *GoRedisClient) Close() error {
+	return c.Client.Close()
+}
@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	domain "github.com/heroiclabs/nakama/v3/src/domain/bot"
+)
+
+// DefaultRedisQueueKey is the list RedisQueueProducer pushes commands to.
+const DefaultRedisQueueKey = "sandai:bot:commands"
+
+// RedisClient is the subset of a Redis client RedisQueueProducer needs.
+type RedisClient interface {
+	// RPush appends value to the tail of the list at key.
+	RPush(ctx context.Context, key string, value []byte) error
+}
+
+// RedisQueueProducer implements bot.QueueProducer by pushing each command, as
+// JSON, onto a Redis list. Workers pop from the head of the list.
+type RedisQueueProducer struct {
+	Client RedisClient
+	Key    string
+}
+
+// NewRedisQueueProducer creates a producer that pushes to key, or to
+// DefaultRedisQueueKey when key is empty.
+func NewRedisQueueProducer(client RedisClient, key string) *RedisQueueProducer {
+	if key == "" {
+		key = DefaultRedisQueueKey
+	}
+	return &RedisQueueProducer{Client: client, Key: key}
+}
+
+// queuedCommand is the wire format of a command on the queue. Payload is
+// base64-encoded by encoding/json.
+type queuedCommand struct {
+	CommandID      string    `json:"command_id"`
+	Channel        string    `json:"channel"`
+	PlayerID       string    `json:"player_id,omitempty"`
+	Payload        []byte    `json:"payload"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	RetryCount     int       `json:"retry_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Enqueue pushes the serialized command onto the queue.
+func (p *RedisQueueProducer) Enqueue(ctx context.Context, command *domain.Command) error {
+	body, err := json.Marshal(queuedCommand{
+		CommandID:      string(command.ID),
+		Channel:        command.Channel,
+		PlayerID:       string(command.PlayerID),
+		Payload:        command.Payload,
+		IdempotencyKey: string(command.IdempotencyKey),
+		RetryCount:     command.RetryCount,
+		CreatedAt:      command.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if err := p.Client.RPush(ctx, p.Key, body); err != nil {
+		return fmt.Errorf("enqueue bot command %s: %w", command.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package bot_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	domain "github.com/heroiclabs/nakama/v3/src/domain/bot"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraBot "github.com/heroiclabs/nakama/v3/src/infra/bot"
+)
+
+// fakeRedisClient keeps pushed lists in memory.
+type fakeRedisClient struct {
+	lists map[string][]string
+	err   error
+}
+
+func (c *fakeRedisClient) RPush(ctx context.Context, key string, value []byte) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.lists[key] = append(c.lists[key], string(value))
+	return nil
+}
+
+func TestRedisQueueProducer_Enqueue(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeRedisClient{lists: make(map[string][]string)}
+	producer := infraBot.NewRedisQueueProducer(client, "")
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= 2; i++ {
+		cmd, err := domain.NewCommand(shared.BotCommandID(fmt.Sprintf("command-%d", i)), "discord", []byte(`{"op":"balance"}`), shared.IdempotencyKey(fmt.Sprintf("key-%d", i)), now)
+		if err != nil {
+			t.Fatalf("NewCommand() error = %v", err)
+		}
+		cmd.PlayerID = "player-1"
+		if err := producer.Enqueue(ctx, cmd); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	list := client.lists[infraBot.DefaultRedisQueueKey]
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 queued commands, got %d", len(list))
+	}
+	var queued struct {
+		CommandID      string    `json:"command_id"`
+		PlayerID       string    `json:"player_id"`
+		Payload        []byte    `json:"payload"`
+		IdempotencyKey string    `json:"idempotency_key"`
+		CreatedAt      time.Time `json:"created_at"`
+	}
+	if err := json.Unmarshal([]byte(list[1]), &queued); err != nil {
+		t.Fatalf("decoding queued command: %v", err)
+	}
+	if queued.CommandID != "command-2" || queued.PlayerID != "player-1" || queued.IdempotencyKey != "key-2" ||
+		string(queued.Payload) != `{"op":"balance"}` || !queued.CreatedAt.Equal(now) {
+		t.Errorf("queued command = %+v, want command-2 with its key and payload", queued)
+	}
+}
+
+func TestRedisQueueProducer_EnqueueError(t *testing.T) {
+	pushErr := errors.New("connection refused")
+	producer := infraBot.NewRedisQueueProducer(&fakeRedisClient{err: pushErr}, "bot:queue")
+
+	cmd, err := domain.NewCommand("command-1", "discord", []byte(`{}`), "key-1", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("NewCommand() error = %v", err)
+	}
+	if err := producer.Enqueue(context.Background(), cmd); !errors.Is(err, pushErr) {
+		t.Errorf("Enqueue() error = %v, want %v", err, pushErr)
+	}
+}
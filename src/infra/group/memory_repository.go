@@ -0,0 +1,89 @@
+package group
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/group"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// MemoryRepository implements group.Repository using in-memory storage.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	groups map[shared.GroupID]*group.Group
+}
+
+// NewMemoryRepository creates a new in-memory group repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		groups: make(map[shared.GroupID]*group.Group),
+	}
+}
+
+// Get returns a copy of the group with the given ID.
+func (r *MemoryRepository) Get(ctx context.Context, id shared.GroupID) (*group.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	g, ok := r.groups[id]
+	if !ok {
+		return nil, shared.ErrNotFound
+	}
+	return copyGroup(g), nil
+}
+
+// Save stores a copy of the group using optimistic locking. The group's
+// Version must match the stored version; on success it is incremented.
+func (r *MemoryRepository) Save(ctx context.Context, g *group.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stored int64
+	if existing, ok := r.groups[g.ID]; ok {
+		stored = existing.Version
+	}
+	if g.Version != stored {
+		return group.ErrConcurrentModification
+	}
+	g.Version++
+	r.groups[g.ID] = copyGroup(g)
+	return nil
+}
+
+// AddMember records member in a stored group and increments its Version.
+func (r *MemoryRepository) AddMember(ctx context.Context, groupID shared.GroupID, member group.Membership) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g, ok := r.groups[groupID]
+	if !ok {
+		return shared.ErrNotFound
+	}
+	g.Members[member.PlayerID] = member
+	g.Version++
+	return nil
+}
+
+// List returns a copy of every stored group, ordered by ID.
+func (r *MemoryRepository) List(ctx context.Context) ([]*group.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	groups := make([]*group.Group, 0, len(r.groups))
+	for _, g := range r.groups {
+		groups = append(groups, copyGroup(g))
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].ID < groups[j].ID
+	})
+	return groups, nil
+}
+
+func copyGroup(g *group.Group) *group.Group {
+	copied := *g
+	copied.Members = maps.Clone(g.Members)
+	return &copied
+}
@@ -0,0 +1,63 @@
+package group_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/group"
+	infraGroup "github.com/heroiclabs/nakama/v3/src/infra/group"
+)
+
+func TestMemoryRepository_SaveOptimisticLocking(t *testing.T) {
+	ctx := context.Background()
+	repo := infraGroup.NewMemoryRepository()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	g, err := group.NewGroup("group-1", "Night Owls", "owner-1", 2, now, nil)
+	if err != nil {
+		t.Fatalf("NewGroup() error = %v", err)
+	}
+	if err := repo.Save(ctx, g); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if g.Version != 1 {
+		t.Fatalf("Version = %d after create, want 1", g.Version)
+	}
+
+	// Two readers each take the last free place; only the first save wins.
+	first, _ := repo.Get(ctx, "group-1")
+	second, _ := repo.Get(ctx, "group-1")
+	if _, err := first.AddMember("player-1", now); err != nil {
+		t.Fatalf("AddMember() error = %v", err)
+	}
+	if err := repo.Save(ctx, first); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := second.AddMember("player-2", now); err != nil {
+		t.Fatalf("AddMember() error = %v", err)
+	}
+	if err := repo.Save(ctx, second); !errors.Is(err, group.ErrConcurrentModification) {
+		t.Fatalf("Save() of stale group error = %v, want ErrConcurrentModification", err)
+	}
+
+	again, _ := group.NewGroup("group-1", "Night Owls", "owner-2", 0, now, nil)
+	if err := repo.Save(ctx, again); !errors.Is(err, group.ErrConcurrentModification) {
+		t.Errorf("Save() of a second new group error = %v, want ErrConcurrentModification", err)
+	}
+
+	stored, err := repo.Get(ctx, "group-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, ok := stored.Members["player-2"]; stored.Version != 2 || len(stored.Members) != 2 || ok {
+		t.Errorf("stored group = %+v, want version 2 with the owner and player-1", stored)
+	}
+
+	// Changing a returned group must not change the stored one.
+	delete(stored.Members, "player-1")
+	if reread, _ := repo.Get(ctx, "group-1"); len(reread.Members) != 2 {
+		t.Errorf("Expected the stored members to be unaffected, got %v", reread.Members)
+	}
+}
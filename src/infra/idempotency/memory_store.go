@@ -0,0 +1,141 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// DefaultCapacity is the number of records a MemoryStore holds by default.
+const DefaultCapacity = 100000
+
+// MemoryStore implements IdempotencyStore using in-memory storage. Expired
+// records are treated as absent. It holds at most Capacity records: when full,
+// Reserve sweeps expired records and then evicts those closest to expiry.
+type MemoryStore struct {
+	mu       sync.Mutex
+	records  map[storeKey]shared.IdempotencyRecord
+	Clock    func() time.Time
+	Capacity int
+}
+
+type storeKey struct {
+	scope string
+	key   shared.IdempotencyKey
+}
+
+// NewMemoryStore creates a new in-memory idempotency store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records:  make(map[storeKey]shared.IdempotencyRecord),
+		Clock:    func() time.Time { return time.Now().UTC() },
+		Capacity: DefaultCapacity,
+	}
+}
+
+// Reserve claims a key for ttl, reporting ErrDuplicate if it is already held.
+func (s *MemoryStore) Reserve(ctx context.Context, scope string, key shared.IdempotencyKey, ttl time.Duration) (*shared.IdempotencyRecord, error) {
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Clock()
+	k := storeKey{scope: scope, key: key}
+	if existing, ok := s.live(k, now); ok {
+		return &existing, shared.ErrDuplicate
+	}
+	s.makeRoom(now)
+
+	record := shared.IdempotencyRecord{
+		Scope:     scope,
+		Key:       key,
+		State:     shared.IdempotencyStatePending,
+		ExpiresAt: now.Add(ttl),
+	}
+	s.records[k] = record
+	return &record, nil
+}
+
+// Complete marks a reserved key as finished and stores its result.
+func (s *MemoryStore) Complete(ctx context.Context, scope string, key shared.IdempotencyKey, result []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := storeKey{scope: scope, key: key}
+	record, ok := s.live(k, s.Clock())
+	if !ok {
+		return shared.ErrNotFound
+	}
+	record.State = shared.IdempotencyStateCompleted
+	record.Result = result
+	s.records[k] = record
+	return nil
+}
+
+// Release drops a reservation.
+func (s *MemoryStore) Release(ctx context.Context, scope string, key shared.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, storeKey{scope: scope, key: key})
+	return nil
+}
+
+// Lookup returns the live record for a key.
+func (s *MemoryStore) Lookup(ctx context.Context, scope string, key shared.IdempotencyKey) (*shared.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.live(storeKey{scope: scope, key: key}, s.Clock())
+	if !ok {
+		return nil, shared.ErrNotFound
+	}
+	return &record, nil
+}
+
+// live returns the record for k if it has not expired, evicting it otherwise.
+func (s *MemoryStore) live(k storeKey, now time.Time) (shared.IdempotencyRecord, bool) {
+	record, ok := s.records[k]
+	if !ok {
+		return shared.IdempotencyRecord{}, false
+	}
+	if !now.Before(record.ExpiresAt) {
+		delete(s.records, k)
+		return shared.IdempotencyRecord{}, false
+	}
+	return record, true
+}
+
+// makeRoom frees a slot for a new record when the store is at Capacity.
+func (s *MemoryStore) makeRoom(now time.Time) {
+	if s.Capacity <= 0 || len(s.records) < s.Capacity {
+		return
+	}
+	for k, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, k)
+		}
+	}
+	for len(s.records) >= s.Capacity {
+		var oldest storeKey
+		var oldestExpiry time.Time
+		for k, record := range s.records {
+			if oldestExpiry.IsZero() || record.ExpiresAt.Before(oldestExpiry) {
+				oldest, oldestExpiry = k, record.ExpiresAt
+			}
+		}
+		delete(s.records, oldest)
+	}
+}
+
+// Len returns the number of records currently held, including expired ones
+// not yet evicted.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
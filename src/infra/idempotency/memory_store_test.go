@@ -0,0 +1,126 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/infra/idempotency"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+)
+
+func newTestStore() (*idempotency.MemoryStore, *testsupport.FakeClock) {
+	clock := testsupport.NewFakeClock()
+	store := idempotency.NewMemoryStore()
+	store.Clock = clock.Now
+	return store, clock
+}
+
+func TestMemoryStore_ReserveComplete(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore()
+
+	record, err := store.Reserve(ctx, "bot", "key-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if record.State != shared.IdempotencyStatePending {
+		t.Errorf("Expected pending record, got %s", record.State)
+	}
+
+	existing, err := store.Reserve(ctx, "bot", "key-1", time.Hour)
+	if !errors.Is(err, shared.ErrDuplicate) {
+		t.Fatalf("Reserve() duplicate error = %v, want %v", err, shared.ErrDuplicate)
+	}
+	if existing.Completed() {
+		t.Error("Expected in-flight duplicate to report pending")
+	}
+
+	if err := store.Complete(ctx, "bot", "key-1", []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	existing, err = store.Reserve(ctx, "bot", "key-1", time.Hour)
+	if !errors.Is(err, shared.ErrDuplicate) {
+		t.Fatalf("Reserve() after complete error = %v, want %v", err, shared.ErrDuplicate)
+	}
+	if !existing.Completed() || string(existing.Result) != `{"ok":true}` {
+		t.Errorf("Expected completed record with result, got %+v", existing)
+	}
+}
+
+func TestMemoryStore_ScopesAreIndependent(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore()
+
+	if _, err := store.Reserve(ctx, "bot", "key-1", time.Hour); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if _, err := store.Reserve(ctx, "battle", "key-1", time.Hour); err != nil {
+		t.Errorf("Reserve() in another scope error = %v", err)
+	}
+}
+
+func TestMemoryStore_TTLAndRelease(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestStore()
+
+	if _, err := store.Reserve(ctx, "bot", "key-1", time.Minute); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	clock.Advance(time.Minute)
+	if _, err := store.Lookup(ctx, "bot", "key-1"); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("Lookup() after expiry error = %v, want %v", err, shared.ErrNotFound)
+	}
+	if _, err := store.Reserve(ctx, "bot", "key-1", time.Minute); err != nil {
+		t.Fatalf("Reserve() after expiry error = %v", err)
+	}
+
+	if err := store.Release(ctx, "bot", "key-1"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := store.Reserve(ctx, "bot", "key-1", time.Minute); err != nil {
+		t.Errorf("Reserve() after release error = %v", err)
+	}
+	if err := store.Complete(ctx, "bot", "missing", nil); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("Complete() unknown key error = %v, want %v", err, shared.ErrNotFound)
+	}
+}
+
+func TestMemoryStore_Capacity(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestStore()
+	store.Capacity = 2
+
+	if _, err := store.Reserve(ctx, "bot", "short", time.Minute); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if _, err := store.Reserve(ctx, "bot", "long", time.Hour); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+
+	// A full store evicts the record closest to expiry.
+	if _, err := store.Reserve(ctx, "bot", "next", time.Hour); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if got := store.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+	if _, err := store.Lookup(ctx, "bot", "short"); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("Lookup(short) error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Lookup(ctx, "bot", "long"); err != nil {
+		t.Errorf("Lookup(long) error = %v", err)
+	}
+
+	// Expired records are swept before any live one is evicted.
+	clock.Advance(2 * time.Hour)
+	if _, err := store.Reserve(ctx, "bot", "after", time.Hour); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if got := store.Len(); got != 1 {
+		t.Errorf("Len() after sweep = %d, want 1", got)
+	}
+}
@@ -0,0 +1,59 @@
+package leaderboard
+
+import (
+	"context"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/leaderboard"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// MemoryPendingRepository implements PendingRepository using in-memory storage.
+type MemoryPendingRepository struct {
+	mu      sync.Mutex
+	pending map[shared.IdempotencyKey]leaderboard.ScoreSubmission
+}
+
+// NewMemoryPendingRepository creates a new in-memory pending submission repository.
+func NewMemoryPendingRepository() *MemoryPendingRepository {
+	return &MemoryPendingRepository{
+		pending: make(map[shared.IdempotencyKey]leaderboard.ScoreSubmission),
+	}
+}
+
+// Reserve stores a pending submission keyed by its idempotency key.
+func (r *MemoryPendingRepository) Reserve(ctx context.Context, submission leaderboard.ScoreSubmission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.pending[submission.IdempotencyKey]; exists {
+		return leaderboard.ErrSubmissionAlreadyReserved
+	}
+	r.pending[submission.IdempotencyKey] = submission
+	return nil
+}
+
+// Take removes and returns a pending submission.
+func (r *MemoryPendingRepository) Take(ctx context.Context, key shared.IdempotencyKey) (leaderboard.ScoreSubmission, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	submission, exists := r.pending[key]
+	if !exists {
+		return leaderboard.ScoreSubmission{}, leaderboard.ErrSubmissionNotFound
+	}
+	delete(r.pending, key)
+	return submission, nil
+}
+
+// Delete removes a pending submission.
+func (r *MemoryPendingRepository) Delete(ctx context.Context, key shared.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.pending[key]; !exists {
+		return leaderboard.ErrSubmissionNotFound
+	}
+	delete(r.pending, key)
+	return nil
+}
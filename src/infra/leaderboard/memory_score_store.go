@@ -0,0 +1,122 @@
+package leaderboard
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/leaderboard"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+type scoreKey struct {
+	season   shared.SeasonID
+	playerID shared.PlayerID
+}
+
+// DefaultExpiredCapacity is the number of expired scores a MemoryScoreStore
+// keeps for all-time listings.
+const DefaultExpiredCapacity = 10000
+
+// MemoryScoreStore implements ScoreStore using in-memory storage. It keeps at
+// most capacity expired scores and drops the oldest first, so all-time
+// listings only cover the most recent closed windows.
+type MemoryScoreStore struct {
+	mu       sync.RWMutex
+	capacity int
+	scores   map[scoreKey]leaderboard.PlayerScore
+	// expired holds scores from closed windows, oldest window first.
+	expired []leaderboard.PlayerScore
+}
+
+// NewMemoryScoreStore creates an in-memory score store keeping up to capacity
+// expired scores.
+func NewMemoryScoreStore(capacity int) *MemoryScoreStore {
+	if capacity <= 0 {
+		capacity = DefaultExpiredCapacity
+	}
+	return &MemoryScoreStore{
+		capacity: capacity,
+		scores:   make(map[scoreKey]leaderboard.PlayerScore),
+	}
+}
+
+// GetScore retrieves a player's score for a season.
+func (s *MemoryScoreStore) GetScore(ctx context.Context, season shared.SeasonID, playerID shared.PlayerID) (leaderboard.PlayerScore, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	score, ok := s.scores[scoreKey{season: season, playerID: playerID}]
+	if !ok {
+		return leaderboard.PlayerScore{}, leaderboard.ErrScoreNotFound
+	}
+	return score, nil
+}
+
+// CompareAndSetScore stores the score if the stored version matches
+// expectedVersion, incrementing the version on success.
+func (s *MemoryScoreStore) CompareAndSetScore(ctx context.Context, score leaderboard.PlayerScore, expectedVersion int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := scoreKey{season: score.SeasonID, playerID: score.PlayerID}
+	if s.scores[key].Version != expectedVersion {
+		return leaderboard.ErrScoreConflict
+	}
+	score.Version = expectedVersion + 1
+	s.scores[key] = score
+	return nil
+}
+
+// ExpireScores moves a season's current scores into the expired set, dropping
+// the oldest expired scores beyond capacity.
+func (s *MemoryScoreStore) ExpireScores(ctx context.Context, season shared.SeasonID, boundary time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for key, score := range s.scores {
+		if key.season != season {
+			continue
+		}
+		score.ExpiresAt = boundary
+		s.expired = append(s.expired, score)
+		delete(s.scores, key)
+		n++
+	}
+	if excess := len(s.expired) - s.capacity; excess > 0 {
+		s.expired = append([]leaderboard.PlayerScore(nil), s.expired[excess:]...)
+	}
+	return n, nil
+}
+
+// ListScores returns a season's scores in window, highest first. Ties go to
+// the score written first.
+func (s *MemoryScoreStore) ListScores(ctx context.Context, season shared.SeasonID, window leaderboard.Window, limit int) ([]leaderboard.PlayerScore, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scores := []leaderboard.PlayerScore{}
+	for key, score := range s.scores {
+		if key.season == season {
+			scores = append(scores, score)
+		}
+	}
+	for _, score := range s.expired {
+		if score.SeasonID == season && window.Includes(score) {
+			scores = append(scores, score)
+		}
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Value != scores[j].Value {
+			return scores[i].Value > scores[j].Value
+		}
+		return scores[i].UpdatedAt.Before(scores[j].UpdatedAt)
+	})
+	if limit > 0 && len(scores) > limit {
+		scores = scores[:limit]
+	}
+	return scores, nil
+}
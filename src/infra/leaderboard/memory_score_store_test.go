@@ -0,0 +1,36 @@
+package leaderboard_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/leaderboard"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraLeaderboard "github.com/heroiclabs/nakama/v3/src/infra/leaderboard"
+)
+
+func TestMemoryScoreStore_ExpiredCapacity(t *testing.T) {
+	ctx := context.Background()
+	store := infraLeaderboard.NewMemoryScoreStore(2)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Three windows close with one score each; only the last two are kept.
+	for i, player := range []shared.PlayerID{"player-1", "player-2", "player-3"} {
+		written := start.Add(time.Duration(i) * time.Hour)
+		if err := store.CompareAndSetScore(ctx, leaderboard.PlayerScore{SeasonID: "weekly", PlayerID: player, Value: int64(i + 1), UpdatedAt: written}, 0); err != nil {
+			t.Fatalf("CompareAndSetScore() error = %v", err)
+		}
+		if _, err := store.ExpireScores(ctx, "weekly", written.Add(time.Minute)); err != nil {
+			t.Fatalf("ExpireScores() error = %v", err)
+		}
+	}
+
+	scores, err := store.ListScores(ctx, "weekly", leaderboard.WindowAllTime, 0)
+	if err != nil {
+		t.Fatalf("ListScores() error = %v", err)
+	}
+	if len(scores) != 2 || scores[0].PlayerID != "player-3" || scores[1].PlayerID != "player-2" {
+		t.Errorf("all-time scores = %+v, want player-3 and player-2", scores)
+	}
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/heroiclabs/nakama/v3/src/app/auth"
+)
+
+// AuthMetrics counts login attempts and their outcomes by strategy. It
+// implements auth.LoginRecorder.
+type AuthMetrics struct {
+	attempts  *prometheus.CounterVec
+	successes *prometheus.CounterVec
+	failures  *prometheus.CounterVec
+}
+
+// NewAuthMetrics creates the login collectors and registers them with reg.
+func NewAuthMetrics(reg prometheus.Registerer) *AuthMetrics {
+	m := &AuthMetrics{
+		attempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sandai",
+			Subsystem: "auth",
+			Name:      "login_attempts_total",
+			Help:      "Total login attempts by strategy",
+		}, []string{"strategy"}),
+		successes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sandai",
+			Subsystem: "auth",
+			Name:      "login_successes_total",
+			Help:      "Total successful logins by strategy",
+		}, []string{"strategy"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sandai",
+			Subsystem: "auth",
+			Name:      "login_failures_total",
+			Help:      "Total failed logins by strategy and reason",
+		}, []string{"strategy", "reason"}),
+	}
+	reg.MustRegister(m.attempts, m.successes, m.failures)
+	return m
+}
+
+// RecordLogin implements auth.LoginRecorder.
+func (m *AuthMetrics) RecordLogin(strategy string, err error) {
+	m.attempts.WithLabelValues(strategy).Inc()
+	if err == nil {
+		m.successes.WithLabelValues(strategy).Inc()
+		return
+	}
+	m.failures.WithLabelValues(strategy, auth.FailureReason(err)).Inc()
+}
@@ -0,0 +1,89 @@
+package metrics_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/heroiclabs/nakama/v3/src/app/auth"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/infra/metrics"
+)
+
+// counterValue returns the value of the counter in family name whose labels
+// match labels exactly, or zero if it has not been created.
+func counterValue(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			if len(metric.GetLabel()) != len(labels) {
+				continue
+			}
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestAuthMetrics_RecordLogin(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategy   string
+		err        error
+		wantReason string
+	}{
+		{name: "success", strategy: auth.StrategyDevice},
+		{name: "invalid credentials", strategy: auth.StrategyEmail, err: fmt.Errorf("nakama: %w", player.ErrBadCredentials), wantReason: auth.ReasonInvalidCredentials},
+		{name: "suspended", strategy: auth.StrategyEmail, err: &player.SuspendedError{Message: "cheating"}, wantReason: auth.ReasonSuspended},
+		{name: "banned", strategy: auth.StrategyCustom, err: player.ErrAccountBanned, wantReason: auth.ReasonBanned},
+		{name: "invalid request", strategy: auth.StrategyCustom, err: shared.NewValidationError("token", "custom token is required"), wantReason: auth.ReasonInvalidRequest},
+		{name: "other error", strategy: auth.StrategyDevice, err: errors.New("connection refused"), wantReason: auth.ReasonError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			m := metrics.NewAuthMetrics(reg)
+
+			m.RecordLogin(tt.strategy, tt.err)
+			m.RecordLogin(tt.strategy, tt.err)
+
+			strategy := map[string]string{"strategy": tt.strategy}
+			if got := counterValue(t, reg, "sandai_auth_login_attempts_total", strategy); got != 2 {
+				t.Errorf("attempts = %v, want 2", got)
+			}
+			wantSuccesses := 0.0
+			if tt.err == nil {
+				wantSuccesses = 2
+			}
+			if got := counterValue(t, reg, "sandai_auth_login_successes_total", strategy); got != wantSuccesses {
+				t.Errorf("successes = %v, want %v", got, wantSuccesses)
+			}
+			for _, reason := range []string{auth.ReasonInvalidCredentials, auth.ReasonSuspended, auth.ReasonBanned, auth.ReasonInvalidRequest, auth.ReasonError} {
+				want := 0.0
+				if reason == tt.wantReason {
+					want = 2
+				}
+				labels := map[string]string{"strategy": tt.strategy, "reason": reason}
+				if got := counterValue(t, reg, "sandai_auth_login_failures_total", labels); got != want {
+					t.Errorf("failures{reason=%q} = %v, want %v", reason, got, want)
+				}
+			}
+		})
+	}
+}
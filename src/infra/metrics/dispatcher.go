@@ -0,0 +1,41 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// BufferStats is implemented by dispatchers that queue work in a bounded buffer.
+type BufferStats interface {
+	Depth() int
+	Capacity() int
+	Dropped() int64
+}
+
+// RegisterDispatcherBuffer exposes the depth, capacity, and dropped-event count
+// of an async dispatcher buffer. The values are read from stats on every scrape.
+func RegisterDispatcherBuffer(reg prometheus.Registerer, stats BufferStats) error {
+	collectors := []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "sandai",
+			Subsystem: "analytics_buffer",
+			Name:      "depth",
+			Help:      "Batches currently waiting in the async analytics buffer",
+		}, func() float64 { return float64(stats.Depth()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "sandai",
+			Subsystem: "analytics_buffer",
+			Name:      "capacity",
+			Help:      "Maximum number of batches the async analytics buffer holds",
+		}, func() float64 { return float64(stats.Capacity()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: "sandai",
+			Subsystem: "analytics_buffer",
+			Name:      "dropped_events_total",
+			Help:      "Events dropped because the async analytics buffer was full",
+		}, func() float64 { return float64(stats.Dropped()) }),
+	}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package metrics_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/heroiclabs/nakama/v3/src/infra/metrics"
+)
+
+type fakeBufferStats struct {
+	depth, capacity int
+	dropped         int64
+}
+
+func (f *fakeBufferStats) Depth() int     { return f.depth }
+func (f *fakeBufferStats) Capacity() int  { return f.capacity }
+func (f *fakeBufferStats) Dropped() int64 { return f.dropped }
+
+func gatherValues(t *testing.T, reg *prometheus.Registry) map[string]float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			switch {
+			case metric.GetGauge() != nil:
+				values[family.GetName()] = metric.GetGauge().GetValue()
+			case metric.GetCounter() != nil:
+				values[family.GetName()] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return values
+}
+
+func TestRegisterDispatcherBuffer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	stats := &fakeBufferStats{capacity: 256}
+	if err := metrics.RegisterDispatcherBuffer(reg, stats); err != nil {
+		t.Fatalf("RegisterDispatcherBuffer() error = %v", err)
+	}
+
+	stats.depth = 12
+	stats.dropped = 3
+	values := gatherValues(t, reg)
+	if got := values["sandai_analytics_buffer_depth"]; got != 12 {
+		t.Errorf("depth = %v, want 12", got)
+	}
+	if got := values["sandai_analytics_buffer_capacity"]; got != 256 {
+		t.Errorf("capacity = %v, want 256", got)
+	}
+	if got := values["sandai_analytics_buffer_dropped_events_total"]; got != 3 {
+		t.Errorf("dropped = %v, want 3", got)
+	}
+
+	stats.depth = 0
+	if got := gatherValues(t, reg)["sandai_analytics_buffer_depth"]; got != 0 {
+		t.Errorf("depth after drain = %v, want 0", got)
+	}
+
+	if err := metrics.RegisterDispatcherBuffer(reg, stats); err == nil {
+		t.Error("Expected duplicate registration to fail")
+	}
+}
@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+)
+
+// RepositoryMetrics records call counts, latency, and errors per repository method.
+type RepositoryMetrics struct {
+	calls   *prometheus.CounterVec
+	errors  *prometheus.CounterVec
+	latency *prometheus.HistogramVec
+}
+
+// NewRepositoryMetrics creates the repository collectors and registers them with reg.
+func NewRepositoryMetrics(reg prometheus.Registerer) *RepositoryMetrics {
+	m := &RepositoryMetrics{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sandai",
+			Subsystem: "repository",
+			Name:      "calls_total",
+			Help:      "Total repository calls by repository and method",
+		}, []string{"repository", "method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sandai",
+			Subsystem: "repository",
+			Name:      "errors_total",
+			Help:      "Total repository calls that returned an error",
+		}, []string{"repository", "method"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "sandai",
+			Subsystem: "repository",
+			Name:      "latency_seconds",
+			Help:      "Repository call latency",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"repository", "method"}),
+	}
+	reg.MustRegister(m.calls, m.errors, m.latency)
+	return m
+}
+
+func (m *RepositoryMetrics) observe(repository, method string, start time.Time, err error) {
+	labels := prometheus.Labels{"repository": repository, "method": method}
+	m.calls.With(labels).Inc()
+	m.latency.With(labels).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.errors.With(labels).Inc()
+	}
+}
+
+func instrument[T any](m *RepositoryMetrics, repository, method string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	result, err := fn()
+	m.observe(repository, method, start, err)
+	return result, err
+}
+
+func instrumentErr(m *RepositoryMetrics, repository, method string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	m.observe(repository, method, start, err)
+	return err
+}
+
+// TournamentRepository instruments a tournament.Repository.
+type TournamentRepository struct {
+	Next    tournament.Repository
+	Metrics *RepositoryMetrics
+}
+
+const tournamentRepo = "tournament"
+
+func (r *TournamentRepository) Save(ctx context.Context, t *tournament.Tournament) error {
+	return instrumentErr(r.Metrics, tournamentRepo, "Save", func() error { return r.Next.Save(ctx, t) })
+}
+
+func (r *TournamentRepository) Get(ctx context.Context, id shared.TournamentID) (*tournament.Tournament, error) {
+	return instrument(r.Metrics, tournamentRepo, "Get", func() (*tournament.Tournament, error) { return r.Next.Get(ctx, id) })
+}
+
+func (r *TournamentRepository) Delete(ctx context.Context, id shared.TournamentID) error {
+	return instrumentErr(r.Metrics, tournamentRepo, "Delete", func() error { return r.Next.Delete(ctx, id) })
+}
+
+func (r *TournamentRepository) List(ctx context.Context, filter tournament.ListFilter, limit, offset int) ([]*tournament.Tournament, error) {
+	return instrument(r.Metrics, tournamentRepo, "List", func() ([]*tournament.Tournament, error) { return r.Next.List(ctx, filter, limit, offset) })
+}
+
+func (r *TournamentRepository) Count(ctx context.Context, filter tournament.ListFilter) (int, error) {
+	return instrument(r.Metrics, tournamentRepo, "Count", func() (int, error) { return r.Next.Count(ctx, filter) })
+}
+
+// ParticipantRepository instruments a tournament.ParticipantRepository.
+type ParticipantRepository struct {
+	Next    tournament.ParticipantRepository
+	Metrics *RepositoryMetrics
+}
+
+const participantRepo = "participant"
+
+func (r *ParticipantRepository) Save(ctx context.Context, p *tournament.Participant) error {
+	return instrumentErr(r.Metrics, participantRepo, "Save", func() error { return r.Next.Save(ctx, p) })
+}
+
+func (r *ParticipantRepository) Create(ctx context.Context, p *tournament.Participant) error {
+	return instrumentErr(r.Metrics, participantRepo, "Create", func() error { return r.Next.Create(ctx, p) })
+}
+
+func (r *ParticipantRepository) Get(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) (*tournament.Participant, error) {
+	return instrument(r.Metrics, participantRepo, "Get", func() (*tournament.Participant, error) { return r.Next.Get(ctx, tournamentID, playerID) })
+}
+
+func (r *ParticipantRepository) ListByTournament(ctx context.Context, tournamentID shared.TournamentID) ([]*tournament.Participant, error) {
+	return instrument(r.Metrics, participantRepo, "ListByTournament", func() ([]*tournament.Participant, error) {
+		return r.Next.ListByTournament(ctx, tournamentID)
+	})
+}
+
+func (r *ParticipantRepository) ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*tournament.Participant, error) {
+	return instrument(r.Metrics, participantRepo, "ListByPlayer", func() ([]*tournament.Participant, error) {
+		return r.Next.ListByPlayer(ctx, playerID, limit, offset)
+	})
+}
+
+func (r *ParticipantRepository) Delete(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error {
+	return instrumentErr(r.Metrics, participantRepo, "Delete", func() error { return r.Next.Delete(ctx, tournamentID, playerID) })
+}
+
+func (r *ParticipantRepository) DeleteByTournament(ctx context.Context, tournamentID shared.TournamentID) error {
+	return instrumentErr(r.Metrics, participantRepo, "DeleteByTournament", func() error { return r.Next.DeleteByTournament(ctx, tournamentID) })
+}
+
+// SessionRepository instruments an analytics.SessionRepository.
+type SessionRepository struct {
+	Next    analytics.SessionRepository
+	Metrics *RepositoryMetrics
+}
+
+const sessionRepo = "session"
+
+func (r *SessionRepository) Save(ctx context.Context, session *analytics.Session) error {
+	return instrumentErr(r.Metrics, sessionRepo, "Save", func() error { return r.Next.Save(ctx, session) })
+}
+
+func (r *SessionRepository) Get(ctx context.Context, userID shared.PlayerID) (*analytics.Session, error) {
+	return instrument(r.Metrics, sessionRepo, "Get", func() (*analytics.Session, error) { return r.Next.Get(ctx, userID) })
+}
+
+func (r *SessionRepository) Delete(ctx context.Context, userID shared.PlayerID) error {
+	return instrumentErr(r.Metrics, sessionRepo, "Delete", func() error { return r.Next.Delete(ctx, userID) })
+}
+
+// BattleRepository instruments a battle.Repository.
+type BattleRepository struct {
+	Next    battle.Repository
+	Metrics *RepositoryMetrics
+}
+
+const battleRepo = "battle"
+
+func (r *BattleRepository) Get(ctx context.Context, id shared.BattleID) (*battle.Battle, error) {
+	return instrument(r.Metrics, battleRepo, "Get", func() (*battle.Battle, error) { return r.Next.Get(ctx, id) })
+}
+
+func (r *BattleRepository) GetByIdempotencyKey(ctx context.Context, key shared.IdempotencyKey) (*battle.Battle, error) {
+	return instrument(r.Metrics, battleRepo, "GetByIdempotencyKey", func() (*battle.Battle, error) {
+		return r.Next.GetByIdempotencyKey(ctx, key)
+	})
+}
+
+func (r *BattleRepository) Save(ctx context.Context, b *battle.Battle) error {
+	return instrumentErr(r.Metrics, battleRepo, "Save", func() error { return r.Next.Save(ctx, b) })
+}
+
+func (r *BattleRepository) StoreSnapshot(ctx context.Context, id shared.BattleID, state battle.MatchState) error {
+	return instrumentErr(r.Metrics, battleRepo, "StoreSnapshot", func() error { return r.Next.StoreSnapshot(ctx, id, state) })
+}
+
+func (r *BattleRepository) ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*battle.Battle, error) {
+	return instrument(r.Metrics, battleRepo, "ListByPlayer", func() ([]*battle.Battle, error) {
+		return r.Next.ListByPlayer(ctx, playerID, limit, offset)
+	})
+}
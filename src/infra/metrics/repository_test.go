@@ -0,0 +1,105 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+	infraAnalytics "github.com/heroiclabs/nakama/v3/src/infra/analytics"
+	"github.com/heroiclabs/nakama/v3/src/infra/metrics"
+	infraTournament "github.com/heroiclabs/nakama/v3/src/infra/tournament"
+)
+
+// metricValue returns the counter value or histogram sample count for the given labels.
+func metricValue(t *testing.T, reg *prometheus.Registry, name, repository, method string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, pair := range metric.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			if labels["repository"] != repository || labels["method"] != method {
+				continue
+			}
+			if metric.GetCounter() != nil {
+				return metric.GetCounter().GetValue()
+			}
+			if metric.GetHistogram() != nil {
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+func TestTournamentRepository_RecordsCalls(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	repo := &metrics.TournamentRepository{
+		Next:    infraTournament.NewMemoryRepository(),
+		Metrics: metrics.NewRepositoryMetrics(reg),
+	}
+
+	_, err := repo.Get(context.Background(), "missing")
+	if err == nil {
+		t.Fatal("Expected not-found error from wrapped repository")
+	}
+	if _, err := repo.Count(context.Background(), tournament.ListFilter{}); err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+
+	if got := metricValue(t, reg, "sandai_repository_calls_total", "tournament", "Get"); got != 1 {
+		t.Errorf("Expected 1 Get call, got %v", got)
+	}
+	if got := metricValue(t, reg, "sandai_repository_errors_total", "tournament", "Get"); got != 1 {
+		t.Errorf("Expected 1 Get error, got %v", got)
+	}
+	if got := metricValue(t, reg, "sandai_repository_latency_seconds", "tournament", "Get"); got != 1 {
+		t.Errorf("Expected 1 Get latency sample, got %v", got)
+	}
+	if got := metricValue(t, reg, "sandai_repository_errors_total", "tournament", "Count"); got != 0 {
+		t.Errorf("Expected no Count errors, got %v", got)
+	}
+}
+
+func TestSessionRepository_RecordsCalls(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	repo := &metrics.SessionRepository{
+		Next:    infraAnalytics.NewMemorySessionRepository(),
+		Metrics: metrics.NewRepositoryMetrics(reg),
+	}
+
+	ctx := context.Background()
+	session := &analytics.Session{UserID: shared.PlayerID("player-1"), State: analytics.SessionStateActive}
+	if err := repo.Save(ctx, session); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := repo.Get(ctx, "player-1"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := repo.Get(ctx, "player-2"); !errors.Is(err, analytics.ErrSessionNotFound) {
+		t.Fatalf("Expected ErrSessionNotFound, got %v", err)
+	}
+
+	if got := metricValue(t, reg, "sandai_repository_calls_total", "session", "Get"); got != 2 {
+		t.Errorf("Expected 2 Get calls, got %v", got)
+	}
+	if got := metricValue(t, reg, "sandai_repository_errors_total", "session", "Get"); got != 1 {
+		t.Errorf("Expected 1 Get error, got %v", got)
+	}
+	if got := metricValue(t, reg, "sandai_repository_calls_total", "session", "Save"); got != 1 {
+		t.Errorf("Expected 1 Save call, got %v", got)
+	}
+}
@@ -0,0 +1,39 @@
+package player
+
+import (
+	"context"
+
+	"github.com/heroiclabs/nakama/v3/src/app/auth"
+)
+
+// TranslatingAuthProvider wraps the Nakama auth.AuthProvider so login
+// failures reach the auth service as player.ErrAccountBanned or
+// player.ErrBadCredentials, as auth.AuthProvider requires.
+type TranslatingAuthProvider struct {
+	Next auth.AuthProvider
+}
+
+// NewTranslatingAuthProvider wraps next.
+func NewTranslatingAuthProvider(next auth.AuthProvider) *TranslatingAuthProvider {
+	return &TranslatingAuthProvider{Next: next}
+}
+
+func (p *TranslatingAuthProvider) AuthenticateDevice(ctx context.Context, deviceID, username string, vars map[string]string) (auth.AuthResult, error) {
+	result, err := p.Next.AuthenticateDevice(ctx, deviceID, username, vars)
+	return result, translateAuthError(err)
+}
+
+func (p *TranslatingAuthProvider) AuthenticateEmail(ctx context.Context, email, password string, vars map[string]string) (auth.AuthResult, error) {
+	result, err := p.Next.AuthenticateEmail(ctx, email, password, vars)
+	return result, translateAuthError(err)
+}
+
+func (p *TranslatingAuthProvider) AuthenticateCustom(ctx context.Context, token string, vars map[string]string) (auth.AuthResult, error) {
+	result, err := p.Next.AuthenticateCustom(ctx, token, vars)
+	return result, translateAuthError(err)
+}
+
+func (p *TranslatingAuthProvider) SessionRefresh(ctx context.Context, refreshToken string) (auth.AuthResult, error) {
+	result, err := p.Next.SessionRefresh(ctx, refreshToken)
+	return result, translateAuthError(err)
+}
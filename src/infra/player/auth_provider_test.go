@@ -0,0 +1,79 @@
+package player_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/heroiclabs/nakama/v3/src/app/auth"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	infraPlayer "github.com/heroiclabs/nakama/v3/src/infra/player"
+)
+
+// failingAuthProvider fails every call with err.
+type failingAuthProvider struct {
+	err error
+}
+
+func (p failingAuthProvider) AuthenticateDevice(ctx context.Context, deviceID, username string, vars map[string]string) (auth.AuthResult, error) {
+	return auth.AuthResult{}, p.err
+}
+
+func (p failingAuthProvider) AuthenticateEmail(ctx context.Context, email, password string, vars map[string]string) (auth.AuthResult, error) {
+	return auth.AuthResult{}, p.err
+}
+
+func (p failingAuthProvider) AuthenticateCustom(ctx context.Context, token string, vars map[string]string) (auth.AuthResult, error) {
+	return auth.AuthResult{}, p.err
+}
+
+func (p failingAuthProvider) SessionRefresh(ctx context.Context, refreshToken string) (auth.AuthResult, error) {
+	return auth.AuthResult{}, p.err
+}
+
+func TestTranslatingAuthProvider(t *testing.T) {
+	ctx := context.Background()
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantErr    error
+		wantReason string
+	}{
+		{name: "banned", err: status.Error(codes.PermissionDenied, "User account banned."), wantErr: player.ErrAccountBanned, wantReason: auth.ReasonBanned},
+		{name: "wrong password", err: status.Error(codes.Unauthenticated, "Invalid credentials."), wantErr: player.ErrBadCredentials, wantReason: auth.ReasonInvalidCredentials},
+		{name: "unknown account", err: status.Error(codes.NotFound, "User account not found."), wantErr: player.ErrBadCredentials, wantReason: auth.ReasonInvalidCredentials},
+		{name: "runtime error", err: runtime.NewError("User account banned.", int(codes.PermissionDenied)), wantErr: player.ErrAccountBanned, wantReason: auth.ReasonBanned},
+		{name: "outage", err: unavailable, wantErr: unavailable, wantReason: auth.ReasonError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := infraPlayer.NewTranslatingAuthProvider(failingAuthProvider{err: tt.err})
+			calls := map[string]func() error{
+				"AuthenticateDevice": func() error { _, err := provider.AuthenticateDevice(ctx, "device-1", "", nil); return err },
+				"AuthenticateEmail":  func() error { _, err := provider.AuthenticateEmail(ctx, "a@example.com", "secret", nil); return err },
+				"AuthenticateCustom": func() error { _, err := provider.AuthenticateCustom(ctx, "token", nil); return err },
+				"SessionRefresh":     func() error { _, err := provider.SessionRefresh(ctx, "refresh"); return err },
+			}
+			for name, call := range calls {
+				err := call()
+				if !errors.Is(err, tt.wantErr) || !errors.Is(err, tt.err) {
+					t.Errorf("%s() error = %v, want %v wrapping %v", name, err, tt.wantErr, tt.err)
+				}
+				if reason := auth.FailureReason(err); reason != tt.wantReason {
+					t.Errorf("%s() failure reason = %q, want %q", name, reason, tt.wantReason)
+				}
+			}
+		})
+	}
+
+	ok := infraPlayer.NewTranslatingAuthProvider(failingAuthProvider{})
+	if _, err := ok.AuthenticateDevice(ctx, "device-1", "", nil); err != nil {
+		t.Errorf("AuthenticateDevice() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,167 @@
+package player
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+const (
+	// DefaultCacheCapacity is the number of accounts a CachedRepository holds.
+	DefaultCacheCapacity = 10000
+	// DefaultCacheTTL is how long a cached account is served before it is re-read.
+	DefaultCacheTTL = time.Minute
+)
+
+// CachedRepository is a bounded LRU cache in front of a player.Repository.
+// Lookups by ID are served from the cache until the entry expires; any write
+// through the repository evicts the affected account, so suspensions and
+// profile updates are visible on the next read. Callers receive copies, so
+// mutating a returned account does not change the cached one.
+type CachedRepository struct {
+	Next player.Repository
+	// Clock supplies the time used for expiry. Defaults to time.Now.
+	Clock func() time.Time
+
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List
+	entries  map[shared.PlayerID]*list.Element
+	// generation counts invalidations so a read that raced a write does not
+	// cache the account it read before the write landed.
+	generation uint64
+}
+
+type cacheEntry struct {
+	account   *player.PlayerAccount
+	expiresAt time.Time
+}
+
+// NewCachedRepository wraps next with a cache holding up to capacity accounts
+// for ttl each. Non-positive values fall back to the defaults.
+func NewCachedRepository(next player.Repository, capacity int, ttl time.Duration) *CachedRepository {
+	if capacity <= 0 {
+		capacity = DefaultCacheCapacity
+	}
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &CachedRepository{
+		Next:     next,
+		Clock:    time.Now,
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		entries:  make(map[shared.PlayerID]*list.Element, capacity),
+	}
+}
+
+// GetByID returns the cached account when fresh, otherwise reads through.
+func (r *CachedRepository) GetByID(ctx context.Context, id shared.PlayerID) (*player.PlayerAccount, error) {
+	account, generation, ok := r.lookup(id)
+	if ok {
+		return account, nil
+	}
+	account, err := r.Next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.store(account, generation)
+	return cloneAccount(account), nil
+}
+
+// Save writes through and evicts the cached account.
+func (r *CachedRepository) Save(ctx context.Context, account *player.PlayerAccount) error {
+	defer r.Invalidate(account.ID)
+	return r.Next.Save(ctx, account)
+}
+
+// AppendSession writes through and evicts the cached account.
+func (r *CachedRepository) AppendSession(ctx context.Context, id shared.PlayerID, session player.SessionMetadata) error {
+	defer r.Invalidate(id)
+	return r.Next.AppendSession(ctx, id, session)
+}
+
+// SearchByDisplayName is not cached.
+func (r *CachedRepository) SearchByDisplayName(ctx context.Context, query player.SearchQuery, limit int) ([]*player.PlayerAccount, error) {
+	return r.Next.SearchByDisplayName(ctx, query, limit)
+}
+
+// Invalidate evicts an account so the next read goes to the repository.
+func (r *CachedRepository) Invalidate(id shared.PlayerID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	if elem, ok := r.entries[id]; ok {
+		r.order.Remove(elem)
+		delete(r.entries, id)
+	}
+}
+
+// Len returns the number of cached accounts, including expired ones not yet evicted.
+func (r *CachedRepository) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// lookup returns a fresh cached account, or the current generation on a miss.
+func (r *CachedRepository) lookup(id shared.PlayerID) (*player.PlayerAccount, uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, ok := r.entries[id]
+	if !ok {
+		return nil, r.generation, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !r.Clock().Before(entry.expiresAt) {
+		r.order.Remove(elem)
+		delete(r.entries, id)
+		return nil, r.generation, false
+	}
+	r.order.MoveToFront(elem)
+	return cloneAccount(entry.account), r.generation, true
+}
+
+// store caches an account read at generation, unless a write has since invalidated the cache.
+func (r *CachedRepository) store(account *player.PlayerAccount, generation uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if generation != r.generation {
+		return
+	}
+
+	entry := &cacheEntry{account: cloneAccount(account), expiresAt: r.Clock().Add(r.ttl)}
+	if elem, ok := r.entries[account.ID]; ok {
+		elem.Value = entry
+		r.order.MoveToFront(elem)
+		return
+	}
+	for r.order.Len() >= r.capacity {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*cacheEntry).account.ID)
+	}
+	r.entries[account.ID] = r.order.PushFront(entry)
+}
+
+// cloneAccount copies an account so cached state cannot be mutated through a returned pointer.
+func cloneAccount(a *player.PlayerAccount) *player.PlayerAccount {
+	clone := *a
+	if a.Devices != nil {
+		clone.Devices = make(map[string]player.DeviceFingerprint, len(a.Devices))
+		for k, v := range a.Devices {
+			clone.Devices[k] = v
+		}
+	}
+	clone.Sessions = append([]player.SessionMetadata(nil), a.Sessions...)
+	return &clone
+}
@@ -0,0 +1,144 @@
+package player_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/app/auth"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraPlayer "github.com/heroiclabs/nakama/v3/src/infra/player"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+)
+
+// countingRepository counts reads that reach the underlying repository.
+type countingRepository struct {
+	*infraPlayer.MemoryRepository
+	reads int
+}
+
+func (r *countingRepository) GetByID(ctx context.Context, id shared.PlayerID) (*player.PlayerAccount, error) {
+	r.reads++
+	return r.MemoryRepository.GetByID(ctx, id)
+}
+
+func newCountingRepository(t *testing.T, ids ...shared.PlayerID) *countingRepository {
+	t.Helper()
+	repo := &countingRepository{MemoryRepository: infraPlayer.NewMemoryRepository()}
+	for _, id := range ids {
+		if err := repo.Save(context.Background(), testsupport.NewAccount(t, id)); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	return repo
+}
+
+func TestCachedRepository_Hit(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingRepository(t, "player-1")
+	cache := infraPlayer.NewCachedRepository(next, 10, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GetByID(ctx, "player-1"); err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+	}
+	if next.reads != 1 {
+		t.Errorf("Expected 1 repository read, got %d", next.reads)
+	}
+
+	account, _ := cache.GetByID(ctx, "player-1")
+	account.DisplayName = "mutated"
+	if cached, _ := cache.GetByID(ctx, "player-1"); cached.DisplayName == "mutated" {
+		t.Error("Mutating a returned account changed the cached copy")
+	}
+}
+
+func TestCachedRepository_TTLExpiry(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingRepository(t, "player-1")
+	clock := testsupport.NewFakeClock()
+	cache := infraPlayer.NewCachedRepository(next, 10, time.Minute)
+	cache.Clock = clock.Now
+
+	_, _ = cache.GetByID(ctx, "player-1")
+	clock.Advance(59 * time.Second)
+	_, _ = cache.GetByID(ctx, "player-1")
+	if next.reads != 1 {
+		t.Fatalf("Expected 1 repository read before expiry, got %d", next.reads)
+	}
+
+	clock.Advance(time.Second)
+	_, _ = cache.GetByID(ctx, "player-1")
+	if next.reads != 2 {
+		t.Errorf("Expected 2 repository reads after expiry, got %d", next.reads)
+	}
+}
+
+func TestCachedRepository_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingRepository(t, "player-1", "player-2", "player-3")
+	cache := infraPlayer.NewCachedRepository(next, 2, time.Minute)
+
+	_, _ = cache.GetByID(ctx, "player-1")
+	_, _ = cache.GetByID(ctx, "player-2")
+	_, _ = cache.GetByID(ctx, "player-1")
+	_, _ = cache.GetByID(ctx, "player-3")
+	if cache.Len() != 2 {
+		t.Fatalf("Expected 2 cached accounts, got %d", cache.Len())
+	}
+
+	next.reads = 0
+	_, _ = cache.GetByID(ctx, "player-1")
+	if next.reads != 0 {
+		t.Errorf("Expected recently used player-1 to stay cached")
+	}
+	_, _ = cache.GetByID(ctx, "player-2")
+	if next.reads != 1 {
+		t.Errorf("Expected least recently used player-2 to be evicted")
+	}
+}
+
+func TestCachedRepository_InvalidatesOnSuspend(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingRepository(t, "player-1")
+	cache := infraPlayer.NewCachedRepository(next, 10, time.Hour)
+	service := auth.NewService(cache, nil)
+
+	before, err := cache.GetByID(ctx, "player-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if before.Suspended {
+		t.Fatal("Expected account to start active")
+	}
+
+	if err := service.SuspendAccount(ctx, auth.SuspendAccountCommand{PlayerID: "player-1", Reason: "cheating"}); err != nil {
+		t.Fatalf("SuspendAccount() error = %v", err)
+	}
+
+	after, err := cache.GetByID(ctx, "player-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if !after.Suspended || after.SuspensionMsg != "cheating" {
+		t.Errorf("Expected cached read to see the suspension, got %+v", after)
+	}
+}
+
+func TestCachedRepository_NotFoundIsNotCached(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingRepository(t)
+	cache := infraPlayer.NewCachedRepository(next, 10, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GetByID(ctx, "missing"); !errors.Is(err, shared.ErrNotFound) {
+			t.Fatalf("GetByID() error = %v, wantErr %v", err, shared.ErrNotFound)
+		}
+	}
+	if next.reads != 2 {
+		t.Errorf("Expected 2 repository reads, got %d", next.reads)
+	}
+}
@@ -0,0 +1,54 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// translateError maps Nakama errors onto domain errors, keeping the original
+// error in the chain.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch errorCode(err) {
+	case codes.ResourceExhausted:
+		return fmt.Errorf("%w: %w", shared.ErrRateLimited, err)
+	case codes.NotFound:
+		return fmt.Errorf("%w: %w", shared.ErrNotFound, err)
+	}
+	return err
+}
+
+// translateAuthError maps Nakama's authentication failures onto player
+// errors, keeping the original error in the chain. Nakama answers a banned
+// account with PermissionDenied, a wrong password with Unauthenticated and
+// credentials matching no account with NotFound.
+func translateAuthError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch errorCode(err) {
+	case codes.PermissionDenied:
+		return fmt.Errorf("%w: %w", player.ErrAccountBanned, err)
+	case codes.Unauthenticated, codes.NotFound:
+		return fmt.Errorf("%w: %w", player.ErrBadCredentials, err)
+	}
+	return err
+}
+
+// errorCode returns the gRPC code of a runtime or gRPC status error.
+func errorCode(err error) codes.Code {
+	var runtimeErr *runtime.Error
+	if errors.As(err, &runtimeErr) {
+		return codes.Code(runtimeErr.Code)
+	}
+	return status.Code(err)
+}
@@ -0,0 +1,81 @@
+package player
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// MemoryRepository implements player.Repository using in-memory storage.
+type MemoryRepository struct {
+	mu       sync.RWMutex
+	accounts map[shared.PlayerID]*player.PlayerAccount
+}
+
+// NewMemoryRepository creates a new in-memory player repository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		accounts: make(map[shared.PlayerID]*player.PlayerAccount),
+	}
+}
+
+// GetByID retrieves an account by player ID.
+func (r *MemoryRepository) GetByID(ctx context.Context, id shared.PlayerID) (*player.PlayerAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	account, ok := r.accounts[id]
+	if !ok {
+		return nil, shared.ErrNotFound
+	}
+	return account, nil
+}
+
+// Save stores an account.
+func (r *MemoryRepository) Save(ctx context.Context, account *player.PlayerAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.accounts[account.ID] = account
+	return nil
+}
+
+// AppendSession records a session on an existing account.
+func (r *MemoryRepository) AppendSession(ctx context.Context, id shared.PlayerID, session player.SessionMetadata) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.accounts[id]
+	if !ok {
+		return shared.ErrNotFound
+	}
+	account.RecordSession(session)
+	return nil
+}
+
+// SearchByDisplayName returns accounts whose display name matches the query,
+// ordered by display name.
+func (r *MemoryRepository) SearchByDisplayName(ctx context.Context, query player.SearchQuery, limit int) ([]*player.PlayerAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []*player.PlayerAccount
+	for _, account := range r.accounts {
+		if query.Matches(account.DisplayName) {
+			matches = append(matches, account)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].DisplayName != matches[j].DisplayName {
+			return matches[i].DisplayName < matches[j].DisplayName
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
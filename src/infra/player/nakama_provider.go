@@ -0,0 +1,53 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// NakamaAccountProvider implements player.AccountProvider using Nakama runtime.
+type NakamaAccountProvider struct {
+	nk runtime.NakamaModule
+}
+
+// NewNakamaAccountProvider creates a new Nakama account provider.
+func NewNakamaAccountProvider(nk runtime.NakamaModule) *NakamaAccountProvider {
+	return &NakamaAccountProvider{nk: nk}
+}
+
+// UpdateAccount writes the changes to the Nakama account. AccountUpdateId
+// replaces metadata wholesale, so metadata changes are merged into the
+// account's current metadata first.
+func (p *NakamaAccountProvider) UpdateAccount(ctx context.Context, id shared.PlayerID, changes player.AccountChanges) error {
+	if changes.IsEmpty() {
+		return nil
+	}
+
+	var metadata map[string]interface{}
+	if len(changes.Metadata) > 0 {
+		account, err := p.nk.AccountGetId(ctx, string(id))
+		if err != nil {
+			return fmt.Errorf("reading account metadata: %w", translateError(err))
+		}
+		metadata = make(map[string]interface{}, len(changes.Metadata))
+		if raw := account.GetUser().GetMetadata(); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+				return fmt.Errorf("decoding account metadata: %w", err)
+			}
+		}
+		for key, value := range changes.Metadata {
+			metadata[key] = value
+		}
+	}
+
+	var displayName string
+	if changes.DisplayName != nil {
+		displayName = *changes.DisplayName
+	}
+	return translateError(p.nk.AccountUpdateId(ctx, string(id), "", metadata, displayName, "", "", "", ""))
+}
@@ -0,0 +1,93 @@
+package player_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/grpc/codes"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraPlayer "github.com/heroiclabs/nakama/v3/src/infra/player"
+)
+
+// fakeNakamaModule records AccountUpdateId calls.
+type fakeNakamaModule struct {
+	runtime.NakamaModule
+	metadata string
+
+	updates     int
+	displayName string
+	written     map[string]interface{}
+	updateErr   error
+}
+
+func (m *fakeNakamaModule) AccountGetId(ctx context.Context, userID string) (*api.Account, error) {
+	return &api.Account{User: &api.User{Id: userID, Metadata: m.metadata}}, nil
+}
+
+func (m *fakeNakamaModule) AccountUpdateId(ctx context.Context, userID, username string, metadata map[string]interface{}, displayName, timezone, location, langTag, avatarUrl string) error {
+	m.updates++
+	m.displayName = displayName
+	m.written = metadata
+	return m.updateErr
+}
+
+func TestNakamaAccountProvider_UpdateAccount(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("merges metadata", func(t *testing.T) {
+		nk := &fakeNakamaModule{metadata: `{"level":3}`}
+		provider := infraPlayer.NewNakamaAccountProvider(nk)
+
+		err := provider.UpdateAccount(ctx, "player-1", player.AccountChanges{Metadata: map[string]any{"suspended": true}})
+		if err != nil {
+			t.Fatalf("UpdateAccount() error = %v", err)
+		}
+		if nk.written["level"] != float64(3) || nk.written["suspended"] != true {
+			t.Errorf("Expected merged metadata, got %v", nk.written)
+		}
+		if nk.displayName != "" {
+			t.Errorf("Expected display name untouched, got %q", nk.displayName)
+		}
+	})
+
+	t.Run("display name only", func(t *testing.T) {
+		nk := &fakeNakamaModule{}
+		provider := infraPlayer.NewNakamaAccountProvider(nk)
+
+		name := "New Name"
+		if err := provider.UpdateAccount(ctx, "player-1", player.AccountChanges{DisplayName: &name}); err != nil {
+			t.Fatalf("UpdateAccount() error = %v", err)
+		}
+		if nk.displayName != name || nk.written != nil {
+			t.Errorf("AccountUpdateId got name %q metadata %v", nk.displayName, nk.written)
+		}
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		nk := &fakeNakamaModule{}
+		provider := infraPlayer.NewNakamaAccountProvider(nk)
+
+		if err := provider.UpdateAccount(ctx, "player-1", player.AccountChanges{}); err != nil {
+			t.Fatalf("UpdateAccount() error = %v", err)
+		}
+		if nk.updates != 0 {
+			t.Errorf("Expected no AccountUpdateId call, got %d", nk.updates)
+		}
+	})
+
+	t.Run("translates errors", func(t *testing.T) {
+		nk := &fakeNakamaModule{updateErr: runtime.NewError("rate limited", int(codes.ResourceExhausted))}
+		provider := infraPlayer.NewNakamaAccountProvider(nk)
+
+		name := "New Name"
+		err := provider.UpdateAccount(ctx, "player-1", player.AccountChanges{DisplayName: &name})
+		if !errors.Is(err, shared.ErrRateLimited) {
+			t.Errorf("UpdateAccount() error = %v, want %v", err, shared.ErrRateLimited)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/heroiclabs/nakama-common/api"
+
+	"github.com/heroiclabs/nakama/v3/apigrpc"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// UpdateAccountRPC is the runtime RPC that applies account changes with a
+// NakamaAccountProvider. It only accepts server-to-server calls.
+const UpdateAccountRPC = "sandai_update_account"
+
+// UpdateAccountRequest is the payload of UpdateAccountRPC.
+type UpdateAccountRequest struct {
+	PlayerID    string         `json:"player_id"`
+	DisplayName *string        `json:"display_name,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+}
+
+// RPCAccountProvider implements player.AccountProvider for processes outside
+// the Nakama runtime by calling UpdateAccountRPC over gRPC with the server's
+// HTTP key.
+type RPCAccountProvider struct {
+	Client  apigrpc.NakamaClient
+	HTTPKey string
+}
+
+// NewRPCAccountProvider creates a provider calling client with httpKey.
+func NewRPCAccountProvider(client apigrpc.NakamaClient, httpKey string) *RPCAccountProvider {
+	return &RPCAccountProvider{Client: client, HTTPKey: httpKey}
+}
+
+// UpdateAccount sends the changes to the runtime module.
+func (p *RPCAccountProvider) UpdateAccount(ctx context.Context, id shared.PlayerID, changes player.AccountChanges) error {
+	if changes.IsEmpty() {
+		return nil
+	}
+	payload, err := json.Marshal(UpdateAccountRequest{
+		PlayerID:    string(id),
+		DisplayName: changes.DisplayName,
+		Metadata:    changes.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = p.Client.RpcFunc(ctx, &api.Rpc{Id: UpdateAccountRPC, Payload: string(payload), HttpKey: p.HTTPKey})
+	return translateError(err)
+}
@@ -0,0 +1,77 @@
+package player_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/heroiclabs/nakama/v3/apigrpc"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	infraPlayer "github.com/heroiclabs/nakama/v3/src/infra/player"
+)
+
+// fakeRPCClient records RpcFunc calls.
+type fakeRPCClient struct {
+	apigrpc.NakamaClient
+	calls []*api.Rpc
+	err   error
+}
+
+func (c *fakeRPCClient) RpcFunc(ctx context.Context, in *api.Rpc, opts ...grpc.CallOption) (*api.Rpc, error) {
+	c.calls = append(c.calls, in)
+	return &api.Rpc{Id: in.Id}, c.err
+}
+
+func TestRPCAccountProvider_UpdateAccount(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("calls the runtime rpc", func(t *testing.T) {
+		client := &fakeRPCClient{}
+		provider := infraPlayer.NewRPCAccountProvider(client, "http-key")
+
+		name := "Alice"
+		if err := provider.UpdateAccount(ctx, "player-1", player.AccountChanges{DisplayName: &name, Metadata: map[string]any{"level": 3}}); err != nil {
+			t.Fatalf("UpdateAccount() error = %v", err)
+		}
+		if len(client.calls) != 1 {
+			t.Fatalf("Expected 1 rpc call, got %d", len(client.calls))
+		}
+		call := client.calls[0]
+		if call.Id != infraPlayer.UpdateAccountRPC || call.HttpKey != "http-key" {
+			t.Errorf("RpcFunc(id %q, key %q), want %q with the http key", call.Id, call.HttpKey, infraPlayer.UpdateAccountRPC)
+		}
+		var req infraPlayer.UpdateAccountRequest
+		if err := json.Unmarshal([]byte(call.Payload), &req); err != nil {
+			t.Fatalf("decoding payload: %v", err)
+		}
+		if req.PlayerID != "player-1" || req.DisplayName == nil || *req.DisplayName != name || req.Metadata["level"] != float64(3) {
+			t.Errorf("payload = %s, want player-1's changes", call.Payload)
+		}
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		client := &fakeRPCClient{}
+		if err := infraPlayer.NewRPCAccountProvider(client, "http-key").UpdateAccount(ctx, "player-1", player.AccountChanges{}); err != nil {
+			t.Fatalf("UpdateAccount() error = %v", err)
+		}
+		if len(client.calls) != 0 {
+			t.Errorf("Expected no rpc call, got %d", len(client.calls))
+		}
+	})
+
+	t.Run("translates errors", func(t *testing.T) {
+		client := &fakeRPCClient{err: status.Error(codes.NotFound, "User account not found.")}
+		name := "Alice"
+		err := infraPlayer.NewRPCAccountProvider(client, "http-key").UpdateAccount(ctx, "player-1", player.AccountChanges{DisplayName: &name})
+		if !errors.Is(err, shared.ErrNotFound) {
+			t.Errorf("UpdateAccount() error = %v, want %v", err, shared.ErrNotFound)
+		}
+	})
+}
@@ -4,12 +4,27 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
 	"time"
 
+	"github.com/gofrs/uuid/v5"
 	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
+
+	"github.com/heroiclabs/nakama/v3/src/app/battles"
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	battleinfra "github.com/heroiclabs/nakama/v3/src/infra/battle"
+	playerinfra "github.com/heroiclabs/nakama/v3/src/infra/player"
 )
 
+// battleMatchModule is the name the authoritative battle match is registered
+// under.
+const battleMatchModule = "sandai_battle"
+
 // InitModule is the entrypoint for the Sand-ai Nakama runtime extension.
 func InitModule(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, initializer runtime.Initializer) error {
 	if err := initializer.RegisterBeforeAuthenticateDevice(beforeAuthenticateDevice); err != nil {
@@ -21,18 +36,24 @@ func InitModule(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runti
 	if err := initializer.RegisterBeforeWriteLeaderboardRecord(beforeWriteLeaderboardRecord); err != nil {
 		return err
 	}
-	if err := initializer.RegisterMatch("sandai_battle", func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule) (runtime.Match, error) {
-		return &battleMatch{}, nil
+	if err := initializer.RegisterMatch(battleMatchModule, func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule) (runtime.Match, error) {
+		return &battleMatch{tracker: battles.NewService(battleinfra.NewStorageRepository(nk), nil)}, nil
 	}); err != nil {
 		return err
 	}
+	if err := initializer.RegisterRpc("sandai_create_battle_match", rpcCreateBattleMatch); err != nil {
+		return err
+	}
+	if err := initializer.RegisterRpc(playerinfra.UpdateAccountRPC, rpcUpdateAccount); err != nil {
+		return err
+	}
 	logger.Info("Sand-ai runtime module registered")
 	return nil
 }
 
 func beforeAuthenticateDevice(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, in *api.AuthenticateDeviceRequest) (*api.AuthenticateDeviceRequest, error) {
 	if in.Account == nil || in.Account.Id == "" {
-		return nil, runtime.BadInputError("device id required", nil)
+		return nil, runtime.NewError("device id required", 3)
 	}
 	return in, nil
 }
@@ -44,7 +65,7 @@ func afterAuthenticateDevice(ctx context.Context, logger runtime.Logger, db *sql
 
 func beforeWriteLeaderboardRecord(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, in *api.WriteLeaderboardRecordRequest) (*api.WriteLeaderboardRecordRequest, error) {
 	if in.Record == nil {
-		return nil, runtime.BadInputError("missing leaderboard record", nil)
+		return nil, runtime.NewError("missing leaderboard record", 3)
 	}
 	if in.Record.Metadata == "" {
 		metadata := map[string]any{"validated_at": time.Now().UTC()}
@@ -54,20 +75,265 @@ func beforeWriteLeaderboardRecord(ctx context.Context, logger runtime.Logger, db
 	return in, nil
 }
 
-type battleMatch struct{}
+// createBattleMatchResponse is returned by the sandai_create_battle_match RPC.
+type createBattleMatchResponse struct {
+	BattleID string `json:"battle_id"`
+	MatchID  string `json:"match_id"`
+}
+
+// rpcCreateBattleMatch creates an authoritative battle match from the JSON
+// object of match params in payload. The match snapshots to the battle named
+// by "battle_id"; when it is missing a new id is generated, and the caller
+// must store the battle under the returned id.
+func rpcCreateBattleMatch(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	params := make(map[string]any)
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &params); err != nil {
+			return "", runtime.NewError("match params must be a JSON object", 3)
+		}
+	}
+	battleID, _ := params["battle_id"].(string)
+	if battleID == "" {
+		battleID = uuid.Must(uuid.NewV4()).String()
+		params["battle_id"] = battleID
+	}
+	matchID, err := nk.MatchCreate(ctx, battleMatchModule, params)
+	if err != nil {
+		return "", err
+	}
+	response, err := json.Marshal(createBattleMatchResponse{BattleID: battleID, MatchID: matchID})
+	if err != nil {
+		return "", err
+	}
+	return string(response), nil
+}
+
+// rpcUpdateAccount applies the account changes in payload, a
+// playerinfra.UpdateAccountRequest, for the API's RPCAccountProvider. Calls
+// made with a user session are refused: players must not edit other accounts.
+func rpcUpdateAccount(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); userID != "" {
+		return "", runtime.NewError("server to server calls only", 7)
+	}
+	var req playerinfra.UpdateAccountRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil || req.PlayerID == "" {
+		return "", runtime.NewError("payload must be an object with a player_id", 3)
+	}
+	changes := player.AccountChanges{DisplayName: req.DisplayName, Metadata: req.Metadata}
+	if err := playerinfra.NewNakamaAccountProvider(nk).UpdateAccount(ctx, shared.PlayerID(req.PlayerID), changes); err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// battleTracker persists a battle's match state and records when its match
+// ends; *battles.Service implements it.
+type battleTracker interface {
+	UpdateSnapshot(ctx context.Context, battleID shared.BattleID, state battle.MatchState) error
+	FinishBattle(ctx context.Context, battleID shared.BattleID) error
+}
+
+// battleMatch is the authoritative match handler. When tracker is set, the
+// match state is saved to the match's battle every snapshot interval and the
+// battle is marked finished when the match ends.
+type battleMatch struct {
+	tracker battleTracker
+}
+
+// defaultBroadcastBudget caps how many messages a match relays per tick unless
+// overridden by the "max_broadcasts_per_tick" match param. Zero disables the cap.
+const defaultBroadcastBudget = 32
+
+// droppedBroadcastsMetric counts messages discarded by the per-tick broadcast budget.
+const droppedBroadcastsMetric = "sandai_match_broadcasts_dropped"
+
+// matchGame is the "game" field of every match label, so all SandAI matches
+// can be listed with "+label.game:sandai".
+const matchGame = "sandai"
+
+// defaultTickRate and maxTickRate bound the "tick_rate" match param.
+const (
+	defaultTickRate = 10
+	maxTickRate     = 60
+)
+
+// defaultStartTimeout is how long a match waits for "min_players" to join
+// before it is terminated, unless overridden by "start_timeout_sec". Matches
+// created without "min_players" start immediately.
+const defaultStartTimeout = 60 * time.Second
+
+// defaultSnapshotInterval is how many ticks pass between match state
+// snapshots unless overridden by "snapshot_interval_ticks". Zero or less
+// disables snapshots.
+const defaultSnapshotInterval = 50
+
+// Match phases. A match waits until enough players have joined, then runs.
+const (
+	phaseWaiting = "waiting"
+	phaseActive  = "active"
+)
+
+// labelValuePattern restricts preset and region values so they are safe to use
+// in match listing queries such as "+label.region:eu".
+var labelValuePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// matchLabel is the JSON label Nakama indexes for match listing queries.
+type matchLabel struct {
+	Game   string `json:"game"`
+	Preset string `json:"preset,omitempty"`
+	Region string `json:"region,omitempty"`
+}
 
 type matchState struct {
-	Tick    int64                       `json:"tick"`
-	Players map[string]runtime.Presence `json:"-"`
+	Tick            int64                       `json:"tick"`
+	Players         map[string]runtime.Presence `json:"-"`
+	BroadcastBudget int                         `json:"broadcast_budget"`
+	MaxPlayers      int                         `json:"max_players"`
+	MinPlayers      int                         `json:"min_players"`
+	Phase           string                      `json:"phase"`
+	// StartDeadline is the tick by which MinPlayers must have joined.
+	StartDeadline int64 `json:"start_deadline"`
+	// BattleID is the battle snapshots are saved to, from the "battle_id"
+	// param or else the match id. Empty disables snapshots.
+	BattleID         string `json:"battle_id"`
+	SnapshotInterval int64  `json:"snapshot_interval"`
 }
 
 func (m *battleMatch) MatchInit(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, params map[string]any) (interface{}, int, string) {
-	state := &matchState{Tick: 0, Players: make(map[string]runtime.Presence)}
-	return state, 10, "sandai"
+	label, err := matchLabelParam(params)
+	if err != nil {
+		// A nil state makes Nakama reject the match creation.
+		logger.Warn("rejecting match with invalid params: %v", err)
+		return nil, 0, ""
+	}
+	tickRate := tickRateParam(params)
+	state := &matchState{
+		Tick:            0,
+		Players:         make(map[string]runtime.Presence),
+		BroadcastBudget: broadcastBudgetParam(params),
+		MaxPlayers:      intParam(params, "max_players", 0),
+		MinPlayers:      intParam(params, "min_players", 0),
+		Phase:           phaseActive,
+	}
+	state.BattleID = matchBattleID(ctx, params)
+	state.SnapshotInterval = int64(intParam(params, "snapshot_interval_ticks", defaultSnapshotInterval))
+	if state.MinPlayers > 0 {
+		state.Phase = phaseWaiting
+	}
+	state.StartDeadline = int64(startTimeoutParam(params)/time.Second) * int64(tickRate)
+	return state, tickRate, label
+}
+
+// matchLabelParam composes the match label from the optional "preset" and
+// "region" params. Without either the label only names the game.
+func matchLabelParam(params map[string]any) (string, error) {
+	label := matchLabel{Game: matchGame}
+	fields := []struct {
+		key   string
+		value *string
+	}{{"preset", &label.Preset}, {"region", &label.Region}}
+	for _, field := range fields {
+		raw, ok := params[field.key]
+		if !ok {
+			continue
+		}
+		value, ok := raw.(string)
+		if !ok || !labelValuePattern.MatchString(value) {
+			return "", fmt.Errorf("invalid %s %v", field.key, raw)
+		}
+		*field.value = value
+	}
+	encoded, err := json.Marshal(label)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// matchBattleID returns the "battle_id" param, falling back to the id of the
+// match being created.
+func matchBattleID(ctx context.Context, params map[string]any) string {
+	if id, ok := params["battle_id"].(string); ok && id != "" {
+		return id
+	}
+	if ctx != nil {
+		if id, ok := ctx.Value(runtime.RUNTIME_CTX_MATCH_ID).(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+func tickRateParam(params map[string]any) int {
+	rate := intParam(params, "tick_rate", defaultTickRate)
+	if rate <= 0 || rate > maxTickRate {
+		return defaultTickRate
+	}
+	return rate
+}
+
+// startTimeoutParam reads "start_timeout_sec", falling back to
+// defaultStartTimeout for values that would end the match before anyone
+// could join.
+func startTimeoutParam(params map[string]any) time.Duration {
+	seconds := intParam(params, "start_timeout_sec", int(defaultStartTimeout/time.Second))
+	if seconds <= 0 {
+		return defaultStartTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func broadcastBudgetParam(params map[string]any) int {
+	return intParam(params, "max_broadcasts_per_tick", defaultBroadcastBudget)
+}
+
+// intParam reads a numeric match param. JSON-decoded params arrive as float64.
+func intParam(params map[string]any, key string, fallback int) int {
+	switch v := params[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return fallback
+	}
+}
+
+// throttleMessages decides which of a tick's messages to broadcast. Repeated
+// messages from the same sender with the same op code are coalesced into the
+// latest one, and anything beyond budget is dropped. A budget of zero or less
+// keeps every coalesced message. It returns the messages to send and how many
+// were dropped or merged.
+func throttleMessages(messages []runtime.MatchData, budget int) ([]runtime.MatchData, int) {
+	type senderOp struct {
+		sessionID string
+		opCode    int64
+	}
+	index := make(map[senderOp]int, len(messages))
+	send := make([]runtime.MatchData, 0, len(messages))
+	for _, msg := range messages {
+		key := senderOp{sessionID: msg.GetSessionId(), opCode: msg.GetOpCode()}
+		if i, ok := index[key]; ok {
+			send[i] = msg
+			continue
+		}
+		index[key] = len(send)
+		send = append(send, msg)
+	}
+	if budget > 0 && len(send) > budget {
+		send = send[:budget]
+	}
+	return send, len(messages) - len(send)
 }
 
 func (m *battleMatch) MatchJoinAttempt(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, dispatcher runtime.MatchDispatcher, tick int64, st interface{}, presence runtime.Presence, metadata map[string]string) (interface{}, bool, string) {
-	return st, true, ""
+	state := st.(*matchState)
+	if state.MaxPlayers > 0 && len(state.Players) >= state.MaxPlayers {
+		return state, false, "match full"
+	}
+	return state, true, ""
 }
 
 func (m *battleMatch) MatchJoin(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, dispatcher runtime.MatchDispatcher, tick int64, st interface{}, presences []runtime.Presence) interface{} {
@@ -89,18 +355,74 @@ func (m *battleMatch) MatchLeave(ctx context.Context, logger runtime.Logger, db
 func (m *battleMatch) MatchLoop(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, dispatcher runtime.MatchDispatcher, tick int64, st interface{}, messages []runtime.MatchData) interface{} {
 	state := st.(*matchState)
 	state.Tick = tick
+	if state.Phase == phaseWaiting {
+		if len(state.Players) < state.MinPlayers {
+			if tick >= state.StartDeadline {
+				logger.Info("terminating match: %d of %d players joined before the start timeout", len(state.Players), state.MinPlayers)
+				m.finish(ctx, logger, state)
+				return nil
+			}
+			return state
+		}
+		state.Phase = phaseActive
+	}
 	if len(messages) > 0 {
-		for _, msg := range messages {
+		send, dropped := throttleMessages(messages, state.BroadcastBudget)
+		for _, msg := range send {
 			dispatcher.BroadcastMessage(1, msg.GetData(), nil, nil, true)
 		}
+		if dropped > 0 {
+			nk.MetricsCounterAdd(droppedBroadcastsMetric, nil, int64(dropped))
+		}
 	}
 	if len(state.Players) == 0 && tick > 30 {
+		m.finish(ctx, logger, state)
 		return nil
 	}
+	if state.SnapshotInterval > 0 && tick%state.SnapshotInterval == 0 {
+		m.saveSnapshot(ctx, logger, state)
+	}
 	return state
 }
 
+// saveSnapshot persists the match state to its battle. A match without a
+// battle record stops snapshotting; other failures are logged and retried at
+// the next interval, since losing one snapshot must not end the match.
+func (m *battleMatch) saveSnapshot(ctx context.Context, logger runtime.Logger, state *matchState) {
+	if m.tracker == nil || state.BattleID == "" {
+		return
+	}
+	payload, err := json.Marshal(state)
+	if err != nil {
+		logger.Error("encoding snapshot of battle %s: %v", state.BattleID, err)
+		return
+	}
+	err = m.tracker.UpdateSnapshot(ctx, shared.BattleID(state.BattleID), battle.MatchState{Tick: state.Tick, Payload: payload})
+	if errors.Is(err, shared.ErrNotFound) {
+		logger.Warn("no battle %s for match snapshots, disabling them", state.BattleID)
+		state.BattleID = ""
+		return
+	}
+	if err != nil {
+		logger.Error("saving snapshot of battle %s: %v", state.BattleID, err)
+	}
+}
+
+// finish marks the match's battle finished so it leaves the active index and
+// is not cancelled later. Failures are logged, since the match ends anyway.
+func (m *battleMatch) finish(ctx context.Context, logger runtime.Logger, state *matchState) {
+	if m.tracker == nil || state.BattleID == "" {
+		return
+	}
+	if err := m.tracker.FinishBattle(ctx, shared.BattleID(state.BattleID)); err != nil && !errors.Is(err, shared.ErrNotFound) {
+		logger.Error("finishing battle %s: %v", state.BattleID, err)
+	}
+}
+
 func (m *battleMatch) MatchTerminate(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, dispatcher runtime.MatchDispatcher, tick int64, st interface{}, graceSeconds int) interface{} {
+	if state, ok := st.(*matchState); ok {
+		m.finish(ctx, logger, state)
+	}
 	return st
 }
 
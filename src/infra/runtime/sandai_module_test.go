@@ -0,0 +1,424 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/battle"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+type fakeMatchData struct {
+	runtime.Presence
+	sessionID string
+	opCode    int64
+	data      string
+}
+
+func (d *fakeMatchData) GetSessionId() string  { return d.sessionID }
+func (d *fakeMatchData) GetOpCode() int64      { return d.opCode }
+func (d *fakeMatchData) GetData() []byte       { return []byte(d.data) }
+func (d *fakeMatchData) GetReliable() bool     { return true }
+func (d *fakeMatchData) GetReceiveTime() int64 { return 0 }
+
+func TestThrottleMessages(t *testing.T) {
+	msg := func(session string, op int64, data string) runtime.MatchData {
+		return &fakeMatchData{sessionID: session, opCode: op, data: data}
+	}
+
+	tests := []struct {
+		name        string
+		messages    []runtime.MatchData
+		budget      int
+		wantData    []string
+		wantDropped int
+	}{
+		{
+			name:     "under budget",
+			messages: []runtime.MatchData{msg("a", 1, "a1"), msg("b", 1, "b1")},
+			budget:   5,
+			wantData: []string{"a1", "b1"},
+		},
+		{
+			name:        "over budget drops excess",
+			messages:    []runtime.MatchData{msg("a", 1, "a1"), msg("b", 1, "b1"), msg("c", 1, "c1")},
+			budget:      2,
+			wantData:    []string{"a1", "b1"},
+			wantDropped: 1,
+		},
+		{
+			name:        "coalesces repeats from the same sender",
+			messages:    []runtime.MatchData{msg("a", 1, "a1"), msg("b", 1, "b1"), msg("a", 1, "a2")},
+			budget:      5,
+			wantData:    []string{"a2", "b1"},
+			wantDropped: 1,
+		},
+		{
+			name:     "different op codes are kept apart",
+			messages: []runtime.MatchData{msg("a", 1, "move"), msg("a", 2, "chat")},
+			budget:   5,
+			wantData: []string{"move", "chat"},
+		},
+		{
+			name:     "zero budget is unlimited",
+			messages: []runtime.MatchData{msg("a", 1, "a1"), msg("b", 1, "b1"), msg("c", 1, "c1")},
+			budget:   0,
+			wantData: []string{"a1", "b1", "c1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			send, dropped := throttleMessages(tt.messages, tt.budget)
+			if dropped != tt.wantDropped {
+				t.Errorf("throttleMessages() dropped = %d, want %d", dropped, tt.wantDropped)
+			}
+			if len(send) != len(tt.wantData) {
+				t.Fatalf("throttleMessages() sent %d messages, want %d", len(send), len(tt.wantData))
+			}
+			for i, m := range send {
+				if string(m.GetData()) != tt.wantData[i] {
+					t.Errorf("message %d = %q, want %q", i, m.GetData(), tt.wantData[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMatchLabelParam(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]any
+		want    string
+		wantErr bool
+	}{
+		{name: "default", params: nil, want: `{"game":"sandai"}`},
+		{name: "preset only", params: map[string]any{"preset": "ranked"}, want: `{"game":"sandai","preset":"ranked"}`},
+		{name: "region only", params: map[string]any{"region": "eu-west"}, want: `{"game":"sandai","region":"eu-west"}`},
+		{name: "preset and region", params: map[string]any{"preset": "casual_2v2", "region": "us"}, want: `{"game":"sandai","preset":"casual_2v2","region":"us"}`},
+		{name: "unrelated params", params: map[string]any{"tick_rate": 20}, want: `{"game":"sandai"}`},
+		{name: "uppercase rejected", params: map[string]any{"preset": "Ranked"}, wantErr: true},
+		{name: "query syntax rejected", params: map[string]any{"region": "eu +label.preset:x"}, wantErr: true},
+		{name: "non-string rejected", params: map[string]any{"region": 5}, wantErr: true},
+		{name: "empty rejected", params: map[string]any{"preset": ""}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchLabelParam(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchLabelParam() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("matchLabelParam() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBattleMatch_MatchInit(t *testing.T) {
+	m := &battleMatch{}
+	st, tickRate, label := m.MatchInit(nil, nil, nil, nil, map[string]any{"preset": "ranked", "tick_rate": float64(20), "max_players": float64(2)})
+	state, ok := st.(*matchState)
+	if !ok {
+		t.Fatalf("MatchInit() state = %T, want *matchState", st)
+	}
+	if tickRate != 20 {
+		t.Errorf("tick rate = %d, want 20", tickRate)
+	}
+	if label != `{"game":"sandai","preset":"ranked"}` {
+		t.Errorf("label = %q", label)
+	}
+
+	state.Players["a"] = nil
+	state.Players["b"] = nil
+	if _, ok, reason := m.MatchJoinAttempt(nil, nil, nil, nil, nil, 0, state, nil, nil); ok || reason != "match full" {
+		t.Errorf("MatchJoinAttempt() = %v, %q, want rejection when full", ok, reason)
+	}
+
+	_, tickRate, label = m.MatchInit(nil, nil, nil, nil, map[string]any{"tick_rate": float64(500)})
+	if tickRate != defaultTickRate || label != `{"game":"sandai"}` {
+		t.Errorf("MatchInit() defaults = %d, %q", tickRate, label)
+	}
+}
+
+type fakeLogger struct {
+	runtime.Logger
+}
+
+func (fakeLogger) Info(format string, v ...interface{})  {}
+func (fakeLogger) Warn(format string, v ...interface{})  {}
+func (fakeLogger) Error(format string, v ...interface{}) {}
+
+type fakePresence struct {
+	runtime.Presence
+	sessionID string
+}
+
+func (p *fakePresence) GetSessionId() string { return p.sessionID }
+
+func TestBattleMatch_WaitingPhase(t *testing.T) {
+	m := &battleMatch{}
+	params := map[string]any{"min_players": float64(2), "start_timeout_sec": float64(3), "tick_rate": float64(10)}
+
+	t.Run("waiting to active", func(t *testing.T) {
+		st, _, _ := m.MatchInit(nil, nil, nil, nil, params)
+		state := st.(*matchState)
+		if state.Phase != phaseWaiting {
+			t.Fatalf("Phase = %q, want %q", state.Phase, phaseWaiting)
+		}
+
+		st = m.MatchJoin(nil, nil, nil, nil, nil, 1, state, []runtime.Presence{&fakePresence{sessionID: "a"}})
+		st = m.MatchLoop(nil, fakeLogger{}, nil, nil, nil, 5, st, nil)
+		if st == nil || st.(*matchState).Phase != phaseWaiting {
+			t.Fatalf("Expected match to keep waiting with one player, got %+v", st)
+		}
+
+		st = m.MatchJoin(nil, nil, nil, nil, nil, 6, st, []runtime.Presence{&fakePresence{sessionID: "b"}})
+		st = m.MatchLoop(nil, fakeLogger{}, nil, nil, nil, 7, st, nil)
+		if st == nil || st.(*matchState).Phase != phaseActive {
+			t.Fatalf("Expected match to become active, got %+v", st)
+		}
+
+		// Once active, the start deadline no longer applies.
+		if st = m.MatchLoop(nil, fakeLogger{}, nil, nil, nil, 31, st, nil); st == nil {
+			t.Error("Expected active match to keep running past the start deadline")
+		}
+	})
+
+	t.Run("waiting to terminate", func(t *testing.T) {
+		st, _, _ := m.MatchInit(nil, nil, nil, nil, params)
+		st = m.MatchJoin(nil, nil, nil, nil, nil, 1, st, []runtime.Presence{&fakePresence{sessionID: "a"}})
+
+		if st = m.MatchLoop(nil, fakeLogger{}, nil, nil, nil, 29, st, nil); st == nil {
+			t.Fatal("Expected match to wait until the deadline")
+		}
+		if st = m.MatchLoop(nil, fakeLogger{}, nil, nil, nil, 30, st, nil); st != nil {
+			t.Errorf("Expected match to terminate at the start deadline, got %+v", st)
+		}
+	})
+
+	t.Run("non-positive timeout uses the default", func(t *testing.T) {
+		for _, timeout := range []float64{0, -5} {
+			st, _, _ := m.MatchInit(nil, nil, nil, nil, map[string]any{"min_players": float64(2), "start_timeout_sec": timeout, "tick_rate": float64(10)})
+			want := int64(defaultStartTimeout/time.Second) * 10
+			if deadline := st.(*matchState).StartDeadline; deadline != want {
+				t.Errorf("StartDeadline with start_timeout_sec %v = %d, want %d", timeout, deadline, want)
+			}
+		}
+	})
+
+	t.Run("no minimum starts immediately", func(t *testing.T) {
+		st, _, _ := m.MatchInit(nil, nil, nil, nil, nil)
+		if phase := st.(*matchState).Phase; phase != phaseActive {
+			t.Errorf("Phase = %q, want %q", phase, phaseActive)
+		}
+	})
+}
+
+type fakeSnapshots struct {
+	err      error
+	calls    []shared.BattleID
+	ticks    []int64
+	last     battle.MatchState
+	finished []shared.BattleID
+}
+
+func (f *fakeSnapshots) UpdateSnapshot(ctx context.Context, battleID shared.BattleID, state battle.MatchState) error {
+	f.calls = append(f.calls, battleID)
+	f.ticks = append(f.ticks, state.Tick)
+	f.last = state
+	return f.err
+}
+
+func (f *fakeSnapshots) FinishBattle(ctx context.Context, battleID shared.BattleID) error {
+	f.finished = append(f.finished, battleID)
+	return nil
+}
+
+func TestBattleMatch_Snapshots(t *testing.T) {
+	presence := []runtime.Presence{&fakePresence{sessionID: "a"}}
+	run := func(m *battleMatch, params map[string]any, ticks int64) interface{} {
+		st, _, _ := m.MatchInit(nil, nil, nil, nil, params)
+		st = m.MatchJoin(nil, nil, nil, nil, nil, 0, st, presence)
+		for tick := int64(1); tick <= ticks && st != nil; tick++ {
+			st = m.MatchLoop(nil, fakeLogger{}, nil, nil, nil, tick, st, nil)
+		}
+		return st
+	}
+
+	t.Run("every interval", func(t *testing.T) {
+		snapshots := &fakeSnapshots{}
+		run(&battleMatch{tracker: snapshots}, map[string]any{"battle_id": "battle-1", "snapshot_interval_ticks": float64(4)}, 10)
+		if want := []int64{4, 8}; len(snapshots.ticks) != 2 || snapshots.ticks[0] != want[0] || snapshots.ticks[1] != want[1] {
+			t.Fatalf("snapshot ticks = %v, want %v", snapshots.ticks, want)
+		}
+		if snapshots.calls[0] != "battle-1" {
+			t.Errorf("battle id = %q, want battle-1", snapshots.calls[0])
+		}
+		var payload matchState
+		if err := json.Unmarshal(snapshots.last.Payload, &payload); err != nil {
+			t.Fatalf("decoding payload: %v", err)
+		}
+		if payload.Tick != 8 || payload.BattleID != "battle-1" {
+			t.Errorf("payload = %+v, want tick 8 of battle-1", payload)
+		}
+	})
+
+	t.Run("default interval", func(t *testing.T) {
+		snapshots := &fakeSnapshots{}
+		run(&battleMatch{tracker: snapshots}, map[string]any{"battle_id": "battle-1"}, defaultSnapshotInterval)
+		if len(snapshots.ticks) != 1 || snapshots.ticks[0] != defaultSnapshotInterval {
+			t.Errorf("snapshot ticks = %v, want [%d]", snapshots.ticks, defaultSnapshotInterval)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		snapshots := &fakeSnapshots{}
+		run(&battleMatch{tracker: snapshots}, map[string]any{"battle_id": "battle-1", "snapshot_interval_ticks": float64(0)}, 10)
+		run(&battleMatch{tracker: snapshots}, map[string]any{"snapshot_interval_ticks": float64(2)}, 10)
+		if len(snapshots.calls) != 0 {
+			t.Errorf("Expected no snapshots, got %v", snapshots.ticks)
+		}
+	})
+
+	t.Run("unknown battle stops snapshots", func(t *testing.T) {
+		snapshots := &fakeSnapshots{err: shared.ErrNotFound}
+		st := run(&battleMatch{tracker: snapshots}, map[string]any{"battle_id": "missing", "snapshot_interval_ticks": float64(2)}, 10)
+		if st == nil {
+			t.Fatal("Expected the match to keep running")
+		}
+		if len(snapshots.calls) != 1 {
+			t.Errorf("Expected one snapshot attempt, got %d", len(snapshots.calls))
+		}
+	})
+
+	t.Run("failures are retried", func(t *testing.T) {
+		snapshots := &fakeSnapshots{err: errors.New("storage unavailable")}
+		st := run(&battleMatch{tracker: snapshots}, map[string]any{"battle_id": "battle-1", "snapshot_interval_ticks": float64(2)}, 10)
+		if st == nil {
+			t.Fatal("Expected the match to keep running")
+		}
+		if len(snapshots.calls) != 5 {
+			t.Errorf("Expected a snapshot attempt every interval, got %d", len(snapshots.calls))
+		}
+	})
+}
+
+func TestBattleMatch_FinishesBattle(t *testing.T) {
+	t.Run("start timeout", func(t *testing.T) {
+		tracker := &fakeSnapshots{}
+		m := &battleMatch{tracker: tracker}
+		st, _, _ := m.MatchInit(nil, nil, nil, nil, map[string]any{"battle_id": "battle-1", "min_players": float64(2), "start_timeout_sec": float64(1), "tick_rate": float64(10)})
+		if st = m.MatchLoop(nil, fakeLogger{}, nil, nil, nil, 10, st, nil); st != nil {
+			t.Fatalf("Expected match to terminate at the start deadline, got %+v", st)
+		}
+		if len(tracker.finished) != 1 || tracker.finished[0] != "battle-1" {
+			t.Errorf("finished = %v, want [battle-1]", tracker.finished)
+		}
+	})
+
+	t.Run("terminate", func(t *testing.T) {
+		tracker := &fakeSnapshots{}
+		m := &battleMatch{tracker: tracker}
+		st, _, _ := m.MatchInit(nil, nil, nil, nil, map[string]any{"battle_id": "battle-1"})
+		m.MatchTerminate(nil, fakeLogger{}, nil, nil, nil, 5, st, 0)
+		if len(tracker.finished) != 1 || tracker.finished[0] != "battle-1" {
+			t.Errorf("finished = %v, want [battle-1]", tracker.finished)
+		}
+	})
+}
+
+type fakeMatchCreator struct {
+	runtime.NakamaModule
+	module string
+	params map[string]interface{}
+}
+
+func (m *fakeMatchCreator) MatchCreate(ctx context.Context, module string, params map[string]interface{}) (string, error) {
+	m.module, m.params = module, params
+	return "match-1", nil
+}
+
+func TestRPCCreateBattleMatch(t *testing.T) {
+	t.Run("passes the battle id", func(t *testing.T) {
+		nk := &fakeMatchCreator{}
+		out, err := rpcCreateBattleMatch(context.Background(), fakeLogger{}, nil, nk, `{"battle_id":"battle-1","preset":"ranked"}`)
+		if err != nil {
+			t.Fatalf("rpcCreateBattleMatch() error = %v", err)
+		}
+		if out != `{"battle_id":"battle-1","match_id":"match-1"}` {
+			t.Errorf("response = %s", out)
+		}
+		if nk.module != battleMatchModule || nk.params["battle_id"] != "battle-1" || nk.params["preset"] != "ranked" {
+			t.Errorf("MatchCreate(%q, %v), want the battle module with the request params", nk.module, nk.params)
+		}
+	})
+
+	t.Run("generates a battle id", func(t *testing.T) {
+		nk := &fakeMatchCreator{}
+		out, err := rpcCreateBattleMatch(context.Background(), fakeLogger{}, nil, nk, "")
+		if err != nil {
+			t.Fatalf("rpcCreateBattleMatch() error = %v", err)
+		}
+		var response createBattleMatchResponse
+		if err := json.Unmarshal([]byte(out), &response); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		if response.BattleID == "" || nk.params["battle_id"] != response.BattleID {
+			t.Errorf("battle id = %q, match param = %v, want the same generated id", response.BattleID, nk.params["battle_id"])
+		}
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		if _, err := rpcCreateBattleMatch(context.Background(), fakeLogger{}, nil, &fakeMatchCreator{}, `[1]`); err == nil {
+			t.Error("Expected an error for a non-object payload")
+		}
+	})
+}
+
+// fakeAccountUpdater records AccountUpdateId calls.
+type fakeAccountUpdater struct {
+	runtime.NakamaModule
+	userID      string
+	displayName string
+}
+
+func (m *fakeAccountUpdater) AccountUpdateId(ctx context.Context, userID, username string, metadata map[string]interface{}, displayName, timezone, location, langTag, avatarUrl string) error {
+	m.userID, m.displayName = userID, displayName
+	return nil
+}
+
+func TestRPCUpdateAccount(t *testing.T) {
+	t.Run("applies the changes", func(t *testing.T) {
+		nk := &fakeAccountUpdater{}
+		if _, err := rpcUpdateAccount(context.Background(), fakeLogger{}, nil, nk, `{"player_id":"player-1","display_name":"Alice"}`); err != nil {
+			t.Fatalf("rpcUpdateAccount() error = %v", err)
+		}
+		if nk.userID != "player-1" || nk.displayName != "Alice" {
+			t.Errorf("AccountUpdateId(%q, %q), want player-1 renamed Alice", nk.userID, nk.displayName)
+		}
+	})
+
+	t.Run("refuses client calls", func(t *testing.T) {
+		nk := &fakeAccountUpdater{}
+		ctx := context.WithValue(context.Background(), runtime.RUNTIME_CTX_USER_ID, "player-2")
+		if _, err := rpcUpdateAccount(ctx, fakeLogger{}, nil, nk, `{"player_id":"player-1","display_name":"Alice"}`); err == nil {
+			t.Error("Expected a call with a user session to be refused")
+		}
+		if nk.userID != "" {
+			t.Errorf("Expected no account update, got one for %q", nk.userID)
+		}
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		if _, err := rpcUpdateAccount(context.Background(), fakeLogger{}, nil, &fakeAccountUpdater{}, `{"display_name":"Alice"}`); err == nil {
+			t.Error("Expected an error for a payload without a player id")
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package tournament
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/app/tournaments"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// DefaultMaxAttemptsInFlight bounds concurrent TournamentAddAttempt calls when
+// NewBatchingProvider is given no limit.
+const DefaultMaxAttemptsInFlight = 8
+
+type attemptKey struct {
+	tournamentID shared.TournamentID
+	playerID     shared.PlayerID
+}
+
+// attemptBatch is the attempts coalesced into one provider call. Every caller
+// that contributed waits on done and receives err.
+type attemptBatch struct {
+	ctx     context.Context
+	count   int
+	callers int
+	done    chan struct{}
+	err     error
+}
+
+// attemptQueue holds the next batch for one player in one tournament while a
+// sender drains it.
+type attemptQueue struct {
+	next *attemptBatch
+}
+
+// BatchingProvider wraps a NakamaProvider so bursts of AddAttempt calls reach
+// Nakama as fewer, larger TournamentAddAttempt calls. A player's first grant
+// is sent at once; grants arriving while it is in flight, or within Window of
+// it finishing, are summed into the next call. At most MaxInFlight calls run
+// at a time across all tournaments. Other provider methods pass through.
+//
+// tournaments.Service calls AddAttempt once per grant and does no batching of
+// its own, so this is the only layer that coalesces. Each AddAttempt returns
+// the result of the call that carried its attempts.
+type BatchingProvider struct {
+	tournaments.NakamaProvider
+	window   time.Duration
+	inFlight chan struct{}
+
+	mu     sync.Mutex
+	queues map[attemptKey]*attemptQueue
+}
+
+// NewBatchingProvider wraps next. A non-positive window only coalesces grants
+// that arrive while a call is in flight; a non-positive maxInFlight falls back
+// to DefaultMaxAttemptsInFlight.
+func NewBatchingProvider(next tournaments.NakamaProvider, window time.Duration, maxInFlight int) *BatchingProvider {
+	if maxInFlight <= 0 {
+		maxInFlight = DefaultMaxAttemptsInFlight
+	}
+	return &BatchingProvider{
+		NakamaProvider: next,
+		window:         window,
+		inFlight:       make(chan struct{}, maxInFlight),
+		queues:         make(map[attemptKey]*attemptQueue),
+	}
+}
+
+// AddAttempt queues count attempts and waits for the call that carries them.
+// If ctx ends first it returns ctx.Err(), but the attempts are still sent.
+func (p *BatchingProvider) AddAttempt(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID, count int) error {
+	key := attemptKey{tournamentID: tournamentID, playerID: playerID}
+
+	p.mu.Lock()
+	queue, draining := p.queues[key]
+	if !draining {
+		queue = &attemptQueue{}
+		p.queues[key] = queue
+	}
+	if queue.next == nil {
+		// The batch outlives the request that opened it, so it keeps the
+		// request's values but not its cancellation.
+		queue.next = &attemptBatch{ctx: context.WithoutCancel(ctx), done: make(chan struct{})}
+	}
+	batch := queue.next
+	batch.count += count
+	batch.callers++
+	p.mu.Unlock()
+
+	if !draining {
+		go p.drain(key, queue)
+	}
+
+	select {
+	case <-batch.done:
+		return batch.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pending returns the number of AddAttempt calls waiting for a batch to be sent.
+func (p *BatchingProvider) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, queue := range p.queues {
+		if queue.next != nil {
+			n += queue.next.callers
+		}
+	}
+	return n
+}
+
+// drain sends a player's batches one at a time until none is left, then
+// retires the queue.
+func (p *BatchingProvider) drain(key attemptKey, queue *attemptQueue) {
+	for {
+		p.inFlight <- struct{}{}
+
+		p.mu.Lock()
+		batch := queue.next
+		if batch == nil {
+			delete(p.queues, key)
+			p.mu.Unlock()
+			<-p.inFlight
+			return
+		}
+		queue.next = nil
+		p.mu.Unlock()
+
+		batch.err = p.NakamaProvider.AddAttempt(batch.ctx, key.tournamentID, key.playerID, batch.count)
+		<-p.inFlight
+		close(batch.done)
+
+		if p.window > 0 {
+			time.Sleep(p.window)
+		}
+	}
+}
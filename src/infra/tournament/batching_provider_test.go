@@ -0,0 +1,153 @@
+package tournament_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/app/tournaments"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/infra/tournament"
+)
+
+// gatedProvider records AddAttempt calls and holds each one until release is
+// closed or sent to.
+type gatedProvider struct {
+	tournaments.NakamaProvider
+	release chan struct{}
+	err     error
+
+	mu        sync.Mutex
+	calls     []string
+	active    int
+	maxActive int
+}
+
+func newGatedProvider() *gatedProvider {
+	return &gatedProvider{release: make(chan struct{})}
+}
+
+func (p *gatedProvider) AddAttempt(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID, count int) error {
+	p.mu.Lock()
+	p.calls = append(p.calls, fmt.Sprintf("%s:%d", playerID, count))
+	p.active++
+	p.maxActive = max(p.maxActive, p.active)
+	p.mu.Unlock()
+
+	<-p.release
+
+	p.mu.Lock()
+	p.active--
+	p.mu.Unlock()
+	return p.err
+}
+
+func (p *gatedProvider) snapshot() (calls []string, active, maxActive int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.calls...), p.active, p.maxActive
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBatchingProvider_CoalescesBurst(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "success"},
+		{name: "failure reaches every caller", err: errors.New("nakama down")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			next := newGatedProvider()
+			next.err = tt.err
+			provider := tournament.NewBatchingProvider(next, 0, 0)
+
+			const burst = 10
+			errs := make(chan error, burst)
+			go func() { errs <- provider.AddAttempt(ctx, "tournament-1", "player-1", 1) }()
+			waitFor(t, func() bool { calls, _, _ := next.snapshot(); return len(calls) == 1 })
+
+			for i := 1; i < burst; i++ {
+				go func() { errs <- provider.AddAttempt(ctx, "tournament-1", "player-1", 2) }()
+			}
+			waitFor(t, func() bool { return provider.Pending() == burst-1 })
+			close(next.release)
+
+			for i := 0; i < burst; i++ {
+				if err := <-errs; !errors.Is(err, tt.err) {
+					t.Errorf("AddAttempt() error = %v, wantErr %v", err, tt.err)
+				}
+			}
+			calls, _, _ := next.snapshot()
+			if want := []string{"player-1:1", "player-1:18"}; !reflect.DeepEqual(calls, want) {
+				t.Errorf("provider calls = %v, want %v", calls, want)
+			}
+		})
+	}
+}
+
+func TestBatchingProvider_SeparatesPlayers(t *testing.T) {
+	ctx := context.Background()
+	next := newGatedProvider()
+	close(next.release)
+	provider := tournament.NewBatchingProvider(next, 0, 0)
+
+	for _, player := range []shared.PlayerID{"player-1", "player-2", "player-3"} {
+		if err := provider.AddAttempt(ctx, "tournament-1", player, 1); err != nil {
+			t.Fatalf("AddAttempt() error = %v", err)
+		}
+	}
+	calls, _, _ := next.snapshot()
+	if want := []string{"player-1:1", "player-2:1", "player-3:1"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("provider calls = %v, want %v", calls, want)
+	}
+}
+
+func TestBatchingProvider_MaxInFlight(t *testing.T) {
+	ctx := context.Background()
+	next := newGatedProvider()
+	provider := tournament.NewBatchingProvider(next, 0, 2)
+
+	const players = 5
+	var wg sync.WaitGroup
+	for i := 0; i < players; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := provider.AddAttempt(ctx, "tournament-1", shared.PlayerID(fmt.Sprintf("player-%d", i)), 1); err != nil {
+				t.Errorf("AddAttempt() error = %v", err)
+			}
+		}()
+	}
+
+	waitFor(t, func() bool { _, active, _ := next.snapshot(); return active == 2 })
+	for i := 0; i < players; i++ {
+		next.release <- struct{}{}
+	}
+	wg.Wait()
+
+	calls, _, maxActive := next.snapshot()
+	if len(calls) != players {
+		t.Errorf("Expected %d provider calls, got %v", players, calls)
+	}
+	if maxActive != 2 {
+		t.Errorf("Expected at most 2 calls in flight, got %d", maxActive)
+	}
+}
@@ -0,0 +1,32 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// translateError maps Nakama errors onto domain errors, keeping the original
+// error in the chain.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if isResourceExhausted(err) {
+		return fmt.Errorf("%w: %w", shared.ErrRateLimited, err)
+	}
+	return err
+}
+
+func isResourceExhausted(err error) bool {
+	var runtimeErr *runtime.Error
+	if errors.As(err, &runtimeErr) {
+		return runtimeErr.Code == int(codes.ResourceExhausted)
+	}
+	return status.Code(err) == codes.ResourceExhausted
+}
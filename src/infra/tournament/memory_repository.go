@@ -2,6 +2,7 @@ package tournament
 
 import (
 	"context"
+	"sort"
 	"sync"
 
 	"github.com/heroiclabs/nakama/v3/src/domain/shared"
@@ -21,12 +22,24 @@ func NewMemoryRepository() *MemoryRepository {
 	}
 }
 
-// Save stores a tournament.
+// Save stores a tournament using optimistic locking. The tournament's Version
+// must match the stored version; on success it is incremented.
 func (r *MemoryRepository) Save(ctx context.Context, t *tournament.Tournament) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.tournaments[t.ID] = t
+	existing, exists := r.tournaments[t.ID]
+	switch {
+	case !exists && t.Version != 0:
+		return tournament.ErrConcurrentModification
+	case exists && t.Version == 0:
+		return tournament.ErrTournamentAlreadyExists
+	case exists && t.Version != existing.Version:
+		return tournament.ErrConcurrentModification
+	}
+
+	t.Version++
+	r.tournaments[t.ID] = cloneTournament(t)
 	return nil
 }
 
@@ -40,7 +53,17 @@ func (r *MemoryRepository) Get(ctx context.Context, id shared.TournamentID) (*to
 		return nil, tournament.ErrTournamentNotFound
 	}
 
-	return t, nil
+	return cloneTournament(t), nil
+}
+
+// cloneTournament copies a tournament so callers cannot mutate stored state without saving.
+func cloneTournament(t *tournament.Tournament) *tournament.Tournament {
+	c := *t
+	if t.EndTime != nil {
+		endTime := *t.EndTime
+		c.EndTime = &endTime
+	}
+	return &c
 }
 
 // Delete removes a tournament.
@@ -52,15 +75,31 @@ func (r *MemoryRepository) Delete(ctx context.Context, id shared.TournamentID) e
 	return nil
 }
 
-// List retrieves a paginated list of tournaments.
-func (r *MemoryRepository) List(ctx context.Context, limit, offset int) ([]*tournament.Tournament, error) {
+// List retrieves a paginated list of tournaments ordered by creation time,
+// with ties broken by ID so pages never overlap or skip entries.
+func (r *MemoryRepository) List(ctx context.Context, filter tournament.ListFilter, limit, offset int) ([]*tournament.Tournament, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	if limit <= 0 {
+		return []*tournament.Tournament{}, nil
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
 	tournaments := make([]*tournament.Tournament, 0, len(r.tournaments))
 	for _, t := range r.tournaments {
-		tournaments = append(tournaments, t)
+		if filter.Matches(t) {
+			tournaments = append(tournaments, t)
+		}
 	}
+	sort.Slice(tournaments, func(i, j int) bool {
+		if !tournaments[i].CreatedAt.Equal(tournaments[j].CreatedAt) {
+			return tournaments[i].CreatedAt.Before(tournaments[j].CreatedAt)
+		}
+		return tournaments[i].ID < tournaments[j].ID
+	})
 
 	// Apply pagination
 	start := offset
@@ -73,7 +112,25 @@ func (r *MemoryRepository) List(ctx context.Context, limit, offset int) ([]*tour
 		end = len(tournaments)
 	}
 
-	return tournaments[start:end], nil
+	page := make([]*tournament.Tournament, 0, end-start)
+	for _, t := range tournaments[start:end] {
+		page = append(page, cloneTournament(t))
+	}
+	return page, nil
+}
+
+// Count returns the number of stored tournaments that pass filter.
+func (r *MemoryRepository) Count(ctx context.Context, filter tournament.ListFilter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := 0
+	for _, t := range r.tournaments {
+		if filter.Matches(t) {
+			n++
+		}
+	}
+	return n, nil
 }
 
 // MemoryParticipantRepository implements ParticipantRepository using in-memory storage.
@@ -103,6 +160,19 @@ func (r *MemoryParticipantRepository) Save(ctx context.Context, p *tournament.Pa
 	return nil
 }
 
+// Create stores a participant unless one already exists for the same player.
+func (r *MemoryParticipantRepository) Create(ctx context.Context, p *tournament.Participant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := makeKey(p.TournamentID, p.PlayerID)
+	if _, exists := r.participants[key]; exists {
+		return tournament.ErrParticipantAlreadyJoined
+	}
+	r.participants[key] = p
+	return nil
+}
+
 // Get retrieves a participant.
 func (r *MemoryParticipantRepository) Get(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) (*tournament.Participant, error) {
 	r.mu.RLock()
@@ -132,6 +202,39 @@ func (r *MemoryParticipantRepository) ListByTournament(ctx context.Context, tour
 	return participants, nil
 }
 
+// ListByPlayer retrieves a paginated list of a player's participations, oldest join first.
+func (r *MemoryParticipantRepository) ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*tournament.Participant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	participants := make([]*tournament.Participant, 0)
+	for _, p := range r.participants {
+		if p.PlayerID == playerID {
+			participants = append(participants, p)
+		}
+	}
+
+	sort.Slice(participants, func(i, j int) bool {
+		if !participants[i].JoinedAt.Equal(participants[j].JoinedAt) {
+			return participants[i].JoinedAt.Before(participants[j].JoinedAt)
+		}
+		return participants[i].TournamentID < participants[j].TournamentID
+	})
+
+	// Apply pagination
+	start := offset
+	if start > len(participants) {
+		return []*tournament.Participant{}, nil
+	}
+
+	end := start + limit
+	if end > len(participants) {
+		end = len(participants)
+	}
+
+	return participants[start:end], nil
+}
+
 // Delete removes a participant.
 func (r *MemoryParticipantRepository) Delete(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error {
 	r.mu.Lock()
@@ -141,3 +244,16 @@ func (r *MemoryParticipantRepository) Delete(ctx context.Context, tournamentID s
 	delete(r.participants, key)
 	return nil
 }
+
+// DeleteByTournament removes every participant of a tournament.
+func (r *MemoryParticipantRepository) DeleteByTournament(ctx context.Context, tournamentID shared.TournamentID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, p := range r.participants {
+		if p.TournamentID == tournamentID {
+			delete(r.participants, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package tournament_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+	infraTournament "github.com/heroiclabs/nakama/v3/src/infra/tournament"
+)
+
+func newTestTournament(t *testing.T, id string, now time.Time) *tournament.Tournament {
+	t.Helper()
+	tour, err := tournament.NewTournament(
+		shared.TournamentID(id), "Test Tournament", "", 0,
+		tournament.SortOrderDescending, tournament.OperatorBest, "",
+		true, false, 0, 0, now, time.Hour, now,
+	)
+	if err != nil {
+		t.Fatalf("NewTournament() error = %v", err)
+	}
+	return tour
+}
+
+func TestMemoryRepository_SaveVersioned(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := infraTournament.NewMemoryRepository()
+
+	tour := newTestTournament(t, "tournament-1", now)
+	if err := repo.Save(ctx, tour); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if tour.Version != 1 {
+		t.Fatalf("Expected version 1 after first save, got %d", tour.Version)
+	}
+
+	loaded, err := repo.Get(ctx, tour.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	loaded.Title = "Renamed"
+	if err := repo.Save(ctx, loaded); err != nil {
+		t.Fatalf("Save() with current version error = %v", err)
+	}
+	if loaded.Version != 2 {
+		t.Errorf("Expected version 2 after second save, got %d", loaded.Version)
+	}
+
+	stored, err := repo.Get(ctx, tour.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if stored.Title != "Renamed" || stored.Version != 2 {
+		t.Errorf("Expected stored title %q at version 2, got %q at version %d", "Renamed", stored.Title, stored.Version)
+	}
+}
+
+func TestMemoryRepository_SaveStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := infraTournament.NewMemoryRepository()
+
+	if err := repo.Save(ctx, newTestTournament(t, "tournament-1", now)); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	first, _ := repo.Get(ctx, "tournament-1")
+	second, _ := repo.Get(ctx, "tournament-1")
+
+	first.Title = "First writer"
+	if err := repo.Save(ctx, first); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	second.Title = "Second writer"
+	if err := repo.Save(ctx, second); !errors.Is(err, tournament.ErrConcurrentModification) {
+		t.Fatalf("Expected ErrConcurrentModification, got %v", err)
+	}
+
+	stored, _ := repo.Get(ctx, "tournament-1")
+	if stored.Title != "First writer" {
+		t.Errorf("Expected first write to survive, got %q", stored.Title)
+	}
+}
+
+func TestMemoryRepository_SaveDuplicateNew(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := infraTournament.NewMemoryRepository()
+
+	if err := repo.Save(ctx, newTestTournament(t, "tournament-1", now)); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	err := repo.Save(ctx, newTestTournament(t, "tournament-1", now))
+	if !errors.Is(err, tournament.ErrTournamentAlreadyExists) {
+		t.Errorf("Expected ErrTournamentAlreadyExists, got %v", err)
+	}
+}
+
+func TestMemoryRepository_ListPagination(t *testing.T) {
+	ctx := context.Background()
+	repo := infraTournament.NewMemoryRepository()
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Pairs of tournaments share a creation time so ordering relies on the ID
+	// tie-break as well.
+	const total = 30
+	for i := 0; i < total; i++ {
+		tour := newTestTournament(t, fmt.Sprintf("tournament-%02d", i), base.Add(time.Duration(i/2)*time.Minute))
+		if err := repo.Save(ctx, tour); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	const pageSize = 7
+	for run := 0; run < 3; run++ {
+		var ids []shared.TournamentID
+		for offset := 0; offset < total+pageSize; offset += pageSize {
+			page, err := repo.List(ctx, tournament.ListFilter{}, pageSize, offset)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			for _, tour := range page {
+				ids = append(ids, tour.ID)
+			}
+		}
+		if len(ids) != total {
+			t.Fatalf("Expected %d tournaments across pages, got %d", total, len(ids))
+		}
+		for i, id := range ids {
+			if want := shared.TournamentID(fmt.Sprintf("tournament-%02d", i)); id != want {
+				t.Fatalf("Expected tournament %d to be %q, got %q", i, want, id)
+			}
+		}
+	}
+
+	for _, limit := range []int{0, -1} {
+		page, err := repo.List(ctx, tournament.ListFilter{}, limit, 0)
+		if err != nil || len(page) != 0 {
+			t.Errorf("List(%d, 0) = %d tournaments, %v; want none", limit, len(page), err)
+		}
+	}
+	if page, err := repo.List(ctx, tournament.ListFilter{}, pageSize, -5); err != nil || len(page) != pageSize || page[0].ID != "tournament-00" {
+		t.Errorf("List() with a negative offset should start at the first tournament, got %d, %v", len(page), err)
+	}
+}
@@ -0,0 +1,49 @@
+package tournament
+
+import (
+	"context"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+)
+
+// MemorySnapshotRepository implements tournament.SnapshotRepository using in-memory storage.
+type MemorySnapshotRepository struct {
+	mu        sync.RWMutex
+	snapshots map[shared.TournamentID]*tournament.StandingsSnapshot
+}
+
+// NewMemorySnapshotRepository creates a new in-memory snapshot repository.
+func NewMemorySnapshotRepository() *MemorySnapshotRepository {
+	return &MemorySnapshotRepository{
+		snapshots: make(map[shared.TournamentID]*tournament.StandingsSnapshot),
+	}
+}
+
+// Save stores a snapshot, replacing any earlier snapshot of the same tournament.
+func (r *MemorySnapshotRepository) Save(ctx context.Context, snapshot *tournament.StandingsSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.snapshots[snapshot.TournamentID] = cloneSnapshot(snapshot)
+	return nil
+}
+
+// Get retrieves the snapshot for a tournament.
+func (r *MemorySnapshotRepository) Get(ctx context.Context, tournamentID shared.TournamentID) (*tournament.StandingsSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot, exists := r.snapshots[tournamentID]
+	if !exists {
+		return nil, tournament.ErrSnapshotNotFound
+	}
+	return cloneSnapshot(snapshot), nil
+}
+
+func cloneSnapshot(s *tournament.StandingsSnapshot) *tournament.StandingsSnapshot {
+	clone := *s
+	clone.Standings = append([]tournament.Standing(nil), s.Standings...)
+	return &clone
+}
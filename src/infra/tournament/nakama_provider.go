@@ -20,7 +20,7 @@ func NewNakamaProvider(nk runtime.NakamaModule) *NakamaProviderImpl {
 
 // CreateTournament creates a tournament in Nakama.
 func (p *NakamaProviderImpl) CreateTournament(ctx context.Context, params tournaments.CreateTournamentParams) error {
-	return p.nk.TournamentCreate(
+	return translateError(p.nk.TournamentCreate(
 		ctx,
 		params.ID,
 		params.Authoritative,
@@ -37,16 +37,78 @@ func (p *NakamaProviderImpl) CreateTournament(ctx context.Context, params tourna
 		params.MaxSize,
 		params.MaxNumScore,
 		params.JoinRequired,
-		false, // enableRanks
-	)
+		params.EnableRanks,
+	))
 }
 
 // DeleteTournament deletes a tournament from Nakama.
 func (p *NakamaProviderImpl) DeleteTournament(ctx context.Context, id shared.TournamentID) error {
-	return p.nk.TournamentDelete(ctx, string(id))
+	return translateError(p.nk.TournamentDelete(ctx, string(id)))
 }
 
 // AddAttempt adds attempts for a player in a tournament.
 func (p *NakamaProviderImpl) AddAttempt(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID, count int) error {
-	return p.nk.TournamentAddAttempt(ctx, string(tournamentID), string(playerID), count)
+	return translateError(p.nk.TournamentAddAttempt(ctx, string(tournamentID), string(playerID), count))
+}
+
+// JoinTournament joins a player to a tournament in Nakama. Nakama records the
+// join under the player's username, so the account is looked up first.
+func (p *NakamaProviderImpl) JoinTournament(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error {
+	users, err := p.nk.UsersGetId(ctx, []string{string(playerID)}, nil)
+	if err != nil {
+		return translateError(err)
+	}
+	if len(users) == 0 {
+		return shared.ErrNotFound
+	}
+	return translateError(p.nk.TournamentJoin(ctx, string(tournamentID), string(playerID), users[0].GetUsername()))
+}
+
+// ListRecords lists ranked records for a tournament in Nakama.
+func (p *NakamaProviderImpl) ListRecords(ctx context.Context, tournamentID shared.TournamentID, limit int, cursor string) (tournaments.RecordList, error) {
+	records, _, prevCursor, nextCursor, err := p.nk.TournamentRecordsList(ctx, string(tournamentID), nil, limit, cursor, 0)
+	if err != nil {
+		return tournaments.RecordList{}, translateError(err)
+	}
+
+	result := tournaments.RecordList{
+		Records:    make([]tournaments.Record, 0, len(records)),
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	}
+	for _, record := range records {
+		result.Records = append(result.Records, tournaments.Record{
+			OwnerID:  record.GetOwnerId(),
+			Username: record.GetUsername().GetValue(),
+			Score:    record.GetScore(),
+			Subscore: record.GetSubscore(),
+			NumScore: int(record.GetNumScore()),
+			Rank:     record.GetRank(),
+		})
+	}
+	return result, nil
+}
+
+// tournamentListPageSize is the largest page Nakama's TournamentList accepts.
+const tournamentListPageSize = 100
+
+// ListTournamentIDs pages through every tournament in Nakama that has not ended.
+func (p *NakamaProviderImpl) ListTournamentIDs(ctx context.Context) ([]shared.TournamentID, error) {
+	var ids []shared.TournamentID
+	cursor := ""
+	for {
+		// Categories 0-127 cover every tournament; an end time of -1 keeps
+		// open-ended and not yet ended ones.
+		list, err := p.nk.TournamentList(ctx, 0, 127, -1, -1, tournamentListPageSize, cursor)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		for _, t := range list.GetTournaments() {
+			ids = append(ids, shared.TournamentID(t.GetId()))
+		}
+		if list.GetCursor() == "" || list.GetCursor() == cursor {
+			return ids, nil
+		}
+		cursor = list.GetCursor()
+	}
 }
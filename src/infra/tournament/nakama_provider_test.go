@@ -0,0 +1,97 @@
+package tournament_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/heroiclabs/nakama/v3/src/app/tournaments"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/infra/tournament"
+)
+
+// fakeNakamaModule implements the tournament calls used by the provider.
+type fakeNakamaModule struct {
+	runtime.NakamaModule
+	err       error
+	usernames map[string]string
+	joinedAs  string
+}
+
+func (m *fakeNakamaModule) TournamentCreate(ctx context.Context, id string, authoritative bool, sortOrder, operator, resetSchedule string, metadata map[string]interface{}, title, description string, category, startTime, endTime, duration, maxSize, maxNumScore int, joinRequired, enableRanks bool) error {
+	return m.err
+}
+
+func (m *fakeNakamaModule) TournamentDelete(ctx context.Context, id string) error {
+	return m.err
+}
+
+func (m *fakeNakamaModule) UsersGetId(ctx context.Context, userIDs []string, facebookIDs []string) ([]*api.User, error) {
+	users := make([]*api.User, 0, len(userIDs))
+	for _, id := range userIDs {
+		if username, ok := m.usernames[id]; ok {
+			users = append(users, &api.User{Id: id, Username: username})
+		}
+	}
+	return users, nil
+}
+
+func (m *fakeNakamaModule) TournamentJoin(ctx context.Context, id, ownerID, username string) error {
+	m.joinedAs = username
+	return m.err
+}
+
+func TestNakamaProvider_TranslatesRateLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantRateLimit bool
+	}{
+		{name: "success", err: nil},
+		{name: "grpc resource exhausted", err: status.Error(codes.ResourceExhausted, "slow down"), wantRateLimit: true},
+		{name: "runtime resource exhausted", err: runtime.NewError("slow down", int(codes.ResourceExhausted)), wantRateLimit: true},
+		{name: "other grpc code", err: status.Error(codes.NotFound, "missing")},
+		{name: "plain error", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := tournament.NewNakamaProvider(&fakeNakamaModule{err: tt.err})
+
+			err := provider.CreateTournament(context.Background(), tournaments.CreateTournamentParams{ID: "tournament-123"})
+			if (err != nil) != (tt.err != nil) {
+				t.Fatalf("CreateTournament() error = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("Expected original error %v to be preserved, got %v", tt.err, err)
+			}
+			if got := errors.Is(err, shared.ErrRateLimited); got != tt.wantRateLimit {
+				t.Errorf("errors.Is(err, ErrRateLimited) = %v, want %v", got, tt.wantRateLimit)
+			}
+
+			if err := provider.DeleteTournament(context.Background(), "tournament-123"); errors.Is(err, shared.ErrRateLimited) != tt.wantRateLimit {
+				t.Errorf("DeleteTournament() error = %v, wantRateLimit %v", err, tt.wantRateLimit)
+			}
+		})
+	}
+}
+
+func TestNakamaProvider_JoinTournament(t *testing.T) {
+	module := &fakeNakamaModule{usernames: map[string]string{"player-1": "alice"}}
+	provider := tournament.NewNakamaProvider(module)
+
+	if err := provider.JoinTournament(context.Background(), "tournament-123", "player-1"); err != nil {
+		t.Fatalf("JoinTournament() error = %v", err)
+	}
+	if module.joinedAs != "alice" {
+		t.Errorf("Expected join as %q, got %q", "alice", module.joinedAs)
+	}
+	if err := provider.JoinTournament(context.Background(), "tournament-123", "player-9"); !errors.Is(err, shared.ErrNotFound) {
+		t.Errorf("JoinTournament() unknown player error = %v, want %v", err, shared.ErrNotFound)
+	}
+}
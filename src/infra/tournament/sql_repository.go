@@ -0,0 +1,372 @@
+package tournament
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+)
+
+// tournamentTableSchema and participantTableSchema are valid for both
+// PostgreSQL and CockroachDB. Durations are stored in nanoseconds.
+const (
+	tournamentTableSchema = `
+CREATE TABLE IF NOT EXISTS sandai_tournaments (
+	id             VARCHAR(128) PRIMARY KEY,
+	title          VARCHAR(255) NOT NULL,
+	description    TEXT         NOT NULL DEFAULT '',
+	category       INTEGER      NOT NULL DEFAULT 0,
+	sort_order     VARCHAR(16)  NOT NULL,
+	operator       VARCHAR(16)  NOT NULL,
+	reset_schedule VARCHAR(64)  NOT NULL DEFAULT '',
+	authoritative  BOOLEAN      NOT NULL DEFAULT FALSE,
+	join_required  BOOLEAN      NOT NULL DEFAULT FALSE,
+	max_size       INTEGER      NOT NULL DEFAULT 0,
+	max_num_score  INTEGER      NOT NULL DEFAULT 0,
+	start_time     TIMESTAMPTZ  NOT NULL,
+	end_time       TIMESTAMPTZ,
+	duration       BIGINT       NOT NULL DEFAULT 0,
+	state          VARCHAR(16)  NOT NULL,
+	join_opens_at  TIMESTAMPTZ,
+	join_closes_at TIMESTAMPTZ,
+	version        BIGINT       NOT NULL,
+	created_at     TIMESTAMPTZ  NOT NULL,
+	updated_at     TIMESTAMPTZ  NOT NULL
+)`
+	participantTableSchema = `
+CREATE TABLE IF NOT EXISTS sandai_tournament_participants (
+	tournament_id VARCHAR(128) NOT NULL,
+	player_id     VARCHAR(128) NOT NULL,
+	attempts      INTEGER      NOT NULL DEFAULT 0,
+	joined_at     TIMESTAMPTZ  NOT NULL,
+	updated_at    TIMESTAMPTZ  NOT NULL,
+	PRIMARY KEY (tournament_id, player_id)
+)`
+)
+
+// tournamentMigrations bring tables created by an earlier release up to the
+// current schema. Each statement must be idempotent; append new ones at the
+// end and never edit a released one.
+var tournamentMigrations = []string{
+	`ALTER TABLE sandai_tournaments ADD COLUMN IF NOT EXISTS cancel_reason TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE sandai_tournaments ADD COLUMN IF NOT EXISTS joined INTEGER NOT NULL DEFAULT 0`,
+}
+
+// tournamentColumns is the column list every tournament SELECT reads, in the
+// order scanTournament expects.
+const tournamentColumns = `id, title, description, category, sort_order, operator, reset_schedule,
+	authoritative, join_required, max_size, max_num_score, start_time, end_time, duration, state,
+	join_opens_at, join_closes_at, version, created_at, updated_at, cancel_reason, joined`
+
+// CreateTables creates the tables SQLRepository, SQLParticipantRepository and
+// SQLSnapshotRepository use if they do not exist yet and applies tournamentMigrations. It is safe to
+// call on every start.
+func CreateTables(ctx context.Context, db *sql.DB) error {
+	statements := append([]string{tournamentTableSchema, participantTableSchema, snapshotTableSchema}, tournamentMigrations...)
+	for _, schema := range statements {
+		if _, err := db.ExecContext(ctx, schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SQLRepository implements tournament.Repository on the database Nakama hands
+// to the runtime, so tournaments survive a restart and are shared by every
+// node. Save keeps the memory repository's optimistic locking.
+type SQLRepository struct {
+	db *sql.DB
+}
+
+// NewSQLRepository creates a repository over db. Call CreateTables before
+// first use.
+func NewSQLRepository(db *sql.DB) *SQLRepository {
+	return &SQLRepository{db: db}
+}
+
+// Save inserts a new tournament (Version 0) or updates a stored one whose
+// version matches, incrementing Version on success.
+func (r *SQLRepository) Save(ctx context.Context, t *tournament.Tournament) error {
+	args := []any{
+		string(t.ID), t.Title, t.Description, t.Category, string(t.SortOrder), string(t.Operator), t.ResetSchedule,
+		t.Authoritative, t.JoinRequired, t.MaxSize, t.MaxNumScore, t.StartTime.UTC(), nullTime(t.EndTime),
+		int64(t.Duration), string(t.State), nullTime(t.JoinOpensAt), nullTime(t.JoinClosesAt),
+		t.Version + 1, t.CreatedAt.UTC(), t.UpdatedAt.UTC(), t.CancelReason, t.Joined,
+	}
+
+	var (
+		result sql.Result
+		err    error
+	)
+	if t.Version == 0 {
+		result, err = r.db.ExecContext(ctx, `
+INSERT INTO sandai_tournaments (`+tournamentColumns+`)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+ON CONFLICT (id) DO NOTHING`, args...)
+	} else {
+		result, err = r.db.ExecContext(ctx, `
+UPDATE sandai_tournaments SET
+	title = $2, description = $3, category = $4, sort_order = $5, operator = $6, reset_schedule = $7,
+	authoritative = $8, join_required = $9, max_size = $10, max_num_score = $11, start_time = $12,
+	end_time = $13, duration = $14, state = $15, join_opens_at = $16, join_closes_at = $17,
+	version = $18, created_at = $19, updated_at = $20, cancel_reason = $21, joined = $22
+WHERE id = $1 AND version = $23`, append(args, t.Version)...)
+	}
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		if t.Version == 0 {
+			return tournament.ErrTournamentAlreadyExists
+		}
+		return tournament.ErrConcurrentModification
+	}
+
+	t.Version++
+	return nil
+}
+
+// Get retrieves a tournament by ID.
+func (r *SQLRepository) Get(ctx context.Context, id shared.TournamentID) (*tournament.Tournament, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+tournamentColumns+` FROM sandai_tournaments WHERE id = $1`, string(id))
+	t, err := scanTournament(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, tournament.ErrTournamentNotFound
+	}
+	return t, err
+}
+
+// Delete removes a tournament. Deleting a missing tournament is not an error.
+func (r *SQLRepository) Delete(ctx context.Context, id shared.TournamentID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM sandai_tournaments WHERE id = $1`, string(id))
+	return err
+}
+
+// List retrieves a paginated list of tournaments ordered by creation time,
+// then ID, matching MemoryRepository.
+func (r *SQLRepository) List(ctx context.Context, filter tournament.ListFilter, limit, offset int) ([]*tournament.Tournament, error) {
+	if limit <= 0 {
+		return []*tournament.Tournament{}, nil
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+tournamentColumns+`
+FROM sandai_tournaments
+WHERE $1 OR state <> $2
+ORDER BY created_at, id
+LIMIT $3 OFFSET $4`, filter.IncludeCancelled, string(tournament.StateCancelled), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tournaments := make([]*tournament.Tournament, 0, limit)
+	for rows.Next() {
+		t, err := scanTournament(rows)
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, t)
+	}
+	return tournaments, rows.Err()
+}
+
+// Count returns the number of stored tournaments that pass filter.
+func (r *SQLRepository) Count(ctx context.Context, filter tournament.ListFilter) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sandai_tournaments WHERE $1 OR state <> $2`,
+		filter.IncludeCancelled, string(tournament.StateCancelled)).Scan(&n)
+	return n, err
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTournament(row rowScanner) (*tournament.Tournament, error) {
+	var (
+		t                                  tournament.Tournament
+		id, sortOrder, operator, state     string
+		duration                           int64
+		endTime, joinOpensAt, joinClosesAt sql.NullTime
+	)
+	err := row.Scan(
+		&id, &t.Title, &t.Description, &t.Category, &sortOrder, &operator, &t.ResetSchedule,
+		&t.Authoritative, &t.JoinRequired, &t.MaxSize, &t.MaxNumScore, &t.StartTime, &endTime, &duration, &state,
+		&joinOpensAt, &joinClosesAt, &t.Version, &t.CreatedAt, &t.UpdatedAt, &t.CancelReason, &t.Joined,
+	)
+	if err != nil {
+		return nil, err
+	}
+	t.ID = shared.TournamentID(id)
+	t.SortOrder = tournament.SortOrder(sortOrder)
+	t.Operator = tournament.Operator(operator)
+	t.State = tournament.TournamentState(state)
+	t.Duration = time.Duration(duration)
+	t.StartTime = t.StartTime.UTC()
+	t.CreatedAt = t.CreatedAt.UTC()
+	t.UpdatedAt = t.UpdatedAt.UTC()
+	t.EndTime = timePtr(endTime)
+	t.JoinOpensAt = timePtr(joinOpensAt)
+	t.JoinClosesAt = timePtr(joinClosesAt)
+	return &t, nil
+}
+
+// SQLParticipantRepository implements tournament.ParticipantRepository on the
+// runtime database. Save is an upsert keyed by tournament and player.
+type SQLParticipantRepository struct {
+	db *sql.DB
+}
+
+// NewSQLParticipantRepository creates a repository over db. Call CreateTables
+// before first use.
+func NewSQLParticipantRepository(db *sql.DB) *SQLParticipantRepository {
+	return &SQLParticipantRepository{db: db}
+}
+
+// Save stores a participant, replacing any earlier row for the same player.
+func (r *SQLParticipantRepository) Save(ctx context.Context, p *tournament.Participant) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO sandai_tournament_participants (tournament_id, player_id, attempts, joined_at, updated_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (tournament_id, player_id) DO UPDATE SET
+	attempts = EXCLUDED.attempts,
+	joined_at = EXCLUDED.joined_at,
+	updated_at = EXCLUDED.updated_at`,
+		string(p.TournamentID), string(p.PlayerID), p.Attempts, p.JoinedAt.UTC(), p.UpdatedAt.UTC(),
+	)
+	return err
+}
+
+// Create inserts a participant, relying on the primary key so that only one
+// of several concurrent joins for the same player succeeds.
+func (r *SQLParticipantRepository) Create(ctx context.Context, p *tournament.Participant) error {
+	result, err := r.db.ExecContext(ctx, `
+INSERT INTO sandai_tournament_participants (tournament_id, player_id, attempts, joined_at, updated_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (tournament_id, player_id) DO NOTHING`,
+		string(p.TournamentID), string(p.PlayerID), p.Attempts, p.JoinedAt.UTC(), p.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return tournament.ErrParticipantAlreadyJoined
+	}
+	return nil
+}
+
+// Get retrieves a participant.
+func (r *SQLParticipantRepository) Get(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) (*tournament.Participant, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT tournament_id, player_id, attempts, joined_at, updated_at
+FROM sandai_tournament_participants
+WHERE tournament_id = $1 AND player_id = $2`, string(tournamentID), string(playerID))
+	p, err := scanParticipant(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, tournament.ErrParticipantNotFound
+	}
+	return p, err
+}
+
+// ListByTournament retrieves all participants for a tournament, oldest join first.
+func (r *SQLParticipantRepository) ListByTournament(ctx context.Context, tournamentID shared.TournamentID) ([]*tournament.Participant, error) {
+	return r.query(ctx, `
+SELECT tournament_id, player_id, attempts, joined_at, updated_at
+FROM sandai_tournament_participants
+WHERE tournament_id = $1
+ORDER BY joined_at, player_id`, string(tournamentID))
+}
+
+// ListByPlayer retrieves a paginated list of a player's participations, oldest join first.
+func (r *SQLParticipantRepository) ListByPlayer(ctx context.Context, playerID shared.PlayerID, limit, offset int) ([]*tournament.Participant, error) {
+	if limit <= 0 {
+		return []*tournament.Participant{}, nil
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return r.query(ctx, `
+SELECT tournament_id, player_id, attempts, joined_at, updated_at
+FROM sandai_tournament_participants
+WHERE player_id = $1
+ORDER BY joined_at, tournament_id
+LIMIT $2 OFFSET $3`, string(playerID), limit, offset)
+}
+
+// Delete removes a participant. Deleting a missing participant is not an error.
+func (r *SQLParticipantRepository) Delete(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error {
+	_, err := r.db.ExecContext(ctx, `
+DELETE FROM sandai_tournament_participants WHERE tournament_id = $1 AND player_id = $2`,
+		string(tournamentID), string(playerID))
+	return err
+}
+
+// DeleteByTournament removes every participant of a tournament.
+func (r *SQLParticipantRepository) DeleteByTournament(ctx context.Context, tournamentID shared.TournamentID) error {
+	_, err := r.db.ExecContext(ctx, `
+DELETE FROM sandai_tournament_participants WHERE tournament_id = $1`, string(tournamentID))
+	return err
+}
+
+func (r *SQLParticipantRepository) query(ctx context.Context, query string, args ...any) ([]*tournament.Participant, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	participants := make([]*tournament.Participant, 0)
+	for rows.Next() {
+		p, err := scanParticipant(rows)
+		if err != nil {
+			return nil, err
+		}
+		participants = append(participants, p)
+	}
+	return participants, rows.Err()
+}
+
+func scanParticipant(row rowScanner) (*tournament.Participant, error) {
+	var (
+		p                      tournament.Participant
+		tournamentID, playerID string
+	)
+	if err := row.Scan(&tournamentID, &playerID, &p.Attempts, &p.JoinedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	p.TournamentID = shared.TournamentID(tournamentID)
+	p.PlayerID = shared.PlayerID(playerID)
+	p.JoinedAt = p.JoinedAt.UTC()
+	p.UpdatedAt = p.UpdatedAt.UTC()
+	return &p, nil
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
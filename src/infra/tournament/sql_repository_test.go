@@ -0,0 +1,235 @@
+package tournament_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+	infraTournament "github.com/heroiclabs/nakama/v3/src/infra/tournament"
+	"github.com/heroiclabs/nakama/v3/src/testsupport"
+)
+
+// tournamentVersionColumn is the index of version in a tournament row.
+const tournamentVersionColumn = 17
+
+// openTestDB returns a fake database that understands just the single-row
+// statements the SQL repositories issue, keeping rows in memory. It checks
+// versions the way the UPDATE's WHERE clause does.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	var (
+		tournaments  = make(map[driver.Value][]driver.Value)
+		participants = make(map[string][]driver.Value)
+		snapshots    = make(map[driver.Value][]driver.Value)
+	)
+	participantKey := func(args []driver.Value) string {
+		return fmt.Sprintf("%s/%s", args[0], args[1])
+	}
+	lookup := func(rows map[driver.Value][]driver.Value) testsupport.QueryHandler {
+		return func(args []driver.Value) ([][]driver.Value, error) {
+			if row, ok := rows[args[0]]; ok {
+				return [][]driver.Value{row}, nil
+			}
+			return nil, nil
+		}
+	}
+	noop := func([]driver.Value) (int64, error) { return 0, nil }
+
+	fake := &testsupport.FakeSQL{}
+	fake.OnExec("CREATE TABLE IF NOT EXISTS", noop)
+	fake.OnExec("ALTER TABLE sandai_tournaments ADD COLUMN IF NOT EXISTS", noop)
+	fake.OnExec("INSERT INTO sandai_tournaments (", func(args []driver.Value) (int64, error) {
+		if _, exists := tournaments[args[0]]; exists {
+			return 0, nil
+		}
+		tournaments[args[0]] = args
+		return 1, nil
+	})
+	fake.OnExec("UPDATE sandai_tournaments SET", func(args []driver.Value) (int64, error) {
+		row, exists := tournaments[args[0]]
+		if !exists || row[tournamentVersionColumn] != args[len(args)-1] {
+			return 0, nil
+		}
+		tournaments[args[0]] = args[:len(args)-1]
+		return 1, nil
+	})
+	fake.OnExec("ON CONFLICT (tournament_id, player_id) DO NOTHING", func(args []driver.Value) (int64, error) {
+		if _, exists := participants[participantKey(args)]; exists {
+			return 0, nil
+		}
+		participants[participantKey(args)] = args
+		return 1, nil
+	})
+	fake.OnExec("INSERT INTO sandai_tournament_participants", func(args []driver.Value) (int64, error) {
+		participants[participantKey(args)] = args
+		return 1, nil
+	})
+	fake.OnExec("DELETE FROM sandai_tournament_participants WHERE tournament_id = $1 AND player_id = $2", func(args []driver.Value) (int64, error) {
+		delete(participants, participantKey(args))
+		return 1, nil
+	})
+	fake.OnExec("INSERT INTO sandai_tournament_snapshots", func(args []driver.Value) (int64, error) {
+		snapshots[args[0]] = args[1:]
+		return 1, nil
+	})
+	fake.OnQuery("FROM sandai_tournaments WHERE id = $1", 22, lookup(tournaments))
+	fake.OnQuery("FROM sandai_tournament_participants WHERE tournament_id = $1 AND player_id = $2", 5, func(args []driver.Value) ([][]driver.Value, error) {
+		if row, ok := participants[participantKey(args)]; ok {
+			return [][]driver.Value{row}, nil
+		}
+		return nil, nil
+	})
+	fake.OnQuery("FROM sandai_tournament_snapshots WHERE tournament_id = $1", 3, lookup(snapshots))
+	return fake.Open(t)
+}
+
+func TestSQLRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	if err := infraTournament.CreateTables(ctx, db); err != nil {
+		t.Fatalf("CreateTables() error = %v", err)
+	}
+	repo := infraTournament.NewSQLRepository(db)
+	id := fmt.Sprintf("tournament-%d", time.Now().UnixNano())
+
+	if _, err := repo.Get(ctx, shared.TournamentID(id)); !errors.Is(err, tournament.ErrTournamentNotFound) {
+		t.Fatalf("Get() error = %v, want ErrTournamentNotFound", err)
+	}
+
+	opensAt := testsupport.DefaultTime
+	tour := testsupport.NewTournament(t, shared.TournamentID(id), testsupport.WithJoinWindow(&opensAt, nil))
+	if err := repo.Save(ctx, tour); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if tour.Version != 1 {
+		t.Fatalf("Expected version 1 after first save, got %d", tour.Version)
+	}
+
+	got, err := repo.Get(ctx, tour.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Title != tour.Title || got.SortOrder != tour.SortOrder || got.Operator != tour.Operator ||
+		got.Duration != tour.Duration || got.State != tour.State || got.Version != 1 {
+		t.Errorf("Get() = %+v, want the saved tournament", got)
+	}
+	if !got.StartTime.Equal(tour.StartTime) || got.EndTime != nil || got.JoinClosesAt != nil ||
+		got.JoinOpensAt == nil || !got.JoinOpensAt.Equal(opensAt) {
+		t.Errorf("Get() times = %v/%v/%v/%v, want the saved times", got.StartTime, got.EndTime, got.JoinOpensAt, got.JoinClosesAt)
+	}
+
+	duplicate := testsupport.NewTournament(t, shared.TournamentID(id))
+	if err := repo.Save(ctx, duplicate); !errors.Is(err, tournament.ErrTournamentAlreadyExists) {
+		t.Errorf("Save() duplicate error = %v, want ErrTournamentAlreadyExists", err)
+	}
+
+	got.Title = "Renamed"
+	if err := repo.Save(ctx, got); err != nil {
+		t.Fatalf("Save() with current version error = %v", err)
+	}
+	if got.Version != 2 {
+		t.Errorf("Expected version 2 after second save, got %d", got.Version)
+	}
+
+	// tour still carries version 1, so saving it would overwrite "Renamed".
+	if err := repo.Save(ctx, tour); !errors.Is(err, tournament.ErrConcurrentModification) {
+		t.Errorf("Save() stale error = %v, want ErrConcurrentModification", err)
+	}
+	if tour.Version != 1 {
+		t.Errorf("Expected stale version to stay 1, got %d", tour.Version)
+	}
+}
+
+func TestSQLParticipantRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	repo := infraTournament.NewSQLParticipantRepository(db)
+	tournamentID := shared.TournamentID(fmt.Sprintf("tournament-%d", time.Now().UnixNano()))
+
+	if _, err := repo.Get(ctx, tournamentID, "player-1"); !errors.Is(err, tournament.ErrParticipantNotFound) {
+		t.Fatalf("Get() error = %v, want ErrParticipantNotFound", err)
+	}
+
+	participant, err := tournament.NewParticipant(tournamentID, "player-1", testsupport.DefaultTime)
+	if err != nil {
+		t.Fatalf("NewParticipant() error = %v", err)
+	}
+	if err := repo.Create(ctx, participant); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := repo.Create(ctx, participant); !errors.Is(err, tournament.ErrParticipantAlreadyJoined) {
+		t.Errorf("Create() duplicate error = %v, want ErrParticipantAlreadyJoined", err)
+	}
+
+	// Saving again overwrites the row rather than failing on the primary key.
+	participant.Attempts = 3
+	participant.UpdatedAt = testsupport.DefaultTime.Add(time.Minute)
+	if err := repo.Save(ctx, participant); err != nil {
+		t.Fatalf("Save() upsert error = %v", err)
+	}
+	got, err := repo.Get(ctx, tournamentID, "player-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.TournamentID != tournamentID || got.PlayerID != "player-1" || got.Attempts != 3 {
+		t.Errorf("Get() = %+v, want the saved participant", got)
+	}
+	if !got.JoinedAt.Equal(testsupport.DefaultTime) || !got.UpdatedAt.Equal(participant.UpdatedAt) {
+		t.Errorf("Get() times = %v/%v, want %v/%v", got.JoinedAt, got.UpdatedAt, testsupport.DefaultTime, participant.UpdatedAt)
+	}
+
+	if err := repo.Delete(ctx, tournamentID, "player-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.Get(ctx, tournamentID, "player-1"); !errors.Is(err, tournament.ErrParticipantNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrParticipantNotFound", err)
+	}
+}
+
+func TestSQLSnapshotRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	if err := infraTournament.CreateTables(ctx, db); err != nil {
+		t.Fatalf("CreateTables() error = %v", err)
+	}
+	repo := infraTournament.NewSQLSnapshotRepository(db)
+	id := shared.TournamentID(fmt.Sprintf("tournament-%d", time.Now().UnixNano()))
+
+	if _, err := repo.Get(ctx, id); !errors.Is(err, tournament.ErrSnapshotNotFound) {
+		t.Fatalf("Get() error = %v, want ErrSnapshotNotFound", err)
+	}
+
+	snapshot := &tournament.StandingsSnapshot{
+		TournamentID: id,
+		Title:        "Weekly Cup",
+		Standings: []tournament.Standing{
+			{Rank: 1, OwnerID: "player-1", Username: "alice", Score: 120, Subscore: 3, NumScore: 2},
+			{Rank: 2, OwnerID: "player-2", Username: "bob", Score: 90, NumScore: 1},
+		},
+		TakenAt: testsupport.DefaultTime,
+	}
+	if err := repo.Save(ctx, snapshot); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	snapshot.Standings = snapshot.Standings[:1]
+	if err := repo.Save(ctx, snapshot); err != nil {
+		t.Fatalf("Save() replacing error = %v", err)
+	}
+
+	got, err := repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Title != "Weekly Cup" || !got.TakenAt.Equal(testsupport.DefaultTime) {
+		t.Errorf("Get() = %+v, want the saved title and time", got)
+	}
+	if len(got.Standings) != 1 || got.Standings[0] != snapshot.Standings[0] {
+		t.Errorf("Get() standings = %+v, want %+v", got.Standings, snapshot.Standings)
+	}
+}
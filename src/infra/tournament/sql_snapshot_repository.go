@@ -0,0 +1,94 @@
+package tournament
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+)
+
+// snapshotTableSchema stores one standings snapshot per tournament, with the
+// standings encoded as JSON.
+const snapshotTableSchema = `
+CREATE TABLE IF NOT EXISTS sandai_tournament_snapshots (
+	tournament_id VARCHAR(128) PRIMARY KEY,
+	title         VARCHAR(255) NOT NULL,
+	standings     JSONB        NOT NULL,
+	taken_at      TIMESTAMPTZ  NOT NULL
+)`
+
+// SQLSnapshotRepository implements tournament.SnapshotRepository on the
+// runtime database, so final standings outlive the process that took them
+// and can be read by every node.
+type SQLSnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewSQLSnapshotRepository creates a repository over db. Call CreateTables
+// before first use.
+func NewSQLSnapshotRepository(db *sql.DB) *SQLSnapshotRepository {
+	return &SQLSnapshotRepository{db: db}
+}
+
+// storedStanding is the JSON form of a tournament.Standing.
+type storedStanding struct {
+	Rank     int64  `json:"rank"`
+	OwnerID  string `json:"owner_id"`
+	Username string `json:"username"`
+	Score    int64  `json:"score"`
+	Subscore int64  `json:"subscore"`
+	NumScore int    `json:"num_score"`
+}
+
+// Save stores a snapshot, replacing any earlier snapshot of the same tournament.
+func (r *SQLSnapshotRepository) Save(ctx context.Context, snapshot *tournament.StandingsSnapshot) error {
+	standings := make([]storedStanding, 0, len(snapshot.Standings))
+	for _, s := range snapshot.Standings {
+		standings = append(standings, storedStanding(s))
+	}
+	body, err := json.Marshal(standings)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+INSERT INTO sandai_tournament_snapshots (tournament_id, title, standings, taken_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (tournament_id) DO UPDATE SET
+	title = EXCLUDED.title,
+	standings = EXCLUDED.standings,
+	taken_at = EXCLUDED.taken_at`,
+		string(snapshot.TournamentID), snapshot.Title, body, snapshot.TakenAt.UTC(),
+	)
+	return err
+}
+
+// Get retrieves the snapshot for a tournament.
+func (r *SQLSnapshotRepository) Get(ctx context.Context, tournamentID shared.TournamentID) (*tournament.StandingsSnapshot, error) {
+	var (
+		snapshot = tournament.StandingsSnapshot{TournamentID: tournamentID}
+		body     []byte
+	)
+	err := r.db.QueryRowContext(ctx, `
+SELECT title, standings, taken_at FROM sandai_tournament_snapshots WHERE tournament_id = $1`,
+		string(tournamentID)).Scan(&snapshot.Title, &body, &snapshot.TakenAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, tournament.ErrSnapshotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var standings []storedStanding
+	if err := json.Unmarshal(body, &standings); err != nil {
+		return nil, err
+	}
+	snapshot.Standings = make([]tournament.Standing, 0, len(standings))
+	for _, s := range standings {
+		snapshot.Standings = append(snapshot.Standings, tournament.Standing(s))
+	}
+	snapshot.TakenAt = snapshot.TakenAt.UTC()
+	return &snapshot, nil
+}
@@ -0,0 +1,42 @@
+package testsupport
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTime is the instant a new FakeClock starts at.
+var DefaultTime = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// FakeClock is a manually advanced clock. Pass its Now method wherever a
+// service takes a Clock func.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock creates a clock fixed at DefaultTime.
+func NewFakeClock() *FakeClock {
+	return &FakeClock{now: DefaultTime}
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
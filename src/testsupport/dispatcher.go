@@ -0,0 +1,53 @@
+package testsupport
+
+import (
+	"context"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/analytics"
+)
+
+// FakeDispatcher records every batch it is given and returns Err.
+type FakeDispatcher struct {
+	mu      sync.Mutex
+	batches [][]*analytics.Event
+
+	Err error
+}
+
+// Dispatch records the batch.
+func (d *FakeDispatcher) Dispatch(ctx context.Context, events []*analytics.Event) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.batches = append(d.batches, events)
+	return d.Err
+}
+
+// Batches returns the recorded batches in dispatch order.
+func (d *FakeDispatcher) Batches() [][]*analytics.Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([][]*analytics.Event(nil), d.batches...)
+}
+
+// BatchSizes returns the length of each recorded batch.
+func (d *FakeDispatcher) BatchSizes() []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	sizes := make([]int, 0, len(d.batches))
+	for _, batch := range d.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+// Events returns every recorded event, flattened across batches.
+func (d *FakeDispatcher) Events() []*analytics.Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var events []*analytics.Event
+	for _, batch := range d.batches {
+		events = append(events, batch...)
+	}
+	return events
+}
@@ -0,0 +1,4 @@
+// Package testsupport provides in-memory fakes, a controllable clock and
+// fixture builders shared by service tests. It is imported only from
+// _test.go files so none of it is linked into production binaries.
+package testsupport
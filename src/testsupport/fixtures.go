@@ -0,0 +1,74 @@
+package testsupport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/src/domain/player"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+	"github.com/heroiclabs/nakama/v3/src/domain/tournament"
+)
+
+// TournamentOption customizes a fixture tournament.
+type TournamentOption func(*tournament.Tournament)
+
+// WithResetSchedule sets the fixture's reset schedule.
+func WithResetSchedule(schedule string) TournamentOption {
+	return func(t *tournament.Tournament) { t.ResetSchedule = schedule }
+}
+
+// WithMaxSize sets the fixture's participant cap.
+func WithMaxSize(size int) TournamentOption {
+	return func(t *tournament.Tournament) { t.MaxSize = size }
+}
+
+// WithTitle sets the fixture's title.
+func WithTitle(title string) TournamentOption {
+	return func(t *tournament.Tournament) { t.Title = title }
+}
+
+// WithJoinWindow sets the fixture's join window bounds.
+func WithJoinWindow(opensAt, closesAt *time.Time) TournamentOption {
+	return func(t *tournament.Tournament) {
+		t.JoinOpensAt = opensAt
+		t.JoinClosesAt = closesAt
+	}
+}
+
+// NewTournament builds a valid active tournament starting at DefaultTime.
+func NewTournament(tb testing.TB, id shared.TournamentID, opts ...TournamentOption) *tournament.Tournament {
+	tb.Helper()
+	t, err := tournament.NewTournament(
+		id, "Fixture Cup", "", 1,
+		tournament.SortOrderDescending, tournament.OperatorBest,
+		"", true, false, 0, 0, DefaultTime, 0, DefaultTime,
+	)
+	if err != nil {
+		tb.Fatalf("tournament.NewTournament() error = %v", err)
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// SaveTournament builds a fixture tournament and stores it in repo.
+func SaveTournament(tb testing.TB, repo tournament.Repository, id shared.TournamentID, opts ...TournamentOption) *tournament.Tournament {
+	tb.Helper()
+	t := NewTournament(tb, id, opts...)
+	if err := repo.Save(context.Background(), t); err != nil {
+		tb.Fatalf("Save() error = %v", err)
+	}
+	return t
+}
+
+// NewAccount builds a valid player account created at DefaultTime.
+func NewAccount(tb testing.TB, id shared.PlayerID) *player.PlayerAccount {
+	tb.Helper()
+	acct, err := player.NewPlayerAccount(id, string(id)+"@example.com", string(id), DefaultTime)
+	if err != nil {
+		tb.Fatalf("player.NewPlayerAccount() error = %v", err)
+	}
+	return acct
+}
@@ -0,0 +1,134 @@
+package testsupport
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/heroiclabs/nakama/v3/src/app/tournaments"
+	"github.com/heroiclabs/nakama/v3/src/domain/shared"
+)
+
+// FakeNakamaProvider implements tournaments.NakamaProvider in memory. Created
+// tournaments, joins and attempts are recorded; the Err fields make the matching call fail.
+type FakeNakamaProvider struct {
+	mu         sync.Mutex
+	created    map[shared.TournamentID]tournaments.CreateTournamentParams
+	attempts   map[shared.TournamentID]map[shared.PlayerID]int
+	joined     map[shared.TournamentID]map[shared.PlayerID]bool
+	Records    map[shared.TournamentID]tournaments.RecordList
+	CreateErr  error
+	DeleteErr  error
+	AttemptErr error
+	JoinErr    error
+	ListErr    error
+}
+
+// NewFakeNakamaProvider creates an empty fake provider.
+func NewFakeNakamaProvider() *FakeNakamaProvider {
+	return &FakeNakamaProvider{
+		created:  make(map[shared.TournamentID]tournaments.CreateTournamentParams),
+		attempts: make(map[shared.TournamentID]map[shared.PlayerID]int),
+		joined:   make(map[shared.TournamentID]map[shared.PlayerID]bool),
+		Records:  make(map[shared.TournamentID]tournaments.RecordList),
+	}
+}
+
+// CreateTournament records the creation parameters.
+func (p *FakeNakamaProvider) CreateTournament(ctx context.Context, params tournaments.CreateTournamentParams) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.CreateErr != nil {
+		return p.CreateErr
+	}
+	p.created[shared.TournamentID(params.ID)] = params
+	return nil
+}
+
+// DeleteTournament forgets a created tournament.
+func (p *FakeNakamaProvider) DeleteTournament(ctx context.Context, id shared.TournamentID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.DeleteErr != nil {
+		return p.DeleteErr
+	}
+	delete(p.created, id)
+	delete(p.attempts, id)
+	delete(p.joined, id)
+	return nil
+}
+
+// AddAttempt accumulates attempts per player.
+func (p *FakeNakamaProvider) AddAttempt(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID, count int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.AttemptErr != nil {
+		return p.AttemptErr
+	}
+	if p.attempts[tournamentID] == nil {
+		p.attempts[tournamentID] = make(map[shared.PlayerID]int)
+	}
+	p.attempts[tournamentID][playerID] += count
+	return nil
+}
+
+// JoinTournament records the player as joined.
+func (p *FakeNakamaProvider) JoinTournament(ctx context.Context, tournamentID shared.TournamentID, playerID shared.PlayerID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.JoinErr != nil {
+		return p.JoinErr
+	}
+	if p.joined[tournamentID] == nil {
+		p.joined[tournamentID] = make(map[shared.PlayerID]bool)
+	}
+	p.joined[tournamentID][playerID] = true
+	return nil
+}
+
+// ListRecords returns the records configured for the tournament.
+func (p *FakeNakamaProvider) ListRecords(ctx context.Context, tournamentID shared.TournamentID, limit int, cursor string) (tournaments.RecordList, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ListErr != nil {
+		return tournaments.RecordList{}, p.ListErr
+	}
+	return p.Records[tournamentID], nil
+}
+
+// ListTournamentIDs returns the IDs of created tournaments in ID order.
+func (p *FakeNakamaProvider) ListTournamentIDs(ctx context.Context) ([]shared.TournamentID, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ListErr != nil {
+		return nil, p.ListErr
+	}
+	ids := make([]shared.TournamentID, 0, len(p.created))
+	for id := range p.created {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// Created returns the parameters a tournament was created with.
+func (p *FakeNakamaProvider) Created(id shared.TournamentID) (tournaments.CreateTournamentParams, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	params, ok := p.created[id]
+	return params, ok
+}
+
+// Attempts returns the attempts recorded for a player.
+func (p *FakeNakamaProvider) Attempts(tournamentID shared.TournamentID, playerID shared.PlayerID) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.attempts[tournamentID][playerID]
+}
+
+// Joined reports whether a player has joined a tournament.
+func (p *FakeNakamaProvider) Joined(tournamentID shared.TournamentID, playerID shared.PlayerID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.joined[tournamentID][playerID]
+}
@@ -0,0 +1,142 @@
+package testsupport
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ExecHandler answers a statement with the number of rows it affected.
+type ExecHandler func(args []driver.Value) (int64, error)
+
+// QueryHandler answers a query with its result rows.
+type QueryHandler func(args []driver.Value) ([][]driver.Value, error)
+
+// FakeSQL is an in-memory database/sql driver for testing SQL repositories
+// without a database server. Each statement is routed to the first handler
+// registered for a fragment it contains, compared with runs of whitespace
+// collapsed; a statement no handler matches fails. Handlers run one at a
+// time, so they may share state without locking.
+type FakeSQL struct {
+	mu      sync.Mutex
+	execs   []fakeExec
+	queries []fakeQuery
+}
+
+type fakeExec struct {
+	fragment string
+	handle   ExecHandler
+}
+
+type fakeQuery struct {
+	fragment string
+	columns  int
+	handle   QueryHandler
+}
+
+// OnExec routes statements containing fragment to h.
+func (f *FakeSQL) OnExec(fragment string, h ExecHandler) {
+	f.execs = append(f.execs, fakeExec{fragment: normalizeSQL(fragment), handle: h})
+}
+
+// OnQuery routes queries containing fragment to h, whose rows have the given
+// number of columns.
+func (f *FakeSQL) OnQuery(fragment string, columns int, h QueryHandler) {
+	f.queries = append(f.queries, fakeQuery{fragment: normalizeSQL(fragment), columns: columns, handle: h})
+}
+
+// Open returns a database handle backed by f, closed when the test ends.
+func (f *FakeSQL) Open(t testing.TB) *sql.DB {
+	t.Helper()
+	db := sql.OpenDB(f)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// Connect implements driver.Connector.
+func (f *FakeSQL) Connect(ctx context.Context) (driver.Conn, error) {
+	return fakeConn{f: f}, nil
+}
+
+// Driver implements driver.Connector.
+func (f *FakeSQL) Driver() driver.Driver { return fakeDriver{f: f} }
+
+type fakeDriver struct{ f *FakeSQL }
+
+func (d fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{f: d.f}, nil }
+
+type fakeConn struct{ f *FakeSQL }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c fakeConn) Close() error { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	query = normalizeSQL(query)
+	for _, e := range c.f.execs {
+		if strings.Contains(query, e.fragment) {
+			affected, err := e.handle(namedValues(args))
+			if err != nil {
+				return nil, err
+			}
+			return driver.RowsAffected(affected), nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected statement %q", query)
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	query = normalizeSQL(query)
+	for _, q := range c.f.queries {
+		if strings.Contains(query, q.fragment) {
+			values, err := q.handle(namedValues(args))
+			if err != nil {
+				return nil, err
+			}
+			return &fakeRows{columns: q.columns, values: values}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+type fakeRows struct {
+	columns int
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return make([]string, r.columns) }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	return values
+}
+
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}